@@ -0,0 +1,156 @@
+package poker
+
+import (
+	"time"
+)
+
+// ClockState is whether a timer's clock is counting down
+type ClockState string
+
+const (
+	ClockStateStopped ClockState = "stopped"
+	ClockStateRunning ClockState = "running"
+	ClockStatePaused  ClockState = "paused"
+)
+
+// AllClockStates is every state a clock can be in
+var AllClockStates = []ClockState{ClockStateStopped, ClockStateRunning, ClockStatePaused}
+
+// APITimer is a timer as the JSON API returns it
+type APITimer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Role is the role the token's user has on the timer
+	Role TimerRole `json:"role"`
+	// CurrentLevel is the 1 based position of the level the timer is on, 0 when it has no levels
+	CurrentLevel uint        `json:"current_level"`
+	IsComplete   bool        `json:"is_complete"`
+	Clock        APIClock    `json:"clock"`
+	Levels       []*APILevel `json:"levels"`
+	// Version is incremented every time the timer is saved
+	Version   uint      `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// APIClock is the state of a timer's clock as of when the response was made
+type APIClock struct {
+	State        ClockState `json:"state"`
+	ElapsedSec   float64    `json:"elapsed_sec"`
+	RemainingSec float64    `json:"remaining_sec"`
+}
+
+// APILevel is a level as the JSON API returns and accepts it. ID is ignored when a level is sent
+type APILevel struct {
+	ID string `json:"id,omitempty"`
+	// Position is the level's 1 based position. When a level is sent it is where the level is moved or inserted,
+	// 0 leaves an existing level where it is and appends a new one
+	Position   uint      `json:"position"`
+	Type       LevelType `json:"type"`
+	SmallBlind float64   `json:"small_blind"`
+	BigBlind   float64   `json:"big_blind"`
+	Ante       float64   `json:"ante"`
+	// AnteType is left out of breaks, when a level is sent without one it is traditional if there is an ante
+	AnteType    AnteType     `json:"ante_type,omitempty"`
+	DurationMin float64      `json:"duration_min"`
+	Events      []LevelEvent `json:"events,omitempty"`
+}
+
+// APITimerInput creates a timer, or renames one when only Name is sent
+type APITimerInput struct {
+	Name   string      `json:"name"`
+	Levels []*APILevel `json:"levels,omitempty"`
+}
+
+// APIError is the body of every failed API request
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// NewAPITimer describes the timer as of now for a user with role
+func NewAPITimer(timer *Timer, role TimerRole, now time.Time) *APITimer {
+
+	out := &APITimer{
+		ID:         timer.ID,
+		Name:       timer.Name,
+		Role:       role,
+		IsComplete: timer.IsComplete,
+		Levels:     make([]*APILevel, 0, len(timer.Levels)),
+		Version:    timer.Version,
+		CreatedAt:  timer.CreatedAt,
+		UpdatedAt:  timer.UpdatedAt,
+		Clock: APIClock{
+			State:        ClockStateStopped,
+			ElapsedSec:   timer.Elapsed(now).Seconds(),
+			RemainingSec: timer.Remaining(now).Seconds(),
+		},
+	}
+
+	if timer.IsRunning() {
+		out.Clock.State = ClockStateRunning
+	} else if timer.IsPaused() {
+		out.Clock.State = ClockStatePaused
+	}
+
+	if len(timer.Levels) > 0 {
+		out.CurrentLevel = timer.CurrentLevel + 1
+	}
+
+	for i, level := range timer.Levels {
+		out.Levels = append(out.Levels, NewAPILevel(level, i))
+	}
+
+	return out
+
+}
+
+// NewAPILevel describes the level found at index i of its timer
+func NewAPILevel(level *TimerLevel, i int) *APILevel {
+
+	return &APILevel{
+		ID:          level.ID,
+		Position:    uint(i + 1),
+		Type:        level.Type,
+		SmallBlind:  level.SmallBlind,
+		BigBlind:    level.BigBlind,
+		Ante:        level.Ante,
+		AnteType:    level.EffectiveAnteType(),
+		DurationMin: level.DurationMin,
+		Events:      level.Events,
+	}
+
+}
+
+// Apply copies what was sent onto level, leaving its id, timer and position alone. The ante type defaults the
+// same way the level form does, so a big blind ante without an amount is the big blind
+func (l *APILevel) Apply(level *TimerLevel) {
+
+	level.Type = l.Type
+	level.SmallBlind = l.SmallBlind
+	level.BigBlind = l.BigBlind
+	level.Ante = l.Ante
+	level.AnteType = l.AnteType
+	level.DurationMin = l.DurationMin
+	level.DurationSec = l.DurationMin * 60
+	level.DurationStr = ""
+	level.Events = l.Events
+
+	if level.Type != LevelTypeBlind {
+		level.SmallBlind, level.BigBlind, level.Ante, level.AnteType = 0, 0, 0, ""
+		return
+	}
+
+	switch level.AnteType {
+	case "":
+		if level.Ante > 0 {
+			level.AnteType = AnteTypeTraditional
+		} else {
+			level.AnteType = AnteTypeNone
+		}
+	case AnteTypeBigBlind:
+		if level.Ante == 0 {
+			level.Ante = level.BigBlind
+		}
+	}
+
+}
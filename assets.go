@@ -13,6 +13,11 @@ var assets embed.FS
 
 const assetsDirectory = "assets"
 
+// structures holds the built-in timer structures in the YAML timer format, see BuiltinStructures
+//
+//go:embed structures
+var structures embed.FS
+
 func AssetFS(environment Environment) fs.FS {
 
 	if !environment.IsProduction() {
@@ -1,2 +1,2 @@
-"use strict";(()=>{var d=class{constructor({initialValue:e,showHour:t,emitter:n,onComplete:i}){this.isRunning=!1;this.initialValue=e,this.showHour=t,this.emitter=n,this.onComplete=i,this.countdownValue=e,this.interval=null}decrementCountdown(){this.countdownValue--,this.emitter(this.countdownValue,this.format(this.countdownValue)),this.countdownValue===0&&(this.stop(),this.onComplete())}format(e){let t={hours:Math.floor(e/3600%24),minutes:Math.floor(e/60%60),seconds:Math.floor(e%60)},n=[];if(t.hours>0&&this.showHour){let i="";t.hours<10&&(i="0"),i=`${i}${t.hours}`}if(t.minutes==0)n.push("00");else if(t.minutes>0){let i="";t.minutes<10&&(i="0"),i=`${i}${t.minutes}`,n.push(i)}if(t.seconds==0)n.push("00");else if(t.seconds>0){let i="";t.seconds<10&&(i="0"),i=`${i}${t.seconds}`,n.push(i)}return n.join(":")}start(){if(console.debug("Countdown.start()"),this.interval&&(console.debug("Countdown.start() if this.interval"),this.reset()),this.countdownValue===0){console.error("countdownValue is 0, shutdown down"),this.stop();return}console.debug("Countdown.start() this.interval"),this.interval=setInterval(()=>this.decrementCountdown(),1e3),this.isRunning=!0,console.debug("Countdown.start() done",this.interval)}continue(){this.stop(),this.start()}stop(){console.debug("Countdown.stop() start",this.interval),this.interval&&(console.debug("Countdown.stop() clearInterval"),clearInterval(this.interval)),this.interval=null,this.isRunning=!1,console.debug("Countdown.stop() stop")}toggle(){console.debug("Countdown.toggle() start"),this.isRunning?this.stop():this.start(),console.debug("Countdown.toggle() stop")}getIsRunning(){return this.isRunning}hasCounted(){return this.countdownValue<this.initialValue}reset(){console.debug("Countdown.reset() start"),this.stop(),this.countdownValue=this.initialValue,console.log("reset :: ",this),console.debug("Countdown.reset() stop")}},h=d;function l(){let o=document.getElementById("timer-container"),e=document.getElementById("timer"),t=document.getElementById("toggle-timer-button"),n=document.getElementById("audio-play"),i=document.getElementById("audio-continue"),a=document.getElementById("audio-beep"),s=document.getElementById("trigger-next-timer-level");if(!o)return console.error("failed to fetch timer-container by id"),null;if(!e)return console.error("failed to fetch timer element by id"),null;if(!t)return console.error("failed to fetch toggle-timer-button element by id"),null;let c="";s&&(console.error("failed to fetch trigger-next-timer-level element by id"),c=s.getAttribute("hx-get")||"");let u=e.getAttribute("data-level-duration-sec");return u||(console.error("trigger-next-timer-level element is missing attribute data-level-duration-sec"),u="0"),{timer:e,timerContainer:o,timerToggle:t,nextTimerButton:s,nextLevelURI:c,durationSecStr:u,audioPlay:n,audioContinue:i,audioBeep:a}}var m;function f(){m=new AbortController}f();document.addEventListener("DOMContentLoaded",()=>{console.log("DOMContentLoaded :: start"),g(),w(),console.log("DOMContentLoaded :: complete")});document.body.addEventListener("countdown::proceed",()=>{console.debug("countdown::proceed :: start"),b(),y(),console.debug("countdown::proceed :: complete")});document.body.addEventListener("countdown::reset",()=>{console.debug("countdown::reset :: start"),b(),console.debug("countdown::reset :: complete")});function b(){v(),m.abort(),f(),g(),w()}function w(){let o=l();if(!o){console.error("failed to fetch elements, unable to register click event on timer toggle");return}let{timerToggle:e}=o;e.addEventListener("click",()=>C(),{signal:m.signal})}var r;function g(){console.debug("initCountdown :: start");let o=l();if(!o)return;let{nextLevelURI:e,durationSecStr:t,timer:n,audioBeep:i}=o,a=0;t&&(a=parseInt(t)),r=new h({initialValue:a,showHour:a>3600,emitter:(s,c)=>{if(n.innerHTML=c,console.debug(`received emitted value ${c}`),s==11){console.log("starting end of level beep");let{audioBeep:u}=o;u||console.error("audio play is undefined :-("),u?.play().then(p=>{console.log("end of level beep is playing")}).catch(p=>{console.error("There was an issue playing audio beep",p)})}},onComplete:()=>{if(e){let s=`${e}?proceed=true`;setTimeout(()=>{console.log("timeout set for 1 second"),htmx.ajax("GET",s,htmx.find("#timer-container"))},1e3)}else htmx.removeClass(n,"timer-large-font"),htmx.addClass(n,"timer-complete-font"),n.innerHTML="Timer Complete"}}),console.debug("initCountdown :: complete")}function C(){if(console.debug("toggleCountdown :: start"),!r){console.error("timerToggleEventClick :: countdown not set");return}let o=l();if(!o){console.error("failed to fetch elements, unable to register click event on timer toggle");return}let{timerToggle:e}=o;if(r.toggle(),!r.getIsRunning())htmx.removeClass(e,"fa-circle-stop"),htmx.addClass(e,"fa-circle-play");else{if(!r.hasCounted()){let{audioPlay:t}=o;t||console.error("audio play is undefined :-("),t?.play().then(n=>{console.log("audio is playing")}).catch(n=>{console.error("There was an issue playing audio play",n)})}htmx.removeClass(e,"fa-circle-play"),htmx.addClass(e,"fa-circle-stop")}console.debug("toggleCountdown :: complete")}function v(){if(console.debug("stopCountdown :: start"),!r){console.error("failed to stop countdown, countdown is undefined",r);return}r.stop();let o=l();if(!o){console.error("failed to fetch elements, unable to register click event on timer toggle");return}let{timerToggle:e}=o;htmx.removeClass(e,"fa-circle-stop"),htmx.addClass(e,"fa-circle-play"),console.debug("stopCountdown :: complete")}function y(){console.debug("startCountdown :: start");let o=l();if(!o){console.error("failed to fetch elements, unable to register click event on timer toggle");return}let{timerToggle:e,audioContinue:t}=o;t?.play().then(n=>{console.log("audio is playing")}).catch(n=>{console.error("There was an issue playing audio continue",n)}),htmx.removeClass(e,"fa-circle-stop"),htmx.addClass(e,"fa-circle-play"),r?.start(),console.debug("startCountdown :: complete")}})();
+"use strict";(()=>{var p=class{constructor({initialValue:t,showHour:e,emitter:r,onComplete:n}){this.isRunning=!1;this.initialValue=t,this.showHour=e,this.emitter=r,this.onComplete=n,this.countdownValue=t,this.interval=null}decrementCountdown(){this.countdownValue--,this.emitter(this.countdownValue,this.format(this.countdownValue)),this.countdownValue===0&&(this.stop(),this.onComplete())}format(t){let e={hours:Math.floor(t/3600%24),minutes:Math.floor(t/60%60),seconds:Math.floor(t%60)},r=[];if(e.hours>0&&this.showHour){let n="";e.hours<10&&(n="0"),n=`${n}${e.hours}`}if(e.minutes==0)r.push("00");else if(e.minutes>0){let n="";e.minutes<10&&(n="0"),n=`${n}${e.minutes}`,r.push(n)}if(e.seconds==0)r.push("00");else if(e.seconds>0){let n="";e.seconds<10&&(n="0"),n=`${n}${e.seconds}`,r.push(n)}return r.join(":")}start(){if(console.debug("Countdown.start()"),this.interval&&(console.debug("Countdown.start() if this.interval"),this.reset()),this.countdownValue===0){console.error("countdownValue is 0, shutdown down"),this.stop();return}console.debug("Countdown.start() this.interval"),this.interval=setInterval(()=>this.decrementCountdown(),1e3),this.isRunning=!0,console.debug("Countdown.start() done",this.interval)}continue(){this.stop(),this.start()}stop(){console.debug("Countdown.stop() start",this.interval),this.interval&&(console.debug("Countdown.stop() clearInterval"),clearInterval(this.interval)),this.interval=null,this.isRunning=!1,console.debug("Countdown.stop() stop")}toggle(){console.debug("Countdown.toggle() start"),this.isRunning?this.stop():this.start(),console.debug("Countdown.toggle() stop")}getIsRunning(){return this.isRunning}hasCounted(){return this.countdownValue<this.initialValue}reset(){console.debug("Countdown.reset() start"),this.stop(),this.countdownValue=this.initialValue,console.log("reset :: ",this),console.debug("Countdown.reset() stop")}},v=p;function c(){let o=document.getElementById("timer-container"),t=document.getElementById("timer"),e=document.getElementById("toggle-timer-button"),r=document.getElementById("audio-play"),n=document.getElementById("audio-continue"),l=document.getElementById("audio-beep"),i=document.getElementById("trigger-next-timer-level");if(!o)return console.error("failed to fetch timer-container by id"),null;if(!t)return console.error("failed to fetch timer element by id"),null;e||console.debug("no toggle-timer-button element, timer is read only");let g="";i&&(console.error("failed to fetch trigger-next-timer-level element by id"),g=i.getAttribute("hx-get")||"");let a=o.getAttribute("data-refresh-uri")||"",s=t.getAttribute("data-level-duration-sec");s||(console.error("trigger-next-timer-level element is missing attribute data-level-duration-sec"),s="0");let u=t.getAttribute("data-level-remaining-sec");u||(u=s);let m=t.getAttribute("data-clock-running")==="true";return{timer:t,timerContainer:o,timerToggle:e,nextTimerButton:i,nextLevelURI:g,refreshURI:a,durationSecStr:s,remainingSecStr:u,clockRunning:m,audioPlay:r,audioContinue:n,audioBeep:l}}function w(){console.debug("initStream :: start");let o=document.body.getAttribute("data-events-uri"),t=document.body.getAttribute("data-masthead-uri");if(!o||!t){console.debug("initStream :: no event stream for this page");return}let e=()=>{htmx.ajax("GET",t,htmx.find("#timer-container"))},r=!1,n=new EventSource(o);n.addEventListener("timer",l=>{console.debug("stream :: received timer event",l.data),e()}),n.addEventListener("error",()=>{if(n.readyState===EventSource.CLOSED){console.error("stream :: connection refused, reloading"),window.location.reload();return}console.error("stream :: connection lost, the browser will reconnect"),r=!0}),setInterval(()=>{n.readyState!==EventSource.OPEN&&(console.debug("stream :: not connected, polling masthead"),e())},1e4),n.addEventListener("open",()=>{r&&(r=!1,e())}),console.debug("initStream :: complete")}var C;function y(){C=new AbortController}y();document.addEventListener("DOMContentLoaded",()=>{console.log("DOMContentLoaded :: start"),f(),w(),console.log("DOMContentLoaded :: complete")});document.body.addEventListener("countdown::proceed",()=>{console.debug("countdown::proceed :: start"),h(),T(),console.debug("countdown::proceed :: complete")});document.body.addEventListener("countdown::start",()=>{console.debug("countdown::start :: start"),h(),E(),console.debug("countdown::start :: complete")});document.body.addEventListener("countdown::reset",()=>{console.debug("countdown::reset :: start"),h(),console.debug("countdown::reset :: complete")});function h(){x(),C.abort(),y(),f()}var d;function f(){console.debug("initCountdown :: start");let o=c();if(!o)return;let{nextLevelURI:t,refreshURI:e,remainingSecStr:r,clockRunning:n,timerToggle:l,timer:i,audioBeep:g}=o,a=0;r&&(a=parseInt(r)),d=new v({initialValue:a,showHour:a>3600,emitter:(s,u)=>{if(i.innerHTML=u,console.debug(`received emitted value ${u}`),s==11){console.log("starting end of level beep");let{audioBeep:m}=o;m||console.error("audio play is undefined :-("),m?.play().then(b=>{console.log("end of level beep is playing")}).catch(b=>{console.error("There was an issue playing audio beep",b)})}},onComplete:()=>{if(e)setTimeout(()=>{htmx.ajax("GET",e,htmx.find("#timer-container"))},1e3);else if(t){let s=`${t}?proceed=true`;setTimeout(()=>{console.log("timeout set for 1 second"),htmx.ajax("GET",s,htmx.find("#timer-container"))},1e3)}else htmx.removeClass(i,"timer-large-font"),htmx.addClass(i,"timer-complete-font"),i.innerHTML="Timer Complete"}}),n&&(d.start(),l&&(htmx.removeClass(l,"fa-circle-play"),htmx.addClass(l,"fa-circle-stop"))),console.debug("initCountdown :: complete")}function E(){console.debug("playAnnouncement :: start");let o=c();if(!o){console.error("failed to fetch elements, unable to play announcement");return}let{audioPlay:t}=o;t||console.error("audio play is undefined :-("),t?.play().then(e=>{console.log("audio is playing")}).catch(e=>{console.error("There was an issue playing audio play",e)}),console.debug("playAnnouncement :: complete")}function x(){if(console.debug("stopCountdown :: start"),!d){console.error("failed to stop countdown, countdown is undefined",d);return}d.stop();let o=c();if(!o){console.error("failed to fetch elements, unable to register click event on timer toggle");return}let{timerToggle:t}=o;t&&(htmx.removeClass(t,"fa-circle-stop"),htmx.addClass(t,"fa-circle-play")),console.debug("stopCountdown :: complete")}function T(){console.debug("startCountdown :: start");let o=c();if(!o){console.error("failed to fetch elements, unable to register click event on timer toggle");return}let{audioContinue:t}=o;t?.play().then(e=>{console.log("audio is playing")}).catch(e=>{console.error("There was an issue playing audio continue",e)}),console.debug("startCountdown :: complete")}})();
 //# sourceMappingURL=countdown.js.map
@@ -0,0 +1,29 @@
+package poker
+
+import (
+	"context"
+	"time"
+)
+
+// BlobStore holds generated files, such as synthesized announcements, so they only need to be generated once
+type BlobStore interface {
+	// Blob returns the blob stored under key, or nil if there isn't one
+	Blob(ctx context.Context, key string) (*Blob, error)
+	Blobs(ctx context.Context, prefix string) ([]*BlobInfo, error)
+	SaveBlob(ctx context.Context, blob *Blob) error
+	DeleteBlob(ctx context.Context, key string) error
+	// PurgeBlobs deletes every blob whose key starts with prefix and returns how many were deleted
+	PurgeBlobs(ctx context.Context, prefix string) (int, error)
+}
+
+type BlobInfo struct {
+	Key         string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+}
+
+type Blob struct {
+	BlobInfo
+	Data []byte
+}
@@ -0,0 +1,256 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ChipSet is the physical set of chips a timer is played with
+type ChipSet struct {
+	Name  string
+	Chips []*Chip
+}
+
+// Chip is one denomination of a chip set
+type Chip struct {
+	Denomination float64
+	Color        string
+	// Count is how many chips of the denomination come in the set
+	Count uint
+}
+
+func (c Chip) String() string {
+	return fmt.Sprintf("%s %v", c.Color, c.Denomination)
+}
+
+func (cs ChipSet) Validate() error {
+
+	if len(cs.Chips) == 0 {
+		return fmt.Errorf("a chip set needs at least one denomination")
+	}
+
+	seen := make(map[float64]bool, len(cs.Chips))
+	for _, chip := range cs.Chips {
+		if chip.Denomination <= 0 {
+			return fmt.Errorf("denominations must be greater than 0")
+		}
+
+		if seen[chip.Denomination] {
+			return fmt.Errorf("the %v denomination is listed more than once", chip.Denomination)
+		}
+		seen[chip.Denomination] = true
+
+		if strings.TrimSpace(chip.Color) == "" {
+			return fmt.Errorf("the %v chips need a color", chip.Denomination)
+		}
+
+		if chip.Count == 0 {
+			return fmt.Errorf("the count of %v chips must be greater than 0", chip.Denomination)
+		}
+	}
+
+	return nil
+
+}
+
+// SortedChips returns the chips from the smallest denomination to the largest
+func (cs ChipSet) SortedChips() []*Chip {
+
+	chips := make([]*Chip, len(cs.Chips))
+	copy(chips, cs.Chips)
+	sort.Slice(chips, func(i, j int) bool {
+		return chips[i].Denomination < chips[j].Denomination
+	})
+
+	return chips
+
+}
+
+// CanMake reports whether amount can be put together from the set's denominations
+func (cs ChipSet) CanMake(amount float64) bool {
+	return canMakeAmount(amount, cs.Chips)
+}
+
+// ValidateLevel checks that every blind and ante of the level can be made with the set's denominations
+func (cs ChipSet) ValidateLevel(level *TimerLevel) error {
+	return validateLevelChips(level, cs.Chips)
+}
+
+// ColorUps works out which chips are taken out of play at each break, keyed by the id of the break. Chips
+// are colored up smallest first, once no blind or ante after the break needs them
+func (cs ChipSet) ColorUps(levels []*TimerLevel) map[string][]*Chip {
+
+	colorUps := make(map[string][]*Chip)
+
+	inPlay := cs.SortedChips()
+	for i, level := range levels {
+		if level.Type != LevelTypeBreak {
+			continue
+		}
+
+		var later []*TimerLevel
+		for _, l := range levels[i+1:] {
+			if l.Type == LevelTypeBlind {
+				later = append(later, l)
+			}
+		}
+
+		if len(later) == 0 {
+			continue
+		}
+
+		var removed []*Chip
+		for len(inPlay) > 1 && levelsCanBeMade(later, inPlay[1:]) {
+			removed = append(removed, inPlay[0])
+			inPlay = inPlay[1:]
+		}
+
+		if len(removed) > 0 {
+			colorUps[level.ID] = removed
+		}
+	}
+
+	return colorUps
+
+}
+
+// ValidateChips checks that every blind and ante of the timer can be made with its chip set, if it has one
+func (t Timer) ValidateChips() error {
+
+	if t.ChipSet == nil {
+		return nil
+	}
+
+	for i, level := range t.Levels {
+		err := t.ChipSet.ValidateLevel(level)
+		if err != nil {
+			return fmt.Errorf("level %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+
+}
+
+// ColorUpsAt returns the chips colored up at the break with the id, nil when the timer doesn't have a chip set
+// or nothing is colored up
+func (t Timer) ColorUpsAt(levelID string) []*Chip {
+
+	if t.ChipSet == nil {
+		return nil
+	}
+
+	return t.ChipSet.ColorUps(t.Levels)[levelID]
+
+}
+
+func levelsCanBeMade(levels []*TimerLevel, chips []*Chip) bool {
+
+	for _, level := range levels {
+		if validateLevelChips(level, chips) != nil {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+func validateLevelChips(level *TimerLevel, chips []*Chip) error {
+
+	if level.Type != LevelTypeBlind {
+		return nil
+	}
+
+	type amount struct {
+		name   string
+		amount float64
+	}
+
+	amounts := []amount{{"small blind", level.SmallBlind}, {"big blind", level.BigBlind}}
+	if level.EffectiveAnteType() != AnteTypeNone {
+		amounts = append(amounts, amount{"ante", level.Ante})
+	}
+
+	for _, a := range amounts {
+		if !canMakeAmount(a.amount, chips) {
+			denominations := make([]string, 0, len(chips))
+			for _, chip := range chips {
+				denominations = append(denominations, fmt.Sprintf("%v", chip.Denomination))
+			}
+			return fmt.Errorf("the %s of %v can't be made with %s chips", a.name, a.amount, strings.Join(denominations, ", "))
+		}
+	}
+
+	return nil
+
+}
+
+// canMakeAmount reports whether amount is a sum of the chips' denominations, using as many of each as needed.
+// Amounts are worked in hundredths so fractional denominations are supported
+func canMakeAmount(amount float64, chips []*Chip) bool {
+
+	target := int64(math.Round(amount * 100))
+	if target == 0 {
+		return true
+	}
+
+	if target < 0 || len(chips) == 0 {
+		return false
+	}
+
+	var divisor int64
+	coins := make([]int64, 0, len(chips))
+	for _, chip := range chips {
+		coin := int64(math.Round(chip.Denomination * 100))
+		if coin <= 0 {
+			continue
+		}
+		coins = append(coins, coin)
+		divisor = gcd(divisor, coin)
+	}
+
+	if len(coins) == 0 || target%divisor != 0 {
+		return false
+	}
+
+	target /= divisor
+	smallest, largest := coins[0]/divisor, coins[0]/divisor
+	for i := range coins {
+		coins[i] /= divisor
+		if coins[i] < smallest {
+			smallest = coins[i]
+		}
+		if coins[i] > largest {
+			largest = coins[i]
+		}
+	}
+
+	// Once the denominations share no common factor, every amount from (smallest-1)*(largest-1) up can be made
+	if target >= (smallest-1)*(largest-1) {
+		return true
+	}
+
+	reachable := make([]bool, target+1)
+	reachable[0] = true
+	for n := int64(1); n <= target; n++ {
+		for _, coin := range coins {
+			if coin <= n && reachable[n-coin] {
+				reachable[n] = true
+				break
+			}
+		}
+	}
+
+	return reachable[target]
+
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,190 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+func chipDenominations(chips []*Chip) []float64 {
+
+	denominations := make([]float64, 0, len(chips))
+	for _, chip := range chips {
+		denominations = append(denominations, chip.Denomination)
+	}
+
+	return denominations
+
+}
+
+func TestCanMake(t *testing.T) {
+
+	tt := []struct {
+		name   string
+		chips  []float64
+		amount float64
+		want   bool
+	}{
+		{name: "a single chip", chips: []float64{25, 100, 500}, amount: 25, want: true},
+		{name: "several chips", chips: []float64{25, 100, 500}, amount: 625, want: true},
+		{name: "nothing", chips: []float64{25, 100, 500}, amount: 0, want: true},
+		{name: "less than the smallest chip", chips: []float64{25, 100, 500}, amount: 10},
+		{name: "not a multiple of the smallest chip", chips: []float64{25, 100, 500}, amount: 130},
+		{name: "a multiple of their common factor that can't be made", chips: []float64{100, 250}, amount: 150},
+		{name: "a mix without the smallest chip", chips: []float64{100, 250}, amount: 450, want: true},
+		{name: "fractional", chips: []float64{0.25, 1, 5}, amount: 1.75, want: true},
+		{name: "fractional that can't be made", chips: []float64{0.25, 1, 5}, amount: 0.1},
+		{name: "negative", chips: []float64{25}, amount: -25},
+		{name: "no chips", amount: 25},
+	}
+
+	for _, tc := range tt {
+		set := ChipSet{}
+		for _, denomination := range tc.chips {
+			set.Chips = append(set.Chips, &Chip{Denomination: denomination, Color: "white", Count: 100})
+		}
+
+		if got := set.CanMake(tc.amount); got != tc.want {
+			t.Errorf("%s: expected being able to make %v from %v to be %t, got %t", tc.name, tc.amount, tc.chips, tc.want, got)
+		}
+	}
+
+}
+
+func TestValidateChips(t *testing.T) {
+
+	set := &ChipSet{Chips: []*Chip{
+		{Denomination: 25, Color: "white", Count: 100},
+		{Denomination: 100, Color: "black", Count: 100},
+	}}
+
+	tt := []struct {
+		name    string
+		level   TimerLevel
+		wantErr string
+	}{
+		{name: "blinds the set can make", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 25, BigBlind: 50}},
+		{name: "small blind the set can't make", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 10, BigBlind: 50}, wantErr: "the small blind of 10"},
+		{name: "big blind the set can't make", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 50, BigBlind: 110}, wantErr: "the big blind of 110"},
+		{name: "ante the set can't make", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 50, BigBlind: 100, Ante: 5}, wantErr: "the ante of 5"},
+		{name: "big blind ante", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 50, BigBlind: 100, Ante: 100, AnteType: AnteTypeBigBlind}},
+		{name: "break", level: TimerLevel{Type: LevelTypeBreak, DurationMin: 10}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			timer := &Timer{
+				ChipSet: set,
+				Levels:  []*TimerLevel{{Type: LevelTypeBlind, SmallBlind: 25, BigBlind: 50}, &tc.level},
+			}
+
+			err := timer.ValidateChips()
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("expected the level to be valid, got %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), "level 2: "+tc.wantErr)) {
+				t.Errorf("expected an error about level 2 containing %q, got %v", tc.wantErr, err)
+			}
+
+		})
+	}
+
+	// Without a chip set any blinds go
+	timer := &Timer{Levels: []*TimerLevel{{Type: LevelTypeBlind, SmallBlind: 10, BigBlind: 15}}}
+	if err := timer.ValidateChips(); err != nil {
+		t.Errorf("expected a timer without a chip set to be valid, got %s", err)
+	}
+
+}
+
+func TestColorUps(t *testing.T) {
+
+	set := &ChipSet{Chips: []*Chip{
+		{Denomination: 500, Color: "purple", Count: 50},
+		{Denomination: 25, Color: "white", Count: 100},
+		{Denomination: 5, Color: "red", Count: 100},
+		{Denomination: 100, Color: "black", Count: 100},
+	}}
+
+	blind := func(id string, small, big, ante float64) *TimerLevel {
+		return &TimerLevel{ID: id, Type: LevelTypeBlind, SmallBlind: small, BigBlind: big, Ante: ante}
+	}
+	brk := func(id string) *TimerLevel {
+		return &TimerLevel{ID: id, Type: LevelTypeBreak}
+	}
+
+	tt := []struct {
+		name   string
+		levels []*TimerLevel
+		// want lists the denominations colored up at each break that has any
+		want map[string][]float64
+	}{
+		{
+			name:   "smallest first once no later blind needs them",
+			levels: []*TimerLevel{blind("1", 5, 10, 0), brk("a"), blind("2", 25, 50, 0), brk("b"), blind("3", 500, 1000, 0)},
+			want:   map[string][]float64{"a": {5}, "b": {25, 100}},
+		},
+		{
+			name:   "kept in play for an ante",
+			levels: []*TimerLevel{blind("1", 5, 10, 0), brk("a"), blind("2", 100, 200, 25), brk("b"), blind("3", 500, 1000, 0)},
+			want:   map[string][]float64{"a": {5}, "b": {25, 100}},
+		},
+		{
+			name:   "kept in play for a blind several levels on",
+			levels: []*TimerLevel{blind("1", 5, 10, 0), brk("a"), blind("2", 100, 200, 0), blind("3", 125, 250, 0), brk("b"), blind("4", 500, 1000, 0)},
+			want:   map[string][]float64{"a": {5}, "b": {25, 100}},
+		},
+		{
+			name:   "a larger chip needed after a smaller one could go",
+			levels: []*TimerLevel{blind("1", 5, 10, 0), brk("a"), blind("2", 100, 200, 0), blind("3", 105, 210, 0)},
+		},
+		{
+			name:   "nothing at a break without blinds after it",
+			levels: []*TimerLevel{blind("1", 500, 1000, 0), brk("a")},
+		},
+		{
+			name:   "the last denomination stays in play",
+			levels: []*TimerLevel{blind("1", 5, 10, 0), brk("a"), blind("2", 1000, 2000, 0)},
+			want:   map[string][]float64{"a": {5, 25, 100}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			colorUps := set.ColorUps(tc.levels)
+
+			if len(colorUps) != len(tc.want) {
+				t.Errorf("expected color ups at %d breaks, got %d", len(tc.want), len(colorUps))
+			}
+
+			for id, want := range tc.want {
+				got := chipDenominations(colorUps[id])
+				if len(got) != len(want) {
+					t.Errorf("break %s: expected %v to be colored up, got %v", id, want, got)
+					continue
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Errorf("break %s: expected %v to be colored up, got %v", id, want, got)
+						break
+					}
+				}
+			}
+
+			timer := &Timer{ChipSet: set, Levels: tc.levels}
+			for _, level := range tc.levels {
+				if got := chipDenominations(timer.ColorUpsAt(level.ID)); len(got) != len(tc.want[level.ID]) {
+					t.Errorf("level %s: expected the timer to color up %v, got %v", level.ID, tc.want[level.ID], got)
+				}
+			}
+
+		})
+	}
+
+	timer := &Timer{Levels: []*TimerLevel{blind("1", 5, 10, 0), brk("a"), blind("2", 500, 1000, 0)}}
+	if got := timer.ColorUpsAt("a"); got != nil {
+		t.Errorf("expected nothing colored up without a chip set, got %v", chipDenominations(got))
+	}
+
+}
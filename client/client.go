@@ -0,0 +1,257 @@
+// Package client makes requests to the poker JSON API, which is described by the OpenAPI document served at
+// /api/v1/openapi.json. There is a method for each of the document's operations, sending and returning the poker
+// package's API types
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"poker"
+	"strconv"
+	"strings"
+)
+
+type Config struct {
+	// BaseURL is where the server is, such as https://poker.example.com
+	BaseURL string
+	// Token is a personal API token created from the dashboard
+	Token string
+	// HTTPClient is optional, http.DefaultClient is used when it is nil
+	HTTPClient *http.Client
+}
+
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+func New(cfg *Config) (*Client, error) {
+
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("base url cannot be empty")
+	}
+
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("token cannot be empty")
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/") + "/api/v1",
+		token:      cfg.Token,
+		httpClient: httpClient,
+	}, nil
+
+}
+
+// Error is returned when the API responds with an error status
+type Error struct {
+	StatusCode int
+	// Message is the API's explanation of the error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("poker api responded with %d: %s", e.StatusCode, e.Message)
+}
+
+// Timers lists the timers the token's user owns or has accepted a role on
+func (c *Client) Timers(ctx context.Context) ([]*poker.APITimer, error) {
+
+	var timers []*poker.APITimer
+	err := c.do(ctx, nil, http.MethodGet, "/timers", nil, &timers)
+
+	return timers, err
+
+}
+
+// CreateTimer creates a timer, with its levels when input has them
+func (c *Client) CreateTimer(ctx context.Context, input *poker.APITimerInput) (*poker.APITimer, error) {
+
+	var timer = new(poker.APITimer)
+	err := c.do(ctx, nil, http.MethodPost, "/timers", input, timer)
+	if err != nil {
+		return nil, err
+	}
+
+	return timer, nil
+
+}
+
+func (c *Client) Timer(ctx context.Context, timerID string) (*poker.APITimer, error) {
+
+	var timer = new(poker.APITimer)
+	err := c.do(ctx, nil, http.MethodGet, timerPath(timerID), nil, timer)
+	if err != nil {
+		return nil, err
+	}
+
+	return timer, nil
+
+}
+
+// RenameTimer renames the timer. version is the Version of the timer the rename was decided from, when the timer has
+// been saved since an Error with a 409 status is returned
+func (c *Client) RenameTimer(ctx context.Context, timerID, name string, version uint) (*poker.APITimer, error) {
+
+	var timer = new(poker.APITimer)
+	err := c.do(ctx, ifMatch(version), http.MethodPatch, timerPath(timerID), &poker.APITimerInput{Name: name}, timer)
+	if err != nil {
+		return nil, err
+	}
+
+	return timer, nil
+
+}
+
+func (c *Client) DeleteTimer(ctx context.Context, timerID string) error {
+	return c.do(ctx, nil, http.MethodDelete, timerPath(timerID), nil, nil)
+}
+
+// Levels lists the timer's levels in the order they are played
+func (c *Client) Levels(ctx context.Context, timerID string) ([]*poker.APILevel, error) {
+
+	var levels []*poker.APILevel
+	err := c.do(ctx, nil, http.MethodGet, timerPath(timerID)+"/levels", nil, &levels)
+
+	return levels, err
+
+}
+
+// CreateLevel adds the level at its position, or after the last level when the position is 0
+func (c *Client) CreateLevel(ctx context.Context, timerID string, level *poker.APILevel) (*poker.APILevel, error) {
+
+	var created = new(poker.APILevel)
+	err := c.do(ctx, nil, http.MethodPost, timerPath(timerID)+"/levels", level, created)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+
+}
+
+func (c *Client) Level(ctx context.Context, timerID, levelID string) (*poker.APILevel, error) {
+
+	var level = new(poker.APILevel)
+	err := c.do(ctx, nil, http.MethodGet, levelPath(timerID, levelID), nil, level)
+	if err != nil {
+		return nil, err
+	}
+
+	return level, nil
+
+}
+
+// UpdateLevel replaces the level, moving it when its position isn't 0. version is the Version of the timer the level
+// was read from, when the timer has been saved since an Error with a 409 status is returned
+func (c *Client) UpdateLevel(ctx context.Context, timerID, levelID string, level *poker.APILevel, version uint) (*poker.APILevel, error) {
+
+	var updated = new(poker.APILevel)
+	err := c.do(ctx, ifMatch(version), http.MethodPut, levelPath(timerID, levelID), level, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+
+}
+
+// DeleteLevel deletes the level. version is the Version of the timer the level was read from, when the timer has been
+// saved since an Error with a 409 status is returned
+func (c *Client) DeleteLevel(ctx context.Context, timerID, levelID string, version uint) error {
+	return c.do(ctx, ifMatch(version), http.MethodDelete, levelPath(timerID, levelID), nil, nil)
+}
+
+// Play runs one of the play page's controls, returning the timer as it is afterwards
+func (c *Client) Play(ctx context.Context, timerID string, action poker.PlayAction) (*poker.APITimer, error) {
+
+	var timer = new(poker.APITimer)
+	err := c.do(ctx, nil, http.MethodPost, timerPath(timerID)+"/play/"+url.PathEscape(action.String()), nil, timer)
+	if err != nil {
+		return nil, err
+	}
+
+	return timer, nil
+
+}
+
+func timerPath(timerID string) string {
+	return "/timers/" + url.PathEscape(timerID)
+}
+
+func levelPath(timerID, levelID string) string {
+	return timerPath(timerID) + "/levels/" + url.PathEscape(levelID)
+}
+
+// ifMatch is the header that makes a change only when the timer is still at version
+func ifMatch(version uint) http.Header {
+	return http.Header{"If-Match": {strconv.Quote(strconv.FormatUint(uint64(version), 10))}}
+}
+
+// do makes the request with header, sending in as the JSON body when it isn't nil and decoding a successful response
+// into out when it isn't nil
+func (c *Client) do(ctx context.Context, header http.Header, method, path string, in, out any) error {
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		var apiErr = new(poker.APIError)
+		err = json.NewDecoder(res.Body).Decode(apiErr)
+		if err != nil || apiErr.Error == "" {
+			apiErr.Error = http.StatusText(res.StatusCode)
+		}
+
+		return &Error{StatusCode: res.StatusCode, Message: apiErr.Error}
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(res.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return nil
+
+}
@@ -0,0 +1,227 @@
+package poker
+
+import (
+	"time"
+)
+
+// IsRunning reports whether the clock for the current level is counting down
+func (t *Timer) IsRunning() bool {
+	return t.StartedAt != nil
+}
+
+// IsPaused reports whether the clock has been started and then paused part way through the current level
+func (t *Timer) IsPaused() bool {
+	return t.StartedAt == nil && t.PausedAt != nil
+}
+
+// Elapsed returns how much of the current level has been played as of now
+func (t *Timer) Elapsed(now time.Time) time.Duration {
+
+	elapsed := time.Duration(t.ElapsedSec * float64(time.Second))
+	if t.StartedAt != nil && now.After(*t.StartedAt) {
+		elapsed += now.Sub(*t.StartedAt)
+	}
+
+	return elapsed
+
+}
+
+// Remaining returns how much time is left in the current level as of now. It is never negative
+func (t *Timer) Remaining(now time.Time) time.Duration {
+
+	level := t.Level()
+	if level == nil {
+		return 0
+	}
+
+	remaining := time.Duration(level.DurationSec*float64(time.Second)) - t.Elapsed(now)
+	if remaining < 0 {
+		return 0
+	}
+
+	return remaining
+
+}
+
+// Level returns the level the timer is currently on, or nil if the timer has no levels
+func (t *Timer) Level() *TimerLevel {
+
+	if len(t.Levels) == 0 {
+		return nil
+	}
+
+	if int(t.CurrentLevel) > len(t.Levels)-1 {
+		t.CurrentLevel = 0
+	}
+
+	return t.Levels[t.CurrentLevel]
+
+}
+
+// StartClock starts or resumes the clock for the current level. Calling it on a running clock is a no-op
+func (t *Timer) StartClock(now time.Time) {
+
+	if t.IsRunning() || t.IsComplete {
+		return
+	}
+
+	t.StartedAt = &now
+	t.PausedAt = nil
+
+}
+
+// PauseClock stops the clock, folding the time played since it was last started into ElapsedSec
+func (t *Timer) PauseClock(now time.Time) {
+
+	if !t.IsRunning() {
+		return
+	}
+
+	t.ElapsedSec = t.Elapsed(now).Seconds()
+	t.StartedAt = nil
+	t.PausedAt = &now
+
+}
+
+// ResetClock discards any time played on the current level. A running clock keeps running from zero
+func (t *Timer) ResetClock(now time.Time) {
+
+	t.ElapsedSec = 0
+	t.PausedAt = nil
+	if t.IsRunning() {
+		t.StartedAt = &now
+	}
+
+}
+
+// StopClock discards any time played on the current level and stops the clock
+func (t *Timer) StopClock() {
+
+	t.ElapsedSec = 0
+	t.StartedAt = nil
+	t.PausedAt = nil
+
+}
+
+// RollForward advances the timer through every level that ran out while the clock was
+// running, carrying any overrun into the following level. When the final level runs out
+// the timer is marked complete and the clock is stopped. It reports whether the timer changed
+func (t *Timer) RollForward(now time.Time) bool {
+
+	if !t.IsRunning() || len(t.Levels) == 0 {
+		return false
+	}
+
+	var changed bool
+	for {
+		level := t.Level()
+		duration := time.Duration(level.DurationSec * float64(time.Second))
+		elapsed := t.Elapsed(now)
+		if elapsed < duration {
+			return changed
+		}
+
+		changed = true
+
+		if int(t.CurrentLevel) >= len(t.Levels)-1 {
+			t.IsComplete = true
+			t.StopClock()
+			return changed
+		}
+
+		t.CurrentLevel += 1
+		t.ElapsedSec = (elapsed - duration).Seconds()
+		t.StartedAt = &now
+	}
+
+}
+
+// NextLevel moves on to the following level with the clock stopped. Moving on from the final level completes the
+// timer instead
+func (t *Timer) NextLevel() {
+
+	if int(t.CurrentLevel) >= len(t.Levels)-1 {
+		t.IsComplete = true
+	} else {
+		t.CurrentLevel += 1
+	}
+
+	t.StopClock()
+
+}
+
+// PreviousLevel goes back to the level before with the clock stopped, reporting whether there was one to go back to
+func (t *Timer) PreviousLevel() bool {
+
+	if t.CurrentLevel == 0 {
+		return false
+	}
+
+	t.CurrentLevel -= 1
+	t.IsComplete = false
+	t.StopClock()
+
+	return true
+
+}
+
+// RestartLevel stops the clock at the start of the current level, reopening the timer if it was complete
+func (t *Timer) RestartLevel() {
+
+	t.IsComplete = false
+	t.StopClock()
+
+}
+
+// PlayAction changes the level a timer is on or runs its clock, see Timer.Play
+type PlayAction string
+
+const (
+	PlayActionStart    PlayAction = "start"
+	PlayActionPause    PlayAction = "pause"
+	PlayActionResume   PlayAction = "resume"
+	PlayActionNext     PlayAction = "next"
+	PlayActionPrevious PlayAction = "previous"
+	PlayActionReset    PlayAction = "reset"
+)
+
+func (pa PlayAction) String() string {
+	return string(pa)
+}
+
+// AllPlayActions is every play action, in the order they are documented
+var AllPlayActions = []PlayAction{PlayActionStart, PlayActionPause, PlayActionResume, PlayActionNext, PlayActionPrevious, PlayActionReset}
+
+func (pa PlayAction) Valid() bool {
+	for _, a := range AllPlayActions {
+		if a == pa {
+			return true
+		}
+	}
+	return false
+}
+
+// Play applies the action to the timer as of now, after rolling it forward through any levels that ran out. It
+// reports whether the action changed the level rather than just the clock
+func (t *Timer) Play(action PlayAction, now time.Time) bool {
+
+	t.RollForward(now)
+
+	switch action {
+	case PlayActionStart, PlayActionResume:
+		t.StartClock(now)
+	case PlayActionPause:
+		t.PauseClock(now)
+	case PlayActionNext:
+		t.NextLevel()
+		return true
+	case PlayActionPrevious:
+		return t.PreviousLevel()
+	case PlayActionReset:
+		t.RestartLevel()
+		return true
+	}
+
+	return false
+
+}
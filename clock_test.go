@@ -0,0 +1,261 @@
+package poker
+
+import (
+	"testing"
+	"time"
+)
+
+type clockStep struct {
+	at     time.Duration
+	action PlayAction
+}
+
+func clockTimer() *Timer {
+	return &Timer{
+		ID: "clock",
+		Levels: []*TimerLevel{
+			{ID: "1", Type: LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 10, DurationSec: 600},
+			{ID: "2", Type: LevelTypeBreak, DurationMin: 5, DurationSec: 300},
+			{ID: "3", Type: LevelTypeBlind, SmallBlind: 50, BigBlind: 100, DurationMin: 10, DurationSec: 600},
+		},
+	}
+}
+
+func TestRollForward(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name          string
+		steps         []clockStep
+		at            time.Duration
+		wantChanged   bool
+		wantLevel     uint
+		wantRemaining time.Duration
+		wantRunning   bool
+		wantComplete  bool
+	}{
+		{
+			name:          "never started",
+			at:            30 * time.Minute,
+			wantRemaining: 10 * time.Minute,
+		},
+		{
+			name:          "part way through the first level",
+			steps:         []clockStep{{0, PlayActionStart}},
+			at:            4 * time.Minute,
+			wantRemaining: 6 * time.Minute,
+			wantRunning:   true,
+		},
+		{
+			name:          "as the first level runs out",
+			steps:         []clockStep{{0, PlayActionStart}},
+			at:            10 * time.Minute,
+			wantChanged:   true,
+			wantLevel:     1,
+			wantRemaining: 5 * time.Minute,
+			wantRunning:   true,
+		},
+		{
+			name:          "overrun carried into the next level",
+			steps:         []clockStep{{0, PlayActionStart}},
+			at:            12 * time.Minute,
+			wantChanged:   true,
+			wantLevel:     1,
+			wantRemaining: 3 * time.Minute,
+			wantRunning:   true,
+		},
+		{
+			name:          "through more than one level",
+			steps:         []clockStep{{0, PlayActionStart}},
+			at:            16 * time.Minute,
+			wantChanged:   true,
+			wantLevel:     2,
+			wantRemaining: 9 * time.Minute,
+			wantRunning:   true,
+		},
+		{
+			name:         "through the final level",
+			steps:        []clockStep{{0, PlayActionStart}},
+			at:           40 * time.Minute,
+			wantChanged:  true,
+			wantLevel:    2,
+			wantComplete: true,
+		},
+		{
+			name:          "paused",
+			steps:         []clockStep{{0, PlayActionStart}, {4 * time.Minute, PlayActionPause}},
+			at:            30 * time.Minute,
+			wantRemaining: 6 * time.Minute,
+		},
+		{
+			name:          "resumed",
+			steps:         []clockStep{{0, PlayActionStart}, {4 * time.Minute, PlayActionPause}, {20 * time.Minute, PlayActionResume}},
+			at:            25 * time.Minute,
+			wantRemaining: 1 * time.Minute,
+			wantRunning:   true,
+		},
+		{
+			name:          "resumed past the end of the level",
+			steps:         []clockStep{{0, PlayActionStart}, {4 * time.Minute, PlayActionPause}, {20 * time.Minute, PlayActionResume}},
+			at:            27 * time.Minute,
+			wantChanged:   true,
+			wantLevel:     1,
+			wantRemaining: 4 * time.Minute,
+			wantRunning:   true,
+		},
+		{
+			name:          "paused in the level it rolled forward to",
+			steps:         []clockStep{{0, PlayActionStart}, {12 * time.Minute, PlayActionPause}},
+			at:            30 * time.Minute,
+			wantLevel:     1,
+			wantRemaining: 3 * time.Minute,
+		},
+		{
+			name:          "moved on part way through a level",
+			steps:         []clockStep{{0, PlayActionStart}, {4 * time.Minute, PlayActionNext}},
+			at:            30 * time.Minute,
+			wantLevel:     1,
+			wantRemaining: 5 * time.Minute,
+		},
+		{
+			name:          "moved on after the level ran out",
+			steps:         []clockStep{{0, PlayActionStart}, {12 * time.Minute, PlayActionNext}},
+			at:            30 * time.Minute,
+			wantLevel:     2,
+			wantRemaining: 10 * time.Minute,
+		},
+		{
+			name:          "gone back after the level ran out",
+			steps:         []clockStep{{0, PlayActionStart}, {12 * time.Minute, PlayActionPrevious}},
+			at:            30 * time.Minute,
+			wantRemaining: 10 * time.Minute,
+		},
+		{
+			name:          "reset after the level ran out",
+			steps:         []clockStep{{0, PlayActionStart}, {12 * time.Minute, PlayActionReset}},
+			at:            30 * time.Minute,
+			wantLevel:     1,
+			wantRemaining: 5 * time.Minute,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			timer := clockTimer()
+			for _, step := range tc.steps {
+				timer.Play(step.action, start.Add(step.at))
+			}
+
+			now := start.Add(tc.at)
+
+			if changed := timer.RollForward(now); changed != tc.wantChanged {
+				t.Errorf("expected rolling forward to report a change %t, got %t", tc.wantChanged, changed)
+			}
+
+			if timer.CurrentLevel != tc.wantLevel {
+				t.Errorf("expected to be on level %d, got %d", tc.wantLevel+1, timer.CurrentLevel+1)
+			}
+
+			if timer.IsRunning() != tc.wantRunning {
+				t.Errorf("expected the clock running to be %t, got %t", tc.wantRunning, timer.IsRunning())
+			}
+
+			if timer.IsComplete != tc.wantComplete {
+				t.Errorf("expected the timer being complete to be %t, got %t", tc.wantComplete, timer.IsComplete)
+			}
+
+			// The clock stops at the start of the final level once the timer is complete
+			if !tc.wantComplete && timer.Remaining(now) != tc.wantRemaining {
+				t.Errorf("expected %s remaining, got %s", tc.wantRemaining, timer.Remaining(now))
+			}
+
+			if timer.RollForward(now) {
+				t.Errorf("expected rolling forward again at the same moment to change nothing")
+			}
+
+		})
+	}
+
+}
+
+func TestRemaining(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name  string
+		timer func() *Timer
+		at    time.Duration
+		want  time.Duration
+	}{
+		{
+			name:  "stopped",
+			timer: clockTimer,
+			at:    time.Hour,
+			want:  10 * time.Minute,
+		},
+		{
+			name: "running",
+			timer: func() *Timer {
+				timer := clockTimer()
+				timer.StartClock(start)
+				return timer
+			},
+			at:   90 * time.Second,
+			want: 8*time.Minute + 30*time.Second,
+		},
+		{
+			name: "paused part way through",
+			timer: func() *Timer {
+				timer := clockTimer()
+				timer.StartClock(start.Add(-2 * time.Minute))
+				timer.PauseClock(start)
+				return timer
+			},
+			at:   time.Hour,
+			want: 8 * time.Minute,
+		},
+		{
+			name: "resumed with time played before it was paused",
+			timer: func() *Timer {
+				timer := clockTimer()
+				timer.ElapsedSec = 120
+				timer.StartClock(start)
+				return timer
+			},
+			at:   3 * time.Minute,
+			want: 5 * time.Minute,
+		},
+		{
+			name: "run out without rolling forward",
+			timer: func() *Timer {
+				timer := clockTimer()
+				timer.StartClock(start)
+				return timer
+			},
+			at: 12 * time.Minute,
+		},
+		{
+			name: "started in the future",
+			timer: func() *Timer {
+				timer := clockTimer()
+				timer.StartClock(start.Add(time.Minute))
+				return timer
+			},
+			want: 10 * time.Minute,
+		},
+		{
+			name:  "no levels",
+			timer: func() *Timer { return &Timer{ID: "empty"} },
+		},
+	}
+
+	for _, tc := range tt {
+		if got := tc.timer().Remaining(start.Add(tc.at)); got != tc.want {
+			t.Errorf("%s: expected %s remaining, got %s", tc.name, tc.want, got)
+		}
+	}
+
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"poker"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage: poker [command]
+
+Runs the server when no command is given.
+
+commands:
+  audio-cache list [prefix]    list cached announcements
+  audio-cache purge [prefix]   delete cached announcements so they are generated again
+  timers list <user-id>        list a user's timers
+  timers export <timer-id> [json|yaml|csv]
+                               write a timer and its levels to stdout, json by default
+  timers import <user-id> <file>...
+                               create a timer for the user from each json, yaml or csv file
+`
+
+// commands are the maintenance tasks the binary can run instead of starting the server
+type commands struct {
+	audioCache poker.BlobStore
+	timers     poker.TimerRepository
+	out        io.Writer
+}
+
+func (c *commands) run(ctx context.Context, args []string) error {
+
+	switch args[0] {
+	case "audio-cache":
+		return c.runAudioCache(ctx, args[1:])
+	case "timers":
+		return c.runTimers(ctx, args[1:])
+	case "help", "-h", "--help":
+		_, err := fmt.Fprint(c.out, usage)
+		return err
+	}
+
+	return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+
+}
+
+func (c *commands) runAudioCache(ctx context.Context, args []string) error {
+
+	if len(args) == 0 {
+		return fmt.Errorf("audio-cache expects a subcommand\n\n%s", usage)
+	}
+
+	var prefix string
+	if len(args) > 1 {
+		prefix = args[1]
+	}
+
+	switch args[0] {
+	case "list":
+		blobs, err := c.audioCache.Blobs(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tCONTENT TYPE\tSIZE\tCREATED")
+		for _, blob := range blobs {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", blob.Key, blob.ContentType, blob.Size, blob.CreatedAt.Format(time.RFC3339))
+		}
+
+		return w.Flush()
+	case "purge":
+		purged, err := c.audioCache.PurgeBlobs(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(c.out, "purged %d cached announcements\n", purged)
+		return err
+	}
+
+	return fmt.Errorf("unknown audio-cache subcommand %q\n\n%s", args[0], usage)
+
+}
+
+func (c *commands) runTimers(ctx context.Context, args []string) error {
+
+	if len(args) < 2 {
+		return fmt.Errorf("timers expects a subcommand and its arguments\n\n%s", usage)
+	}
+
+	switch args[0] {
+	case "list":
+		timers, err := c.timers.TimersByUserID(ctx, args[1])
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tLEVELS\tUPDATED")
+		for _, timer := range timers {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", timer.ID, timer.Name, len(timer.Levels), timer.UpdatedAt.Format(time.RFC3339))
+		}
+
+		return w.Flush()
+	case "export":
+		var format = poker.TimerFormatJSON
+		if len(args) > 2 {
+			format = poker.TimerFormat(args[2])
+		}
+
+		if !format.Valid() {
+			return fmt.Errorf("unknown format %q, expected json, yaml or csv", format)
+		}
+
+		timer, err := c.timers.Timer(ctx, args[1])
+		if err != nil {
+			return err
+		}
+
+		if timer == nil {
+			return fmt.Errorf("timer %s not found", args[1])
+		}
+
+		return poker.EncodeTimer(c.out, timer, format)
+	case "import":
+		if len(args) < 3 {
+			return fmt.Errorf("timers import expects a user id and at least one file\n\n%s", usage)
+		}
+
+		// Every file is checked before any are saved so a bad file doesn't leave a bulk import half done
+		userID := args[1]
+		timers := make([]*poker.Timer, 0, len(args)-2)
+		for _, path := range args[2:] {
+			timer, err := importTimerFile(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			timer.UserID = userID
+			err = timer.Validate()
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			timers = append(timers, timer)
+		}
+
+		for _, timer := range timers {
+			err := c.timers.SaveTimer(ctx, timer)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(c.out, "imported %s as %s with %d levels\n", timer.Name, timer.ID, len(timer.Levels))
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown timers subcommand %q\n\n%s", args[0], usage)
+
+}
+
+// importTimerFile decodes the timer in the file at path, named after the file when the file doesn't name it
+func importTimerFile(path string) (*poker.Timer, error) {
+
+	format, ok := poker.TimerFormatFromFilename(path)
+	if !ok {
+		return nil, fmt.Errorf("the format could not be worked out from the file extension, expected .json, .yaml, .yml or .csv")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	timer, err := poker.DecodeTimer(f, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if timer.Name == "" {
+		timer.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	return timer, nil
+
+}
@@ -9,21 +9,60 @@ import (
 var appConfig struct {
 	Mode   string `env:"MODE" default:"server"`
 	AppURL string `env:"APP_URL,required"`
-	Auth0  struct {
-		CallbackURL  string `env:"AUTH0_CALLBACK_URL,required"`
-		ClientID     string `env:"AUTH0_CLIENT_ID,required"`
-		ClientSecret string `ssm:"/poker/auth0-client-secret,required"`
-		Domain       string `env:"AUTH0_DOMAIN,required"`
+	Auth   struct {
+		// Methods is a comma separated list of oidc, password and magic-link, defaults to oidc
+		Methods string `env:"AUTH_METHODS"`
+		// PasswordAlgorithm is one of bcrypt or argon2id, defaults to bcrypt
+		PasswordAlgorithm string `env:"AUTH_PASSWORD_ALGORITHM"`
+		// MagicLinkSecret seals the magic links, defaults to a key derived from the session key
+		MagicLinkSecret string `ssm:"/poker/magic-link-secret" env:"MAGIC_LINK_SECRET"`
+	}
+	// Auth0 is only required when the oidc sign in method is enabled
+	Auth0 struct {
+		CallbackURL  string `env:"AUTH0_CALLBACK_URL"`
+		ClientID     string `env:"AUTH0_CLIENT_ID"`
+		ClientSecret string `ssm:"/poker/auth0-client-secret" env:"AUTH0_CLIENT_SECRET"`
+		Domain       string `env:"AUTH0_DOMAIN"`
+	}
+	Mail struct {
+		// Backend is one of smtp or log, defaults to log
+		Backend      string `env:"MAIL_BACKEND"`
+		From         string `env:"MAIL_FROM"`
+		SMTPHost     string `env:"SMTP_HOST"`
+		SMTPPort     string `env:"SMTP_PORT"`
+		SMTPUsername string `env:"SMTP_USERNAME"`
+		SMTPPassword string `ssm:"/poker/smtp-password" env:"SMTP_PASSWORD"`
 	}
 	Session struct {
-		Key string `ssm:"/poker/session-key,required"`
+		Key string `ssm:"/poker/session-key,required" env:"SESSION_KEY"`
 	}
 	Environment poker.Environment `env:"ENVIRONMENT,required"`
 	Server      struct {
 		Port string `env:"SERVER_PORT" default:"8080"`
 	}
+	Store struct {
+		// Backend is one of dynamo, sqlite or memory, defaults to dynamo
+		Backend    string `env:"STORE_BACKEND"`
+		SQLitePath string `env:"SQLITE_PATH"`
+	}
 	Audio struct {
-		S3Bucket string `env:"POKER_AUDIO_CACHE_BUCKET,required"`
+		// CacheBackend is one of s3, local or memory, defaults to s3
+		CacheBackend string `env:"AUDIO_CACHE_BACKEND"`
+		CacheDir     string `env:"AUDIO_CACHE_DIR"`
+		S3Bucket     string `env:"POKER_AUDIO_CACHE_BUCKET"`
+	}
+	Speech struct {
+		// Backend is one of polly, espeak or tone, defaults to polly
+		Backend string `env:"SPEECH_BACKEND"`
+		Polly   struct {
+			Engine       string `env:"POLLY_ENGINE"`
+			LanguageCode string `env:"POLLY_LANGUAGE_CODE"`
+			VoiceID      string `env:"POLLY_VOICE_ID"`
+		}
+		Espeak struct {
+			Path  string `env:"ESPEAK_PATH"`
+			Voice string `env:"ESPEAK_VOICE"`
+		}
 	}
 }
 
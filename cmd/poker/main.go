@@ -3,17 +3,32 @@ package main
 import (
 	"context"
 	"encoding/gob"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"poker"
 	"poker/internal/authenticator"
+	"poker/internal/blob/local"
+	"poker/internal/blob/memory"
+	s3Blob "poker/internal/blob/s3"
 	"poker/internal/config"
+	logMail "poker/internal/mail/log"
+	"poker/internal/mail/smtp"
 	"poker/internal/server"
+	"poker/internal/speech/espeak"
+	pollySpeech "poker/internal/speech/polly"
+	"poker/internal/speech/tone"
 	"poker/internal/store/dynamo"
+	memoryStore "poker/internal/store/memory"
+	"poker/internal/store/sqlite"
 	"poker/internal/templates"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/akrylysov/algnhsa"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/polly"
@@ -21,6 +36,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/ddouglas/dynastore"
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/sessions"
 	"github.com/sirupsen/logrus"
 )
 
@@ -38,59 +54,79 @@ func main() {
 		logger.WithError(err).Fatal("failed to load aws default config")
 	}
 
-	err = config.Load(
-		ctx,
-		&appConfig,
-		config.WithSSMClient(ssm.NewFromConfig(awsCfg)),
-	)
+	loadOpts := []config.LoadOptFunc{config.WithSSMClient(ssm.NewFromConfig(awsCfg))}
+	// Self hosted installs without aws read their secrets from the environment instead
+	if disable, _ := strconv.ParseBool(os.Getenv("DISABLE_SSM")); disable {
+		loadOpts = append(loadOpts, config.WithoutSSM())
+	}
+
+	err = config.Load(ctx, &appConfig, loadOpts...)
 	if err != nil {
 		logger.WithError(err).Fatal("failed load configuration")
 	}
 
-	dynamodbClient := dynamodb.NewFromConfig(awsCfg)
-	pollyClient := polly.NewFromConfig(awsCfg)
-	s3Client := s3.NewFromConfig(awsCfg)
+	audioCache, err := newAudioCache(awsCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to provision audio cache")
+	}
+
+	store, err := newStore(ctx, awsCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to provision store")
+	}
 
-	sessionStore, _ := dynastore.New(dynamodbClient, dynastore.TableName("poker-sessions-us-east-1"), dynastore.PrimaryKey("ID"))
+	if len(os.Args) > 1 {
+		cmds := &commands{
+			audioCache: audioCache,
+			timers:     store.timers,
+			out:        os.Stdout,
+		}
+
+		err = cmds.run(ctx, os.Args[1:])
+		if err != nil {
+			logger.WithError(err).Fatal("command failed")
+		}
+		return
+	}
+
+	speech, err := newSpeechSynthesizer(awsCfg)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to provision speech synthesizer")
+	}
 
 	gob.Register(make(map[string]any))
 
-	authSrv, err := authenticator.New(&authenticator.Config{
-		ClientID:     appConfig.Auth0.ClientID,
-		ClientSecret: appConfig.Auth0.ClientSecret,
-		Tenant:       appConfig.Auth0.Domain,
-		CallbackURL:  appConfig.Auth0.CallbackURL,
-	})
+	authSrv, err := newAuthenticator()
 	if err != nil {
 		logger.WithError(err).Fatal("failed to provision authenticator service")
 	}
 
-	timerRepo := dynamo.NewTimerRepository(dynamodbClient, "poker-timers-us-east-1")
-	userRepo := dynamo.NewUserRepository(dynamodbClient, "poker-users-us-east-1")
-
 	validator := validator.New(validator.WithRequiredStructEnabled())
 
 	server := server.New(
 		appConfig.Environment,
 		appConfig.AppURL,
 		appConfig.Server.Port,
-		appConfig.Audio.S3Bucket,
 		logger,
 		validator,
 
+		audioCache,
 		authSrv,
-		pollyClient,
-		s3Client,
-		sessionStore,
+		speech,
+		store.sessions,
 
-		timerRepo,
-		userRepo,
+		store.apiTokens,
+		store.timers,
+		store.tournaments,
+		store.users,
+		store.webhooks,
 	)
 
 	tmpl, err := templates.New(
 		appConfig.Environment,
+		appConfig.AppURL,
 		logger,
-		timerRepo,
+		store.timers,
 	)
 	if err != nil {
 		logger.WithError(err).Fatal("failed to provision template service")
@@ -101,8 +137,13 @@ func main() {
 			// RequestType: algnhsa.RequestTypeAPIGatewayV2,
 			// DebugLog: true,
 			// UseProxyPath: true,
+			// Responses of these types are base64 encoded for API Gateway, anything else is passed through as text
 			BinaryContentTypes: []string{
 				"image/jpeg",
+				"application/pdf",
+				"audio/mpeg",
+				"audio/ogg",
+				"audio/wav",
 			},
 		})
 		return
@@ -137,3 +178,188 @@ func main() {
 
 	}
 }
+
+func newSpeechSynthesizer(awsCfg aws.Config) (poker.SpeechSynthesizer, error) {
+
+	switch appConfig.Speech.Backend {
+	case "", "polly":
+		return pollySpeech.New(polly.NewFromConfig(awsCfg), &pollySpeech.Config{
+			Engine:       appConfig.Speech.Polly.Engine,
+			LanguageCode: appConfig.Speech.Polly.LanguageCode,
+			VoiceID:      appConfig.Speech.Polly.VoiceID,
+		}), nil
+	case "espeak":
+		return espeak.New(&espeak.Config{
+			Path:  appConfig.Speech.Espeak.Path,
+			Voice: appConfig.Speech.Espeak.Voice,
+		})
+	case "tone":
+		return tone.New(poker.AssetFS(appConfig.Environment), "audio/10_sec_beep_countdown.mp3"), nil
+	}
+
+	return nil, fmt.Errorf("unsupported speech backend %q, expected one of polly, espeak or tone", appConfig.Speech.Backend)
+
+}
+
+// newAuthenticator provisions the enabled sign in methods, only asking for the configuration of those
+func newAuthenticator() (*authenticator.Service, error) {
+
+	methods, err := authenticator.ParseMethods(appConfig.Auth.Methods)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &authenticator.Config{
+		Methods:           methods,
+		PasswordAlgorithm: authenticator.PasswordAlgorithm(appConfig.Auth.PasswordAlgorithm),
+	}
+
+	for _, method := range methods {
+		switch method {
+		case authenticator.MethodOIDC:
+			cfg.OIDC = &authenticator.OIDCConfig{
+				ClientID:     appConfig.Auth0.ClientID,
+				ClientSecret: appConfig.Auth0.ClientSecret,
+				Tenant:       appConfig.Auth0.Domain,
+				CallbackURL:  appConfig.Auth0.CallbackURL,
+			}
+		case authenticator.MethodPassword:
+			mailer, err := newMailer()
+			if err != nil {
+				return nil, fmt.Errorf("failed to provision mailer: %w", err)
+			}
+
+			cfg.EmailVerification = &authenticator.EmailVerificationConfig{
+				Secret: authenticator.DeriveKey([]byte(appConfig.Session.Key), "email-verification"),
+				Mailer: mailer,
+			}
+		case authenticator.MethodMagicLink:
+			mailer, err := newMailer()
+			if err != nil {
+				return nil, fmt.Errorf("failed to provision mailer: %w", err)
+			}
+
+			// The session key signs cookies, so magic links get a key of their own derived from it
+			secret := []byte(appConfig.Auth.MagicLinkSecret)
+			if len(secret) == 0 {
+				secret = authenticator.DeriveKey([]byte(appConfig.Session.Key), "magic-link")
+			}
+
+			cfg.MagicLink = &authenticator.MagicLinkConfig{
+				Secret: secret,
+				Mailer: mailer,
+			}
+		}
+	}
+
+	return authenticator.New(cfg)
+
+}
+
+func newMailer() (poker.Mailer, error) {
+
+	switch appConfig.Mail.Backend {
+	case "", "log":
+		return logMail.New(logger), nil
+	case "smtp":
+		return smtp.New(&smtp.Config{
+			Host:     appConfig.Mail.SMTPHost,
+			Port:     appConfig.Mail.SMTPPort,
+			Username: appConfig.Mail.SMTPUsername,
+			Password: appConfig.Mail.SMTPPassword,
+			From:     appConfig.Mail.From,
+		})
+	}
+
+	return nil, fmt.Errorf("unsupported mail backend %q, expected one of smtp or log", appConfig.Mail.Backend)
+
+}
+
+func newAudioCache(awsCfg aws.Config) (poker.BlobStore, error) {
+
+	switch appConfig.Audio.CacheBackend {
+	case "", "s3":
+		if appConfig.Audio.S3Bucket == "" {
+			return nil, fmt.Errorf("POKER_AUDIO_CACHE_BUCKET is required when using the s3 audio cache")
+		}
+		return s3Blob.New(s3.NewFromConfig(awsCfg), appConfig.Audio.S3Bucket), nil
+	case "local":
+		return local.New(appConfig.Audio.CacheDir)
+	case "memory":
+		return memory.New(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported audio cache backend %q, expected one of s3, local or memory", appConfig.Audio.CacheBackend)
+
+}
+
+// storage holds the repositories and session store for the configured backend
+type storage struct {
+	apiTokens   poker.APITokenRepository
+	timers      poker.TimerRepository
+	tournaments poker.TournamentRepository
+	users       poker.UserRepository
+	webhooks    poker.WebhookRepository
+	sessions    sessions.Store
+}
+
+// newStore provisions the repositories and the session store for the configured backend. Sessions
+// live in dynamo alongside everything else there, the other backends keep them in a signed cookie
+func newStore(ctx context.Context, awsCfg aws.Config) (*storage, error) {
+
+	switch appConfig.Store.Backend {
+	case "", "dynamo":
+		dynamodbClient := dynamodb.NewFromConfig(awsCfg)
+
+		sessionStore, err := dynastore.New(dynamodbClient, dynastore.TableName("poker-sessions-us-east-1"), dynastore.PrimaryKey("ID"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to provision session store: %w", err)
+		}
+
+		return &storage{
+			apiTokens:   dynamo.NewAPITokenRepository(dynamodbClient, "poker-api-tokens-us-east-1"),
+			timers:      dynamo.NewTimerRepository(dynamodbClient, "poker-timers-us-east-1", "poker-timer-shares-us-east-1"),
+			tournaments: dynamo.NewTournamentRepository(dynamodbClient, "poker-tournaments-us-east-1"),
+			users:       dynamo.NewUserRepository(dynamodbClient, "poker-users-us-east-1"),
+			webhooks:    dynamo.NewWebhookRepository(dynamodbClient, "poker-webhooks-us-east-1", "poker-webhook-deliveries-us-east-1"),
+			sessions:    sessionStore,
+		}, nil
+	case "sqlite":
+		db, err := sqlite.Open(ctx, appConfig.Store.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+		}
+
+		return &storage{
+			apiTokens:   sqlite.NewAPITokenRepository(db),
+			timers:      sqlite.NewTimerRepository(db),
+			tournaments: sqlite.NewTournamentRepository(db),
+			users:       sqlite.NewUserRepository(db),
+			webhooks:    sqlite.NewWebhookRepository(db),
+			sessions:    newCookieStore(),
+		}, nil
+	case "memory":
+		return &storage{
+			apiTokens:   memoryStore.NewAPITokenRepository(),
+			timers:      memoryStore.NewTimerRepository(),
+			tournaments: memoryStore.NewTournamentRepository(),
+			users:       memoryStore.NewUserRepository(),
+			webhooks:    memoryStore.NewWebhookRepository(),
+			sessions:    newCookieStore(),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported store backend %q, expected one of dynamo, sqlite or memory", appConfig.Store.Backend)
+
+}
+
+func newCookieStore() *sessions.CookieStore {
+
+	store := sessions.NewCookieStore([]byte(appConfig.Session.Key))
+	store.Options.HttpOnly = true
+	store.Options.SameSite = http.SameSiteLaxMode
+	store.Options.Secure = appConfig.Environment.IsProduction()
+
+	return store
+
+}
@@ -0,0 +1,183 @@
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// MaxICMPlayers is the most players ICM is worked out for. The work doubles with every extra player,
+// at this size it is still well under a second
+const MaxICMPlayers = 16
+
+// ICM works out each player's share of prizes under the Independent Chip Model, where the chance of a
+// player finishing in the next place to be decided is their share of the chips still in play. prizes
+// starts with first place and places without a prize may be left off. Every ordering of finishes is
+// covered, but the results for each set of players still in are memoized so the work grows with the
+// number of subsets rather than the number of orderings
+func ICM(stacks []float64, prizes []float64) ([]float64, error) {
+
+	n := len(stacks)
+	if n == 0 {
+		return nil, fmt.Errorf("at least one player is needed")
+	}
+
+	if n > MaxICMPlayers {
+		return nil, fmt.Errorf("ICM can be worked out for at most %d players", MaxICMPlayers)
+	}
+
+	if len(prizes) > n {
+		return nil, fmt.Errorf("there are more prizes than players left")
+	}
+
+	for i, chips := range stacks {
+		if chips <= 0 {
+			return nil, fmt.Errorf("player %d must have more than 0 chips", i+1)
+		}
+	}
+
+	// Once every remaining prize has been awarded the players left all get nothing
+	paid := len(prizes)
+	for paid > 0 && prizes[paid-1] == 0 {
+		paid--
+	}
+
+	var zero = make([]float64, n)
+	var memo = make([][]float64, 1<<n)
+
+	// equity returns what each player in the set of players still in can expect to win from the
+	// places they are competing for. Place n-len(set) is the next to be decided, starting with first
+	var equity func(set uint32) []float64
+	equity = func(set uint32) []float64 {
+
+		place := n - bits.OnesCount32(set)
+		if place >= paid {
+			return zero
+		}
+
+		if memo[set] != nil {
+			return memo[set]
+		}
+
+		var total float64
+		for i := 0; i < n; i++ {
+			if set&(1<<i) != 0 {
+				total += stacks[i]
+			}
+		}
+
+		result := make([]float64, n)
+		for i := 0; i < n; i++ {
+			if set&(1<<i) == 0 {
+				continue
+			}
+
+			chance := stacks[i] / total
+			result[i] += chance * prizes[place]
+			for j, value := range equity(set &^ (1 << i)) {
+				result[j] += chance * value
+			}
+		}
+
+		memo[set] = result
+
+		return result
+
+	}
+
+	return equity(1<<n - 1), nil
+
+}
+
+type DealPlayer struct {
+	Name  string
+	Chips float64
+}
+
+// DealShare is what one player walks away with under each way of splitting the prizes
+type DealShare struct {
+	Name  string
+	Chips float64
+	// ChipPercent is the player's share of the chips in play
+	ChipPercent float64
+	ICM         float64
+	ChipChop    float64
+}
+
+// Deal splits the prizes still to be won between the players at a final table
+type Deal struct {
+	Shares []*DealShare
+	// Prizes are the unpaid places, starting with first
+	Prizes []float64
+	// HeldBack is taken out of first place before the deal and played for by the players
+	HeldBack float64
+	// Dealt is the money split between the players, the prizes less HeldBack
+	Dealt float64
+}
+
+// NewDeal works out ICM and chip chop splits of prizes between players. heldBack is kept out of the deal
+// to be played for, it comes out of first place
+func NewDeal(players []*DealPlayer, prizes []float64, heldBack float64) (*Deal, error) {
+
+	if len(players) < 2 {
+		return nil, fmt.Errorf("a deal needs at least 2 players")
+	}
+
+	if len(prizes) == 0 {
+		return nil, fmt.Errorf("there must be at least one prize to deal")
+	}
+
+	var dealt float64
+	for i, prize := range prizes {
+		if prize < 0 {
+			return nil, fmt.Errorf("the prize for place %d must be greater than or equal to 0", i+1)
+		}
+		dealt += prize
+	}
+
+	if heldBack < 0 || heldBack > prizes[0] {
+		return nil, fmt.Errorf("the amount held back must be between 0 and the prize for first place")
+	}
+
+	var totalChips float64
+	stacks := make([]float64, 0, len(players))
+	for i, player := range players {
+		if strings.TrimSpace(player.Name) == "" {
+			return nil, fmt.Errorf("player %d must have a name", i+1)
+		}
+		if player.Chips <= 0 {
+			return nil, fmt.Errorf("%s must have more than 0 chips", strings.TrimSpace(player.Name))
+		}
+		stacks = append(stacks, player.Chips)
+		totalChips += player.Chips
+	}
+
+	remaining := make([]float64, len(prizes))
+	copy(remaining, prizes)
+	remaining[0] -= heldBack
+	dealt -= heldBack
+
+	equities, err := ICM(stacks, remaining)
+	if err != nil {
+		return nil, err
+	}
+
+	deal := &Deal{
+		Prizes:   prizes,
+		HeldBack: heldBack,
+		Dealt:    dealt,
+	}
+
+	for i, player := range players {
+		deal.Shares = append(deal.Shares, &DealShare{
+			Name:        strings.TrimSpace(player.Name),
+			Chips:       player.Chips,
+			ChipPercent: player.Chips / totalChips * 100,
+			ICM:         equities[i],
+			ChipChop:    dealt * player.Chips / totalChips,
+		})
+	}
+
+	return deal, nil
+
+}
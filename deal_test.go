@@ -0,0 +1,148 @@
+package poker
+
+import (
+	"math"
+	"testing"
+)
+
+// bruteForceICM works out ICM by walking every order the players could finish in
+func bruteForceICM(stacks, prizes []float64) []float64 {
+
+	result := make([]float64, len(stacks))
+
+	var walk func(left []int, chance float64, place int)
+	walk = func(left []int, chance float64, place int) {
+		if len(left) == 0 || place >= len(prizes) {
+			return
+		}
+
+		var total float64
+		for _, i := range left {
+			total += stacks[i]
+		}
+
+		for k, i := range left {
+			p := chance * stacks[i] / total
+			result[i] += p * prizes[place]
+
+			rest := append(append([]int{}, left[:k]...), left[k+1:]...)
+			walk(rest, p, place+1)
+		}
+	}
+
+	players := make([]int, len(stacks))
+	for i := range players {
+		players[i] = i
+	}
+	walk(players, 1, 0)
+
+	return result
+
+}
+
+func expectClose(t *testing.T, name string, got, want []float64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("%s: expected %d values, got %d", name, len(want), len(got))
+	}
+
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-6 {
+			t.Errorf("%s: expected player %d to get %v, got %v", name, i+1, want[i], got[i])
+		}
+	}
+}
+
+func TestICM(t *testing.T) {
+
+	tt := []struct {
+		name   string
+		stacks []float64
+		prizes []float64
+	}{
+		{name: "three handed", stacks: []float64{5000, 3000, 2000}, prizes: []float64{500, 300, 200}},
+		{name: "fewer prizes than players", stacks: []float64{1200, 800, 600, 400, 100}, prizes: []float64{700, 300}},
+		{name: "equal stacks", stacks: []float64{100, 100, 100, 100}, prizes: []float64{60, 30, 10}},
+		{name: "unpaid places at the end", stacks: []float64{300, 200, 100}, prizes: []float64{80, 20, 0}},
+	}
+
+	for _, tc := range tt {
+		got, err := ICM(tc.stacks, tc.prizes)
+		if err != nil {
+			t.Fatalf("%s: failed to work out ICM: %s", tc.name, err)
+		}
+
+		expectClose(t, tc.name, got, bruteForceICM(tc.stacks, tc.prizes))
+
+		var total, prizes float64
+		for i := range got {
+			total += got[i]
+		}
+		for _, prize := range tc.prizes {
+			prizes += prize
+		}
+		if math.Abs(total-prizes) > 1e-6 {
+			t.Errorf("%s: expected the shares to add up to the prizes %v, got %v", tc.name, prizes, total)
+		}
+	}
+
+	// Heads up, each player is guaranteed second and plays for the difference by their share of the chips
+	got, err := ICM([]float64{3000, 1000}, []float64{100, 60})
+	if err != nil {
+		t.Fatalf("failed to work out heads up ICM: %s", err)
+	}
+	expectClose(t, "heads up", got, []float64{60 + 40*0.75, 60 + 40*0.25})
+
+}
+
+func TestICMRefused(t *testing.T) {
+
+	_, err := ICM(nil, []float64{100})
+	if err == nil {
+		t.Errorf("expected ICM without players to be refused")
+	}
+
+	_, err = ICM([]float64{100}, []float64{60, 40})
+	if err == nil {
+		t.Errorf("expected more prizes than players to be refused")
+	}
+
+	_, err = ICM([]float64{100, 0}, []float64{60, 40})
+	if err == nil {
+		t.Errorf("expected a player without chips to be refused")
+	}
+
+	_, err = ICM(make([]float64, MaxICMPlayers+1), []float64{100})
+	if err == nil {
+		t.Errorf("expected more than %d players to be refused", MaxICMPlayers)
+	}
+
+}
+
+func TestNewDealHeldBack(t *testing.T) {
+
+	players := []*DealPlayer{{Name: "Ann", Chips: 6000}, {Name: "Bo", Chips: 4000}}
+
+	deal, err := NewDeal(players, []float64{1000, 600}, 200)
+	if err != nil {
+		t.Fatalf("failed to make deal: %s", err)
+	}
+
+	if deal.Dealt != 1400 {
+		t.Errorf("expected 1400 to be dealt once 200 is held back, got %v", deal.Dealt)
+	}
+
+	if deal.Shares[0].ChipChop != 840 || deal.Shares[1].ChipChop != 560 {
+		t.Errorf("expected a chip chop of 840 and 560, got %v and %v", deal.Shares[0].ChipChop, deal.Shares[1].ChipChop)
+	}
+
+	// Both are guaranteed 600 and play for the 200 left of first by their share of the chips
+	expectClose(t, "icm", []float64{deal.Shares[0].ICM, deal.Shares[1].ICM}, []float64{600 + 200*0.6, 600 + 200*0.4})
+
+	_, err = NewDeal(players, []float64{1000, 600}, 1200)
+	if err == nil {
+		t.Errorf("expected holding back more than first place to be refused")
+	}
+
+}
@@ -0,0 +1,242 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+)
+
+// ChipDistribution is how a chip set is split into starting stacks for a field of players
+type ChipDistribution struct {
+	Players       uint
+	StartingStack float64
+	// Stack is the chips each player starts with, smallest denomination first
+	Stack []*ChipCount
+	// Reserve is what is left of the set once every player has a stack, smallest denomination first
+	Reserve      []*ChipCount
+	ReserveValue float64
+	// ExtraStacks is how many more starting stacks can be made from the reserve for rebuys and add-ons
+	ExtraStacks uint
+	// Warnings point out problems that don't stop the field from being dealt in
+	Warnings []string
+}
+
+type ChipCount struct {
+	Chip  *Chip
+	Count uint
+}
+
+// Value is the total value of the chips
+func (c ChipCount) Value() float64 {
+	return c.Chip.Denomination * float64(c.Count)
+}
+
+// changeChips is how many chips of a denomination a stack aims to have for each chip of the next denomination
+// up, so a player can always make change for two of them
+const changeChips = 2
+
+// maxFillUnits bounds the work done making up a stack, it is far more than any home game stack needs
+const maxFillUnits = 1 << 20
+
+// Distribute splits the set into a starting stack of startingStack for each of players. Smaller denominations
+// are handed out first, enough to make change for the next one up, and the rest of the stack is made up from
+// the largest denominations that fit. An error is returned when the set can't give every player a stack
+func (cs ChipSet) Distribute(players uint, startingStack float64) (*ChipDistribution, error) {
+
+	if players == 0 {
+		return nil, fmt.Errorf("players must be greater than 0")
+	}
+
+	if startingStack <= 0 {
+		return nil, fmt.Errorf("starting stack must be greater than 0")
+	}
+
+	err := cs.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if !cs.CanMake(startingStack) {
+		return nil, fmt.Errorf("a starting stack of %v can't be made with the denominations in the set", startingStack)
+	}
+
+	counts, ok := distributeStack(cs.SortedChips(), players, startingStack)
+	if !ok {
+		// Let the organiser know how big a field the set can take at this stack
+		for fewer := players - 1; fewer > 0; fewer-- {
+			if _, ok := distributeStack(cs.SortedChips(), fewer, startingStack); ok {
+				return nil, fmt.Errorf("the set only has enough chips for %d players with a starting stack of %v", fewer, startingStack)
+			}
+		}
+		return nil, fmt.Errorf("the set doesn't have enough chips for a single starting stack of %v", startingStack)
+	}
+
+	distribution := &ChipDistribution{
+		Players:       players,
+		StartingStack: startingStack,
+		ExtraStacks:   math.MaxUint32,
+	}
+
+	for i, chip := range cs.SortedChips() {
+		if counts[i] > 0 {
+			distribution.Stack = append(distribution.Stack, &ChipCount{Chip: chip, Count: counts[i]})
+		}
+
+		reserve := &ChipCount{Chip: chip, Count: chip.Count - counts[i]*players}
+		distribution.Reserve = append(distribution.Reserve, reserve)
+		distribution.ReserveValue += reserve.Value()
+
+		if counts[i] > 0 && reserve.Count/counts[i] < distribution.ExtraStacks {
+			distribution.ExtraStacks = reserve.Count / counts[i]
+		}
+	}
+
+	var chipsPerStack uint
+	for _, count := range distribution.Stack {
+		chipsPerStack += count.Count
+	}
+
+	if distribution.ExtraStacks == 0 {
+		distribution.Warnings = append(distribution.Warnings, "there aren't enough chips left over for any rebuys or add-ons")
+	}
+
+	if len(distribution.Stack) == 1 && len(cs.Chips) > 1 {
+		distribution.Warnings = append(distribution.Warnings, "every stack is a single denomination, players won't be able to make change")
+	} else if counts[0] == 0 && len(cs.Chips) > 1 {
+		smallest := cs.SortedChips()[0]
+		distribution.Warnings = append(distribution.Warnings, fmt.Sprintf("stacks have none of the %v chips, players won't be able to make change for the smallest blinds", smallest.Denomination))
+	}
+
+	if chipsPerStack > 60 {
+		distribution.Warnings = append(distribution.Warnings, fmt.Sprintf("each stack is %d chips, which will be slow to count out and play with", chipsPerStack))
+	}
+
+	return distribution, nil
+
+}
+
+// distributeStack works out how many of each chip, smallest first, go into every stack. It reports false when
+// the chips can't give every player the full stack
+func distributeStack(chips []*Chip, players uint, stack float64) ([]uint, bool) {
+
+	counts := make([]uint, len(chips))
+	available := make([]uint, len(chips))
+	for i, chip := range chips {
+		available[i] = chip.Count / players
+	}
+
+	// Without anything set aside for change the stack has to be made up from the chips as they are
+	if _, ok := fillExact(chips, available, stack); !ok {
+		return nil, false
+	}
+
+	remaining := stack
+
+	// Give each stack enough small chips to make change, leaving room for at least one of the next chip up. Setting
+	// too many aside can leave the rest of the stack impossible to make up, so each denomination sets aside one
+	// fewer at a time until the rest can still be made up
+	for i := 0; i < len(chips)-1; i++ {
+		chip, next := chips[i], chips[i+1]
+		if next.Denomination > remaining {
+			break
+		}
+
+		want := uint(math.Ceil(next.Denomination/chip.Denomination)) * changeChips
+		for want > 0 && (want > available[i] || chip.Denomination*float64(want) > remaining-next.Denomination) {
+			want--
+		}
+
+		for ; want > 0; want-- {
+			available[i] -= want
+			if _, ok := fillExact(chips, available, remaining-chip.Denomination*float64(want)); ok {
+				break
+			}
+			available[i] += want
+		}
+
+		counts[i] = want
+		remaining -= chip.Denomination * float64(want)
+	}
+
+	rest, ok := fillExact(chips, available, remaining)
+	if !ok {
+		return nil, false
+	}
+
+	for i := range counts {
+		counts[i] += rest[i]
+	}
+
+	return counts, true
+
+}
+
+// fillExact makes amount up from at most available[i] of each chip, using as many of the larger chips as it can
+func fillExact(chips []*Chip, available []uint, amount float64) ([]uint, bool) {
+
+	counts := make([]uint, len(chips))
+
+	target := int64(math.Round(amount * 100))
+	if target == 0 {
+		return counts, true
+	}
+
+	var divisor int64
+	coins := make([]int64, len(chips))
+	for i, chip := range chips {
+		coins[i] = int64(math.Round(chip.Denomination * 100))
+		divisor = gcd(divisor, coins[i])
+	}
+
+	if target < 0 || divisor == 0 || target%divisor != 0 || target/divisor > maxFillUnits {
+		return nil, false
+	}
+
+	target /= divisor
+	for i := range coins {
+		coins[i] /= divisor
+	}
+
+	// reachable[i][v] is whether v can be made from the first i chips without going over what is available
+	reachable := make([][]bool, len(chips)+1)
+	reachable[0] = make([]bool, target+1)
+	reachable[0][0] = true
+	for i, coin := range coins {
+		next := make([]bool, target+1)
+		copy(next, reachable[i])
+
+		used := make([]uint, target+1)
+		for v := coin; v <= target; v++ {
+			if !next[v] && next[v-coin] && used[v-coin] < available[i] {
+				next[v] = true
+				used[v] = used[v-coin] + 1
+			}
+		}
+
+		reachable[i+1] = next
+	}
+
+	if !reachable[len(chips)][target] {
+		return nil, false
+	}
+
+	// Take as many of each chip as possible from the largest down, as long as the smaller ones can make the rest
+	v := target
+	for i := len(chips) - 1; i >= 0; i-- {
+		k := uint(v / coins[i])
+		if k > available[i] {
+			k = available[i]
+		}
+
+		for ; ; k-- {
+			if reachable[i][v-int64(k)*coins[i]] {
+				break
+			}
+		}
+
+		counts[i] = k
+		v -= int64(k) * coins[i]
+	}
+
+	return counts, true
+
+}
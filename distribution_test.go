@@ -0,0 +1,146 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+)
+
+func stackCounts(distribution *ChipDistribution) map[float64]uint {
+
+	counts := make(map[float64]uint)
+	for _, count := range distribution.Stack {
+		counts[count.Chip.Denomination] = count.Count
+	}
+
+	return counts
+
+}
+
+func expectStack(t *testing.T, distribution *ChipDistribution, want map[float64]uint) {
+	t.Helper()
+
+	got := stackCounts(distribution)
+
+	var value float64
+	for denomination, count := range got {
+		value += denomination * float64(count)
+		if count != want[denomination] {
+			t.Errorf("expected %d chips of %v in each stack, got %d", want[denomination], denomination, count)
+		}
+	}
+
+	for denomination, count := range want {
+		if _, ok := got[denomination]; !ok && count > 0 {
+			t.Errorf("expected %d chips of %v in each stack, got none", count, denomination)
+		}
+	}
+
+	if value != distribution.StartingStack {
+		t.Errorf("expected each stack to be worth %v, got %v", distribution.StartingStack, value)
+	}
+}
+
+func TestDistribute(t *testing.T) {
+
+	set := ChipSet{Chips: []*Chip{
+		{Denomination: 1000, Color: "yellow", Count: 100},
+		{Denomination: 25, Color: "white", Count: 300},
+		{Denomination: 100, Color: "black", Count: 300},
+		{Denomination: 500, Color: "purple", Count: 100},
+	}}
+
+	distribution, err := set.Distribute(10, 10000)
+	if err != nil {
+		t.Fatalf("failed to distribute: %s", err)
+	}
+
+	// Enough of each chip to make change for two of the next one up, the rest in the largest that fit
+	expectStack(t, distribution, map[float64]uint{25: 8, 100: 13, 500: 5, 1000: 6})
+
+	if distribution.ExtraStacks != 6 {
+		t.Errorf("expected the reserve to make 6 more stacks, got %d", distribution.ExtraStacks)
+	}
+
+	if distribution.ReserveValue+10*10000 != 300*25+300*100+100*500+100*1000 {
+		t.Errorf("expected the reserve and the stacks to add up to the whole set, the reserve is %v", distribution.ReserveValue)
+	}
+
+}
+
+// TestDistributeSetsAsideLessChange has too few small chips to set aside a full two of each for change, fewer are
+// set aside rather than none at all
+func TestDistributeSetsAsideLessChange(t *testing.T) {
+
+	set := ChipSet{Chips: []*Chip{
+		{Denomination: 25, Color: "white", Count: 100},
+		{Denomination: 100, Color: "black", Count: 100},
+		{Denomination: 500, Color: "purple", Count: 50},
+		{Denomination: 1000, Color: "yellow", Count: 50},
+	}}
+
+	distribution, err := set.Distribute(20, 3000)
+	if err != nil {
+		t.Fatalf("failed to distribute: %s", err)
+	}
+
+	expectStack(t, distribution, map[float64]uint{25: 4, 100: 4, 500: 1, 1000: 2})
+
+	for _, warning := range distribution.Warnings {
+		if strings.Contains(warning, "make change") {
+			t.Errorf("expected no warning about making change, got %q", warning)
+		}
+	}
+
+}
+
+func TestDistributeWarnsWithoutSmallestChip(t *testing.T) {
+
+	set := ChipSet{Chips: []*Chip{
+		{Denomination: 25, Color: "white", Count: 10},
+		{Denomination: 100, Color: "black", Count: 100},
+		{Denomination: 500, Color: "purple", Count: 100},
+	}}
+
+	// There aren't enough 25s to go round, so every stack is made up without them
+	distribution, err := set.Distribute(20, 2000)
+	if err != nil {
+		t.Fatalf("failed to distribute: %s", err)
+	}
+
+	if stackCounts(distribution)[25] != 0 {
+		t.Fatalf("expected no 25s in the stacks, got %d", stackCounts(distribution)[25])
+	}
+
+	var warned bool
+	for _, warning := range distribution.Warnings {
+		warned = warned || strings.Contains(warning, "none of the 25 chips")
+	}
+	if !warned {
+		t.Errorf("expected a warning that stacks have none of the smallest chip, got %v", distribution.Warnings)
+	}
+
+}
+
+func TestDistributeRefused(t *testing.T) {
+
+	set := ChipSet{Chips: []*Chip{
+		{Denomination: 25, Color: "white", Count: 100},
+		{Denomination: 100, Color: "black", Count: 100},
+	}}
+
+	_, err := set.Distribute(10, 1010)
+	if err == nil {
+		t.Errorf("expected a stack the denominations can't make to be refused")
+	}
+
+	_, err = set.Distribute(20, 1000)
+	if err == nil || !strings.Contains(err.Error(), "only has enough chips for 12 players") {
+		t.Errorf("expected to be told the set only has enough chips for 12 players, got %v", err)
+	}
+
+	_, err = set.Distribute(0, 1000)
+	if err == nil {
+		t.Errorf("expected a field without players to be refused")
+	}
+
+}
@@ -41,3 +41,20 @@ func (e ValidationError) Error() string {
 
 	return e.Message.Error()
 }
+
+var _ Error = (*ConflictError)(nil)
+
+// ConflictError is returned when saving a record that was changed by someone else after it was loaded
+type ConflictError struct {
+	// Resource names the kind of record, such as timer
+	Resource string
+	ID       string
+}
+
+func (e ConflictError) Public() error {
+	return fmt.Errorf("This %s was changed by someone else while you were working on it, reload to get the latest version and try again", e.Resource)
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("%s %s was changed since it was loaded", e.Resource, e.ID)
+}
@@ -1,64 +1,157 @@
 package authenticator
 
 import (
-	"context"
 	"fmt"
+	"strings"
+)
+
+// Method is a way of signing in, each one is turned on in configuration
+type Method string
 
-	"github.com/coreos/go-oidc/v3/oidc"
-	"golang.org/x/oauth2"
+const (
+	// MethodOIDC signs in through an OpenID Connect provider such as Auth0
+	MethodOIDC Method = "oidc"
+	// MethodPassword signs in with an email address and a password kept with the user
+	MethodPassword Method = "password"
+	// MethodMagicLink signs in by following a link emailed to the user
+	MethodMagicLink Method = "magic-link"
 )
 
-type Service struct {
-	*oidc.Provider
-	*oauth2.Config
+func (m Method) String() string {
+	return string(m)
+}
+
+var allMethods = []Method{MethodOIDC, MethodPassword, MethodMagicLink}
+
+func (m Method) Valid() bool {
+	for _, method := range allMethods {
+		if method == m {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMethods reads a comma separated list of methods. An empty list is OIDC on its own, which is how sign in
+// worked before there was a choice
+func ParseMethods(value string) ([]Method, error) {
+
+	if strings.TrimSpace(value) == "" {
+		return []Method{MethodOIDC}, nil
+	}
+
+	methods := make([]Method, 0, len(allMethods))
+	for _, part := range strings.Split(value, ",") {
+		method := Method(strings.TrimSpace(part))
+		if !method.Valid() {
+			return nil, fmt.Errorf("unsupported sign in method %q, expected one of oidc, password or magic-link", method)
+		}
+
+		methods = append(methods, method)
+	}
+
+	return methods, nil
 
-	IssuerURL string
 }
 
 type Config struct {
-	Tenant       string
-	ClientID     string
-	ClientSecret string
-	CallbackURL  string
+	Methods []Method
+	// OIDC is required when MethodOIDC is enabled
+	OIDC *OIDCConfig
+	// MagicLink is required when MethodMagicLink is enabled
+	MagicLink *MagicLinkConfig
+	// EmailVerification is required when MethodPassword is enabled
+	EmailVerification *EmailVerificationConfig
+	// PasswordAlgorithm hashes new passwords when MethodPassword is enabled, defaults to bcrypt
+	PasswordAlgorithm PasswordAlgorithm
+}
+
+// Service holds the enabled sign in methods. OIDC and MagicLink are nil unless their method is enabled, and
+// EmailVerification unless MethodPassword is
+type Service struct {
+	methods           []Method
+	passwordAlgorithm PasswordAlgorithm
+
+	OIDC              *OIDC
+	MagicLink         *MagicLink
+	EmailVerification *EmailVerification
 }
 
 func New(cfg *Config) (*Service, error) {
 
-	var issuerURL = fmt.Sprintf("https://%s/", cfg.Tenant)
+	if len(cfg.Methods) == 0 {
+		return nil, fmt.Errorf("at least one sign in method must be enabled")
+	}
+
+	s := &Service{
+		methods:           cfg.Methods,
+		passwordAlgorithm: cfg.PasswordAlgorithm,
+	}
 
-	provider, err := oidc.NewProvider(
-		context.Background(),
-		issuerURL,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to provision oidc provider: %w", err)
+	if s.passwordAlgorithm == "" {
+		s.passwordAlgorithm = PasswordAlgorithmBcrypt
 	}
 
-	return &Service{
-		Provider: provider,
-		Config: &oauth2.Config{
-			ClientID:     cfg.ClientID,
-			ClientSecret: cfg.ClientSecret,
-			RedirectURL:  cfg.CallbackURL,
-			Endpoint:     provider.Endpoint(),
-			Scopes:       []string{oidc.ScopeOpenID, "profile"},
-		},
-		IssuerURL: issuerURL,
-	}, nil
+	var err error
+	for _, method := range cfg.Methods {
+		switch method {
+		case MethodOIDC:
+			if cfg.OIDC == nil {
+				return nil, fmt.Errorf("oidc config is required when the oidc method is enabled")
+			}
 
-}
+			s.OIDC, err = NewOIDC(cfg.OIDC)
+			if err != nil {
+				return nil, fmt.Errorf("failed to provision oidc provider: %w", err)
+			}
+		case MethodMagicLink:
+			if cfg.MagicLink == nil {
+				return nil, fmt.Errorf("magic link config is required when the magic-link method is enabled")
+			}
 
-func (a *Service) VerifyIDToken(ctx context.Context, token *oauth2.Token) (*oidc.IDToken, error) {
+			s.MagicLink, err = NewMagicLink(cfg.MagicLink)
+			if err != nil {
+				return nil, fmt.Errorf("failed to provision magic links: %w", err)
+			}
+		case MethodPassword:
+			// Passwords are kept with the user, there is only the algorithm to check and the email addresses of new
+			// accounts to verify
+			if !s.passwordAlgorithm.Valid() {
+				return nil, fmt.Errorf("unsupported password algorithm %q, expected one of bcrypt or argon2id", s.passwordAlgorithm)
+			}
 
-	raw, ok := token.Extra("id_token").(string)
-	if !ok {
-		return nil, fmt.Errorf("field id_token missing from oauth2 token")
-	}
+			if cfg.EmailVerification == nil {
+				return nil, fmt.Errorf("email verification config is required when the password method is enabled")
+			}
 
-	oidcConfig := &oidc.Config{
-		ClientID: a.ClientID,
+			s.EmailVerification, err = NewEmailVerification(cfg.EmailVerification)
+			if err != nil {
+				return nil, fmt.Errorf("failed to provision email verification: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported sign in method %q", method)
+		}
 	}
 
-	return a.Verifier(oidcConfig).Verify(ctx, raw)
+	return s, nil
+
+}
+
+// Methods are the enabled methods in the order they were configured, which is the order they are offered
+func (s *Service) Methods() []Method {
+	return s.methods
+}
+
+func (s *Service) Enabled(method Method) bool {
+	for _, m := range s.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
 
+// HashPassword hashes password with the configured algorithm
+func (s *Service) HashPassword(password string) (string, error) {
+	return HashPassword(s.passwordAlgorithm, password)
 }
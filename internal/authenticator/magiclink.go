@@ -0,0 +1,101 @@
+package authenticator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"poker"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidMagicLink is returned for a link that has been tampered with, has expired or has already been used
+var ErrInvalidMagicLink = errors.New("the sign in link is invalid, has expired or has already been used, request a new one")
+
+type MagicLinkConfig struct {
+	// Secret seals the links, changing it invalidates every link that has been sent
+	Secret []byte
+	// TTL is how long a link can be used for, defaults to 15 minutes
+	TTL    time.Duration
+	Mailer poker.Mailer
+}
+
+// MagicLink signs users in with a link emailed to them. The link carries the email address and when it was sent and
+// expires, sealed so it can't be changed, which means nothing needs to be stored until it is followed. Each link can
+// only be used once, see poker.User.MagicLinkSentAt
+type MagicLink struct {
+	sealer sealer
+	ttl    time.Duration
+	mailer poker.Mailer
+}
+
+func NewMagicLink(cfg *MagicLinkConfig) (*MagicLink, error) {
+
+	if len(cfg.Secret) < 16 {
+		return nil, fmt.Errorf("secret must be 16 or more bytes in length")
+	}
+
+	if cfg.Mailer == nil {
+		return nil, fmt.Errorf("mailer is required")
+	}
+
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+
+	sealer, err := newSealer(cfg.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MagicLink{
+		sealer: sealer,
+		ttl:    ttl,
+		mailer: cfg.Mailer,
+	}, nil
+
+}
+
+// Token returns the sealed token for email, valid until the link's TTL has passed
+func (m *MagicLink) Token(email string, now time.Time) (string, error) {
+
+	return m.sealer.seal(email, strconv.FormatInt(now.UnixNano(), 10), strconv.FormatInt(now.Add(m.ttl).Unix(), 10))
+
+}
+
+// Verify returns the email address the token was made for and when it was sent
+func (m *MagicLink) Verify(token string, now time.Time) (string, time.Time, error) {
+
+	fields, ok := m.sealer.open(token, 3)
+	if !ok {
+		return "", time.Time{}, ErrInvalidMagicLink
+	}
+
+	sentAt, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidMagicLink
+	}
+
+	expiresAt, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return "", time.Time{}, ErrInvalidMagicLink
+	}
+
+	return fields[0], time.Unix(0, sentAt), nil
+
+}
+
+// Send emails link, which must carry a token from Token, to email
+func (m *MagicLink) Send(ctx context.Context, email, link string) error {
+
+	return m.mailer.SendMail(ctx, &poker.Mail{
+		To:      email,
+		Subject: "Your sign in link",
+		Body: fmt.Sprintf(
+			"Follow the link below to sign in, it can be used once in the next %d minutes.\n\n%s\n\nIf you didn't ask to sign in you can ignore this email.\n",
+			int(m.ttl.Minutes()), link,
+		),
+	})
+
+}
@@ -0,0 +1,120 @@
+package authenticator
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+type OIDCConfig struct {
+	Tenant       string
+	ClientID     string
+	ClientSecret string
+	CallbackURL  string
+}
+
+// OIDC signs users in through an OpenID Connect provider. The provider's discovery document is only fetched the
+// first time someone signs in, so the app still starts when the provider can't be reached
+type OIDC struct {
+	cfg *OIDCConfig
+
+	IssuerURL string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	oauth    *oauth2.Config
+}
+
+func NewOIDC(cfg *OIDCConfig) (*OIDC, error) {
+
+	if cfg.Tenant == "" || cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.CallbackURL == "" {
+		return nil, fmt.Errorf("tenant, client id, client secret and callback url are all required")
+	}
+
+	return &OIDC{
+		cfg:       cfg,
+		IssuerURL: fmt.Sprintf("https://%s/", cfg.Tenant),
+	}, nil
+
+}
+
+// discover fetches the provider's configuration, keeping it once it has been fetched successfully
+func (o *OIDC) discover(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider != nil {
+		return o.provider, o.oauth, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, o.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover oidc provider: %w", err)
+	}
+
+	o.provider = provider
+	o.oauth = &oauth2.Config{
+		ClientID:     o.cfg.ClientID,
+		ClientSecret: o.cfg.ClientSecret,
+		RedirectURL:  o.cfg.CallbackURL,
+		Endpoint:     provider.Endpoint(),
+		// email is asked for as accounts are found by the verified address the provider gives
+		Scopes: []string{oidc.ScopeOpenID, "profile", "email"},
+	}
+
+	return o.provider, o.oauth, nil
+
+}
+
+// AuthCodeURL is where the user is sent to sign in with the provider
+func (o *OIDC) AuthCodeURL(ctx context.Context, state string) (string, error) {
+
+	_, config, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(state), nil
+
+}
+
+func (o *OIDC) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+
+	_, config, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Exchange(ctx, code)
+
+}
+
+func (o *OIDC) VerifyIDToken(ctx context.Context, token *oauth2.Token) (*oidc.IDToken, error) {
+
+	provider, _, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("field id_token missing from oauth2 token")
+	}
+
+	oidcConfig := &oidc.Config{
+		ClientID: o.cfg.ClientID,
+	}
+
+	return provider.Verifier(oidcConfig).Verify(ctx, raw)
+
+}
+
+// LogoutURL ends the user's session with the provider before sending them back to returnTo
+func (o *OIDC) LogoutURL(returnTo string) string {
+	return fmt.Sprintf("%sv2/logout?client_id=%s&returnTo=%s", o.IssuerURL, o.cfg.ClientID, url.QueryEscape(returnTo))
+}
@@ -0,0 +1,152 @@
+package authenticator
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the fewest characters a password can have
+const MinPasswordLength = 8
+
+// ErrInvalidCredentials is returned for a wrong email or password, without saying which was wrong
+var ErrInvalidCredentials = errors.New("the email address or password is incorrect")
+
+// PasswordAlgorithm is how new passwords are hashed. Either algorithm's hashes can always be checked, so changing
+// it only affects passwords set afterwards
+type PasswordAlgorithm string
+
+const (
+	PasswordAlgorithmBcrypt   PasswordAlgorithm = "bcrypt"
+	PasswordAlgorithmArgon2ID PasswordAlgorithm = "argon2id"
+)
+
+func (a PasswordAlgorithm) Valid() bool {
+	return a == PasswordAlgorithmBcrypt || a == PasswordAlgorithmArgon2ID
+}
+
+// argon2id parameters, the second recommended option from RFC 9106 for when memory is constrained
+const (
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+	argon2KeyLen  = 32
+	argon2SaltLen = 16
+)
+
+// dummyHash is compared against when there is no user, so a missing account takes as long to reject as a wrong
+// password and can't be told apart by timing
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("poker-dummy-password"), bcrypt.DefaultCost)
+
+// HashPassword returns the hash of password to keep with the user, using algorithm
+func HashPassword(algorithm PasswordAlgorithm, password string) (string, error) {
+
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be %d or more characters in length", MinPasswordLength)
+	}
+
+	switch algorithm {
+	case PasswordAlgorithmBcrypt:
+		// bcrypt ignores everything past 72 bytes, so longer passwords are refused rather than silently shortened
+		if len(password) > 72 {
+			return "", fmt.Errorf("password must be 72 bytes or fewer in length")
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+
+		return string(hash), nil
+	case PasswordAlgorithmArgon2ID:
+		salt := make([]byte, argon2SaltLen)
+		_, err := rand.Read(salt)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+		// The PHC string format, which records the parameters so they can be changed without breaking old hashes
+		return fmt.Sprintf(
+			"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	}
+
+	return "", fmt.Errorf("unsupported password algorithm %q", algorithm)
+
+}
+
+// CheckPassword compares password with the hash kept with the user, whichever algorithm made it. An empty hash,
+// for a user without a password or no user at all, is always rejected with ErrInvalidCredentials
+func CheckPassword(hash, password string) error {
+
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return ErrInvalidCredentials
+	}
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return checkArgon2ID(hash, password)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check password: %w", err)
+	}
+
+	return nil
+
+}
+
+func checkArgon2ID(hash, password string) error {
+
+	// "", "argon2id", version, parameters, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return fmt.Errorf("failed to check password: malformed argon2id hash")
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return fmt.Errorf("failed to check password: unsupported argon2id version %q", parts[2])
+	}
+
+	var memory, time uint32
+	var threads uint8
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+	if err != nil {
+		return fmt.Errorf("failed to check password: malformed argon2id parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return fmt.Errorf("failed to check password: malformed argon2id salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return fmt.Errorf("failed to check password: malformed argon2id key: %w", err)
+	}
+
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrInvalidCredentials
+	}
+
+	return nil
+
+}
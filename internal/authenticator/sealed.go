@@ -0,0 +1,87 @@
+package authenticator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// DeriveKey derives a key for one purpose from key, so a single secret such as the session key can be used for
+// several kinds of token without a token of one kind being accepted as another
+func DeriveKey(key []byte, purpose string) []byte {
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+
+	return mac.Sum(nil)
+
+}
+
+// sealer makes tokens carrying fields that can't be read or changed without the secret. Only the last field can
+// contain a new line
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(secret []byte) (sealer, error) {
+
+	key := sha256.Sum256(secret)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return sealer{}, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return sealer{}, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return sealer{aead: aead}, nil
+
+}
+
+// seal joins fields into a payload and returns it encrypted under a random nonce
+func (s sealer) seal(fields ...string) (string, error) {
+
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, []byte(strings.Join(fields, "\n")), nil)
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+
+}
+
+// open returns the n fields of a token made by seal, reporting false when it has been changed or doesn't have
+// n fields
+func (s sealer) open(token string, n int) ([]string, bool) {
+
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return nil, false
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+
+	payload, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, false
+	}
+
+	fields := strings.SplitN(string(payload), "\n", n)
+	if len(fields) != n {
+		return nil, false
+	}
+
+	return fields, true
+
+}
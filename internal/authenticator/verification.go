@@ -0,0 +1,109 @@
+package authenticator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"poker"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidVerification is returned for a registration link that has been tampered with or has expired
+var ErrInvalidVerification = errors.New("the registration link is invalid or has expired, register again")
+
+type EmailVerificationConfig struct {
+	// Secret seals the links, changing it invalidates every link that has been sent
+	Secret []byte
+	// TTL is how long a link can be used for, defaults to 24 hours
+	TTL    time.Duration
+	Mailer poker.Mailer
+}
+
+// Registration is an account waiting for its email address to be verified
+type Registration struct {
+	Email        string
+	Name         string
+	PasswordHash string
+}
+
+// EmailVerification proves a password registration was made by the owner of the email address. The link emailed
+// carries the registration, encrypted so the password hash can't be read or changed, which means no account exists
+// and no password can be used until it is followed
+type EmailVerification struct {
+	sealer sealer
+	ttl    time.Duration
+	mailer poker.Mailer
+}
+
+func NewEmailVerification(cfg *EmailVerificationConfig) (*EmailVerification, error) {
+
+	if len(cfg.Secret) < 16 {
+		return nil, fmt.Errorf("secret must be 16 or more bytes in length")
+	}
+
+	if cfg.Mailer == nil {
+		return nil, fmt.Errorf("mailer is required")
+	}
+
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	sealer, err := newSealer(cfg.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailVerification{
+		sealer: sealer,
+		ttl:    ttl,
+		mailer: cfg.Mailer,
+	}, nil
+
+}
+
+// Token returns the sealed token for registration, valid until the link's TTL has passed
+func (v *EmailVerification) Token(registration *Registration, now time.Time) (string, error) {
+
+	// The name goes last as it is the only field that could hold a new line
+	return v.sealer.seal(
+		registration.Email,
+		strconv.FormatInt(now.Add(v.ttl).Unix(), 10),
+		registration.PasswordHash,
+		registration.Name,
+	)
+
+}
+
+// Verify returns the registration the token was made for
+func (v *EmailVerification) Verify(token string, now time.Time) (*Registration, error) {
+
+	fields, ok := v.sealer.open(token, 4)
+	if !ok {
+		return nil, ErrInvalidVerification
+	}
+
+	expiresAt, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return nil, ErrInvalidVerification
+	}
+
+	return &Registration{Email: fields[0], PasswordHash: fields[2], Name: fields[3]}, nil
+
+}
+
+// Send emails link, which must carry a token from Token, to email
+func (v *EmailVerification) Send(ctx context.Context, email, link string) error {
+
+	return v.mailer.SendMail(ctx, &poker.Mail{
+		To:      email,
+		Subject: "Finish creating your account",
+		Body: fmt.Sprintf(
+			"Follow the link below to finish creating your account, it can be used for the next %d hours.\n\n%s\n\nIf you didn't create an account you can ignore this email, no account is made until the link is followed.\n",
+			int(v.ttl.Hours()), link,
+		),
+	})
+
+}
@@ -0,0 +1,246 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"poker"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ poker.BlobStore = (*Store)(nil)
+
+// metaSuffix is appended to the name of each blob to get the name of the file holding its metadata
+const metaSuffix = ".meta.json"
+
+type meta struct {
+	ContentType string
+	CreatedAt   time.Time
+}
+
+// Store keeps blobs as files under a directory on the local disk, for deployments that don't have a bucket
+type Store struct {
+	dir string
+}
+
+func New(dir string) (*Store, error) {
+
+	if dir == "" {
+		return nil, fmt.Errorf("directory cannot be empty")
+	}
+
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	return &Store{
+		dir: dir,
+	}, nil
+
+}
+
+// path maps a key onto a file under the store's directory, refusing keys that would escape it
+func (s *Store) path(key string) (string, error) {
+
+	if key == "" || strings.HasSuffix(key, metaSuffix) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+
+	return path, nil
+
+}
+
+func (s *Store) Blob(_ context.Context, key string) (*poker.Blob, error) {
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+
+	m, err := readMeta(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &poker.Blob{
+		BlobInfo: poker.BlobInfo{
+			Key:         key,
+			ContentType: m.ContentType,
+			Size:        int64(len(data)),
+			CreatedAt:   m.CreatedAt,
+		},
+		Data: data,
+	}, nil
+
+}
+
+func (s *Store) Blobs(_ context.Context, prefix string) ([]*poker.BlobInfo, error) {
+
+	var infos = make([]*poker.BlobInfo, 0)
+
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || strings.HasSuffix(path, metaSuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		m, err := readMeta(path)
+		if err != nil {
+			return err
+		}
+
+		infos = append(infos, &poker.BlobInfo{
+			Key:         key,
+			ContentType: m.ContentType,
+			Size:        fi.Size(),
+			CreatedAt:   m.CreatedAt,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs: %w", err)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+
+	return infos, nil
+
+}
+
+func (s *Store) SaveBlob(_ context.Context, blob *poker.Blob) error {
+
+	path, err := s.path(blob.Key)
+	if err != nil {
+		return err
+	}
+
+	if blob.CreatedAt.IsZero() {
+		blob.CreatedAt = time.Now()
+	}
+
+	blob.Size = int64(len(blob.Data))
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory for blob %s: %w", blob.Key, err)
+	}
+
+	data, err := json.Marshal(meta{
+		ContentType: blob.ContentType,
+		CreatedAt:   blob.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata for blob %s: %w", blob.Key, err)
+	}
+
+	err = os.WriteFile(path+metaSuffix, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write metadata for blob %s: %w", blob.Key, err)
+	}
+
+	err = os.WriteFile(path, blob.Data, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", blob.Key, err)
+	}
+
+	return nil
+
+}
+
+func (s *Store) DeleteBlob(_ context.Context, key string) error {
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range []string{path, path + metaSuffix} {
+		err = os.Remove(p)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete blob %s: %w", key, err)
+		}
+	}
+
+	return nil
+
+}
+
+func (s *Store) PurgeBlobs(ctx context.Context, prefix string) (int, error) {
+
+	infos, err := s.Blobs(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, info := range infos {
+		err = s.DeleteBlob(ctx, info.Key)
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(infos), nil
+
+}
+
+func readMeta(path string) (*meta, error) {
+
+	var m = new(meta)
+
+	data, err := os.ReadFile(path + metaSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata for %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(data, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metadata for %s: %w", path, err)
+	}
+
+	return m, nil
+
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"poker"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ poker.BlobStore = (*Store)(nil)
+
+// Store keeps blobs in memory. Everything is lost when the process exits, so it is meant
+// for tests and for trying the app out locally
+type Store struct {
+	mu    sync.RWMutex
+	blobs map[string]*poker.Blob
+}
+
+func New() *Store {
+	return &Store{
+		blobs: make(map[string]*poker.Blob),
+	}
+}
+
+func (s *Store) Blob(_ context.Context, key string) (*poker.Blob, error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blob, ok := s.blobs[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return &poker.Blob{
+		BlobInfo: blob.BlobInfo,
+		Data:     bytes.Clone(blob.Data),
+	}, nil
+
+}
+
+func (s *Store) Blobs(_ context.Context, prefix string) ([]*poker.BlobInfo, error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var infos = make([]*poker.BlobInfo, 0)
+	for key, blob := range s.blobs {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		info := blob.BlobInfo
+		infos = append(infos, &info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+
+	return infos, nil
+
+}
+
+func (s *Store) SaveBlob(_ context.Context, blob *poker.Blob) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if blob.CreatedAt.IsZero() {
+		blob.CreatedAt = time.Now()
+	}
+
+	blob.Size = int64(len(blob.Data))
+
+	s.blobs[blob.Key] = &poker.Blob{
+		BlobInfo: blob.BlobInfo,
+		Data:     bytes.Clone(blob.Data),
+	}
+
+	return nil
+
+}
+
+func (s *Store) DeleteBlob(_ context.Context, key string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blobs, key)
+
+	return nil
+
+}
+
+func (s *Store) PurgeBlobs(_ context.Context, prefix string) (int, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int
+	for key := range s.blobs {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		delete(s.blobs, key)
+		purged++
+	}
+
+	return purged, nil
+
+}
@@ -0,0 +1,187 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"poker"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ poker.BlobStore = (*Store)(nil)
+
+// createdAtMetadataKey is the user defined object metadata the creation time of a blob is kept under
+const createdAtMetadataKey = "created-at"
+
+// Store keeps blobs as objects in an S3 bucket
+type Store struct {
+	client *awss3.Client
+	bucket string
+}
+
+func New(client *awss3.Client, bucket string) *Store {
+	return &Store{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+func (s *Store) Blob(ctx context.Context, key string) (*poker.Blob, error) {
+
+	output, err := s.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch object %s: %w", key, err)
+	}
+
+	defer output.Body.Close()
+
+	var buffer = new(bytes.Buffer)
+	_, err = buffer.ReadFrom(output.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+
+	return &poker.Blob{
+		BlobInfo: poker.BlobInfo{
+			Key:         key,
+			ContentType: aws.ToString(output.ContentType),
+			Size:        int64(buffer.Len()),
+			CreatedAt:   createdAt(output.Metadata, output.LastModified),
+		},
+		Data: buffer.Bytes(),
+	}, nil
+
+}
+
+// Blobs lists the objects under prefix. S3 doesn't return user metadata when listing,
+// so the creation time of each blob is the time the object was last written
+func (s *Store) Blobs(ctx context.Context, prefix string) ([]*poker.BlobInfo, error) {
+
+	var infos = make([]*poker.BlobInfo, 0)
+
+	paginator := awss3.NewListObjectsV2Paginator(s.client, &awss3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, object := range page.Contents {
+			infos = append(infos, &poker.BlobInfo{
+				Key:       aws.ToString(object.Key),
+				Size:      object.Size,
+				CreatedAt: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+
+	return infos, nil
+
+}
+
+func (s *Store) SaveBlob(ctx context.Context, blob *poker.Blob) error {
+
+	if blob.CreatedAt.IsZero() {
+		blob.CreatedAt = time.Now()
+	}
+
+	blob.Size = int64(len(blob.Data))
+
+	_, err := s.client.PutObject(ctx, &awss3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(blob.Key),
+		Body:        bytes.NewReader(blob.Data),
+		ContentType: aws.String(blob.ContentType),
+		Metadata: map[string]string{
+			createdAtMetadataKey: blob.CreatedAt.UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put object %s: %w", blob.Key, err)
+	}
+
+	return nil
+
+}
+
+func (s *Store) DeleteBlob(ctx context.Context, key string) error {
+
+	_, err := s.client.DeleteObject(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object %s: %w", key, err)
+	}
+
+	return nil
+
+}
+
+func (s *Store) PurgeBlobs(ctx context.Context, prefix string) (int, error) {
+
+	infos, err := s.Blobs(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int
+
+	// DeleteObjects accepts at most 1000 keys per request
+	for start := 0; start < len(infos); start += 1000 {
+		end := start + 1000
+		if end > len(infos) {
+			end = len(infos)
+		}
+
+		objects := make([]types.ObjectIdentifier, 0, end-start)
+		for _, info := range infos[start:end] {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(info.Key)})
+		}
+
+		_, err = s.client.DeleteObjects(ctx, &awss3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &types.Delete{
+				Objects: objects,
+				Quiet:   true,
+			},
+		})
+		if err != nil {
+			return purged, fmt.Errorf("failed to delete objects: %w", err)
+		}
+
+		purged += len(objects)
+	}
+
+	return purged, nil
+
+}
+
+func createdAt(metadata map[string]string, lastModified *time.Time) time.Time {
+
+	if value, ok := metadata[createdAtMetadataKey]; ok {
+		t, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return t
+		}
+	}
+
+	return aws.ToTime(lastModified)
+
+}
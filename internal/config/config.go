@@ -18,13 +18,17 @@ type pathConfig struct {
 	name     string
 	required bool
 	provider string
+	// fallback is the environment variable that supplies an ssm value when it is set, so the
+	// app can run without access to ssm
+	fallback string
 	value    reflect.Value
 	// value    *string
 }
 
 type LoadOpts struct {
-	prefix string
-	client *ssm.Client
+	prefix     string
+	client     *ssm.Client
+	disableSSM bool
 }
 
 type LoadOptFunc func(o *LoadOpts)
@@ -35,6 +39,13 @@ func WithSSMClient(c *ssm.Client) LoadOptFunc {
 	}
 }
 
+// WithoutSSM never reads from ssm. Values tagged with ssm are only filled from their env fallback
+func WithoutSSM() LoadOptFunc {
+	return func(o *LoadOpts) {
+		o.disableSSM = true
+	}
+}
+
 func WithPrefix(prefix string) LoadOptFunc {
 	return func(o *LoadOpts) {
 		o.prefix = prefix
@@ -61,7 +72,7 @@ func Load(ctx context.Context, out any, optFuncs ...LoadOptFunc) error {
 
 	outValue = outValue.Elem()
 
-	if opts.client == nil {
+	if opts.client == nil && !opts.disableSSM {
 		config, err := awsConfig.LoadDefaultConfig(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to load default config for aws: %w", err)
@@ -73,13 +84,13 @@ func Load(ctx context.Context, out any, optFuncs ...LoadOptFunc) error {
 	names = append(names, getEnvRecursiveTags(outValue)...)
 
 	envPathName := make([]string, 0, len(names))
-	ssmPathNames := make([]string, 0, len(names))
+	ssmPaths := make([]*pathConfig, 0, len(names))
 	for _, nc := range names {
 		switch nc.provider {
 		case "env":
 			envPathName = append(envPathName, nc.name)
 		case "ssm":
-			ssmPathNames = append(ssmPathNames, nc.name)
+			ssmPaths = append(ssmPaths, nc)
 		default:
 			return fmt.Errorf("unhandled provider %s: %s", nc.provider, nc.name)
 		}
@@ -93,13 +104,21 @@ func Load(ctx context.Context, out any, optFuncs ...LoadOptFunc) error {
 		envList := os.Environ()
 
 		for _, e := range envList {
-			ee := strings.Split(e, "=")
-			name, value := ee[0], ee[1]
+			name, value, _ := strings.Cut(e, "=")
 			resultMap[name] = value
 		}
 
 	}
 
+	// Parameters whose env fallback is set are never fetched
+	ssmPathNames := make([]string, 0, len(ssmPaths))
+	for _, nc := range ssmPaths {
+		if opts.disableSSM || hasFallback(nc, resultMap) {
+			continue
+		}
+		ssmPathNames = append(ssmPathNames, nc.name)
+	}
+
 	if len(ssmPathNames) > 0 {
 		result, err := opts.client.GetParameters(ctx, &ssm.GetParametersInput{
 			Names:          ssmPathNames,
@@ -118,6 +137,10 @@ func Load(ctx context.Context, out any, optFuncs ...LoadOptFunc) error {
 	for _, p := range names {
 
 		result, ok := resultMap[p.name]
+		if !ok && hasFallback(p, resultMap) {
+			// The env entry for the same field sets it
+			continue
+		}
 		if !ok {
 			fmt.Printf("%s is missing, required: %t\n", p.name, p.required)
 			if p.required {
@@ -135,6 +158,14 @@ func Load(ctx context.Context, out any, optFuncs ...LoadOptFunc) error {
 
 }
 
+func hasFallback(p *pathConfig, env map[string]string) bool {
+	if p.fallback == "" {
+		return false
+	}
+	_, ok := env[p.fallback]
+	return ok
+}
+
 func setFieldValue(field reflect.Value, value string) {
 	switch field.Kind() {
 	case reflect.String:
@@ -185,10 +216,13 @@ func getSSMRecursiveTags(v reflect.Value, prefix string) []*pathConfig {
 			required = true
 		}
 
+		fallback, _, _ := strings.Cut(fieldT.Tag.Get("env"), ",")
+
 		nameConfigs = append(nameConfigs, &pathConfig{
 			name:     path.Join(prefix, parts[0]),
 			required: required,
 			provider: "ssm",
+			fallback: fallback,
 			value:    field,
 		})
 	}
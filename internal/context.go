@@ -9,6 +9,7 @@ type contextKey uint
 
 const (
 	userCtxKey contextKey = iota
+	apiTokenCtxKey
 )
 
 func ContextWithUser(ctx context.Context, user *poker.User) context.Context {
@@ -29,3 +30,17 @@ func UserFromContext(ctx context.Context) *poker.User {
 	return nil
 
 }
+
+// ContextWithAPIToken records the token a request to the API was authenticated with
+func ContextWithAPIToken(ctx context.Context, token *poker.APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenCtxKey, token)
+}
+
+// APITokenFromContext returns the token the request was authenticated with, nil for requests from the browser
+func APITokenFromContext(ctx context.Context) *poker.APIToken {
+
+	token, _ := ctx.Value(apiTokenCtxKey).(*poker.APIToken)
+
+	return token
+
+}
@@ -2,10 +2,13 @@
 interface ElementsAndAttributes {
     timerContainer: HTMLElement
     timer: HTMLElement
-    timerToggle: HTMLElement
+    timerToggle: HTMLElement | null
     nextTimerButton: HTMLElement | null
     nextLevelURI: string
+    refreshURI: string
     durationSecStr: string
+    remainingSecStr: string
+    clockRunning: boolean
     audioPlay: HTMLAudioElement | null
     audioContinue: HTMLAudioElement | null
     audioBeep: HTMLAudioElement | null
@@ -31,8 +34,7 @@ export function fetchElements(): ElementsAndAttributes | null {
     }
 
     if (!timerToggle) {
-        console.error("failed to fetch toggle-timer-button element by id")
-        return null
+        console.debug("no toggle-timer-button element, timer is read only")
     }
 
     let nextLevelURI: string = ""
@@ -41,12 +43,21 @@ export function fetchElements(): ElementsAndAttributes | null {
         nextLevelURI = nextTimerButton.getAttribute("hx-get") || ""
     }
 
+    // Read only displays don't have a next button, they ask the server for the masthead instead
+    const refreshURI = timerContainer.getAttribute("data-refresh-uri") || ""
+
     let durationSecStr = timer.getAttribute("data-level-duration-sec")
     if (!durationSecStr) {
         console.error("trigger-next-timer-level element is missing attribute data-level-duration-sec")
         durationSecStr = "0"
     }
 
+    let remainingSecStr = timer.getAttribute("data-level-remaining-sec")
+    if (!remainingSecStr) {
+        remainingSecStr = durationSecStr
+    }
+
+    const clockRunning = timer.getAttribute("data-clock-running") === "true"
 
     return {
         timer,
@@ -54,7 +65,10 @@ export function fetchElements(): ElementsAndAttributes | null {
         timerToggle,
         nextTimerButton,
         nextLevelURI,
+        refreshURI,
         durationSecStr,
+        remainingSecStr,
+        clockRunning,
         audioPlay,
         audioContinue,
         audioBeep
@@ -1,5 +1,5 @@
-import { fetchElements } from "./elements"
-import { initCountdown, startCountdown, stopCountdown, toggleCountdown } from "./main"
+import { initCountdown, playAnnouncement, startCountdown, stopCountdown } from "./main"
+import { initStream } from "./stream"
 
 var abort: AbortController
 
@@ -13,7 +13,7 @@ document.addEventListener("DOMContentLoaded", () => {
 
     console.log("DOMContentLoaded :: start")
     initCountdown()
-    initTimerToggleEventClick()
+    initStream()
     console.log("DOMContentLoaded :: complete")
 })
 
@@ -24,6 +24,13 @@ document.body.addEventListener("countdown::proceed", () => {
     console.debug("countdown::proceed :: complete")
 })
 
+document.body.addEventListener("countdown::start", () => {
+    console.debug("countdown::start :: start")
+    resetCountdown()
+    playAnnouncement()
+    console.debug("countdown::start :: complete")
+})
+
 document.body.addEventListener("countdown::reset", () => {
     console.debug("countdown::reset :: start")
     resetCountdown()
@@ -35,19 +42,4 @@ function resetCountdown() {
     abort.abort()
     initAbort()
     initCountdown()
-    initTimerToggleEventClick()
 }
-
-function initTimerToggleEventClick() {
-
-    const elements = fetchElements()
-    if (!elements) {
-        console.error("failed to fetch elements, unable to register click event on timer toggle")
-        return
-    }
-
-    const { timerToggle } = elements
-    timerToggle.addEventListener("click", () => toggleCountdown(), { signal: abort.signal })
-
-}
-
@@ -18,8 +18,14 @@ export function initCountdown() {
     const {
         // Endpoint that HTMX will use to reach out and fetch the next level
         nextLevelURI,
-        // A String representation of the number of seconds that the time needs to run for
-        durationSecStr,
+        // Endpoint that a read only display uses to fetch the masthead once the level runs out
+        refreshURI,
+        // A String representation of the number of seconds left in the level according to the server
+        remainingSecStr,
+        // Whether the server has the clock running, in which case we pick up where it is
+        clockRunning,
+        // The HTMLElement representing the toggle button
+        timerToggle,
         // The HTMLElement representing the text of our timer
         timer,
         // The HTMLAudioElement that house the beep sound that starts playing at 11 seconds remaining
@@ -30,8 +36,8 @@ export function initCountdown() {
     // no seconds are returns. The attribute is not set on the element, so here we just make sure that we
     // didn't receive an empty string
     let parsedDuractionSec: number = 0
-    if (durationSecStr) {
-        parsedDuractionSec = parseInt(durationSecStr)
+    if (remainingSecStr) {
+        parsedDuractionSec = parseInt(remainingSecStr)
     }
 
     countdown = new Countdown({
@@ -56,7 +62,15 @@ export function initCountdown() {
         },
         onComplete: () => {
 
-            if (nextLevelURI) {
+            if (refreshURI) {
+                setTimeout(() => {
+                    htmx.ajax(
+                        'GET',
+                        refreshURI,
+                        htmx.find('#timer-container')
+                    )
+                }, 1000)
+            } else if (nextLevelURI) {
                 const nextLevelURIProceed = `${nextLevelURI}?proceed=true`
                 setTimeout(() => {
                     console.log("timeout set for 1 second")
@@ -76,53 +90,40 @@ export function initCountdown() {
         }
     })
 
+    if (clockRunning) {
+        countdown.start()
+        if (timerToggle) {
+            htmx.removeClass(timerToggle, "fa-circle-play")
+            htmx.addClass(timerToggle, "fa-circle-stop")
+        }
+    }
+
     console.debug("initCountdown :: complete")
 
 }
 
-export function toggleCountdown() {
+export function playAnnouncement() {
 
-    console.debug("toggleCountdown :: start")
-
-    if (!countdown) {
-        console.error("timerToggleEventClick :: countdown not set")
-        return
-    }
+    console.debug("playAnnouncement :: start")
 
     const elements = fetchElements()
     if (!elements) {
-        console.error("failed to fetch elements, unable to register click event on timer toggle")
+        console.error("failed to fetch elements, unable to play announcement")
         return
     }
 
-    const { timerToggle } = elements
-
-    countdown.toggle()
-
-
-    if (!countdown.getIsRunning()) {
-        htmx.removeClass(timerToggle, "fa-circle-stop")
-        htmx.addClass(timerToggle, "fa-circle-play")
-    } else {
-
-        if (!countdown.hasCounted()) {
-            // The Clock has started. Blinds are now XXX/XXX
-            const { audioPlay } = elements
-            if (!audioPlay) {
-                console.error("audio play is undefined :-(")
-            }
-            audioPlay?.play().then(r => {
-                console.log("audio is playing")
-            }).catch(e => {
-                console.error("There was an issue playing audio play", e)
-            })
-        }
-
-        htmx.removeClass(timerToggle, "fa-circle-play")
-        htmx.addClass(timerToggle, "fa-circle-stop")
+    // The Clock has started. Blinds are now XXX/XXX
+    const { audioPlay } = elements
+    if (!audioPlay) {
+        console.error("audio play is undefined :-(")
     }
+    audioPlay?.play().then(r => {
+        console.log("audio is playing")
+    }).catch(e => {
+        console.error("There was an issue playing audio play", e)
+    })
 
-    console.debug("toggleCountdown :: complete")
+    console.debug("playAnnouncement :: complete")
 
 }
 
@@ -145,8 +146,10 @@ export function stopCountdown() {
 
     const { timerToggle } = elements
 
-    htmx.removeClass(timerToggle, "fa-circle-stop")
-    htmx.addClass(timerToggle, "fa-circle-play")
+    if (timerToggle) {
+        htmx.removeClass(timerToggle, "fa-circle-stop")
+        htmx.addClass(timerToggle, "fa-circle-play")
+    }
 
     console.debug("stopCountdown :: complete")
 
@@ -164,7 +167,7 @@ export function startCountdown() {
 
 
     // The Clock has started. Blinds are now XXX/XXX
-    const { timerToggle, audioContinue } = elements
+    const { audioContinue } = elements
 
     audioContinue?.play().then(r => {
         console.log("audio is playing")
@@ -172,12 +175,6 @@ export function startCountdown() {
         console.error("There was an issue playing audio continue", e)
     })
 
-
-    htmx.removeClass(timerToggle, "fa-circle-stop")
-    htmx.addClass(timerToggle, "fa-circle-play")
-    countdown?.start()
-
     console.debug("startCountdown :: complete")
 
 }
-
@@ -0,0 +1,69 @@
+declare var htmx: any
+
+// How often the masthead is fetched while the event stream is not delivering
+const pollIntervalMS = 10000
+
+// initStream subscribes to the server's event stream for this timer. Whenever another display
+// changes the level or the clock we fetch a fresh masthead, so every screen swaps together.
+// When the stream is not open, as is the case behind lambda where responses are buffered
+// rather than streamed, we poll the masthead instead so displays still converge
+export function initStream() {
+
+    console.debug("initStream :: start")
+
+    const eventsURI = document.body.getAttribute("data-events-uri")
+    const mastheadURI = document.body.getAttribute("data-masthead-uri")
+    if (!eventsURI || !mastheadURI) {
+        console.debug("initStream :: no event stream for this page")
+        return
+    }
+
+    const refresh = () => {
+        htmx.ajax(
+            'GET',
+            mastheadURI,
+            htmx.find('#timer-container')
+        )
+    }
+
+    let disconnected = false
+    const source = new EventSource(eventsURI)
+
+    source.addEventListener("timer", (e: MessageEvent) => {
+        console.debug("stream :: received timer event", e.data)
+        refresh()
+    })
+
+    source.addEventListener("error", () => {
+        if (source.readyState === EventSource.CLOSED) {
+            // The server refused to let us reconnect, most likely because the link was revoked,
+            // so reload and let the server tell the viewer what happened
+            console.error("stream :: connection refused, reloading")
+            window.location.reload()
+            return
+        }
+
+        console.error("stream :: connection lost, the browser will reconnect")
+        disconnected = true
+    })
+
+    setInterval(() => {
+        if (source.readyState === EventSource.OPEN) {
+            return
+        }
+
+        console.debug("stream :: not connected, polling masthead")
+        refresh()
+    }, pollIntervalMS)
+
+    source.addEventListener("open", () => {
+        // Anything could have happened while we were disconnected
+        if (disconnected) {
+            disconnected = false
+            refresh()
+        }
+    })
+
+    console.debug("initStream :: complete")
+
+}
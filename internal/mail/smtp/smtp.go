@@ -0,0 +1,83 @@
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"poker"
+	"strings"
+	"time"
+)
+
+var _ poker.Mailer = (*Mailer)(nil)
+
+type Config struct {
+	Host string
+	Port string
+	// Username and Password are optional, mail is sent without authenticating when Username is empty
+	Username string
+	Password string
+	// From is the address mail is sent from
+	From string
+}
+
+// Mailer sends mail through an SMTP server, upgrading to TLS when the server offers it
+type Mailer struct {
+	cfg *Config
+}
+
+func New(cfg *Config) (*Mailer, error) {
+
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("host cannot be empty")
+	}
+
+	if cfg.From == "" {
+		return nil, fmt.Errorf("from address cannot be empty")
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+
+	return &Mailer{
+		cfg: cfg,
+	}, nil
+
+}
+
+func (m *Mailer) SendMail(_ context.Context, mail *poker.Mail) error {
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	err := smtp.SendMail(net.JoinHostPort(m.cfg.Host, m.cfg.Port), auth, m.cfg.From, []string{mail.To}, m.message(mail))
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+
+	return nil
+
+}
+
+// headerValue strips line breaks so a value can't add headers of its own
+var headerValue = strings.NewReplacer("\r", "", "\n", "")
+
+func (m *Mailer) message(mail *poker.Mail) []byte {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", headerValue.Replace(m.cfg.From))
+	fmt.Fprintf(&b, "To: %s\r\n", headerValue.Replace(mail.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerValue.Replace(mail.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+
+}
@@ -0,0 +1,221 @@
+// Package openapi describes an HTTP API as an OpenAPI 3 document. Schemas are made from the Go types the API
+// encodes rather than written by hand, so the document changes whenever the types do
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       *Info                 `json:"info"`
+	Servers    []*Server             `json:"servers,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem is the operations of a path by lower case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// TokenScope and TimerRole are extensions naming what a request needs, for tools that check them
+	TokenScope string `json:"x-token-scope,omitempty"`
+	TimerRole  string `json:"x-timer-role,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// SecurityRequirement is the scopes needed of each security scheme by name
+type SecurityRequirement map[string][]string
+
+type Schema struct {
+	// Ref names a schema in the document's components, no other field is set alongside it
+	Ref        string             `json:"$ref,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+}
+
+// refPrefix starts the Ref of every schema kept in a document's components
+const refPrefix = "#/components/schemas/"
+
+// RefName is the name of the component schema referenced, empty when the schema isn't a reference
+func (s *Schema) RefName() string {
+	return strings.TrimPrefix(s.Ref, refPrefix)
+}
+
+// Schemas makes schemas from Go types. Structs become component schemas named after their type and are referenced
+// wherever they are used, everything else is described inline
+type Schemas struct {
+	enums      map[reflect.Type][]string
+	components map[string]*Schema
+}
+
+func NewSchemas() *Schemas {
+	return &Schemas{
+		enums:      make(map[reflect.Type][]string),
+		components: make(map[string]*Schema),
+	}
+}
+
+// Enum lists the values a string type may have. values is a slice of the type, such as poker.AllLevelTypes
+func (s *Schemas) Enum(values any) {
+
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.String {
+		panic(fmt.Sprintf("openapi: enum values must be a slice of a string type, got %T", values))
+	}
+
+	enum := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		enum = append(enum, v.Index(i).String())
+	}
+
+	s.enums[v.Type().Elem()] = enum
+
+}
+
+// Of is the schema of v's type
+func (s *Schemas) Of(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+// Components is every struct schema made so far by name
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (s *Schemas) schema(t reflect.Type) *Schema {
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string", Enum: s.enums[t]}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var zero float64
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Struct:
+		return s.component(t)
+	}
+
+	panic(fmt.Sprintf("openapi: %s can't be described by a schema", t))
+
+}
+
+// component adds the schema of the struct to the components, returning a reference to it
+func (s *Schemas) component(t reflect.Type) *Schema {
+
+	ref := &Schema{Ref: refPrefix + t.Name()}
+	if _, ok := s.components[t.Name()]; ok {
+		return ref
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// Added before the fields are described so a struct that contains itself is only described once
+	s.components[t.Name()] = schema
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = s.schema(field.Type)
+
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return ref
+
+}
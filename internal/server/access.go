@@ -0,0 +1,198 @@
+package server
+
+import (
+	"net/http"
+	"poker"
+	"poker/internal"
+
+	"github.com/gorilla/mux"
+)
+
+// timerRouteRoles is the role needed on a timer to use each route with a timerID, by route name and then method.
+// A timer route or method missing from here is refused to everyone, so new timer routes must be added here
+var timerRouteRoles = map[string]map[string]poker.TimerRole{
+	"dashboard-timer": {
+		http.MethodGet:    poker.TimerRoleViewer,
+		http.MethodDelete: poker.TimerRoleOwner,
+	},
+	"dashboard-timer-display": {
+		http.MethodPost:   poker.TimerRoleOwner,
+		http.MethodDelete: poker.TimerRoleOwner,
+	},
+	"dashboard-timer-export":    {http.MethodGet: poker.TimerRoleViewer},
+	"dashboard-timer-sheet":     {http.MethodGet: poker.TimerRoleViewer},
+	"dashboard-timer-sheet-pdf": {http.MethodGet: poker.TimerRoleViewer},
+	"dashboard-timer-duplicate": {http.MethodPost: poker.TimerRoleViewer},
+	"dashboard-timer-template": {
+		http.MethodPost:   poker.TimerRoleOwner,
+		http.MethodDelete: poker.TimerRoleOwner,
+	},
+	"dashboard-timer-chips": {
+		http.MethodPost:   poker.TimerRoleEditor,
+		http.MethodDelete: poker.TimerRoleEditor,
+	},
+	"dashboard-timer-schedule": {http.MethodPost: poker.TimerRoleOperator},
+	"dashboard-timer-chips-distribution": {
+		http.MethodGet:  poker.TimerRoleViewer,
+		http.MethodPost: poker.TimerRoleViewer,
+	},
+	"dashboard-timer-chips-distribution-print": {http.MethodPost: poker.TimerRoleViewer},
+	"dashboard-timer-shares":                   {http.MethodPost: poker.TimerRoleOwner},
+	"dashboard-timer-share":                    {http.MethodDelete: poker.TimerRoleOwner},
+	"dashboard-timer-webhooks": {
+		http.MethodGet:  poker.TimerRoleOwner,
+		http.MethodPost: poker.TimerRoleOwner,
+	},
+	"dashboard-timer-webhook":      {http.MethodDelete: poker.TimerRoleOwner},
+	"dashboard-timer-webhook-test": {http.MethodPost: poker.TimerRoleOwner},
+	"dashboard-timer-levels": {
+		http.MethodGet:  poker.TimerRoleEditor,
+		http.MethodPost: poker.TimerRoleEditor,
+	},
+	"dashboard-timer-levels-reorder": {http.MethodPost: poker.TimerRoleEditor},
+	"dashboard-timer-levels-bulk":    {http.MethodPost: poker.TimerRoleEditor},
+	"dashboard-timer-level": {
+		http.MethodGet:    poker.TimerRoleEditor,
+		http.MethodPost:   poker.TimerRoleEditor,
+		http.MethodDelete: poker.TimerRoleEditor,
+	},
+	"dashboard-timer-level-action": {http.MethodPost: poker.TimerRoleEditor},
+	// The audio is played by whoever is running the clock
+	"dashboard-timer-level-audio": {http.MethodGet: poker.TimerRoleOperator},
+
+	"play-timer":                {http.MethodGet: poker.TimerRoleViewer},
+	"play-timer-masthead":       {http.MethodGet: poker.TimerRoleViewer},
+	"play-timer-events":         {http.MethodGet: poker.TimerRoleViewer},
+	"play-timer-reset-level":    {http.MethodGet: poker.TimerRoleOperator},
+	"play-timer-next-level":     {http.MethodGet: poker.TimerRoleOperator},
+	"play-timer-previous-level": {http.MethodGet: poker.TimerRoleOperator},
+	"play-timer-clock-start":    {http.MethodGet: poker.TimerRoleOperator},
+	"play-timer-clock-pause":    {http.MethodGet: poker.TimerRoleOperator},
+	"play-timer-clock-resume":   {http.MethodGet: poker.TimerRoleOperator},
+
+	"api-timer": {
+		http.MethodGet:    poker.TimerRoleViewer,
+		http.MethodPatch:  poker.TimerRoleEditor,
+		http.MethodDelete: poker.TimerRoleOwner,
+	},
+	"api-timer-levels": {
+		http.MethodGet:  poker.TimerRoleViewer,
+		http.MethodPost: poker.TimerRoleEditor,
+	},
+	"api-timer-level": {
+		http.MethodGet:    poker.TimerRoleViewer,
+		http.MethodPut:    poker.TimerRoleEditor,
+		http.MethodDelete: poker.TimerRoleEditor,
+	},
+	"api-timer-play": {http.MethodPost: poker.TimerRoleOperator},
+}
+
+// timerInvitationRoutes are used by someone the timer was shared with to answer their invitation, before they
+// have any role on it, so they only need to have been invited
+var timerInvitationRoutes = map[string]bool{
+	"dashboard-timer-invitation": true,
+}
+
+// timerAccess authorizes every request to a route with a timerID against the signed in user's role on the timer.
+// Users with no role at all are told the timer doesn't exist, so timer ids can't be probed
+func (s *server) timerAccess(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var ctx = r.Context()
+
+		timerID, ok := mux.Vars(r)["timerID"]
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		var name string
+		if route := mux.CurrentRoute(r); route != nil {
+			name = route.GetName()
+		}
+
+		entry := s.logger.WithContext(ctx).WithField("timerID", timerID).WithField("routeName", name)
+
+		required, known := timerRouteRoles[name][r.Method]
+		invitation := timerInvitationRoutes[name]
+		if !known && !invitation {
+			entry.WithField("method", r.Method).Error("timer route has no role configured, refusing request")
+			s.writeTimerDenied(w, r, http.StatusForbidden)
+			return
+		}
+
+		timer, err := s.timerRepo.Timer(ctx, timerID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch timer")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		user := internal.UserFromContext(ctx)
+
+		if invitation {
+			if timer == nil || timer.Share(user.ID) == nil {
+				entry.Error("user has not been invited to timer")
+				s.writeTimerDenied(w, r, http.StatusNotFound)
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		var role poker.TimerRole
+		if timer != nil {
+			role = timer.RoleOf(user.ID)
+		}
+
+		if role == "" {
+			entry.Error("timer not found or not shared with authenticated user")
+			s.writeTimerDenied(w, r, http.StatusNotFound)
+			return
+		}
+
+		if !role.Includes(required) {
+			entry.WithField("role", role).WithField("required", required).Error("user's role on timer does not allow request")
+			s.writeTimerDenied(w, r, http.StatusForbidden)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+
+	})
+}
+
+// writeTimerDenied renders an error page for pages opened directly, htmx requests only get the status so
+// nothing is swapped in. API requests are answered with a JSON error
+func (s *server) writeTimerDenied(w http.ResponseWriter, r *http.Request, status int) {
+
+	var ctx = r.Context()
+
+	if isAPIRequest(r) {
+		message := "timer not found"
+		if status == http.StatusForbidden {
+			message = "your role on the timer does not allow this request"
+		}
+
+		s.writeAPIError(w, status, message)
+		return
+	}
+
+	w.WriteHeader(status)
+
+	if r.Method != http.MethodGet || r.Header.Get("HX-Request") != "" {
+		return
+	}
+
+	var err error
+	if status == http.StatusNotFound {
+		err = s.templates.ErrorNotFound(ctx).Render(w)
+	} else {
+		err = s.templates.ResourceUnavailable(ctx).Render(w)
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to render timer access error")
+	}
+
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"poker"
+	"poker/internal"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// newAccessRouter serves every route of timerRouteRoles and timerInvitationRoutes behind timerAccess at
+// /timers/{timerID}/<route name>, answering 204 to any request it lets through
+func newAccessRouter(s *server) *mux.Router {
+
+	router := mux.NewRouter()
+	router.Use(s.timerAccess)
+
+	allowed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for name := range timerRouteRoles {
+		router.Handle("/timers/{timerID}/"+name, allowed).Name(name)
+	}
+	for name := range timerInvitationRoutes {
+		router.Handle("/timers/{timerID}/"+name, allowed).Name(name)
+	}
+
+	return router
+
+}
+
+func accessStatus(router *mux.Router, userID, timerID, name, method string) int {
+
+	r := httptest.NewRequest(method, "/timers/"+timerID+"/"+name, nil)
+	r.Header.Set("HX-Request", "true")
+	r = r.WithContext(internal.ContextWithUser(r.Context(), &poker.User{ID: userID}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	return w.Code
+}
+
+// TestTimerAccess checks every user against every route of timerRouteRoles. Users with a role below the one a route
+// needs are forbidden, and anyone without a role, an invitation that hasn't been accepted included, is told the
+// timer doesn't exist
+func TestTimerAccess(t *testing.T) {
+
+	s, _, _ := newLoginServer(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	timer := &poker.Timer{ID: "shared", UserID: "owner", Name: "Shared"}
+	for _, role := range []poker.TimerRole{poker.TimerRoleViewer, poker.TimerRoleOperator, poker.TimerRoleEditor} {
+		user := &poker.User{ID: role.String(), Email: role.String() + "@poker.test"}
+		_ = timer.Invite(user, role, now)
+		_ = timer.AcceptInvitation(user.ID, now)
+	}
+	_ = timer.Invite(&poker.User{ID: "invited", Email: "invited@poker.test"}, poker.TimerRoleEditor, now)
+
+	err := s.timerRepo.SaveTimer(ctx, timer)
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	router := newAccessRouter(s)
+
+	// allowed lists who may use a route needing each role
+	allowed := map[poker.TimerRole]map[string]bool{
+		poker.TimerRoleViewer:   {"viewer": true, "operator": true, "editor": true, "owner": true},
+		poker.TimerRoleOperator: {"operator": true, "editor": true, "owner": true},
+		poker.TimerRoleEditor:   {"editor": true, "owner": true},
+		poker.TimerRoleOwner:    {"owner": true},
+	}
+
+	users := []struct {
+		id      string
+		hasRole bool
+	}{
+		{id: "viewer", hasRole: true},
+		{id: "operator", hasRole: true},
+		{id: "editor", hasRole: true},
+		{id: "owner", hasRole: true},
+		{id: "invited"},
+		{id: "stranger"},
+	}
+
+	names := make([]string, 0, len(timerRouteRoles))
+	for name := range timerRouteRoles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for method, required := range timerRouteRoles[name] {
+			for _, user := range users {
+				want := http.StatusNotFound
+				switch {
+				case allowed[required][user.id]:
+					want = http.StatusNoContent
+				case user.hasRole:
+					want = http.StatusForbidden
+				}
+
+				if got := accessStatus(router, user.id, timer.ID, name, method); got != want {
+					t.Errorf("%s %s as %s: expected %d, got %d", method, name, user.id, want, got)
+				}
+			}
+		}
+	}
+
+	for _, user := range users {
+		if got := accessStatus(router, user.id, "missing", "api-timer", http.MethodGet); got != http.StatusNotFound {
+			t.Errorf("GET api-timer of a missing timer as %s: expected 404, got %d", user.id, got)
+		}
+	}
+
+	// A method a route doesn't list is refused to everyone, the owner included
+	if got := accessStatus(router, "owner", timer.ID, "play-timer-next-level", http.MethodDelete); got != http.StatusForbidden {
+		t.Errorf("DELETE play-timer-next-level as owner: expected 403, got %d", got)
+	}
+
+}
+
+// TestTimerRouteRoles pins the role needed for the routes that change who can use a timer or what it does, so
+// loosening one shows up here as well as in timerRouteRoles
+func TestTimerRouteRoles(t *testing.T) {
+
+	tt := []struct {
+		name   string
+		method string
+		want   poker.TimerRole
+	}{
+		{name: "dashboard-timer", method: http.MethodGet, want: poker.TimerRoleViewer},
+		{name: "dashboard-timer", method: http.MethodDelete, want: poker.TimerRoleOwner},
+		{name: "dashboard-timer-shares", method: http.MethodPost, want: poker.TimerRoleOwner},
+		{name: "dashboard-timer-share", method: http.MethodDelete, want: poker.TimerRoleOwner},
+		{name: "dashboard-timer-display", method: http.MethodPost, want: poker.TimerRoleOwner},
+		{name: "dashboard-timer-webhooks", method: http.MethodPost, want: poker.TimerRoleOwner},
+		{name: "dashboard-timer-levels", method: http.MethodPost, want: poker.TimerRoleEditor},
+		{name: "dashboard-timer-chips", method: http.MethodPost, want: poker.TimerRoleEditor},
+		{name: "play-timer", method: http.MethodGet, want: poker.TimerRoleViewer},
+		{name: "play-timer-next-level", method: http.MethodGet, want: poker.TimerRoleOperator},
+		{name: "play-timer-clock-start", method: http.MethodGet, want: poker.TimerRoleOperator},
+		{name: "api-timer", method: http.MethodPatch, want: poker.TimerRoleEditor},
+		{name: "api-timer", method: http.MethodDelete, want: poker.TimerRoleOwner},
+		{name: "api-timer-play", method: http.MethodPost, want: poker.TimerRoleOperator},
+	}
+
+	for _, tc := range tt {
+		if got := timerRouteRoles[tc.name][tc.method]; got != tc.want {
+			t.Errorf("%s %s: expected to need %s, got %q", tc.method, tc.name, tc.want, got)
+		}
+	}
+
+	// Every method of every route with a timer id behind timerAccess must be configured, or it is refused to everyone.
+	// Displays are opened with their token on the top level router, which timerAccess isn't used on
+	s, _, _ := newLoginServer(t)
+	err := s.router.Walk(func(route *mux.Route, _ *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.Contains(path, "{timerID}") || len(ancestors) == 0 {
+			return nil
+		}
+
+		name := route.GetName()
+		if timerInvitationRoutes[name] {
+			return nil
+		}
+
+		methods, _ := route.GetMethods()
+		for _, method := range methods {
+			if _, ok := timerRouteRoles[name][method]; !ok {
+				t.Errorf("%s %s (%s) has no role configured", method, name, path)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %s", err)
+	}
+
+}
+
+// TestTimerInvitationAccess lets someone with an invitation answer it before accepting, and nobody else
+func TestTimerInvitationAccess(t *testing.T) {
+
+	s, _, _ := newLoginServer(t)
+	now := time.Now()
+
+	timer := &poker.Timer{ID: "shared", UserID: "owner", Name: "Shared"}
+	_ = timer.Invite(&poker.User{ID: "invited", Email: "invited@poker.test"}, poker.TimerRoleViewer, now)
+
+	err := s.timerRepo.SaveTimer(context.Background(), timer)
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	router := newAccessRouter(s)
+
+	for user, want := range map[string]int{"invited": http.StatusNoContent, "stranger": http.StatusNotFound} {
+		if got := accessStatus(router, user, timer.ID, "dashboard-timer-invitation", http.MethodPost); got != want {
+			t.Errorf("answering the invitation as %s: expected %d, got %d", user, want, got)
+		}
+	}
+
+	// Until it is accepted the invitation grants nothing else
+	if got := accessStatus(router, "invited", timer.ID, "play-timer", http.MethodGet); got != http.StatusNotFound {
+		t.Errorf("expected a pending invitation not to open the timer, got %d", got)
+	}
+
+}
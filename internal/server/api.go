@@ -0,0 +1,663 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// apiRouteScopes is the scope a token needs to use each API route, by route name and then method. Like
+// timerRouteRoles, an API route or method missing from here is refused to every token
+var apiRouteScopes = map[string]map[string]poker.TokenScope{
+	"api-timers": {
+		http.MethodGet:  poker.TokenScopeRead,
+		http.MethodPost: poker.TokenScopeWrite,
+	},
+	"api-timer": {
+		http.MethodGet:    poker.TokenScopeRead,
+		http.MethodPatch:  poker.TokenScopeWrite,
+		http.MethodDelete: poker.TokenScopeWrite,
+	},
+	"api-timer-levels": {
+		http.MethodGet:  poker.TokenScopeRead,
+		http.MethodPost: poker.TokenScopeWrite,
+	},
+	"api-timer-level": {
+		http.MethodGet:    poker.TokenScopeRead,
+		http.MethodPut:    poker.TokenScopeWrite,
+		http.MethodDelete: poker.TokenScopeWrite,
+	},
+	"api-timer-play": {http.MethodPost: poker.TokenScopeControl},
+}
+
+// apiTokenUsedInterval is how often a token's LastUsedAt is updated, so a busy script doesn't save the token on
+// every request
+const apiTokenUsedInterval = time.Minute
+
+// apiMaxBodyBytes is the largest request body the API reads, far more than a timer with hundreds of levels needs
+const apiMaxBodyBytes = 1 << 20
+
+// apiAuth authenticates API requests with the bearer token in the Authorization header and checks the token has
+// the scope the route needs. Session cookies are never accepted, so a page can't make API requests as its visitor
+func (s *server) apiAuth(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var ctx = r.Context()
+
+		var name string
+		if route := mux.CurrentRoute(r); route != nil {
+			name = route.GetName()
+		}
+
+		entry := s.logger.WithContext(ctx).WithField("routeName", name)
+
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		secret = strings.TrimSpace(secret)
+		if !ok || secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="poker"`)
+			s.writeAPIError(w, http.StatusUnauthorized, "an api token is required, send it in the Authorization header as a Bearer token")
+			return
+		}
+
+		token, err := s.apiTokenRepo.APITokenByHash(ctx, poker.HashAPIToken(secret))
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch api token")
+			s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+			return
+		}
+
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="poker", error="invalid_token"`)
+			s.writeAPIError(w, http.StatusUnauthorized, "the api token is invalid or has been revoked")
+			return
+		}
+
+		entry = entry.WithField("tokenID", token.ID)
+
+		user, err := s.userRepo.User(ctx, token.UserID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch user for api token")
+			s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+			return
+		}
+
+		if user == nil {
+			entry.Error("api token belongs to a user that no longer exists")
+			s.writeAPIError(w, http.StatusUnauthorized, "the api token is invalid or has been revoked")
+			return
+		}
+
+		required, known := apiRouteScopes[name][r.Method]
+		if !known {
+			entry.WithField("method", r.Method).Error("api route has no scope configured, refusing request")
+			s.writeAPIError(w, http.StatusForbidden, "the api token can't be used for this request")
+			return
+		}
+
+		if !token.HasScope(required) {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="poker", error="insufficient_scope", scope="%s"`, required))
+			s.writeAPIError(w, http.StatusForbidden, fmt.Sprintf("the api token does not have the %s scope", required))
+			return
+		}
+
+		now := time.Now()
+		if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenUsedInterval {
+			token.LastUsedAt = &now
+			err = s.apiTokenRepo.SaveAPIToken(ctx, token)
+			if err != nil {
+				// Only the last used time is lost, which isn't worth failing the request over
+				entry.WithError(err).Error("failed to record api token use")
+			}
+		}
+
+		ctx = internal.ContextWithUser(ctx, user)
+		ctx = internal.ContextWithAPIToken(ctx, token)
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+
+	})
+}
+
+func (s *server) handleGetAPITimers(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	owned, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timers by user id")
+		s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+		return
+	}
+
+	shared, err := s.timerRepo.TimersSharedWithUserID(ctx, user.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timers shared with user")
+		s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+		return
+	}
+
+	now := time.Now()
+
+	timers := make([]*poker.APITimer, 0, len(owned)+len(shared))
+	for _, timer := range append(owned, shared...) {
+		// Invitations that haven't been accepted give no access, so they aren't listed
+		role := timer.RoleOf(user.ID)
+		if role == "" {
+			continue
+		}
+
+		timers = append(timers, s.describeAPITimer(timer, role, now))
+	}
+
+	s.writeAPIJSON(w, http.StatusOK, timers)
+
+}
+
+func (s *server) handlePostAPITimers(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	var input = new(poker.APITimerInput)
+	if !s.decodeAPIBody(w, r, input) {
+		return
+	}
+
+	timer := &poker.Timer{
+		ID:     uuid.New().String(),
+		UserID: user.ID,
+		Name:   strings.TrimSpace(input.Name),
+	}
+
+	for i, in := range input.Levels {
+		level := &poker.TimerLevel{
+			ID:      uuid.New().String(),
+			TimerID: timer.ID,
+		}
+		in.Apply(level)
+
+		err := level.Validate()
+		if err != nil {
+			s.writeAPIError(w, http.StatusUnprocessableEntity, fmt.Sprintf("level %d: %s", i+1, err))
+			return
+		}
+
+		timer.Levels = append(timer.Levels, level)
+	}
+
+	timer.RenumberLevels()
+
+	err := timer.Validate()
+	if err != nil {
+		s.writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if !s.saveAPITimer(w, r, timer) {
+		return
+	}
+
+	entry.WithField("timerID", timer.ID).Info("timer created through the api")
+
+	location, err := s.BuildRoute("api-timer", "timerID", timer.ID)
+	if err == nil {
+		w.Header().Set("Location", location)
+	}
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusCreated, s.describeAPITimer(timer, poker.TimerRoleOwner, time.Now()))
+
+}
+
+func (s *server) handleGetAPITimer(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	user := internal.UserFromContext(ctx)
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusOK, s.describeAPITimer(timer, timer.RoleOf(user.ID), time.Now()))
+
+}
+
+// handlePatchAPITimer renames the timer, its levels are changed through the levels routes
+func (s *server) handlePatchAPITimer(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok || !s.checkAPITimerVersion(w, r, timer) {
+		return
+	}
+
+	var input = new(poker.APITimerInput)
+	if !s.decodeAPIBody(w, r, input) {
+		return
+	}
+
+	if len(input.Levels) > 0 {
+		s.writeAPIError(w, http.StatusUnprocessableEntity, "levels can't be changed here, use the timer's levels instead")
+		return
+	}
+
+	timer.Name = strings.TrimSpace(input.Name)
+
+	err := timer.Validate()
+	if err != nil {
+		s.writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if !s.saveAPITimer(w, r, timer) {
+		return
+	}
+
+	user := internal.UserFromContext(ctx)
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusOK, s.describeAPITimer(timer, timer.RoleOf(user.ID), time.Now()))
+
+}
+
+func (s *server) handleDeleteAPITimer(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	timerID := mux.Vars(r)["timerID"]
+
+	err := s.timerRepo.DeleteTimer(ctx, timerID)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithField("timerID", timerID).Error("failed to delete timer")
+		s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+		return
+	}
+
+	s.deleteTimerWebhooks(ctx, timerID)
+
+	w.WriteHeader(http.StatusNoContent)
+
+}
+
+func (s *server) handleGetAPITimerLevels(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	levels := make([]*poker.APILevel, 0, len(timer.Levels))
+	for i, level := range timer.Levels {
+		levels = append(levels, poker.NewAPILevel(level, i))
+	}
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusOK, levels)
+
+}
+
+func (s *server) handlePostAPITimerLevels(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	var input = new(poker.APILevel)
+	if !s.decodeAPIBody(w, r, input) {
+		return
+	}
+
+	level := &poker.TimerLevel{
+		ID:      uuid.New().String(),
+		TimerID: timer.ID,
+	}
+	input.Apply(level)
+
+	err := level.Validate()
+	if err == nil && timer.ChipSet != nil {
+		err = timer.ChipSet.ValidateLevel(level)
+	}
+	if err == nil {
+		err = timer.InsertLevel(level, input.Position)
+	}
+	if err != nil {
+		s.writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if !s.saveAPITimer(w, r, timer) {
+		return
+	}
+
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+
+	location, err := s.BuildRoute("api-timer-level", "timerID", timer.ID, "levelID", level.ID)
+	if err == nil {
+		w.Header().Set("Location", location)
+	}
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusCreated, poker.NewAPILevel(level, timer.LevelIndex(level.ID)))
+
+}
+
+func (s *server) handleGetAPITimerLevel(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	i, ok := s.apiRouteLevelIndex(w, r, timer)
+	if !ok {
+		return
+	}
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusOK, poker.NewAPILevel(timer.Levels[i], i))
+
+}
+
+// handlePutAPITimerLevel replaces the level with what was sent, moving it when a position is given
+func (s *server) handlePutAPITimerLevel(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok || !s.checkAPITimerVersion(w, r, timer) {
+		return
+	}
+
+	i, ok := s.apiRouteLevelIndex(w, r, timer)
+	if !ok {
+		return
+	}
+
+	var input = new(poker.APILevel)
+	if !s.decodeAPIBody(w, r, input) {
+		return
+	}
+
+	// The change is made to a copy so a level that fails validation is left as it was
+	level := *timer.Levels[i]
+	input.Apply(&level)
+
+	err := level.Validate()
+	if err == nil && timer.ChipSet != nil {
+		err = timer.ChipSet.ValidateLevel(&level)
+	}
+	if err != nil {
+		s.writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	*timer.Levels[i] = level
+
+	if input.Position != 0 {
+		err = timer.MoveLevel(level.ID, input.Position)
+		if err != nil {
+			s.writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	}
+
+	if !s.saveAPITimer(w, r, timer) {
+		return
+	}
+
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+
+	i = timer.LevelIndex(level.ID)
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusOK, poker.NewAPILevel(timer.Levels[i], i))
+
+}
+
+func (s *server) handleDeleteAPITimerLevel(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok || !s.checkAPITimerVersion(w, r, timer) {
+		return
+	}
+
+	i, ok := s.apiRouteLevelIndex(w, r, timer)
+	if !ok {
+		return
+	}
+
+	err := timer.RemoveLevel(timer.Levels[i].ID)
+	if err != nil {
+		s.writeAPIError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !s.saveAPITimer(w, r, timer) {
+		return
+	}
+
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+
+	w.WriteHeader(http.StatusNoContent)
+
+}
+
+// handlePostAPITimerPlay runs one of the play page's controls, returning the timer as it is afterwards
+func (s *server) handlePostAPITimerPlay(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	timer, ok := s.apiRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	action := poker.PlayAction(mux.Vars(r)["action"])
+	if !action.Valid() {
+		s.writeAPIError(w, http.StatusNotFound, fmt.Sprintf("%s is not a play action", action))
+		return
+	}
+
+	if len(timer.Levels) == 0 {
+		s.writeAPIError(w, http.StatusConflict, "the timer does not have any levels to play")
+		return
+	}
+
+	now := time.Now()
+
+	from := timer.PlayPosition()
+	changed := timer.Play(action, now)
+
+	if !s.saveAPITimer(w, r, timer) {
+		return
+	}
+
+	// Displays are sent the same event the play page's controls send
+	event := timerEventClock
+	switch {
+	case action == poker.PlayActionReset:
+		event = timerEventReset
+	case changed:
+		event = timerEventLevel
+	}
+
+	s.publishTimerEvent(r, timer.ID, event)
+
+	if action == poker.PlayActionReset {
+		s.sendTimerWebhooks(ctx, timer, []poker.WebhookEvent{poker.WebhookEventTimerReset}, -1)
+	}
+	s.sendTimerTransitionWebhooks(ctx, timer, from)
+
+	user := internal.UserFromContext(ctx)
+
+	setAPITimerETag(w, timer)
+	s.writeAPIJSON(w, http.StatusOK, s.describeAPITimer(timer, timer.RoleOf(user.ID), now))
+
+}
+
+// describeAPITimer describes the timer as it is at now, playing through any levels that ran out without saving
+// them, which is left to the next change made to the timer
+func (s *server) describeAPITimer(timer *poker.Timer, role poker.TimerRole, now time.Time) *poker.APITimer {
+
+	timer.RollForward(now)
+
+	return poker.NewAPITimer(timer, role, now)
+
+}
+
+// apiRouteTimer loads the timer named by the route. timerAccess has already checked the user's role on it
+func (s *server) apiRouteTimer(w http.ResponseWriter, r *http.Request) (*poker.Timer, bool) {
+
+	var ctx = r.Context()
+
+	timerID := mux.Vars(r)["timerID"]
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithField("timerID", timerID).Error("failed to fetch timer")
+		s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+		return nil, false
+	}
+
+	if timer == nil {
+		s.writeAPIError(w, http.StatusNotFound, "timer not found")
+		return nil, false
+	}
+
+	return timer, true
+
+}
+
+// apiRouteLevelIndex finds the index of the level named by the route
+func (s *server) apiRouteLevelIndex(w http.ResponseWriter, r *http.Request, timer *poker.Timer) (int, bool) {
+
+	i := timer.LevelIndex(mux.Vars(r)["levelID"])
+	if i < 0 {
+		s.writeAPIError(w, http.StatusNotFound, "level not found")
+		return 0, false
+	}
+
+	return i, true
+
+}
+
+// setAPITimerETag sends the timer's version as the entity tag of a timer or level response, to be sent back in
+// If-Match by requests that change what was read
+func setAPITimerETag(w http.ResponseWriter, timer *poker.Timer) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatUint(uint64(timer.Version), 10)))
+}
+
+// checkAPITimerVersion refuses the request with a conflict when its If-Match names none of the timer's current
+// version, writing the error response. Requests without If-Match aren't checked
+func (s *server) checkAPITimerVersion(w http.ResponseWriter, r *http.Request, timer *poker.Timer) bool {
+
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return true
+	}
+
+	var err error
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+		var version uint64
+		version, err = strconv.ParseUint(strings.Trim(tag, `"`), 10, 64)
+		if err != nil {
+			s.writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("If-Match must be the timer's version as sent in its ETag, got %s", tag))
+			return false
+		}
+
+		err = timer.CheckVersion(uint(version))
+		if err == nil {
+			return true
+		}
+	}
+
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		s.logger.WithContext(r.Context()).WithError(err).Warn("api request was made from a stale copy of the timer")
+		s.writeAPIError(w, http.StatusConflict, conflict.Public().Error())
+	}
+
+	return false
+
+}
+
+// saveAPITimer saves the timer, writing the error response when it can't be
+func (s *server) saveAPITimer(w http.ResponseWriter, r *http.Request, timer *poker.Timer) bool {
+
+	var ctx = r.Context()
+
+	err := s.timerRepo.SaveTimer(ctx, timer)
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		s.logger.WithContext(ctx).WithError(err).Warn("timer was changed since it was loaded")
+		s.writeAPIError(w, http.StatusConflict, conflict.Public().Error())
+		return false
+	}
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithField("timerID", timer.ID).Error("failed to save timer")
+		s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+		return false
+	}
+
+	return true
+
+}
+
+// decodeAPIBody reads the JSON request body into v, writing the error response when it can't be read. Unknown
+// fields are refused so a misspelt field isn't silently ignored
+func (s *server) decodeAPIBody(w http.ResponseWriter, r *http.Request, v any) bool {
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err != nil {
+		s.writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("the request body is not valid json: %s", err))
+		return false
+	}
+
+	return true
+
+}
+
+func (s *server) writeAPIJSON(w http.ResponseWriter, status int, v any) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to encode api response")
+	}
+
+}
+
+func (s *server) writeAPIError(w http.ResponseWriter, status int, message string) {
+	s.writeAPIJSON(w, status, &poker.APIError{Error: message})
+}
+
+// isAPIRequest reports whether the request was made to the JSON API, which is answered with JSON errors
+func isAPIRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/api/")
+}
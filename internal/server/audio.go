@@ -8,12 +8,9 @@ import (
 	"net/http"
 	"poker"
 	"poker/internal"
+	"regexp"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/polly"
-	ptypes "github.com/aws/aws-sdk-go-v2/service/polly/types"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gorilla/mux"
 )
 
@@ -76,12 +73,6 @@ func (s *server) handleGetDashboardTimerLevelAudio(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if timer.UserID != user.ID {
-		entry.Error("timer is not owned by authenticated user")
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-
 	var level *poker.TimerLevel
 	for _, lvl := range timer.Levels {
 		if lvl.ID != levelID {
@@ -96,7 +87,7 @@ func (s *server) handleGetDashboardTimerLevelAudio(w http.ResponseWriter, r *htt
 		return
 	}
 
-	buffer, contentType, err := s.generateAndSaveAudio(ctx, level, action)
+	buffer, contentType, err := s.generateAndSaveAudio(ctx, level, timer.ColorUpsAt(level.ID), action)
 	if err != nil {
 		entry.WithError(err).Error("failed to generate/save audio file")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -108,59 +99,50 @@ func (s *server) handleGetDashboardTimerLevelAudio(w http.ResponseWriter, r *htt
 
 }
 
-func (s *server) generateAndSaveAudio(ctx context.Context, level *poker.TimerLevel, action _action) (io.WriterTo, string, error) {
+// generateAndSaveAudio returns the announcement for the level, synthesizing and caching it the first time. Breaks
+// with color ups are announced and cached separately from breaks of the same length without them
+func (s *server) generateAndSaveAudio(ctx context.Context, level *poker.TimerLevel, colorUps []*poker.Chip, action _action) (io.WriterTo, string, error) {
 
-	var objectKey = fmt.Sprintf("%s-%s.mp3", level.AudioS3Key(), action)
+	var objectKey = fmt.Sprintf("%s%s-%s.mp3", level.AudioS3Key(), colorUpAudioKey(colorUps), action)
+	if key := s.speech.Key(); key != "" {
+		objectKey = key + "/" + objectKey
+	}
 
 	entry := s.logger.WithField("objectKey", objectKey).WithContext(ctx)
 
-	objectOutput, err := s.s3.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(s.audioBucket),
-		Key:    aws.String(objectKey),
-	})
-	if err == nil {
+	blob, err := s.audioCache.Blob(ctx, objectKey)
+	if err != nil {
+		// A broken cache shouldn't stop the announcement from playing, so carry on and generate it
+		entry.WithError(err).Error("failed to fetch cached audio file")
+	}
+
+	if blob != nil {
 		entry.Info("cached audio file found, returning")
-		defer objectOutput.Body.Close()
-		var buffer = new(bytes.Buffer)
-		_, _ = buffer.ReadFrom(objectOutput.Body)
-		return buffer, aws.ToString(objectOutput.ContentType), nil
+		return bytes.NewBuffer(blob.Data), blob.ContentType, nil
 	}
 
-	text := generateSpeechText(action, level)
+	text := generateSpeechText(action, level, colorUps)
 	entry.WithField("text", text).Info("generating audio file for text")
 
-	synthesizeSpeechOutput, err := s.polly.SynthesizeSpeech(ctx, &polly.SynthesizeSpeechInput{
-		Engine:       ptypes.EngineNeural,
-		OutputFormat: ptypes.OutputFormatMp3,
-		LanguageCode: ptypes.LanguageCodeEnUs,
-		Text:         aws.String(text),
-		VoiceId:      ptypes.VoiceIdStephen,
-	})
+	speech, err := s.speech.SynthesizeSpeech(ctx, text)
 	if err != nil {
 		entry.WithError(err).Error("failed to synthesize speech")
 		return nil, "", fmt.Errorf("failed to synthesize speech: %w", err)
 	}
 
-	defer synthesizeSpeechOutput.AudioStream.Close()
-
-	var buffer = new(bytes.Buffer)
-	_, _ = buffer.ReadFrom(synthesizeSpeechOutput.AudioStream)
-
-	var buffer2 = bytes.NewBuffer(bytes.Clone(buffer.Bytes()))
-
-	_, err = s.s3.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.audioBucket),
-		Key:         aws.String(objectKey),
-		Body:        buffer,
-		ContentType: synthesizeSpeechOutput.ContentType,
+	err = s.audioCache.SaveBlob(ctx, &poker.Blob{
+		BlobInfo: poker.BlobInfo{
+			Key:         objectKey,
+			ContentType: speech.ContentType,
+		},
+		Data: speech.Audio,
 	})
 	if err != nil {
-		entry.WithError(err).Error("failed to put audio file in S3")
-		return nil, "", fmt.Errorf("failed to put audio file in S3: %w", err)
+		entry.WithError(err).Error("failed to cache audio file")
+		return nil, "", fmt.Errorf("failed to cache audio file: %w", err)
 	}
 
-	fmt.Println(buffer.Len(), buffer2.Len())
-	return buffer2, aws.ToString(synthesizeSpeechOutput.ContentType), nil
+	return bytes.NewBuffer(speech.Audio), speech.ContentType, nil
 }
 
 type _action string
@@ -187,7 +169,25 @@ func (a _action) valid() bool {
 
 }
 
-func generateSpeechText(action _action, level *poker.TimerLevel) string {
+// colorUpAudioKey is added to the audio key of a break with color ups, empty when there aren't any
+func colorUpAudioKey(colorUps []*poker.Chip) string {
+
+	if len(colorUps) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(colorUps))
+	for _, chip := range colorUps {
+		parts = append(parts, fmt.Sprintf("%.0f%s", chip.Denomination, audioKeyUnsafe.ReplaceAllString(strings.ToLower(chip.Color), "")))
+	}
+
+	return "-colorup-" + strings.Join(parts, "-")
+
+}
+
+var audioKeyUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+func generateSpeechText(action _action, level *poker.TimerLevel, colorUps []*poker.Chip) string {
 
 	levelType := level.Type
 
@@ -219,12 +219,52 @@ func generateSpeechText(action _action, level *poker.TimerLevel) string {
 		blinds = fmt.Sprintf(blindFmt, level.SmallBlind, level.BigBlind)
 	}
 
+	// Levels that only differ by their ante are announced differently so players know to put it in
+	var ante string
+	if levelType == poker.LevelTypeBlind {
+		switch level.EffectiveAnteType() {
+		case poker.AnteTypeTraditional:
+			ante = fmt.Sprintf("Every player antes %.0f.", level.Ante)
+		case poker.AnteTypeBigBlind:
+			ante = fmt.Sprintf("There is a big blind ante of %.0f.", level.Ante)
+		}
+	}
+
+	var colorUp string
+	if levelType == poker.LevelTypeBreak && len(colorUps) > 0 {
+		chips := make([]string, 0, len(colorUps))
+		for _, chip := range colorUps {
+			chips = append(chips, fmt.Sprintf("the %s %.0f chips", chip.Color, chip.Denomination))
+		}
+		colorUp = fmt.Sprintf("Please color up %s.", strings.Join(chips, " and "))
+	}
+
+	var eventMap = map[poker.LevelEvent]string{
+		poker.LevelEventLateRegistrationEnds: "Late registration is now closed.",
+		poker.LevelEventRebuysEnd:            "Rebuys are now closed.",
+		poker.LevelEventAddOnAvailable:       "The add-on is now available.",
+	}
+
+	events := make([]string, 0, len(level.Events))
+	for _, event := range poker.AllLevelEvents {
+		if level.HasEvent(event) {
+			events = append(events, eventMap[event])
+		}
+	}
+
 	var duration string
 	var durationFmt = durationMap[levelType]
 	if durationFmt != "" {
 		duration = fmt.Sprintf(durationFmt, level.DurationMin)
 	}
 
-	return strings.Join([]string{prefix, blinds, duration}, " ")
+	parts := make([]string, 0, 6)
+	for _, part := range []string{prefix, blinds, ante, colorUp, strings.Join(events, " "), duration} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " ")
 
 }
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal/templates"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *server) handlePostDashboardTimerChips(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	entry = entry.WithField("timerID", timer.ID)
+
+	renderErrors := func(chipSet *poker.ChipSet, errors []string) {
+		err := s.templates.DashboardTimerChipSetComponent(ctx, &templates.DashboardTimerChipSetProps{
+			Timer:   timer,
+			ChipSet: chipSet,
+			Errors:  errors,
+		}).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render chip set component")
+		}
+	}
+
+	chipSet, errs := chipSetFromForm(r)
+	if len(errs) > 0 {
+		renderErrors(chipSet, errs)
+		return
+	}
+
+	err := chipSet.Validate()
+	if err != nil {
+		renderErrors(chipSet, []string{err.Error()})
+		return
+	}
+
+	previous := timer.ChipSet
+	timer.ChipSet = chipSet
+
+	err = timer.ValidateChips()
+	if err != nil {
+		timer.ChipSet = previous
+		entry.WithError(err).Info("timer levels can't be made with chip set")
+		renderErrors(chipSet, []string{err.Error()})
+		return
+	}
+
+	s.saveDashboardTimerChips(w, r, timer)
+
+}
+
+func (s *server) handleDeleteDashboardTimerChips(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	timer.ChipSet = nil
+
+	s.saveDashboardTimerChips(w, r, timer)
+
+}
+
+// dashboardRouteTimer loads the timer named in the request, which timerAccess has already authorized. False is
+// returned once a response has been written
+func (s *server) dashboardRouteTimer(w http.ResponseWriter, r *http.Request) (*poker.Timer, bool) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timerID := mux.Vars(r)["timerID"]
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	return timer, true
+
+}
+
+func (s *server) saveDashboardTimerChips(w http.ResponseWriter, r *http.Request, timer *poker.Timer) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", timer.ID)
+
+	err := s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Color ups are shown on the break screen, so anyone playing the timer needs to pick up the change
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+
+	err = s.templates.DashboardTimerChipSetComponent(ctx, &templates.DashboardTimerChipSetProps{
+		Timer:   timer,
+		ChipSet: timer.ChipSet,
+	}).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render chip set component")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
+
+// chipSetFromForm reads the chip set form, which has a row of Color, Denomination and Count fields for each
+// chip. Rows left completely empty are skipped
+func chipSetFromForm(r *http.Request) (*poker.ChipSet, []string) {
+
+	chipSet := new(poker.ChipSet)
+
+	err := r.ParseForm()
+	if err != nil {
+		return chipSet, []string{"the chip set could not be read, please try again"}
+	}
+
+	chipSet.Name = strings.TrimSpace(r.PostFormValue("Name"))
+
+	var errs []string
+	colors, denominations, counts := r.PostForm["Color"], r.PostForm["Denomination"], r.PostForm["Count"]
+	for i, color := range colors {
+		var denomination, count string
+		if i < len(denominations) {
+			denomination = strings.TrimSpace(denominations[i])
+		}
+		if i < len(counts) {
+			count = strings.TrimSpace(counts[i])
+		}
+
+		color = strings.TrimSpace(color)
+		if color == "" && denomination == "" && count == "" {
+			continue
+		}
+
+		chip := &poker.Chip{Color: color}
+
+		chip.Denomination, err = parseOptionalFloat(denomination)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("row %d: the denomination must be a number", i+1))
+		}
+
+		if count != "" {
+			n, err := strconv.ParseUint(count, 10, 64)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("row %d: the count must be a whole number", i+1))
+			}
+			chip.Count = uint(n)
+		}
+
+		chipSet.Chips = append(chipSet.Chips, chip)
+	}
+
+	return chipSet, errs
+
+}
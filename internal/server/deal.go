@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *server) handleGetDashboardTournamentDeal(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	tournament, ok := s.dealTournament(w, r, entry)
+	if !ok {
+		return
+	}
+
+	props := &templates.DashboardTournamentDealProps{
+		User:       internal.UserFromContext(ctx),
+		Tournament: tournament,
+	}
+
+	for _, player := range tournament.Players {
+		if player.IsPlaying() {
+			props.Players = append(props.Players, &poker.DealPlayer{Name: player.Name})
+		}
+	}
+
+	// The places still to be decided are the ones the players left will finish in
+	payouts, err := tournament.Payouts()
+	if err != nil {
+		props.Errors = append(props.Errors, err.Error())
+	}
+
+	for _, payout := range payouts {
+		if payout.Place > len(props.Players) {
+			break
+		}
+		props.Prizes = append(props.Prizes, payout.Amount)
+	}
+
+	for len(props.Prizes) < len(props.Players) {
+		props.Prizes = append(props.Prizes, 0)
+	}
+
+	err = s.templates.DashboardTournamentDeal(ctx, props).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard tournament deal")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTournamentDeal(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	tournament, ok := s.dealTournament(w, r, entry)
+	if !ok {
+		return
+	}
+
+	props := s.dealFromForm(r, tournament)
+
+	err := s.templates.DashboardTournamentDealFragment(ctx, props).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard tournament deal")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTournamentDealPrint(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	tournament, ok := s.dealTournament(w, r, entry)
+	if !ok {
+		return
+	}
+
+	err := s.templates.DashboardTournamentDealPrint(ctx, s.dealFromForm(r, tournament)).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard tournament deal print")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+// dealTournament fetches the requested tournament, writing the response and returning false if it can't be
+// found or isn't owned by the authenticated user
+func (s *server) dealTournament(w http.ResponseWriter, r *http.Request, entry *logrus.Entry) (*poker.Tournament, bool) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	tournamentID := mux.Vars(r)["tournamentID"]
+
+	tournament, err := s.tournamentRepo.Tournament(ctx, tournamentID)
+	if err != nil {
+		entry.WithError(err).WithField("tournamentID", tournamentID).Error("failed to fetch tournament")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return nil, false
+	}
+
+	if tournament == nil || tournament.UserID != user.ID {
+		entry.WithField("tournamentID", tournamentID).Error("tournament not found or not owned by authenticated user")
+		w.WriteHeader(http.StatusNotFound)
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
+		return nil, false
+	}
+
+	return tournament, true
+
+}
+
+// dealFromForm reads the chip counts and prizes that were entered and works out the deal. Anything that
+// can't be worked out is returned in Errors with the form values kept so they can be corrected
+func (s *server) dealFromForm(r *http.Request, tournament *poker.Tournament) *templates.DashboardTournamentDealProps {
+
+	props := &templates.DashboardTournamentDealProps{
+		User:       internal.UserFromContext(r.Context()),
+		Tournament: tournament,
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		props.Errors = append(props.Errors, "the deal could not be read, please try again")
+		return props
+	}
+
+	names, chips := r.PostForm["PlayerName"], r.PostForm["Chips"]
+	for i, name := range names {
+		var player = &poker.DealPlayer{Name: name}
+		if i < len(chips) {
+			player.Chips, err = parseOptionalFloat(chips[i])
+			if err != nil {
+				props.Errors = append(props.Errors, fmt.Sprintf("the chips for %s must be a number", name))
+			}
+		}
+		props.Players = append(props.Players, player)
+	}
+
+	for i, value := range r.PostForm["Prize"] {
+		prize, err := parseOptionalFloat(value)
+		if err != nil {
+			props.Errors = append(props.Errors, fmt.Sprintf("the prize for place %d must be a number", i+1))
+		}
+		props.Prizes = append(props.Prizes, prize)
+	}
+
+	props.HeldBack, err = parseOptionalFloat(r.PostFormValue("HeldBack"))
+	if err != nil {
+		props.Errors = append(props.Errors, "the amount held back must be a number")
+	}
+
+	if len(props.Errors) > 0 {
+		return props
+	}
+
+	props.Deal, err = poker.NewDeal(props.Players, props.Prizes, props.HeldBack)
+	if err != nil {
+		props.Errors = append(props.Errors, err.Error())
+	}
+
+	return props
+
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// displayTimer loads the timer named in the request and checks the display token in the
+// request against it. A nil timer is returned when the timer doesn't exist or the token doesn't match
+func (s *server) displayTimer(r *http.Request) (*poker.Timer, error) {
+
+	var ctx = r.Context()
+
+	vars := mux.Vars(r)
+
+	timer, err := s.timerRepo.Timer(ctx, vars["timerID"])
+	if err != nil {
+		return nil, err
+	}
+
+	if timer == nil || timer.DisplayToken == "" {
+		return nil, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(timer.DisplayToken), []byte(vars["token"])) != 1 {
+		return nil, nil
+	}
+
+	return timer, nil
+
+}
+
+func (s *server) handleGetDisplayTimer(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", mux.Vars(r)["timerID"])
+
+	timer, err := s.displayTimer(r)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	if timer == nil || len(timer.Levels) == 0 {
+		entry.Error("timer not found or display token is invalid")
+		w.WriteHeader(http.StatusNotFound)
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
+		return
+	}
+
+	// Displays only ever read the timer, so the clock is rolled forward for rendering without saving
+	now := time.Now()
+	timer.RollForward(now)
+
+	masthead := s.mastheadProps(ctx, timer, now)
+	masthead.DisplayToken = timer.DisplayToken
+
+	err = s.templates.Play(ctx, &templates.PlayProps{
+		User:         internal.UserFromContext(ctx),
+		Masthead:     masthead,
+		CurrentLevel: timer.CurrentLevel + 1,
+		ClientID:     uuid.New().String(),
+	}).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render display timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handleGetDisplayTimerMasthead(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", mux.Vars(r)["timerID"])
+
+	timer, err := s.displayTimer(r)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil || len(timer.Levels) == 0 {
+		entry.Error("timer not found or display token is invalid")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	timer.RollForward(now)
+
+	masthead := s.mastheadProps(ctx, timer, now)
+	masthead.DisplayToken = timer.DisplayToken
+
+	w.Header().Set("HX-Trigger-After-Settle", "countdown::reset")
+	err = s.templates.TimerMasthead(ctx, masthead).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render display masthead")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
+
+func (s *server) handleGetDisplayTimerEvents(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", mux.Vars(r)["timerID"])
+
+	timer, err := s.displayTimer(r)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found or display token is invalid")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.streamTimerEvents(w, r, timer.ID, r.URL.Query().Get("client"), true)
+
+}
+
+func (s *server) handlePostDashboardTimerDisplay(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTimerDisplay(w, r, func(timer *poker.Timer) error {
+		token, err := generateDisplayToken()
+		if err != nil {
+			return err
+		}
+
+		timer.DisplayToken = token
+		return nil
+	})
+}
+
+func (s *server) handleDeleteDashboardTimerDisplay(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTimerDisplay(w, r, func(timer *poker.Timer) error {
+		timer.DisplayToken = ""
+		return nil
+	})
+}
+
+// updateDashboardTimerDisplay applies fn to the requested timer's display token, saves the timer and
+// disconnects every display still using the old token before rendering the display link component
+func (s *server) updateDashboardTimerDisplay(w http.ResponseWriter, r *http.Request, fn func(timer *poker.Timer) error) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	vars := mux.Vars(r)
+
+	timerID, ok := vars["timerID"]
+	if !ok {
+		entry.Error("var timerID missing from request context")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = fn(timer)
+	if err != nil {
+		entry.WithError(err).Error("failed to update display token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.events.disconnectDisplays(timer.ID)
+
+	err = s.templates.DashboardTimerDisplayComponent(ctx, timer).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render display link component")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
+
+func generateDisplayToken() (string, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
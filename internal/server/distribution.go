@@ -0,0 +1,174 @@
+package server
+
+import (
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDistributionPlayers is the field the calculator starts with when no tournament is being played on the timer
+const defaultDistributionPlayers = 10
+
+func (s *server) handleGetDashboardTimerChipsDistribution(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.distributionTimer(w, r, entry)
+	if !ok {
+		return
+	}
+
+	props := &templates.DashboardTimerChipsDistributionProps{
+		User:          internal.UserFromContext(ctx),
+		Timer:         timer,
+		Players:       defaultDistributionPlayers,
+		StartingStack: poker.DefaultStructureParams.StartingStack,
+	}
+
+	// A tournament on the timer already knows how many players there are
+	tournament, err := s.tournamentRepo.TournamentByTimerID(ctx, timer.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch tournament for timer")
+	}
+	if tournament != nil && tournament.Entrants() > 0 {
+		props.Players = uint(tournament.Entrants())
+	}
+
+	distributeChips(props)
+
+	err = s.templates.DashboardTimerChipsDistribution(ctx, props).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render chip distribution")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimerChipsDistribution(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.distributionTimer(w, r, entry)
+	if !ok {
+		return
+	}
+
+	err := s.templates.DashboardTimerChipsDistributionFragment(ctx, s.distributionFromForm(r, timer)).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render chip distribution")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimerChipsDistributionPrint(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.distributionTimer(w, r, entry)
+	if !ok {
+		return
+	}
+
+	err := s.templates.DashboardTimerChipsDistributionPrint(ctx, s.distributionFromForm(r, timer)).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render chip distribution print")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+// distributionTimer fetches the requested timer, writing the response and returning false if it can't be found
+// or isn't owned by the authenticated user
+func (s *server) distributionTimer(w http.ResponseWriter, r *http.Request, entry *logrus.Entry) (*poker.Timer, bool) {
+
+	var ctx = r.Context()
+
+	timerID := mux.Vars(r)["timerID"]
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).WithField("timerID", timerID).Error("failed to fetch timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return nil, false
+	}
+
+	if timer == nil {
+		entry.WithField("timerID", timerID).Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
+		return nil, false
+	}
+
+	return timer, true
+
+}
+
+// distributionFromForm reads the field size and starting stack that were entered and splits the timer's chip
+// set between them. Anything that can't be worked out is returned in Errors with the form values kept
+func (s *server) distributionFromForm(r *http.Request, timer *poker.Timer) *templates.DashboardTimerChipsDistributionProps {
+
+	props := &templates.DashboardTimerChipsDistributionProps{
+		User:  internal.UserFromContext(r.Context()),
+		Timer: timer,
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		props.Errors = append(props.Errors, "the form could not be read, please try again")
+		return props
+	}
+
+	if value := strings.TrimSpace(r.PostFormValue("Players")); value != "" {
+		players, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			props.Errors = append(props.Errors, "players must be a whole number")
+		}
+		props.Players = uint(players)
+	}
+
+	props.StartingStack, err = parseOptionalFloat(r.PostFormValue("StartingStack"))
+	if err != nil {
+		props.Errors = append(props.Errors, "the starting stack must be a number")
+	}
+
+	if len(props.Errors) > 0 {
+		return props
+	}
+
+	distributeChips(props)
+
+	return props
+
+}
+
+// distributeChips splits the timer's chip set between the players in props
+func distributeChips(props *templates.DashboardTimerChipsDistributionProps) {
+
+	if props.Timer.ChipSet == nil {
+		props.Errors = append(props.Errors, "add a chip set to the timer to work out the starting stacks")
+		return
+	}
+
+	var err error
+	props.Distribution, err = props.Timer.ChipSet.Distribute(props.Players, props.StartingStack)
+	if err != nil {
+		props.Errors = append(props.Errors, err.Error())
+	}
+
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// clientIDHeader carries the id the play page generated for itself, so a display is not
+// told about changes it made itself
+const clientIDHeader = "X-Poker-Client"
+
+type timerEventType string
+
+const (
+	timerEventLevel timerEventType = "level"
+	timerEventClock timerEventType = "clock"
+	timerEventReset timerEventType = "reset"
+	// timerEventTournament is published when the tournament played on the timer changes
+	timerEventTournament timerEventType = "tournament"
+)
+
+type timerEvent struct {
+	Type     timerEventType `json:"type"`
+	TimerID  string         `json:"timerID"`
+	clientID string
+}
+
+type subscriber struct {
+	clientID string
+	// display is set for subscribers connected through a public display link
+	display bool
+	events  chan *timerEvent
+	closed  chan struct{}
+}
+
+// broker fans timer events out to every display subscribed to that timer. Subscriptions only
+// live in this process, so when running behind lambda the play page never sees its stream open
+// and polls the masthead instead
+type broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*subscriber]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscribers: make(map[string]map[*subscriber]struct{}),
+	}
+}
+
+func (b *broker) subscribe(timerID, clientID string, display bool) *subscriber {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &subscriber{
+		clientID: clientID,
+		display:  display,
+		events:   make(chan *timerEvent, 8),
+		closed:   make(chan struct{}),
+	}
+
+	if _, ok := b.subscribers[timerID]; !ok {
+		b.subscribers[timerID] = make(map[*subscriber]struct{})
+	}
+
+	b.subscribers[timerID][sub] = struct{}{}
+
+	return sub
+
+}
+
+func (b *broker) unsubscribe(timerID string, sub *subscriber) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs, ok := b.subscribers[timerID]
+	if !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subscribers, timerID)
+	}
+
+}
+
+// disconnectDisplays closes the stream of every display link subscribed to the timer
+func (b *broker) disconnectDisplays(timerID string) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[timerID] {
+		if !sub.display {
+			continue
+		}
+
+		delete(b.subscribers[timerID], sub)
+		close(sub.closed)
+	}
+
+	if len(b.subscribers[timerID]) == 0 {
+		delete(b.subscribers, timerID)
+	}
+
+}
+
+// publish never blocks, a display that is too far behind to take the event will resync
+// from the next one it does receive
+func (b *broker) publish(event *timerEvent) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[event.TimerID] {
+		if event.clientID != "" && sub.clientID == event.clientID {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+
+}
+
+func (s *server) publishTimerEvent(r *http.Request, timerID string, eventType timerEventType) {
+	s.events.publish(&timerEvent{
+		Type:     eventType,
+		TimerID:  timerID,
+		clientID: r.Header.Get(clientIDHeader),
+	})
+}
+
+func (s *server) handleGetPlayTimerEvents(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	vars := mux.Vars(r)
+
+	timerID, ok := vars["timerID"]
+	if !ok {
+		entry.Error("var timerID missing from request context")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.streamTimerEvents(w, r, timer.ID, r.URL.Query().Get("client"), false)
+
+}
+
+// streamTimerEvents holds the request open and writes every event published for the timer
+// as a server sent event until the client goes away
+func (s *server) streamTimerEvents(w http.ResponseWriter, r *http.Request, timerID, clientID string, display bool) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", timerID)
+
+	// The server wide write timeout would otherwise cut the stream off after a few seconds
+	rc := http.NewResponseController(w)
+	err := rc.SetWriteDeadline(time.Time{})
+	if err != nil {
+		entry.WithError(err).Error("failed to clear write deadline for event stream")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sub := s.events.subscribe(timerID, clientID, display)
+	defer s.events.unsubscribe(timerID, sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	heartbeat := time.NewTicker(time.Second * 15)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.closed:
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case event := <-sub.events:
+			var data []byte
+			data, err = json.Marshal(event)
+			if err != nil {
+				entry.WithError(err).Error("failed to encode timer event")
+				return
+			}
+			_, err = fmt.Fprintf(w, "event: timer\ndata: %s\n\n", data)
+		}
+		if err != nil {
+			entry.WithError(err).Debug("failed to write to event stream, closing")
+			return
+		}
+
+		err = rc.Flush()
+		if err != nil {
+			entry.WithError(err).Debug("failed to flush event stream, closing")
+			return
+		}
+	}
+
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"poker"
+	"poker/internal/templates"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func (s *server) handlePostDashboardTimerLevelAction(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTimerLevels(w, r, func(timer *poker.Timer) error {
+
+		vars := mux.Vars(r)
+
+		levelID := vars["levelID"]
+
+		i := timer.LevelIndex(levelID)
+		if i < 0 {
+			return errors.New("level not found")
+		}
+
+		// Positions are 1 based, so the level's current position is i+1
+		switch vars["action"] {
+		case "up":
+			if i == 0 {
+				return nil
+			}
+			return timer.MoveLevel(levelID, uint(i))
+		case "down":
+			if i == len(timer.Levels)-1 {
+				return nil
+			}
+			return timer.MoveLevel(levelID, uint(i+2))
+		case "duplicate":
+			_, err := timer.DuplicateLevel(levelID, uuid.New().String())
+			return err
+		}
+
+		return errors.New("unrecognized level action")
+
+	}, nil)
+}
+
+func (s *server) handlePostDashboardTimerLevelsReorder(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTimerLevels(w, r, func(timer *poker.Timer) error {
+		return timer.ReorderLevels(r.PostForm["LevelID"])
+	}, nil)
+}
+
+func (s *server) handlePostDashboardTimerLevelsBulk(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	timerID := mux.Vars(r)["timerID"]
+
+	var edit = new(poker.LevelBulkEdit)
+	var version uint
+	s.updateDashboardTimerLevels(w, r, func(timer *poker.Timer) error {
+
+		version = timer.Version
+
+		err := s.decoder.Decode(edit, r.PostForm)
+		if err != nil {
+			return errors.New("the bulk edit could not be read, check every field is a number")
+		}
+
+		return timer.BulkEditLevels(*edit)
+
+	}, func(err error) {
+		// Bulk edit errors are shown on the form so the values can be corrected
+		rerr := s.templates.DashboardTimerLevelsBulkComponent(ctx, &templates.DashboardTimerLevelsBulkProps{
+			TimerID: timerID,
+			Version: version,
+			Edit:    edit,
+			Errors:  []string{err.Error()},
+		}).Render(w)
+		if rerr != nil {
+			s.logger.WithError(rerr).Error("failed to render bulk edit component")
+		}
+	})
+}
+
+// updateDashboardTimerLevels applies fn to the requested timer's levels, saves the timer and renders it. When fn
+// fails onError is given the error to render, or when onError is nil the timer is rendered unchanged
+func (s *server) updateDashboardTimerLevels(w http.ResponseWriter, r *http.Request, fn func(timer *poker.Timer) error, onError func(err error)) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timerID := mux.Vars(r)["timerID"]
+
+	entry = entry.WithField("timerID", timerID)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = formTimerVersion(r, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to check timer version")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = fn(timer)
+	if err != nil && onError != nil {
+		entry.WithError(err).Info("level update rejected")
+		onError(err)
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Info("level update rejected")
+
+		// fn may have partially applied the change, so show what is actually stored
+		timer, err = s.timerRepo.Timer(ctx, timerID)
+		if err != nil || timer == nil {
+			entry.WithError(err).Error("failed to refetch timer")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	} else {
+		err = s.timerRepo.SaveTimer(ctx, timer)
+		if s.writeTimerConflict(ctx, w, err) {
+			return
+		}
+		if err != nil {
+			entry.WithError(err).Error("failed to save timer")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		s.publishTimerEvent(r, timer.ID, timerEventLevel)
+	}
+
+	err = s.templates.DashboardTimerFragment(ctx, timer).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Template sources name where a new timer is copied from, see handlePostDashboardTimersTemplates
+const (
+	templateSourceBuiltin = "builtin"
+	templateSourceTimer   = "timer"
+)
+
+func (s *server) handleGetDashboardTimersTemplates(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	props, err := s.dashboardTimerTemplatesProps(r)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to load timer templates")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	err = s.templates.DashboardTimerTemplatesComponent(ctx, props).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render timer templates component")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimersTemplates(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	renderErrors := func(errors []string) {
+		props, err := s.dashboardTimerTemplatesProps(r)
+		if err != nil {
+			entry.WithError(err).Error("failed to load timer templates")
+			_ = s.templates.ResourceUnavailable(ctx).Render(w)
+			return
+		}
+
+		props.Errors = errors
+		err = s.templates.DashboardTimerTemplatesComponent(ctx, props).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render timer templates component")
+		}
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	source, id := r.PostForm.Get("Source"), r.PostForm.Get("ID")
+
+	entry = entry.WithField("source", source).WithField("templateID", id)
+
+	var template *poker.Timer
+	switch source {
+	case templateSourceBuiltin:
+		structure, err := poker.BuiltinStructureBySlug(id)
+		if err != nil {
+			entry.WithError(err).Error("failed to load built-in structures")
+			_ = s.templates.ResourceUnavailable(ctx).Render(w)
+			return
+		}
+		if structure != nil {
+			template = structure.Timer
+		}
+	case templateSourceTimer:
+		timer, err := s.timerRepo.Timer(ctx, id)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch template timer")
+			_ = s.templates.ResourceUnavailable(ctx).Render(w)
+			return
+		}
+		// Only the owner's own templates can be copied
+		if timer != nil && timer.UserID == user.ID && timer.IsTemplate {
+			template = timer
+		}
+	}
+
+	if template == nil {
+		entry.Error("template not found")
+		renderErrors([]string{"the template could not be found, choose another one"})
+		return
+	}
+
+	timer := template.Clone(user.ID)
+	if name := strings.TrimSpace(r.PostForm.Get("Name")); name != "" {
+		timer.Name = name
+	}
+
+	err = timer.Validate()
+	if err != nil {
+		entry.WithError(err).Error("failed to validate timer")
+		renderErrors([]string{err.Error()})
+		return
+	}
+
+	s.saveNewDashboardTimer(w, r, timer)
+
+}
+
+func (s *server) handlePostDashboardTimerDuplicate(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	timerID := mux.Vars(r)["timerID"]
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	duplicate := timer.Clone(user.ID)
+	duplicate.Name = fmt.Sprintf("%s (copy)", timer.Name)
+
+	s.saveNewDashboardTimer(w, r, duplicate)
+
+}
+
+// saveNewDashboardTimer saves a timer that was just created from another one and sends the browser to it
+func (s *server) saveNewDashboardTimer(w http.ResponseWriter, r *http.Request, timer *poker.Timer) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", timer.ID)
+
+	err := s.timerRepo.SaveTimer(ctx, timer)
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	uri, _ := s.router.Get("dashboard-timer").URL("timerID", timer.ID)
+	w.Header().Set("HX-Push", uri.String())
+	err = s.templates.DashboardTimerFragment(ctx, timer).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimerTemplate(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTimerTemplate(w, r, true)
+}
+
+func (s *server) handleDeleteDashboardTimerTemplate(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTimerTemplate(w, r, false)
+}
+
+// updateDashboardTimerTemplate marks the requested timer as one of its owner's templates, or unmarks it
+func (s *server) updateDashboardTimerTemplate(w http.ResponseWriter, r *http.Request, isTemplate bool) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timerID := mux.Vars(r)["timerID"]
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	timer.IsTemplate = isTemplate
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.templates.DashboardTimerTemplateComponent(ctx, timer).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render timer template component")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
+
+// dashboardTimerTemplatesProps lists the built-in structures along with the authenticated user's own templates
+func (s *server) dashboardTimerTemplatesProps(r *http.Request) (*templates.DashboardTimerTemplatesProps, error) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	builtin, err := poker.BuiltinStructures()
+	if err != nil {
+		return nil, err
+	}
+
+	timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var personal []*poker.Timer
+	for _, timer := range timers {
+		if timer.IsTemplate {
+			personal = append(personal, timer)
+		}
+	}
+
+	return &templates.DashboardTimerTemplatesProps{
+		Builtin:  builtin,
+		Personal: personal,
+	}, nil
+
+}
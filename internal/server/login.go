@@ -1,20 +1,63 @@
 package server
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/mail"
 	"net/url"
 	"poker"
+	"poker/internal/authenticator"
+	"poker/internal/templates"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
 )
 
+// handleLogin offers the enabled sign in methods. It is also where the OIDC provider sends the user back to, and
+// when OIDC is the only method it sends the user straight to the provider as there is nothing to choose between
 func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	var ctx = r.Context()
 
+	query := r.URL.Query()
+
+	if query.Get("state") != "" && query.Get("code") != "" && s.authenticator.Enabled(authenticator.MethodOIDC) {
+		s.handleOIDCCallback(w, r)
+		return
+	}
+
+	methods := s.authenticator.Methods()
+	if len(methods) == 1 && methods[0] == authenticator.MethodOIDC {
+		s.startOIDC(w, r)
+		return
+	}
+
+	s.renderLogin(ctx, w, &templates.LoginProps{})
+
+}
+
+func (s *server) handleGetLoginOIDC(w http.ResponseWriter, r *http.Request) {
+
+	if !s.authenticator.Enabled(authenticator.MethodOIDC) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.startOIDC(w, r)
+
+}
+
+// startOIDC sends the user to the OIDC provider, keeping the state in their session to check when they come back
+func (s *server) startOIDC(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
 	session, err := s.sessions.Get(r, "poker-session")
 	if err != nil {
 		// Create an error page and redirect to that. Use session flashing to flash an internal error message of sorts
@@ -23,33 +66,55 @@ func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := r.URL.Query()
-
-	state := query.Get("state")
-	code := query.Get("code")
+	state, err := generateRandomState()
+	if err != nil {
+		// Create an error page and redirect to that. Use session flashing to flash an internal error message of sorts
+		s.logger.WithError(err).Error("failed to generate state for authentication request")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-	if state == "" || code == "" {
-		state, err := generateRandomState()
+	authURL, err := s.authenticator.OIDC.AuthCodeURL(ctx, state)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to build oidc authentication url")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		err = s.templates.ResourceUnavailable(ctx).Render(w)
 		if err != nil {
-			// Create an error page and redirect to that. Use session flashing to flash an internal error message of sorts
-			s.logger.WithError(err).Error("failed to generate state for authentication request")
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+			s.logger.WithError(err).Error("failed to render resource unavailable page")
 		}
+		return
+	}
 
-		session.Values["state"] = state
-		err = session.Save(r, w)
-		if err != nil {
-			s.logger.WithError(err).Error("failed to save state for authentication request")
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+	session.Values["state"] = state
+	err = session.Save(r, w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to save state for authentication request")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-		w.Header().Set("Location", s.authenticator.AuthCodeURL(state))
-		w.WriteHeader(http.StatusTemporaryRedirect)
+	w.Header().Set("Location", authURL)
+	w.WriteHeader(http.StatusTemporaryRedirect)
+
+}
+
+func (s *server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	session, err := s.sessions.Get(r, "poker-session")
+	if err != nil {
+		// Create an error page and redirect to that. Use session flashing to flash an internal error message of sorts
+		s.logger.WithError(err).Error("failed to load session")
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	query := r.URL.Query()
+
+	state := query.Get("state")
+	code := query.Get("code")
+
 	sessionState, ok := session.Values["state"]
 	if !ok {
 		s.logger.Error("invalid session, no state stored in session")
@@ -63,14 +128,16 @@ func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := s.authenticator.Exchange(ctx, code)
+	delete(session.Values, "state")
+
+	token, err := s.authenticator.OIDC.Exchange(ctx, code)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to exchange code for token")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	idToken, err := s.authenticator.VerifyIDToken(ctx, token)
+	idToken, err := s.authenticator.OIDC.VerifyIDToken(ctx, token)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to verify id token")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -85,50 +152,363 @@ func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	emailInf, ok := profile["name"]
-	if !ok {
-		s.logger.WithError(err).Error("profile is missing informaiton necessary to identify user")
+	email, err := oidcEmail(profile)
+	if err != nil {
+		s.logger.WithError(err).Warn("refused oidc sign in")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Users who signed in before the email claim was read were saved under their name claim, which for those
+	// signing in with an email address is the address. They are only moved over when it is this same address
+	if name, _ := profile["name"].(string); normalizeEmail(name) == email {
+		err = s.normalizeUserEmail(ctx, name, email)
+		if err != nil {
+			s.logger.WithError(err).Error("failed to normalize user email")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Support reaching out to the profile api to retrive emaployee id and profile uri
+	user, err := s.userForEmail(ctx, email, fmt.Sprintf("%s %s", profile["given_name"], profile["family_name"]))
+	if err != nil {
+		s.logger.WithError(err).Error("failed to provision user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.signIn(w, r, session, user, authenticator.MethodOIDC)
+
+}
+
+func (s *server) handlePostLoginPassword(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	if !s.authenticator.Enabled(authenticator.MethodPassword) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		s.logger.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var email = normalizeEmail(r.PostFormValue("Email"))
+
+	user, err := s.userRepo.UserByEmail(ctx, email)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to look up user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// A missing user is checked against an empty hash so it is rejected the same way, and as slowly, as a wrong password
+	var hash string
+	if user != nil {
+		hash = user.PasswordHash
+	}
+
+	err = authenticator.CheckPassword(hash, r.PostFormValue("Password"))
+	if errors.Is(err, authenticator.ErrInvalidCredentials) {
+		s.logger.WithField("email", email).Info("failed password sign in")
+		w.WriteHeader(http.StatusUnauthorized)
+		s.renderLogin(ctx, w, &templates.LoginProps{Email: email, Errors: []string{err.Error()}})
+		return
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("failed to check password")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	session, err := s.sessions.Get(r, "poker-session")
+	if err != nil {
+		s.logger.WithError(err).Error("failed to load session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.signIn(w, r, session, user, authenticator.MethodPassword)
+
+}
+
+// handlePostLoginMagicLink emails a sign in link. The same notice is shown whether or not the address has an
+// account, the account is made when the link is followed
+func (s *server) handlePostLoginMagicLink(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	if !s.authenticator.Enabled(authenticator.MethodMagicLink) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		s.logger.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var email = normalizeEmail(r.PostFormValue("Email"))
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		s.renderLogin(ctx, w, &templates.LoginProps{Email: email, Errors: []string{"enter a valid email address"}})
+		return
+	}
+
+	route, err := s.BuildRoute("login-magic-link")
+	if err != nil {
+		s.logger.WithError(err).Error("failed to build sign in link")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	token, err := s.authenticator.MagicLink.Token(email, time.Now())
+	if err != nil {
+		s.logger.WithError(err).Error("failed to make sign in link")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	link := fmt.Sprintf("%s%s?token=%s", strings.TrimSuffix(s.appURL, "/"), route, url.QueryEscape(token))
+
+	err = s.authenticator.MagicLink.Send(ctx, email, link)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to send sign in link")
+		s.renderLogin(ctx, w, &templates.LoginProps{Email: email, Errors: []string{"the sign in link could not be sent, try again shortly"}})
+		return
+	}
+
+	s.renderLogin(ctx, w, &templates.LoginProps{Notice: fmt.Sprintf("a sign in link has been sent to %s", email)})
+
+}
+
+func (s *server) handleGetLoginMagicLink(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	if !s.authenticator.Enabled(authenticator.MethodMagicLink) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	email, sentAt, err := s.authenticator.MagicLink.Verify(r.URL.Query().Get("token"), time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		s.renderLogin(ctx, w, &templates.LoginProps{Errors: []string{err.Error()}})
+		return
+	}
+
+	user, err := s.userForEmail(ctx, email, email)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to provision user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Recording when the link was sent uses it up, along with any sent before it
+	if user.MagicLinkSentAt != nil && !sentAt.After(*user.MagicLinkSentAt) {
 		w.WriteHeader(http.StatusUnauthorized)
+		s.renderLogin(ctx, w, &templates.LoginProps{Errors: []string{authenticator.ErrInvalidMagicLink.Error()}})
 		return
 	}
 
-	user, err := s.userRepo.UserByEmail(ctx, emailInf.(string))
+	user.MagicLinkSentAt = &sentAt
+	user.UpdateAt = time.Now()
+
+	err = s.userRepo.SaveUser(ctx, user)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to save user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	session, err := s.sessions.Get(r, "poker-session")
+	if err != nil {
+		s.logger.WithError(err).Error("failed to load session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.signIn(w, r, session, user, authenticator.MethodMagicLink)
+
+}
+
+func (s *server) handleGetRegister(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	if !s.authenticator.Enabled(authenticator.MethodPassword) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := s.templates.Register(ctx, &templates.RegisterProps{}).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render register page")
+	}
+
+}
+
+func (s *server) handlePostRegister(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	if !s.authenticator.Enabled(authenticator.MethodPassword) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		s.logger.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	props := &templates.RegisterProps{
+		Name:  strings.TrimSpace(r.PostFormValue("Name")),
+		Email: normalizeEmail(r.PostFormValue("Email")),
+	}
+
+	render := func(errors []string) {
+		props.Errors = errors
+		err := s.templates.Register(ctx, props).Render(w)
+		if err != nil {
+			s.logger.WithError(err).Error("failed to render register page")
+		}
+	}
+
+	if props.Name == "" {
+		render([]string{"name cannot be empty"})
+		return
+	}
+
+	address, err := mail.ParseAddress(props.Email)
+	if err != nil || address.Address != props.Email {
+		render([]string{"enter a valid email address"})
+		return
+	}
+
+	existing, err := s.userRepo.UserByEmail(ctx, props.Email)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to look up user")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	// Setting a password on an account someone else already signs in to would hand it over, so existing addresses
+	// have to sign in the way they always have
+	if existing != nil {
+		render([]string{"an account already exists for this email address, sign in instead"})
+		return
+	}
+
+	hash, err := s.authenticator.HashPassword(r.PostFormValue("Password"))
+	if err != nil {
+		render([]string{err.Error()})
+		return
+	}
+
+	// Nothing is saved until the link is followed, so registering someone else's address can't claim it
+	token, err := s.authenticator.EmailVerification.Token(&authenticator.Registration{
+		Email:        props.Email,
+		Name:         props.Name,
+		PasswordHash: hash,
+	}, time.Now())
+	if err != nil {
+		s.logger.WithError(err).Error("failed to make registration link")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	route, err := s.BuildRoute("register-verify")
+	if err != nil {
+		s.logger.WithError(err).Error("failed to build registration link")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	link := fmt.Sprintf("%s%s?token=%s", strings.TrimSuffix(s.appURL, "/"), route, url.QueryEscape(token))
+
+	err = s.authenticator.EmailVerification.Send(ctx, props.Email, link)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to send registration link")
+		render([]string{"the registration link could not be sent, try again shortly"})
+		return
+	}
+
+	s.renderLogin(ctx, w, &templates.LoginProps{Notice: fmt.Sprintf("a link to finish creating your account has been sent to %s", props.Email)})
+
+}
+
+// handleGetRegisterVerify creates the account carried by a registration link once it is followed, proving the address
+// belongs to whoever registered it
+func (s *server) handleGetRegisterVerify(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	if !s.authenticator.Enabled(authenticator.MethodPassword) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	registration, err := s.authenticator.EmailVerification.Verify(r.URL.Query().Get("token"), time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		s.renderLogin(ctx, w, &templates.LoginProps{Errors: []string{err.Error()}})
+		return
+	}
+
+	user, err := s.userRepo.UserByEmail(ctx, registration.Email)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to look up user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Following the link a second time signs in to the account it made, any other account for the address was made
+	// some other way since the link was sent and isn't handed over
+	if user != nil && user.PasswordHash != registration.PasswordHash {
+		w.WriteHeader(http.StatusConflict)
+		s.renderLogin(ctx, w, &templates.LoginProps{Email: registration.Email, Errors: []string{"an account already exists for this email address, sign in instead"}})
+		return
+	}
+
 	if user == nil {
+		now := time.Now()
 		user = &poker.User{
-			ID:    uuid.New().String(),
-			Name:  fmt.Sprintf("%s %s", profile["given_name"], profile["family_name"]),
-			Email: emailInf.(string),
+			ID:           uuid.New().String(),
+			Name:         registration.Name,
+			Email:        registration.Email,
+			PasswordHash: registration.PasswordHash,
+			CreatedAt:    now,
+			UpdateAt:     now,
 		}
 
-		// Support reaching out to the profile api to retrive emaployee id and profile uri
-
-		err := s.userRepo.SaveUser(ctx, user)
+		err = s.userRepo.SaveUser(ctx, user)
 		if err != nil {
 			s.logger.WithError(err).Error("failed to save user")
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-
 	}
 
-	session.Values["userID"] = user.ID
-
-	err = session.Save(r, w)
+	session, err := s.sessions.Get(r, "poker-session")
 	if err != nil {
-		s.logger.WithError(err).Error("failed to exchange code for token")
+		s.logger.WithError(err).Error("failed to load session")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	fmt.Println(s.authenticator.Provider.Endpoint().AuthURL)
-
-	s.writeRedirectRouteName(w, "dashboard")
+	s.signIn(w, r, session, user, authenticator.MethodPassword)
 
 }
 
@@ -142,6 +522,8 @@ func (s *server) handleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	method, _ := session.Values["method"].(string)
+
 	session.Options.MaxAge = -1
 
 	err = session.Save(r, w)
@@ -151,9 +533,133 @@ func (s *server) handleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Location", fmt.Sprintf("%sv2/logout?client_id=%s&returnTo=%s", s.authenticator.IssuerURL, s.authenticator.ClientID, url.QueryEscape(s.appURL)))
-	w.WriteHeader(http.StatusTemporaryRedirect)
+	// Sessions from before there was a choice of method don't record one, they all signed in with OIDC
+	if (method == "" || method == authenticator.MethodOIDC.String()) && s.authenticator.OIDC != nil {
+		w.Header().Set("Location", s.authenticator.OIDC.LogoutURL(s.appURL))
+		w.WriteHeader(http.StatusTemporaryRedirect)
+		return
+	}
+
+	s.writeRedirectRouteName(w, "home")
+
+}
+
+// userForEmail returns the user with the email address, making one called name when this is their first sign in
+func (s *server) userForEmail(ctx context.Context, email, name string) (*poker.User, error) {
+
+	user, err := s.userRepo.UserByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	if user != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	user = &poker.User{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Email:     email,
+		CreatedAt: now,
+		UpdateAt:  now,
+	}
+
+	err = s.userRepo.SaveUser(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	return user, nil
+
+}
+
+// oidcEmail is the normalized email address of an OIDC profile. Accounts are shared by every way of signing in and
+// found by their address, so only an address the provider has verified is accepted. The name claim isn't used, as
+// users can usually set it to anything
+func oidcEmail(profile map[string]any) (string, error) {
+
+	email, _ := profile["email"].(string)
+	if strings.TrimSpace(email) == "" {
+		return "", fmt.Errorf("profile is missing an email address")
+	}
+
+	verified, _ := profile["email_verified"].(bool)
+	if !verified {
+		return "", fmt.Errorf("profile email address %s has not been verified", email)
+	}
+
+	return normalizeEmail(email), nil
+
+}
+
+// normalizeUserEmail moves a user saved with the address exactly as the OIDC provider sent it, from before addresses
+// were normalized, over to the normalized address. A user already at the normalized address is left alone
+func (s *server) normalizeUserEmail(ctx context.Context, provided, email string) error {
+
+	if provided == email {
+		return nil
+	}
+
+	user, err := s.userRepo.UserByEmail(ctx, provided)
+	if err != nil || user == nil {
+		return err
+	}
+
+	existing, err := s.userRepo.UserByEmail(ctx, email)
+	if err != nil || existing != nil {
+		return err
+	}
+
+	user.Email = email
+	user.UpdateAt = time.Now()
+
+	return s.userRepo.SaveUser(ctx, user)
+
+}
+
+// signIn records the user and how they signed in in their session, then sends them to the dashboard. The redirect
+// is a See Other so the dashboard is fetched with a GET after the password and register forms are posted
+func (s *server) signIn(w http.ResponseWriter, r *http.Request, session *sessions.Session, user *poker.User, method authenticator.Method) {
+
+	session.Values["userID"] = user.ID
+	session.Values["method"] = method.String()
+
+	err := session.Save(r, w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to save session")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	route, err := s.BuildRoute("dashboard")
+	if err != nil {
+		s.logger.WithError(err).Error("failed to build dashboard route")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", route)
+	w.WriteHeader(http.StatusSeeOther)
+
+}
+
+// renderLogin renders the sign in page with the enabled methods filled in on props
+func (s *server) renderLogin(ctx context.Context, w http.ResponseWriter, props *templates.LoginProps) {
+
+	props.OIDC = s.authenticator.Enabled(authenticator.MethodOIDC)
+	props.Password = s.authenticator.Enabled(authenticator.MethodPassword)
+	props.MagicLink = s.authenticator.Enabled(authenticator.MethodMagicLink)
+
+	err := s.templates.Login(ctx, props).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render login page")
+	}
+
+}
 
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 func generateRandomState() (string, error) {
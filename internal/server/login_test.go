@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"poker"
+	"poker/internal/authenticator"
+	"poker/internal/store/memory"
+	"poker/internal/templates"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/sirupsen/logrus"
+)
+
+// testMailer keeps the mail sent instead of sending it
+type testMailer struct {
+	sent []*poker.Mail
+}
+
+func (m *testMailer) SendMail(ctx context.Context, mail *poker.Mail) error {
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+// link returns the link in the last mail sent
+func (m *testMailer) link(t *testing.T) string {
+	t.Helper()
+
+	if len(m.sent) == 0 {
+		t.Fatalf("expected a mail to have been sent")
+	}
+
+	link := regexp.MustCompile(`https?://\S+`).FindString(m.sent[len(m.sent)-1].Body)
+	if link == "" {
+		t.Fatalf("expected the mail to carry a link, got %q", m.sent[len(m.sent)-1].Body)
+	}
+
+	return link
+}
+
+// newLoginServer starts a server with in memory stores and every method of signing in other than OIDC
+func newLoginServer(t *testing.T) (*server, *httptest.Server, *testMailer) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	mailer := new(testMailer)
+	sessionKey := []byte("login-test-session-key")
+
+	auth, err := authenticator.New(&authenticator.Config{
+		Methods: []authenticator.Method{authenticator.MethodPassword, authenticator.MethodMagicLink},
+		EmailVerification: &authenticator.EmailVerificationConfig{
+			Secret: authenticator.DeriveKey(sessionKey, "email-verification"),
+			Mailer: mailer,
+		},
+		MagicLink: &authenticator.MagicLinkConfig{
+			Secret: authenticator.DeriveKey(sessionKey, "magic-link"),
+			Mailer: mailer,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to provision authenticator: %s", err)
+	}
+
+	timerRepo := memory.NewTimerRepository()
+	s := New(
+		poker.EnvironmentLocal, "http://poker.test", "0", logger, nil,
+		nil, auth, nil, sessions.NewCookieStore(sessionKey),
+		memory.NewAPITokenRepository(), timerRepo, memory.NewTournamentRepository(), memory.NewUserRepository(),
+		memory.NewWebhookRepository(),
+	)
+
+	tmpl, err := templates.New(poker.EnvironmentLocal, "http://poker.test", logger, timerRepo)
+	if err != nil {
+		t.Fatalf("failed to provision templates: %s", err)
+	}
+
+	ts := httptest.NewServer(s.Mux(tmpl))
+	t.Cleanup(ts.Close)
+
+	return s, ts, mailer
+}
+
+// follow requests link from the test server, without following the redirect that signs in
+func follow(t *testing.T, ts *httptest.Server, link string) *http.Response {
+	t.Helper()
+
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("failed to parse link: %s", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	res, err := client.Get(ts.URL + u.RequestURI())
+	if err != nil {
+		t.Fatalf("failed to follow link: %s", err)
+	}
+	res.Body.Close()
+
+	return res
+}
+
+func register(t *testing.T, ts *httptest.Server, name, email, password string) {
+	t.Helper()
+
+	res, err := http.PostForm(ts.URL+"/register", url.Values{"Name": {name}, "Email": {email}, "Password": {password}})
+	if err != nil {
+		t.Fatalf("failed to register: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected registering to render a page, got %d", res.StatusCode)
+	}
+}
+
+// TestRegisterVerifiesEmail registers someone else's address, which mustn't make an account until the link sent to
+// the address is followed
+func TestRegisterVerifiesEmail(t *testing.T) {
+
+	s, ts, mailer := newLoginServer(t)
+	ctx := context.Background()
+
+	register(t, ts, "Mallory", "Alice@Poker.test", "not-alices-password")
+
+	user, err := s.userRepo.UserByEmail(ctx, "alice@poker.test")
+	if err != nil {
+		t.Fatalf("failed to look up user: %s", err)
+	}
+	if user != nil {
+		t.Fatalf("expected no account until the address is verified, got %+v", user)
+	}
+
+	if len(mailer.sent) != 1 || mailer.sent[0].To != "alice@poker.test" {
+		t.Fatalf("expected the link to be sent to alice@poker.test, got %+v", mailer.sent)
+	}
+
+	if strings.Contains(mailer.link(t), "not-alices-password") || strings.Contains(mailer.link(t), "Mallory") {
+		t.Errorf("expected the registration to be sealed in the link, got %s", mailer.link(t))
+	}
+
+	// A password sign in fails the same way as for an unknown address
+	res, err := http.PostForm(ts.URL+"/login/password", url.Values{"Email": {"alice@poker.test"}, "Password": {"not-alices-password"}})
+	if err != nil {
+		t.Fatalf("failed to sign in: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected an unverified registration not to sign in, got %d", res.StatusCode)
+	}
+
+	res = follow(t, ts, mailer.link(t))
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected following the link to sign in, got %d", res.StatusCode)
+	}
+
+	user, err = s.userRepo.UserByEmail(ctx, "alice@poker.test")
+	if err != nil || user == nil {
+		t.Fatalf("expected the account to be made once the link is followed, got %v", err)
+	}
+
+	if user.Name != "Mallory" || authenticator.CheckPassword(user.PasswordHash, "not-alices-password") != nil {
+		t.Errorf("expected the account to have the registered name and password, got %+v", user)
+	}
+
+	// Following it again signs in to the same account
+	res = follow(t, ts, mailer.link(t))
+	if res.StatusCode != http.StatusSeeOther {
+		t.Errorf("expected following the link again to sign in, got %d", res.StatusCode)
+	}
+
+}
+
+// TestRegisterLinkDoesNotTakeOver follows a registration link for an address that has since signed in another way
+func TestRegisterLinkDoesNotTakeOver(t *testing.T) {
+
+	s, ts, mailer := newLoginServer(t)
+	ctx := context.Background()
+
+	register(t, ts, "Mallory", "bob@poker.test", "not-bobs-password")
+
+	err := s.userRepo.SaveUser(ctx, &poker.User{ID: "bob", Email: "bob@poker.test", Name: "Bob"})
+	if err != nil {
+		t.Fatalf("failed to save user: %s", err)
+	}
+
+	res := follow(t, ts, mailer.link(t))
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("expected the link to be refused for an existing account, got %d", res.StatusCode)
+	}
+
+	user, err := s.userRepo.User(ctx, "bob")
+	if err != nil || user == nil {
+		t.Fatalf("failed to look up user: %v", err)
+	}
+
+	if user.PasswordHash != "" {
+		t.Errorf("expected the existing account not to be given a password")
+	}
+
+	res = follow(t, ts, ts.URL+"/register/verify?token=forged")
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a forged link to be refused, got %d", res.StatusCode)
+	}
+
+}
+
+func TestNormalizeUserEmail(t *testing.T) {
+
+	s, _, _ := newLoginServer(t)
+	ctx := context.Background()
+
+	err := s.userRepo.SaveUser(ctx, &poker.User{ID: "carol", Email: "Carol@Poker.test", Name: "Carol"})
+	if err != nil {
+		t.Fatalf("failed to save user: %s", err)
+	}
+
+	err = s.normalizeUserEmail(ctx, "Carol@Poker.test", normalizeEmail("Carol@Poker.test"))
+	if err != nil {
+		t.Fatalf("failed to normalize email: %s", err)
+	}
+
+	user, err := s.userRepo.UserByEmail(ctx, "carol@poker.test")
+	if err != nil || user == nil || user.ID != "carol" {
+		t.Fatalf("expected the user to be found by the normalized address, got %+v %v", user, err)
+	}
+
+}
+
+func TestMagicLinkUsedOnce(t *testing.T) {
+
+	s, ts, mailer := newLoginServer(t)
+
+	request := func() string {
+		res, err := http.PostForm(ts.URL+"/login/magic-link", url.Values{"Email": {"dave@poker.test"}})
+		if err != nil {
+			t.Fatalf("failed to request sign in link: %s", err)
+		}
+		res.Body.Close()
+
+		return mailer.link(t)
+	}
+
+	first, second := request(), request()
+
+	res := follow(t, ts, second)
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected the link to sign in, got %d", res.StatusCode)
+	}
+
+	user, err := s.userRepo.UserByEmail(context.Background(), "dave@poker.test")
+	if err != nil || user == nil {
+		t.Fatalf("expected the link to make an account, got %v", err)
+	}
+
+	res = follow(t, ts, second)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a used link to be refused, got %d", res.StatusCode)
+	}
+
+	res = follow(t, ts, first)
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a link sent before one that was used to be refused, got %d", res.StatusCode)
+	}
+
+	res = follow(t, ts, request())
+	if res.StatusCode != http.StatusSeeOther {
+		t.Errorf("expected a new link to sign in, got %d", res.StatusCode)
+	}
+
+}
+
+func TestDeriveKeyByPurpose(t *testing.T) {
+
+	key := []byte("login-test-session-key")
+
+	if string(authenticator.DeriveKey(key, "magic-link")) == string(key) {
+		t.Errorf("expected the derived key not to be the key it was derived from")
+	}
+
+	if string(authenticator.DeriveKey(key, "magic-link")) == string(authenticator.DeriveKey(key, "email-verification")) {
+		t.Errorf("expected keys for different purposes to differ")
+	}
+
+}
+
+func TestOIDCEmail(t *testing.T) {
+
+	tt := []struct {
+		name    string
+		profile map[string]any
+		want    string
+	}{
+		{name: "verified", profile: map[string]any{"email": "Erin@Poker.test", "email_verified": true}, want: "erin@poker.test"},
+		{name: "unverified", profile: map[string]any{"email": "erin@poker.test", "email_verified": false}},
+		{name: "verified claim missing", profile: map[string]any{"email": "erin@poker.test"}},
+		{name: "verified as a string", profile: map[string]any{"email": "erin@poker.test", "email_verified": "true"}},
+		{name: "address only in the name", profile: map[string]any{"name": "erin@poker.test", "email_verified": true}},
+	}
+
+	for _, tc := range tt {
+		got, err := oidcEmail(tc.profile)
+		if tc.want == "" && err == nil {
+			t.Errorf("%s: expected the profile to be refused, got %s", tc.name, got)
+		}
+		if tc.want != "" && (err != nil || got != tc.want) {
+			t.Errorf("%s: expected %s, got %s %v", tc.name, tc.want, got, err)
+		}
+	}
+
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal/openapi"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// apiOperation documents one method of an API route. The scope and role it needs come from apiRouteScopes and
+// timerRouteRoles, which are what the requests are checked against
+type apiOperation struct {
+	route   string
+	method  string
+	id      string
+	summary string
+	// request is the type of the request body, nil when there isn't one
+	request any
+	status  int
+	// response is the type of the response body, nil when there isn't one
+	response any
+	// errors are the error statuses the operation responds with besides those every operation can
+	errors []int
+	// etag is set when the response carries the timer's version in an ETag header
+	etag bool
+	// ifMatch is set when the operation is refused with a conflict if an If-Match header names another version
+	ifMatch bool
+}
+
+var apiOperations = []*apiOperation{
+	{
+		route: "api-timers", method: http.MethodGet, id: "listTimers",
+		summary: "List the timers the user owns or has accepted a role on",
+		status:  http.StatusOK, response: []*poker.APITimer{},
+	},
+	{
+		route: "api-timers", method: http.MethodPost, id: "createTimer",
+		summary: "Create a timer, with its levels when they are sent",
+		request: &poker.APITimerInput{}, status: http.StatusCreated, response: &poker.APITimer{},
+		errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		etag:   true,
+	},
+	{
+		route: "api-timer", method: http.MethodGet, id: "getTimer",
+		summary: "Get a timer with its levels and the state of its clock",
+		status:  http.StatusOK, response: &poker.APITimer{},
+		errors: []int{http.StatusNotFound},
+		etag:   true,
+	},
+	{
+		route: "api-timer", method: http.MethodPatch, id: "renameTimer",
+		summary: "Rename a timer, its levels are changed through the levels operations",
+		request: &poker.APITimerInput{}, status: http.StatusOK, response: &poker.APITimer{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+		etag:   true, ifMatch: true,
+	},
+	{
+		route: "api-timer", method: http.MethodDelete, id: "deleteTimer",
+		summary: "Delete a timer",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusNotFound},
+	},
+	{
+		route: "api-timer-levels", method: http.MethodGet, id: "listLevels",
+		summary: "List a timer's levels in the order they are played",
+		status:  http.StatusOK, response: []*poker.APILevel{},
+		errors: []int{http.StatusNotFound},
+		etag:   true,
+	},
+	{
+		route: "api-timer-levels", method: http.MethodPost, id: "createLevel",
+		summary: "Add a level at its position, or after the last level when the position is 0",
+		request: &poker.APILevel{}, status: http.StatusCreated, response: &poker.APILevel{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+		etag:   true,
+	},
+	{
+		route: "api-timer-level", method: http.MethodGet, id: "getLevel",
+		summary: "Get a level",
+		status:  http.StatusOK, response: &poker.APILevel{},
+		errors: []int{http.StatusNotFound},
+		etag:   true,
+	},
+	{
+		route: "api-timer-level", method: http.MethodPut, id: "updateLevel",
+		summary: "Replace a level, moving it when a position is sent",
+		request: &poker.APILevel{}, status: http.StatusOK, response: &poker.APILevel{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+		etag:   true, ifMatch: true,
+	},
+	{
+		route: "api-timer-level", method: http.MethodDelete, id: "deleteLevel",
+		summary: "Delete a level",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+		ifMatch: true,
+	},
+	{
+		route: "api-timer-play", method: http.MethodPost, id: "playTimer",
+		summary: "Run one of the play page's controls, the timer is returned as it is afterwards",
+		status:  http.StatusOK, response: &poker.APITimer{},
+		errors: []int{http.StatusNotFound, http.StatusConflict},
+		etag:   true,
+	},
+}
+
+// apiParameterDescriptions describe the variables of the API's paths
+var apiParameterDescriptions = map[string]string{
+	"timerID": "The id of the timer",
+	"levelID": "The id of the level",
+	"action":  "The control to run",
+}
+
+// apiTokenScheme is the name of the security scheme API tokens are described by
+const apiTokenScheme = "token"
+
+var pathVariable = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// openAPIDocument describes the JSON API. It is made from the routes, the API types and the scopes and roles the
+// routes are checked against, so it can't fall behind them
+func (s *server) openAPIDocument() (*openapi.Document, error) {
+
+	schemas := openapi.NewSchemas()
+	schemas.Enum(poker.AllLevelTypes)
+	schemas.Enum(poker.AllAnteTypes)
+	schemas.Enum(poker.AllLevelEvents)
+	schemas.Enum(poker.AllClockStates)
+	schemas.Enum(poker.AllPlayActions)
+	schemas.Enum(append([]poker.TimerRole{poker.TimerRoleOwner}, poker.AllTimerRoles...))
+
+	errorSchema := schemas.Of(&poker.APIError{})
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: &openapi.Info{
+			Title: "Poker API",
+			Description: "Read and run tournament timers from scripts. Requests are made with a personal API token, " +
+				"created from the dashboard, sent as a Bearer token",
+			Version: "1",
+		},
+		Servers:  []*openapi.Server{{URL: s.appURL}},
+		Paths:    make(map[string]openapi.PathItem),
+		Security: []openapi.SecurityRequirement{{apiTokenScheme: {}}},
+	}
+
+	for _, op := range apiOperations {
+
+		route := s.router.Get(op.route)
+		if route == nil {
+			return nil, fmt.Errorf("route %s is documented but not registered", op.route)
+		}
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get path of route %s: %w", op.route, err)
+		}
+
+		scope, ok := apiRouteScopes[op.route][op.method]
+		if !ok {
+			return nil, fmt.Errorf("route %s has no scope for %s", op.route, op.method)
+		}
+
+		operation := &openapi.Operation{
+			OperationID: op.id,
+			Summary:     op.summary,
+			Description: fmt.Sprintf("Needs a token with the %s scope", scope),
+			Tags:        []string{"timers"},
+			Responses:   make(map[string]*openapi.Response),
+			TokenScope:  scope.String(),
+		}
+
+		if role, ok := timerRouteRoles[op.route][op.method]; ok {
+			operation.Description += fmt.Sprintf(" and the %s role on the timer", role)
+			operation.TimerRole = role.String()
+		}
+
+		if strings.HasPrefix(op.route, "api-timer-level") {
+			operation.Tags = []string{"levels"}
+		}
+
+		for _, match := range pathVariable.FindAllStringSubmatch(path, -1) {
+			schema := &openapi.Schema{Type: "string"}
+			if match[1] == "action" {
+				schema = schemas.Of(poker.PlayAction(""))
+			}
+
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+				Name:        match[1],
+				In:          "path",
+				Description: apiParameterDescriptions[match[1]],
+				Required:    true,
+				Schema:      schema,
+			})
+		}
+
+		if op.ifMatch {
+			operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+				Name: "If-Match",
+				In:   "header",
+				Description: "The ETag of a timer or level response. When the timer has been saved since, the request " +
+					"is refused with a conflict rather than overwriting the change",
+				Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+
+		if op.request != nil {
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{"application/json": {Schema: schemas.Of(op.request)}},
+			}
+		}
+
+		response := &openapi.Response{Description: http.StatusText(op.status)}
+		if op.response != nil {
+			response.Content = map[string]*openapi.MediaType{"application/json": {Schema: schemas.Of(op.response)}}
+		}
+		if op.status == http.StatusCreated || op.etag {
+			response.Headers = make(map[string]*openapi.Header)
+		}
+		if op.status == http.StatusCreated {
+			response.Headers["Location"] = &openapi.Header{Description: "Where what was created can be read", Schema: &openapi.Schema{Type: "string"}}
+		}
+		if op.etag {
+			response.Headers["ETag"] = &openapi.Header{Description: "The timer's version, to send in If-Match", Schema: &openapi.Schema{Type: "string"}}
+		}
+		operation.Responses[strconv.Itoa(op.status)] = response
+
+		for _, status := range append([]int{http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError}, op.errors...) {
+			operation.Responses[strconv.Itoa(status)] = &openapi.Response{
+				Description: http.StatusText(status),
+				Content:     map[string]*openapi.MediaType{"application/json": {Schema: errorSchema}},
+			}
+		}
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(openapi.PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(op.method)] = operation
+
+	}
+
+	doc.Components = &openapi.Components{
+		Schemas: schemas.Components(),
+		SecuritySchemes: map[string]*openapi.SecurityScheme{
+			apiTokenScheme: {
+				Type:        "http",
+				Scheme:      "bearer",
+				Description: "A personal API token created from the dashboard",
+			},
+		},
+	}
+
+	return doc, nil
+
+}
+
+// handleGetAPIOpenAPI serves the API's OpenAPI document, which needs no token to read
+func (s *server) handleGetAPIOpenAPI(w http.ResponseWriter, r *http.Request) {
+
+	doc, err := s.openAPIDocument()
+	if err != nil {
+		s.logger.WithContext(r.Context()).WithError(err).Error("failed to describe api")
+		s.writeAPIError(w, http.StatusInternalServerError, poker.ErrInternalServerErrorContactDeveloper.Error())
+		return
+	}
+
+	s.writeAPIJSON(w, http.StatusOK, doc)
+
+}
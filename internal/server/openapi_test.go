@@ -0,0 +1,532 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"poker"
+	"poker/client"
+	"poker/internal/openapi"
+	"poker/internal/store/memory"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/sirupsen/logrus"
+)
+
+// newContractServer starts a server with in memory stores and a user, returning it with the user's tokens by scope
+// and one with every scope under ""
+func newContractServer(t *testing.T) (*server, *httptest.Server, map[poker.TokenScope]string) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	s := New(
+		poker.EnvironmentLocal, "http://poker.test", "0", logger, nil,
+		nil, nil, nil, sessions.NewCookieStore([]byte("contract")),
+		memory.NewAPITokenRepository(), memory.NewTimerRepository(), memory.NewTournamentRepository(), memory.NewUserRepository(),
+		memory.NewWebhookRepository(),
+	)
+
+	ctx := context.Background()
+
+	user := &poker.User{ID: "contract-user", Email: "contract@poker.test", Name: "Contract", CreatedAt: time.Now()}
+	err := s.userRepo.SaveUser(ctx, user)
+	if err != nil {
+		t.Fatalf("failed to save user: %s", err)
+	}
+
+	tokens := make(map[poker.TokenScope]string)
+	for _, scopes := range [][]poker.TokenScope{{poker.TokenScopeRead}, poker.AllTokenScopes} {
+		token, secret, err := poker.NewAPIToken(user.ID, "contract", scopes, time.Now())
+		if err != nil {
+			t.Fatalf("failed to create token: %s", err)
+		}
+
+		err = s.apiTokenRepo.SaveAPIToken(ctx, token)
+		if err != nil {
+			t.Fatalf("failed to save token: %s", err)
+		}
+
+		if len(scopes) == 1 {
+			tokens[scopes[0]] = secret
+		} else {
+			tokens[""] = secret
+		}
+	}
+
+	ts := httptest.NewServer(s.router)
+	t.Cleanup(ts.Close)
+
+	return s, ts, tokens
+}
+
+func TestOpenAPIDocumentServed(t *testing.T) {
+
+	_, ts, _ := newContractServer(t)
+
+	res, err := http.Get(ts.URL + "/api/v1/openapi.json")
+	if err != nil {
+		t.Fatalf("failed to get document: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected the document to be readable without a token, got status %d", res.StatusCode)
+	}
+
+	var doc = new(openapi.Document)
+	err = json.NewDecoder(res.Body).Decode(doc)
+	if err != nil {
+		t.Fatalf("failed to decode document: %s", err)
+	}
+
+	if doc.OpenAPI != openapi.Version || len(doc.Paths) == 0 {
+		t.Fatalf("expected an OpenAPI %s document with paths, got %q with %d paths", openapi.Version, doc.OpenAPI, len(doc.Paths))
+	}
+
+}
+
+// TestOpenAPIRoutes checks every API route is documented, and that the document names the scope and role each
+// request is checked against
+func TestOpenAPIRoutes(t *testing.T) {
+
+	s, _, _ := newContractServer(t)
+
+	doc, err := s.openAPIDocument()
+	if err != nil {
+		t.Fatalf("failed to describe api: %s", err)
+	}
+
+	documented := make(map[string]bool)
+
+	err = s.router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, "/api/v1/") || route.GetName() == "api-openapi" {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			key := method + " " + path
+			documented[key] = true
+
+			op := doc.Paths[path][strings.ToLower(method)]
+			if op == nil {
+				t.Errorf("%s is routed but not documented", key)
+				continue
+			}
+
+			if scope := apiRouteScopes[route.GetName()][method]; op.TokenScope != scope.String() {
+				t.Errorf("%s is documented as needing the %q scope, it is checked for %q", key, op.TokenScope, scope)
+			}
+
+			if role := timerRouteRoles[route.GetName()][method]; op.TimerRole != role.String() {
+				t.Errorf("%s is documented as needing the %q role, it is checked for %q", key, op.TimerRole, role)
+			}
+		}
+
+		return nil
+
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %s", err)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			if key := strings.ToUpper(method) + " " + path; !documented[key] {
+				t.Errorf("%s is documented but not routed", key)
+			}
+		}
+	}
+
+}
+
+// TestOpenAPIContract makes a request for every documented operation through the client, checking each request
+// and response against the document
+func TestOpenAPIContract(t *testing.T) {
+
+	s, ts, tokens := newContractServer(t)
+
+	doc, err := s.openAPIDocument()
+	if err != nil {
+		t.Fatalf("failed to describe api: %s", err)
+	}
+
+	transport := &contractTransport{t: t, doc: doc, seen: make(map[string]bool)}
+
+	newClient := func(token string) *client.Client {
+		c, err := client.New(&client.Config{BaseURL: ts.URL, Token: token, HTTPClient: &http.Client{Transport: transport}})
+		if err != nil {
+			t.Fatalf("failed to create client: %s", err)
+		}
+		return c
+	}
+
+	ctx := context.Background()
+	c := newClient(tokens[""])
+
+	timer, err := c.CreateTimer(ctx, &poker.APITimerInput{
+		Name: "Friday Night",
+		Levels: []*poker.APILevel{
+			{Type: poker.LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 20},
+			{Type: poker.LevelTypeBlind, SmallBlind: 50, BigBlind: 100, AnteType: poker.AnteTypeBigBlind, DurationMin: 20},
+			{Type: poker.LevelTypeBreak, DurationMin: 10, Events: []poker.LevelEvent{poker.LevelEventLateRegistrationEnds}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create timer: %s", err)
+	}
+
+	if len(timer.Levels) != 3 || timer.Levels[1].Ante != 100 {
+		t.Fatalf("expected the timer's 3 levels with a big blind ante of 100, got %+v", timer.Levels)
+	}
+
+	timers, err := c.Timers(ctx)
+	if err != nil || len(timers) != 1 {
+		t.Fatalf("expected the created timer to be listed, got %d timers and error %v", len(timers), err)
+	}
+
+	_, err = c.Timer(ctx, timer.ID)
+	if err != nil {
+		t.Fatalf("failed to get timer: %s", err)
+	}
+
+	stale := timer.Version
+
+	timer, err = c.RenameTimer(ctx, timer.ID, "Saturday Night", timer.Version)
+	if err != nil || timer.Name != "Saturday Night" {
+		t.Fatalf("expected timer to be renamed, got %q and error %v", timer.Name, err)
+	}
+
+	levels, err := c.Levels(ctx, timer.ID)
+	if err != nil || len(levels) != 3 {
+		t.Fatalf("expected 3 levels, got %d and error %v", len(levels), err)
+	}
+
+	level, err := c.CreateLevel(ctx, timer.ID, &poker.APILevel{Type: poker.LevelTypeBlind, SmallBlind: 100, BigBlind: 200, Ante: 25, DurationMin: 20})
+	if err != nil || level.Position != 4 || level.AnteType != poker.AnteTypeTraditional {
+		t.Fatalf("expected a traditional ante level appended at 4, got %+v and error %v", level, err)
+	}
+
+	_, err = c.Level(ctx, timer.ID, level.ID)
+	if err != nil {
+		t.Fatalf("failed to get level: %s", err)
+	}
+
+	timer, err = c.Timer(ctx, timer.ID)
+	if err != nil {
+		t.Fatalf("failed to get timer: %s", err)
+	}
+
+	level.Position = 1
+	level, err = c.UpdateLevel(ctx, timer.ID, level.ID, level, timer.Version)
+	if err != nil || level.Position != 1 {
+		t.Fatalf("expected level to be moved to 1, got %+v and error %v", level, err)
+	}
+
+	for _, action := range []poker.PlayAction{poker.PlayActionStart, poker.PlayActionNext, poker.PlayActionPause} {
+		timer, err = c.Play(ctx, timer.ID, action)
+		if err != nil {
+			t.Fatalf("failed to %s timer: %s", action, err)
+		}
+	}
+
+	// Moving the new level in front of the first pushed the timer's level back to 2, next then moved it to 3
+	if timer.CurrentLevel != 3 || timer.Clock.State != poker.ClockStateStopped {
+		t.Fatalf("expected the stopped timer to be on level 3, got level %d %s", timer.CurrentLevel, timer.Clock.State)
+	}
+
+	err = c.DeleteLevel(ctx, timer.ID, level.ID, timer.Version)
+	if err != nil {
+		t.Fatalf("failed to delete level: %s", err)
+	}
+
+	// Errors are checked against the document as well
+	expectStatus := func(err error, status int) {
+		t.Helper()
+		var apiErr *client.Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("expected an api error with status %d, got %v", status, err)
+		}
+	}
+
+	_, err = c.CreateTimer(ctx, &poker.APITimerInput{Name: "x"})
+	expectStatus(err, http.StatusUnprocessableEntity)
+
+	// Changes made from a copy of the timer read before it was last saved are refused
+	_, err = c.RenameTimer(ctx, timer.ID, "Sunday Night", stale)
+	expectStatus(err, http.StatusConflict)
+
+	_, err = c.Play(ctx, timer.ID, "shuffle")
+	expectStatus(err, http.StatusNotFound)
+
+	_, err = newClient(tokens[poker.TokenScopeRead]).CreateTimer(ctx, &poker.APITimerInput{Name: "Read Only"})
+	expectStatus(err, http.StatusForbidden)
+
+	_, err = newClient("pkr_revoked").Timers(ctx)
+	expectStatus(err, http.StatusUnauthorized)
+
+	err = c.DeleteTimer(ctx, timer.ID)
+	if err != nil {
+		t.Fatalf("failed to delete timer: %s", err)
+	}
+
+	_, err = c.Timer(ctx, timer.ID)
+	expectStatus(err, http.StatusNotFound)
+
+	var missed []string
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			if !transport.seen[op.OperationID] {
+				missed = append(missed, op.OperationID)
+			}
+		}
+	}
+	sort.Strings(missed)
+
+	if len(missed) > 0 {
+		t.Errorf("operations were not checked: %s", strings.Join(missed, ", "))
+	}
+
+}
+
+// contractTransport checks every request and response made through it against the document
+type contractTransport struct {
+	t    *testing.T
+	doc  *openapi.Document
+	seen map[string]bool
+}
+
+func (ct *contractTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	op := ct.operation(req)
+	if op == nil {
+		ct.t.Errorf("%s %s does not match a documented operation", req.Method, req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	}
+
+	ct.seen[op.OperationID] = true
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		if op.RequestBody == nil {
+			ct.t.Errorf("%s sent a body it does not document", op.OperationID)
+		} else {
+			ct.check(op.OperationID+" request", op.RequestBody.Content["application/json"].Schema, body)
+		}
+	}
+
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	name := fmt.Sprintf("%s %d response", op.OperationID, res.StatusCode)
+
+	documented, ok := op.Responses[strconv.Itoa(res.StatusCode)]
+	if !ok {
+		ct.t.Errorf("%s is not documented", name)
+		return res, nil
+	}
+
+	if documented.Content == nil {
+		if len(body) > 0 {
+			ct.t.Errorf("%s is documented without a body, got %s", name, body)
+		}
+		return res, nil
+	}
+
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/json" {
+		ct.t.Errorf("%s is documented as application/json, got %q", name, contentType)
+	}
+
+	ct.check(name, documented.Content["application/json"].Schema, body)
+
+	for header := range documented.Headers {
+		if res.Header.Get(header) == "" {
+			ct.t.Errorf("%s is documented with a %s header, it was not sent", name, header)
+		}
+	}
+
+	return res, nil
+
+}
+
+// operation finds the documented operation the request is for
+func (ct *contractTransport) operation(req *http.Request) *openapi.Operation {
+
+	for path, item := range ct.doc.Paths {
+		if matchPath(path, req.URL.Path) {
+			if op := item[strings.ToLower(req.Method)]; op != nil {
+				return op
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// matchPath reports whether the path matches the document's path, whose variables match any one segment
+func matchPath(documented, path string) bool {
+
+	want, got := strings.Split(documented, "/"), strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+
+	for i := range want {
+		if strings.HasPrefix(want[i], "{") && got[i] != "" {
+			continue
+		}
+		if want[i] != got[i] {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+func (ct *contractTransport) check(name string, schema *openapi.Schema, body []byte) {
+
+	var v any
+	err := json.Unmarshal(body, &v)
+	if err != nil {
+		ct.t.Errorf("%s is not valid json: %s", name, err)
+		return
+	}
+
+	for _, problem := range validateSchema(ct.doc, schema, v, "$") {
+		ct.t.Errorf("%s does not match the document: %s", name, problem)
+	}
+
+}
+
+// validateSchema lists how v, decoded from JSON, differs from the schema. Properties the schema doesn't have are
+// reported too, so a field added to a type without the document changing is noticed
+func validateSchema(doc *openapi.Document, schema *openapi.Schema, v any, at string) []string {
+
+	if schema.Ref != "" {
+		ref, ok := doc.Components.Schemas[schema.RefName()]
+		if !ok {
+			return []string{fmt.Sprintf("%s references %s which is not in the document", at, schema.Ref)}
+		}
+		return validateSchema(doc, ref, v, at)
+	}
+
+	var problems []string
+
+	switch schema.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s should be an object, got %T", at, v)}
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s is missing required property %s", at, name))
+			}
+		}
+
+		for name, value := range obj {
+			property, ok := schema.Properties[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s has undocumented property %s", at, name))
+				continue
+			}
+			problems = append(problems, validateSchema(doc, property, value, at+"."+name)...)
+		}
+
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s should be an array, got %T", at, v)}
+		}
+
+		for i, item := range arr {
+			problems = append(problems, validateSchema(doc, schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s should be a string, got %T", at, v)}
+		}
+
+		if len(schema.Enum) > 0 && !contains(schema.Enum, str) {
+			problems = append(problems, fmt.Sprintf("%s is %q, expected one of %s", at, str, strings.Join(schema.Enum, ",")))
+		}
+
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				problems = append(problems, fmt.Sprintf("%s is not a date-time: %s", at, err))
+			}
+		}
+
+	case "number", "integer":
+		num, ok := v.(float64)
+		if !ok {
+			return []string{fmt.Sprintf("%s should be a number, got %T", at, v)}
+		}
+
+		if schema.Type == "integer" && num != float64(int64(num)) {
+			problems = append(problems, fmt.Sprintf("%s should be an integer, got %v", at, num))
+		}
+
+		if schema.Minimum != nil && num < *schema.Minimum {
+			problems = append(problems, fmt.Sprintf("%s is %v, below the minimum of %v", at, num, *schema.Minimum))
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []string{fmt.Sprintf("%s should be a boolean, got %T", at, v)}
+		}
+
+	default:
+		problems = append(problems, fmt.Sprintf("%s has a schema of unknown type %q", at, schema.Type))
+	}
+
+	return problems
+
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,19 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
+	"poker"
 	"poker/internal"
 	"poker/internal/templates"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
@@ -18,8 +23,6 @@ func (s *server) handleGetPlayTimer(w http.ResponseWriter, r *http.Request) {
 
 	entry := s.logger.WithContext(ctx)
 
-	user := internal.UserFromContext(ctx)
-
 	vars := mux.Vars(r)
 
 	timerID, ok := vars["timerID"]
@@ -38,10 +41,9 @@ func (s *server) handleGetPlayTimer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if timer.UserID != user.ID {
-		entry.Error("timer is not owned by authenticated user")
-		_ = s.templates.ResourceUnavailable(ctx).Render(w)
-
+	if timer == nil {
+		entry.Error("timer not found, returning not found page")
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
 		return
 	}
 
@@ -58,20 +60,20 @@ func (s *server) handleGetPlayTimer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var currentLevel = int(timer.CurrentLevel)
-	if currentLevel > len(timer.Levels)-1 {
-		timer.CurrentLevel = 0
+	// Any levels that ran out while nobody was watching are played through before rendering
+	now := time.Now()
+	timer, _, err = s.rollForwardTimer(ctx, timer, now)
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
 	}
 
-	level := timer.Levels[currentLevel]
-
-	level.DurationStr = formatDuration(int(level.DurationSec))
-
 	err = s.templates.Play(ctx, &templates.PlayProps{
 		User:         internal.UserFromContext(ctx),
-		Timer:        timer,
-		Level:        level,
+		Masthead:     s.mastheadProps(ctx, timer, now),
 		CurrentLevel: timer.CurrentLevel + 1,
+		ClientID:     uuid.New().String(),
 	}).Render(w)
 	if err != nil {
 		entry.WithError(err).Error("failed to render dashboard timer")
@@ -81,6 +83,59 @@ func (s *server) handleGetPlayTimer(w http.ResponseWriter, r *http.Request) {
 
 }
 
+func (s *server) handleGetPlayTimerMasthead(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	vars := mux.Vars(r)
+
+	timerID, ok := vars["timerID"]
+	if !ok {
+		entry.Error("var timerID missing from request context")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(timer.Levels) == 0 {
+		entry.Error("timer does not have any levels")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	timer, _, err = s.rollForwardTimer(ctx, timer, now)
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger-After-Settle", "countdown::reset")
+	err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timer")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
+
 func (s *server) handleGetPlayTimerResetLevel(w http.ResponseWriter, r *http.Request) {
 
 	var ctx = r.Context()
@@ -101,25 +156,40 @@ func (s *server) handleGetPlayTimerResetLevel(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	timer.IsComplete = false
+	if timer == nil {
+		s.logger.WithField("timerID", timerID).Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(timer.Levels) == 0 {
+		s.logger.WithField("timerID", timerID).Error("timer does not have any levels")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Levels that ran out before the reset are played through first, so it restarts the level the timer is really on
+	now := time.Now()
+	from := timer.PlayPosition()
+	timer.RollForward(now)
+	timer.RestartLevel()
 
 	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
 	if err != nil {
 		s.logger.WithError(err).Error("failed to save timer")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	level := timer.Levels[timer.CurrentLevel]
-
-	level.DurationStr = formatDuration(int(level.DurationSec))
+	s.publishTimerEvent(r, timer.ID, timerEventReset)
+	s.sendTimerTransitionWebhooks(ctx, timer, from)
+	s.sendTimerWebhooks(ctx, timer, []poker.WebhookEvent{poker.WebhookEventTimerReset}, -1)
 
 	w.Header().Set("HX-Trigger-After-Settle", "countdown::reset")
-	err = s.templates.TimerMasthead(
-		ctx,
-		timer,
-		level,
-	).Render(w)
+	err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to render dashboard timer")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -147,17 +217,46 @@ func (s *server) handleGetPlayTimerNextLevel(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	level := timer.Levels[timer.CurrentLevel]
+	if timer == nil {
+		s.logger.WithField("timerID", timerID).Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(timer.Levels) == 0 {
+		s.logger.WithField("timerID", timerID).Error("timer does not have any levels")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var proceed = false
+	proceedStr := r.URL.Query().Get("proceed")
+	if proceedStr != "" {
+		parsedProceed, err := strconv.ParseBool(proceedStr)
+		if err == nil {
+			proceed = parsedProceed
+		}
+	}
+
+	now := time.Now()
 
-	if timer.CurrentLevel == uint(len(timer.Levels)-1) {
+	if proceed {
+		// The display reached the end of the level on its own, so the server clock decides
+		// whether the level is really over. Another display may already have moved it on
+		var changed bool
+		timer, changed, err = s.rollForwardTimer(ctx, timer, now)
+		if err != nil {
+			s.logger.WithError(err).Error("failed to save timer")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 
-		timer.IsComplete = true
+		if changed {
+			s.publishTimerEvent(r, timer.ID, timerEventLevel)
+		}
 
-		err = s.templates.TimerMasthead(
-			ctx,
-			timer,
-			level,
-		).Render(w)
+		w.Header().Set("HX-Trigger-After-Settle", "countdown::proceed")
+		err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
 		if err != nil {
 			s.logger.WithError(err).Error("failed to render dashboard timer")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -166,38 +265,26 @@ func (s *server) handleGetPlayTimerNextLevel(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	timer.CurrentLevel += 1
+	// Moving on goes from the level the timer is really on, not the one it was last saved on
+	from := timer.PlayPosition()
+	timer.RollForward(now)
+	timer.NextLevel()
 
 	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
 	if err != nil {
 		s.logger.WithError(err).Error("failed to save timer")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	level = timer.Levels[timer.CurrentLevel]
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+	s.sendTimerTransitionWebhooks(ctx, timer, from)
 
-	level.DurationStr = formatDuration(int(level.DurationSec))
-
-	var proceed = false
-	proceedStr := r.URL.Query().Get("proceed")
-	if proceedStr != "" {
-		parsedProceed, err := strconv.ParseBool(proceedStr)
-		if err == nil {
-			proceed = parsedProceed
-		}
-	}
-
-	if proceed {
-		w.Header().Set("HX-Trigger-After-Settle", "countdown::proceed")
-	} else {
-		w.Header().Set("HX-Trigger-After-Settle", "countdown::reset")
-	}
-	err = s.templates.TimerMasthead(
-		ctx,
-		timer,
-		level,
-	).Render(w)
+	w.Header().Set("HX-Trigger-After-Settle", "countdown::reset")
+	err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to render dashboard timer")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -225,14 +312,24 @@ func (s *server) handleGetPlayTimerPreviousLevel(w http.ResponseWriter, r *http.
 		return
 	}
 
-	level := timer.Levels[timer.CurrentLevel]
+	if timer == nil {
+		s.logger.WithField("timerID", timerID).Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(timer.Levels) == 0 {
+		s.logger.WithField("timerID", timerID).Error("timer does not have any levels")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
-	if timer.CurrentLevel == 0 {
-		err = s.templates.TimerMasthead(
-			ctx,
-			timer,
-			level,
-		).Render(w)
+	// Going back goes from the level the timer is really on, which has to be saved even when there is no level before it
+	now := time.Now()
+	from := timer.PlayPosition()
+	rolled := timer.RollForward(now)
+	if !timer.PreviousLevel() && !rolled {
+		err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
 		if err != nil {
 			s.logger.WithError(err).Error("failed to render dashboard timer")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -241,26 +338,21 @@ func (s *server) handleGetPlayTimerPreviousLevel(w http.ResponseWriter, r *http.
 		return
 	}
 
-	timer.CurrentLevel -= 1
-	timer.IsComplete = false
-
 	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
 	if err != nil {
 		s.logger.WithError(err).Error("failed to save timer")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	level = timer.Levels[timer.CurrentLevel]
-
-	level.DurationStr = formatDuration(int(level.DurationSec))
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+	s.sendTimerTransitionWebhooks(ctx, timer, from)
 
 	w.Header().Set("HX-Trigger-After-Settle", "countdown::reset")
-	err = s.templates.TimerMasthead(
-		ctx,
-		timer,
-		level,
-	).Render(w)
+	err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to render dashboard timer")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -268,6 +360,178 @@ func (s *server) handleGetPlayTimerPreviousLevel(w http.ResponseWriter, r *http.
 
 }
 
+func (s *server) handleGetPlayTimerClockStart(w http.ResponseWriter, r *http.Request) {
+	s.updatePlayTimerClock(w, r, func(timer *poker.Timer, now time.Time) string {
+
+		// A level that has not been played yet gets its announcement, a paused one just carries on
+		trigger := "countdown::start"
+		if timer.IsPaused() || timer.ElapsedSec > 0 {
+			trigger = "countdown::reset"
+		}
+
+		timer.StartClock(now)
+
+		return trigger
+
+	})
+}
+
+func (s *server) handleGetPlayTimerClockPause(w http.ResponseWriter, r *http.Request) {
+	s.updatePlayTimerClock(w, r, func(timer *poker.Timer, now time.Time) string {
+		timer.PauseClock(now)
+		return "countdown::reset"
+	})
+}
+
+func (s *server) handleGetPlayTimerClockResume(w http.ResponseWriter, r *http.Request) {
+	s.updatePlayTimerClock(w, r, func(timer *poker.Timer, now time.Time) string {
+		timer.StartClock(now)
+		return "countdown::reset"
+	})
+}
+
+// updatePlayTimerClock loads the requested timer, brings its clock up to date, applies fn and
+// renders the masthead, triggering the client side event fn returns once the swap has settled
+func (s *server) updatePlayTimerClock(w http.ResponseWriter, r *http.Request, fn func(timer *poker.Timer, now time.Time) string) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	vars := mux.Vars(r)
+
+	timerID, ok := vars["timerID"]
+	if !ok {
+		entry.Error("var timerID missing from request context")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(timer.Levels) == 0 {
+		entry.Error("timer does not have any levels")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+
+	from := timer.PlayPosition()
+	timer.RollForward(now)
+	trigger := fn(timer, now)
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.publishTimerEvent(r, timer.ID, timerEventClock)
+	s.sendTimerTransitionWebhooks(ctx, timer, from)
+
+	w.Header().Set("HX-Trigger-After-Settle", trigger)
+	err = s.templates.TimerMasthead(ctx, s.mastheadProps(ctx, timer, now)).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timer")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
+
+// mastheadProps gathers everything the masthead shows for the timer as of now. The tournament is only
+// extra information, so failing to load it is logged rather than failing the whole masthead
+func (s *server) mastheadProps(ctx context.Context, timer *poker.Timer, now time.Time) *templates.MastheadProps {
+
+	tournament, err := s.tournamentRepo.TournamentByTimerID(ctx, timer.ID)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).WithField("timerID", timer.ID).Error("failed to fetch tournament for timer")
+	}
+
+	// Displays are read only through their token whoever is signed in, so only the play page depends on the role
+	var readOnly bool
+	if user := internal.UserFromContext(ctx); user != nil {
+		readOnly = !timer.RoleOf(user.ID).Includes(poker.TimerRoleOperator)
+	}
+
+	return &templates.MastheadProps{
+		Timer:      timer,
+		Level:      currentLevelAt(timer, now),
+		Tournament: tournament,
+		Schedule:   timer.Schedule(now),
+		ReadOnly:   readOnly,
+	}
+
+}
+
+// rollForwardTimer plays the timer through any levels that ran out and saves it, reporting whether
+// anything changed. Every open display rolls the timer forward at the same moment, so losing that race
+// is expected and the timer saved by whoever won is returned instead
+func (s *server) rollForwardTimer(ctx context.Context, timer *poker.Timer, now time.Time) (*poker.Timer, bool, error) {
+
+	from := timer.PlayPosition()
+	if !timer.RollForward(now) {
+		return timer, false, nil
+	}
+
+	err := s.timerRepo.SaveTimer(ctx, timer)
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		latest, err := s.timerRepo.Timer(ctx, timer.ID)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if latest == nil {
+			return nil, false, fmt.Errorf("timer %s was deleted while rolling forward", timer.ID)
+		}
+
+		latest.RollForward(now)
+
+		return latest, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.sendTimerTransitionWebhooks(ctx, timer, from)
+
+	return timer, true, nil
+
+}
+
+// currentLevelAt returns the level the timer is on with DurationStr set to the time left in it, or nil if the timer
+// has no levels
+func currentLevelAt(timer *poker.Timer, now time.Time) *poker.TimerLevel {
+
+	level := timer.Level()
+	if level == nil {
+		return nil
+	}
+
+	level.DurationStr = formatDuration(int(timer.Remaining(now).Seconds()))
+
+	return level
+
+}
+
 func formatDuration(duration int) string {
 
 	hours := math.Floor(math.Mod(float64(duration/(60*60)), 24))
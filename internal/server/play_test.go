@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"poker"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func playLevel(t *testing.T, handler http.HandlerFunc, timerID string) int {
+	t.Helper()
+
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/play/"+timerID, nil), map[string]string{"timerID": timerID})
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	return w.Code
+}
+
+func TestPlayLevelWithoutLevels(t *testing.T) {
+
+	s, _, _ := newLoginServer(t)
+
+	err := s.timerRepo.SaveTimer(context.Background(), &poker.Timer{ID: "empty", UserID: "owner"})
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	handlers := map[string]http.HandlerFunc{
+		"reset":    s.handleGetPlayTimerResetLevel,
+		"next":     s.handleGetPlayTimerNextLevel,
+		"previous": s.handleGetPlayTimerPreviousLevel,
+	}
+
+	for name, handler := range handlers {
+		if code := playLevel(t, handler, "empty"); code != http.StatusBadRequest {
+			t.Errorf("%s: expected a timer without levels to be refused, got %d", name, code)
+		}
+		if code := playLevel(t, handler, "missing"); code != http.StatusNotFound {
+			t.Errorf("%s: expected a missing timer to be not found, got %d", name, code)
+		}
+	}
+
+}
+
+// TestPlayLevelRollsForward changes level on a timer whose first level ran out while nobody was watching, which
+// moves from the level it is really on rather than the one it was saved on
+func TestPlayLevelRollsForward(t *testing.T) {
+
+	tt := []struct {
+		name    string
+		handler func(s *server) http.HandlerFunc
+		want    uint
+	}{
+		{name: "next", handler: func(s *server) http.HandlerFunc { return s.handleGetPlayTimerNextLevel }, want: 2},
+		{name: "previous", handler: func(s *server) http.HandlerFunc { return s.handleGetPlayTimerPreviousLevel }, want: 0},
+		{name: "reset", handler: func(s *server) http.HandlerFunc { return s.handleGetPlayTimerResetLevel }, want: 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			s, _, _ := newLoginServer(t)
+			ctx := context.Background()
+
+			// Started twelve minutes ago, so two minutes into the break
+			startedAt := time.Now().Add(-12 * time.Minute)
+			err := s.timerRepo.SaveTimer(ctx, &poker.Timer{
+				ID:     "rolled",
+				UserID: "owner",
+				Levels: []*poker.TimerLevel{
+					{ID: "1", Type: poker.LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 10, DurationSec: 600},
+					{ID: "2", Type: poker.LevelTypeBreak, DurationMin: 5, DurationSec: 300},
+					{ID: "3", Type: poker.LevelTypeBlind, SmallBlind: 50, BigBlind: 100, DurationMin: 10, DurationSec: 600},
+				},
+				StartedAt: &startedAt,
+			})
+			if err != nil {
+				t.Fatalf("failed to save timer: %s", err)
+			}
+
+			if code := playLevel(t, tc.handler(s), "rolled"); code != http.StatusOK {
+				t.Fatalf("expected the level to change, got %d", code)
+			}
+
+			timer, err := s.timerRepo.Timer(ctx, "rolled")
+			if err != nil || timer == nil {
+				t.Fatalf("failed to fetch timer: %v", err)
+			}
+
+			if timer.CurrentLevel != tc.want || timer.IsRunning() || timer.ElapsedSec != 0 {
+				t.Errorf("expected to be stopped at the start of level %d, got level %d running %t with %vs played", tc.want+1, timer.CurrentLevel+1, timer.IsRunning(), timer.ElapsedSec)
+			}
+
+		})
+	}
+
+}
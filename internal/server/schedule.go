@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"poker/internal/templates"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduledStartLayout is the value of a datetime-local input
+const scheduledStartLayout = "2006-01-02T15:04"
+
+func (s *server) handlePostDashboardTimerSchedule(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	entry = entry.WithField("timerID", timer.ID)
+
+	render := func(errors []string) {
+		err := s.templates.DashboardTimerScheduleComponent(ctx, &templates.DashboardTimerScheduleProps{
+			Timer:    timer,
+			Schedule: timer.Schedule(time.Now()),
+			Errors:   errors,
+		}).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render timer schedule component")
+		}
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		render([]string{"the schedule could not be read, please try again"})
+		return
+	}
+
+	// An empty planned start clears it
+	var start *time.Time
+	if value := strings.TrimSpace(r.PostFormValue("ScheduledStart")); value != "" {
+		// The browser sends its offset from UTC in minutes, positive when it is behind UTC
+		offset, _ := strconv.Atoi(r.PostFormValue("TimezoneOffset"))
+		parsed, err := time.ParseInLocation(scheduledStartLayout, value, time.FixedZone("", -offset*60))
+		if err != nil {
+			render([]string{"the planned start must be a date and time"})
+			return
+		}
+
+		parsed = parsed.UTC()
+		start = &parsed
+	}
+
+	timer.ScheduledStart = start
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The schedule is shown alongside the clock, so anyone playing the timer needs to pick up the change
+	s.publishTimerEvent(r, timer.ID, timerEventLevel)
+
+	render(nil)
+
+}
@@ -6,76 +6,84 @@ import (
 	"net/http"
 	"poker"
 	"poker/internal/authenticator"
-	"poker/internal/store/dynamo"
 	"poker/internal/templates"
+	"poker/internal/webhook"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/polly"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/ddouglas/dynastore"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/schema"
+	"github.com/gorilla/sessions"
 	"github.com/sirupsen/logrus"
 )
 
 type server struct {
-	appURL      string
-	env         poker.Environment
-	port        string
-	audioBucket string
+	appURL string
+	env    poker.Environment
+	port   string
 
 	http   *http.Server
 	router *mux.Router
 
 	// Services
+	audioCache    poker.BlobStore
 	authenticator *authenticator.Service
 	decoder       *schema.Decoder
+	events        *broker
 	logger        *logrus.Logger
-	polly         *polly.Client
-	s3            *s3.Client
-	sessions      *dynastore.Store
+	speech        poker.SpeechSynthesizer
+	sessions      sessions.Store
 	templates     *templates.Service
 	validator     *validator.Validate
+	webhooks      *webhook.Sender
 
 	// Repositories
-	timerRepo *dynamo.TimerRepository
-	userRepo  *dynamo.UserRepository
+	apiTokenRepo   poker.APITokenRepository
+	timerRepo      poker.TimerRepository
+	tournamentRepo poker.TournamentRepository
+	userRepo       poker.UserRepository
+	webhookRepo    poker.WebhookRepository
 }
 
 func New(
 	env poker.Environment,
 	appURL string,
 	port string,
-	audioBucket string,
 	logger *logrus.Logger,
 	validator *validator.Validate,
 
+	audioCache poker.BlobStore,
 	authenticator *authenticator.Service,
-	polly *polly.Client,
-	s3 *s3.Client,
-	sessions *dynastore.Store,
-
-	timerRepo *dynamo.TimerRepository,
-	userRepo *dynamo.UserRepository,
+	speech poker.SpeechSynthesizer,
+	sessions sessions.Store,
+
+	apiTokenRepo poker.APITokenRepository,
+	timerRepo poker.TimerRepository,
+	tournamentRepo poker.TournamentRepository,
+	userRepo poker.UserRepository,
+	webhookRepo poker.WebhookRepository,
 ) *server {
 
 	s := &server{
-		appURL:      appURL,
-		env:         env,
-		port:        port,
-		audioBucket: audioBucket,
+		appURL: appURL,
+		env:    env,
+		port:   port,
 
+		audioCache:    audioCache,
 		authenticator: authenticator,
 		decoder:       schema.NewDecoder(),
+		events:        newBroker(),
 		logger:        logger,
-		polly:         polly,
-		s3:            s3,
+		speech:        speech,
 		sessions:      sessions,
 		validator:     validator,
+		webhooks:      webhook.New(webhookRepo, logger, &webhook.Config{AllowPrivateNetworks: !env.IsProduction()}),
 
-		timerRepo: timerRepo,
-		userRepo:  userRepo,
+		apiTokenRepo:   apiTokenRepo,
+		timerRepo:      timerRepo,
+		tournamentRepo: tournamentRepo,
+		userRepo:       userRepo,
+		webhookRepo:    webhookRepo,
 	}
 
 	s.router = s.buildRouter()
@@ -107,7 +115,21 @@ func (s *server) Run(tmpl *templates.Service) error {
 }
 
 func (s *server) GracefullyShutdown(ctx context.Context) error {
-	return s.http.Shutdown(ctx)
+
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Webhooks still being retried are given until ctx is done, the rest are abandoned rather than holding up the
+	// shutdown
+	err = s.webhooks.Wait(ctx)
+	if err != nil {
+		s.logger.WithError(err).Warn("shut down with webhook deliveries still being retried")
+	}
+
+	return nil
+
 }
 
 func (s *server) BuildRoute(name string, pairsInf ...any) (string, error) {
@@ -139,6 +161,21 @@ func (s *server) buildRouter() *mux.Router {
 
 	router.HandleFunc("/", s.handleHome).Name("home").Methods(http.MethodGet)
 	router.HandleFunc("/login", s.handleLogin).Name("login").Methods(http.MethodGet)
+	router.HandleFunc("/login/oidc", s.handleGetLoginOIDC).Name("login-oidc").Methods(http.MethodGet)
+	router.HandleFunc("/login/password", s.handlePostLoginPassword).Name("login-password").Methods(http.MethodPost)
+	router.HandleFunc("/login/magic-link", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetLoginMagicLink,
+			http.MethodPost: s.handlePostLoginMagicLink,
+		}[r.Method](w, r)
+	}).Name("login-magic-link").Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetRegister,
+			http.MethodPost: s.handlePostRegister,
+		}[r.Method](w, r)
+	}).Name("register").Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/register/verify", s.handleGetRegisterVerify).Name("register-verify").Methods(http.MethodGet)
 	router.HandleFunc("/logout", s.handleLogout).Name("logout").Methods(http.MethodGet)
 	// router.PathPrefix("/static").Handler().Name("static").Methods(http.MethodGet)
 	router.PathPrefix("/static").Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -146,8 +183,48 @@ func (s *server) buildRouter() *mux.Router {
 		http.StripPrefix("/static/", http.FileServer(http.FS(poker.AssetFS(s.env)))).ServeHTTP(w, r)
 	})).Name("static").Methods(http.MethodGet)
 
+	router.HandleFunc("/display/{timerID}/{token}", s.handleGetDisplayTimer).Name("display-timer").Methods(http.MethodGet)
+	router.HandleFunc("/display/{timerID}/{token}/masthead", s.handleGetDisplayTimerMasthead).Name("display-timer-masthead").Methods(http.MethodGet)
+	router.HandleFunc("/display/{timerID}/{token}/events", s.handleGetDisplayTimerEvents).Name("display-timer-events").Methods(http.MethodGet)
+
+	// The API authenticates with tokens rather than the session, so it is kept apart from the pages
+	// Registered ahead of the other API routes as it is read without a token
+	router.HandleFunc("/api/v1/openapi.json", s.handleGetAPIOpenAPI).Name("api-openapi").Methods(http.MethodGet)
+
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(s.apiAuth)
+	api.Use(s.timerAccess)
+	api.HandleFunc("/timers", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetAPITimers,
+			http.MethodPost: s.handlePostAPITimers,
+		}[r.Method](w, r)
+	}).Name("api-timers").Methods(http.MethodGet, http.MethodPost)
+	api.HandleFunc("/timers/{timerID}", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:    s.handleGetAPITimer,
+			http.MethodPatch:  s.handlePatchAPITimer,
+			http.MethodDelete: s.handleDeleteAPITimer,
+		}[r.Method](w, r)
+	}).Name("api-timer").Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
+	api.HandleFunc("/timers/{timerID}/levels", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetAPITimerLevels,
+			http.MethodPost: s.handlePostAPITimerLevels,
+		}[r.Method](w, r)
+	}).Name("api-timer-levels").Methods(http.MethodGet, http.MethodPost)
+	api.HandleFunc("/timers/{timerID}/levels/{levelID}", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:    s.handleGetAPITimerLevel,
+			http.MethodPut:    s.handlePutAPITimerLevel,
+			http.MethodDelete: s.handleDeleteAPITimerLevel,
+		}[r.Method](w, r)
+	}).Name("api-timer-level").Methods(http.MethodGet, http.MethodPut, http.MethodDelete)
+	api.HandleFunc("/timers/{timerID}/play/{action}", s.handlePostAPITimerPlay).Name("api-timer-play").Methods(http.MethodPost)
+
 	authed := router.NewRoute().Subrouter()
 	authed.Use(s.auth)
+	authed.Use(s.timerAccess)
 	authed.HandleFunc("/dashboard", s.handleDashboard).Name("dashboard").Methods(http.MethodGet)
 	authed.HandleFunc("/dashboard/timers", s.handleDashboardTimers).Name("dashboard-timers").Methods(http.MethodGet)
 	authed.HandleFunc("/dashboard/timers/new", func(w http.ResponseWriter, r *http.Request) {
@@ -157,6 +234,29 @@ func (s *server) buildRouter() *mux.Router {
 		}[r.Method](w, r)
 	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timers-new")
 
+	authed.HandleFunc("/dashboard/timers/generate", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTimersGenerate,
+			http.MethodPost: s.handlePostDashboardTimersGenerate,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timers-generate")
+
+	authed.HandleFunc("/dashboard/timers/generate/preview", s.handlePostDashboardTimersGeneratePreview).Name("dashboard-timers-generate-preview").Methods(http.MethodPost)
+
+	authed.HandleFunc("/dashboard/timers/import", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTimersImport,
+			http.MethodPost: s.handlePostDashboardTimersImport,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timers-import")
+
+	authed.HandleFunc("/dashboard/timers/templates", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTimersTemplates,
+			http.MethodPost: s.handlePostDashboardTimersTemplates,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timers-templates")
+
 	authed.HandleFunc("/dashboard/timers/{timerID}", func(w http.ResponseWriter, r *http.Request) {
 		map[string]http.HandlerFunc{
 			http.MethodGet:    s.handleGetDashboardTimer,
@@ -164,12 +264,142 @@ func (s *server) buildRouter() *mux.Router {
 		}[r.Method](w, r)
 	}).Methods(http.MethodGet, http.MethodDelete).Name("dashboard-timer")
 
+	authed.HandleFunc("/dashboard/timers/{timerID}/display", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost:   s.handlePostDashboardTimerDisplay,
+			http.MethodDelete: s.handleDeleteDashboardTimerDisplay,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost, http.MethodDelete).Name("dashboard-timer-display")
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/export/{format:json|yaml|csv}", s.handleGetDashboardTimerExport).Name("dashboard-timer-export").Methods(http.MethodGet)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/sheet", s.handleGetDashboardTimerSheet).Name("dashboard-timer-sheet").Methods(http.MethodGet)
+	authed.HandleFunc("/dashboard/timers/{timerID}/sheet.pdf", s.handleGetDashboardTimerSheetPDF).Name("dashboard-timer-sheet-pdf").Methods(http.MethodGet)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/duplicate", s.handlePostDashboardTimerDuplicate).Name("dashboard-timer-duplicate").Methods(http.MethodPost)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/template", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost:   s.handlePostDashboardTimerTemplate,
+			http.MethodDelete: s.handleDeleteDashboardTimerTemplate,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost, http.MethodDelete).Name("dashboard-timer-template")
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/chips", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost:   s.handlePostDashboardTimerChips,
+			http.MethodDelete: s.handleDeleteDashboardTimerChips,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost, http.MethodDelete).Name("dashboard-timer-chips")
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/schedule", s.handlePostDashboardTimerSchedule).Name("dashboard-timer-schedule").Methods(http.MethodPost)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/chips/distribution", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTimerChipsDistribution,
+			http.MethodPost: s.handlePostDashboardTimerChipsDistribution,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timer-chips-distribution")
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/chips/distribution/print", s.handlePostDashboardTimerChipsDistributionPrint).Name("dashboard-timer-chips-distribution-print").Methods(http.MethodPost)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/shares", s.handlePostDashboardTimerShares).Name("dashboard-timer-shares").Methods(http.MethodPost)
+	authed.HandleFunc("/dashboard/timers/{timerID}/shares/{userID}", s.handleDeleteDashboardTimerShare).Name("dashboard-timer-share").Methods(http.MethodDelete)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTimerWebhooks,
+			http.MethodPost: s.handlePostDashboardTimerWebhooks,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timer-webhooks")
+	authed.HandleFunc("/dashboard/timers/{timerID}/webhooks/{webhookID}", s.handleDeleteDashboardTimerWebhook).Name("dashboard-timer-webhook").Methods(http.MethodDelete)
+	authed.HandleFunc("/dashboard/timers/{timerID}/webhooks/{webhookID}/test", s.handlePostDashboardTimerWebhookTest).Name("dashboard-timer-webhook-test").Methods(http.MethodPost)
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/invitation", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost:   s.handlePostDashboardTimerInvitation,
+			http.MethodDelete: s.handleDeleteDashboardTimerInvitation,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost, http.MethodDelete).Name("dashboard-timer-invitation")
+
+	authed.HandleFunc("/dashboard/tokens", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTokens,
+			http.MethodPost: s.handlePostDashboardTokens,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-tokens")
+	authed.HandleFunc("/dashboard/tokens/{tokenID}", s.handleDeleteDashboardToken).Name("dashboard-token").Methods(http.MethodDelete)
+
+	authed.HandleFunc("/dashboard/tournaments", s.handleDashboardTournaments).Name("dashboard-tournaments").Methods(http.MethodGet)
+	authed.HandleFunc("/dashboard/tournaments/new", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTournamentNew,
+			http.MethodPost: s.handlePostDashboardTournamentNew,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-tournaments-new")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:    s.handleGetDashboardTournament,
+			http.MethodDelete: s.handleDeleteDashboardTournament,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodDelete).Name("dashboard-tournament")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}/players", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTournamentPlayers,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-tournament-players")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}/players/{playerID}", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodDelete: s.handleDeleteDashboardTournamentPlayer,
+		}[r.Method](w, r)
+	}).Methods(http.MethodDelete).Name("dashboard-tournament-player")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}/players/{playerID}/{action:rebuy|addon|eliminate}", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTournamentPlayerAction,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-tournament-player-action")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}/payouts", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTournamentPayouts,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-tournament-payouts")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}/deal", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet:  s.handleGetDashboardTournamentDeal,
+			http.MethodPost: s.handlePostDashboardTournamentDeal,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-tournament-deal")
+
+	authed.HandleFunc("/dashboard/tournaments/{tournamentID}/deal/print", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTournamentDealPrint,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-tournament-deal-print")
+
 	authed.HandleFunc("/play/{timerID}", func(w http.ResponseWriter, r *http.Request) {
 		map[string]http.HandlerFunc{
 			http.MethodGet: s.handleGetPlayTimer,
 		}[r.Method](w, r)
 	}).Methods(http.MethodGet).Name("play-timer")
 
+	authed.HandleFunc("/play/{timerID}/masthead", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet: s.handleGetPlayTimerMasthead,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet).Name("play-timer-masthead")
+
+	authed.HandleFunc("/play/{timerID}/events", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet: s.handleGetPlayTimerEvents,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet).Name("play-timer-events")
+
 	authed.HandleFunc("/play/{timerID}/levels/reset", func(w http.ResponseWriter, r *http.Request) {
 		map[string]http.HandlerFunc{
 			http.MethodGet: s.handleGetPlayTimerResetLevel,
@@ -188,6 +418,24 @@ func (s *server) buildRouter() *mux.Router {
 		}[r.Method](w, r)
 	}).Methods(http.MethodGet).Name("play-timer-previous-level")
 
+	authed.HandleFunc("/play/{timerID}/clock/start", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet: s.handleGetPlayTimerClockStart,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet).Name("play-timer-clock-start")
+
+	authed.HandleFunc("/play/{timerID}/clock/pause", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet: s.handleGetPlayTimerClockPause,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet).Name("play-timer-clock-pause")
+
+	authed.HandleFunc("/play/{timerID}/clock/resume", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodGet: s.handleGetPlayTimerClockResume,
+		}[r.Method](w, r)
+	}).Methods(http.MethodGet).Name("play-timer-clock-resume")
+
 	authed.HandleFunc("/dashboard/timers/{timerID}/levels/new", func(w http.ResponseWriter, r *http.Request) {
 		map[string]http.HandlerFunc{
 			http.MethodGet:  s.handleGetDashboardTimerLevelNew,
@@ -195,6 +443,18 @@ func (s *server) buildRouter() *mux.Router {
 		}[r.Method](w, r)
 	}).Methods(http.MethodGet, http.MethodPost).Name("dashboard-timer-levels")
 
+	authed.HandleFunc("/dashboard/timers/{timerID}/levels/reorder", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTimerLevelsReorder,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-timer-levels-reorder")
+
+	authed.HandleFunc("/dashboard/timers/{timerID}/levels/bulk", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTimerLevelsBulk,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-timer-levels-bulk")
+
 	authed.HandleFunc("/dashboard/timers/{timerID}/levels/{levelID}", func(w http.ResponseWriter, r *http.Request) {
 		map[string]http.HandlerFunc{
 			http.MethodGet:    s.handleGetDashboardTimerLevelEdit,
@@ -203,11 +463,17 @@ func (s *server) buildRouter() *mux.Router {
 		}[r.Method](w, r)
 	}).Methods(http.MethodGet, http.MethodPost, http.MethodDelete).Name("dashboard-timer-level")
 
+	authed.HandleFunc("/dashboard/timers/{timerID}/levels/{levelID}/{action:up|down|duplicate}", func(w http.ResponseWriter, r *http.Request) {
+		map[string]http.HandlerFunc{
+			http.MethodPost: s.handlePostDashboardTimerLevelAction,
+		}[r.Method](w, r)
+	}).Methods(http.MethodPost).Name("dashboard-timer-level-action")
+
 	authed.HandleFunc("/dashboard/timers/{timerID}/levels/{levelID}/audio/{action}", func(w http.ResponseWriter, r *http.Request) {
 		map[string]http.HandlerFunc{
 			http.MethodGet: s.handleGetDashboardTimerLevelAudio,
 		}[r.Method](w, r)
-	}).Methods(http.MethodGet, http.MethodPost, http.MethodDelete).Name("dashboard-timer-level-audio")
+	}).Methods(http.MethodGet).Name("dashboard-timer-level-audio")
 
 	return router
 }
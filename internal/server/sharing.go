@@ -0,0 +1,189 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *server) handlePostDashboardTimerShares(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	entry = entry.WithField("timerID", timer.ID)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	props := &templates.DashboardTimerSharingProps{
+		Timer: timer,
+		Email: strings.ToLower(strings.TrimSpace(r.PostFormValue("Email"))),
+		Role:  poker.TimerRole(r.PostFormValue("Role")),
+	}
+
+	render := func(errors []string) {
+		props.Errors = errors
+		err := s.templates.DashboardTimerSharingComponent(ctx, props).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render timer sharing component")
+		}
+	}
+
+	if props.Email == "" {
+		render([]string{"enter the email address of the person to invite"})
+		return
+	}
+
+	// Only people who have signed in before can be invited, there is no account to share with until then
+	invitee, err := s.userRepo.UserByEmail(ctx, props.Email)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch user by email")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if invitee == nil {
+		render([]string{fmt.Sprintf("nobody has signed in with %s yet, ask them to sign in once and then invite them", props.Email)})
+		return
+	}
+
+	err = timer.Invite(invitee, props.Role, time.Now())
+	if err != nil {
+		render([]string{err.Error()})
+		return
+	}
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The form is cleared for the next invitation
+	props.Email, props.Role = "", ""
+
+	render(nil)
+
+}
+
+func (s *server) handleDeleteDashboardTimerShare(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	userID := mux.Vars(r)["userID"]
+
+	entry = entry.WithField("timerID", timer.ID).WithField("userID", userID)
+
+	if !timer.Unshare(userID) {
+		entry.Error("timer is not shared with user")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.templates.DashboardTimerSharingComponent(ctx, &templates.DashboardTimerSharingProps{Timer: timer}).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render timer sharing component")
+	}
+
+}
+
+func (s *server) handlePostDashboardTimerInvitation(w http.ResponseWriter, r *http.Request) {
+	s.answerDashboardTimerInvitation(w, r, func(timer *poker.Timer, user *poker.User) error {
+		return timer.AcceptInvitation(user.ID, time.Now())
+	})
+}
+
+// handleDeleteDashboardTimerInvitation declines an invitation, or leaves a timer once it has been accepted
+func (s *server) handleDeleteDashboardTimerInvitation(w http.ResponseWriter, r *http.Request) {
+	s.answerDashboardTimerInvitation(w, r, func(timer *poker.Timer, user *poker.User) error {
+		timer.Unshare(user.ID)
+		return nil
+	})
+}
+
+// answerDashboardTimerInvitation applies fn to the timer the signed in user was invited to, then renders their
+// list of timers
+func (s *server) answerDashboardTimerInvitation(w http.ResponseWriter, r *http.Request, fn func(timer *poker.Timer, user *poker.User) error) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	entry = entry.WithField("timerID", timer.ID)
+
+	err := fn(timer, user)
+	if err != nil {
+		entry.WithError(err).Error("failed to answer invitation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	props, err := s.dashboardTimersProps(ctx)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timers")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.templates.DashboardTimersFragment(ctx, props).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timers")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
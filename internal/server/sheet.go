@@ -0,0 +1,97 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"poker"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+func (s *server) handleGetDashboardTimerSheet(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	sheet, ok := s.dashboardTimerSheet(w, r, entry)
+	if !ok {
+		return
+	}
+
+	err := s.templates.DashboardTimerSheetPrint(ctx, sheet).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render timer sheet")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handleGetDashboardTimerSheetPDF(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	sheet, ok := s.dashboardTimerSheet(w, r, entry)
+	if !ok {
+		return
+	}
+
+	// The PDF is built in memory so a failure part way through can still be answered with an error
+	var buf bytes.Buffer
+	err := sheet.WritePDF(&buf)
+	if err != nil {
+		entry.WithError(err).WithField("timerID", sheet.Timer.ID).Error("failed to generate timer sheet pdf")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	filename := strings.Trim(strings.ToLower(filenameUnsafe.ReplaceAllString(sheet.Timer.Name, "-")), "-")
+	if filename == "" {
+		filename = "timer"
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+	_, _ = buf.WriteTo(w)
+
+}
+
+// dashboardTimerSheet lays out the requested timer along with the tournament played on it, writing the response
+// and returning false if the timer can't be found or isn't owned by the authenticated user
+func (s *server) dashboardTimerSheet(w http.ResponseWriter, r *http.Request, entry *logrus.Entry) (*poker.TimerSheet, bool) {
+
+	var ctx = r.Context()
+
+	timerID := mux.Vars(r)["timerID"]
+
+	entry = entry.WithField("timerID", timerID)
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return nil, false
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
+		return nil, false
+	}
+
+	// The sheet is still worth printing without the tournament details
+	tournament, err := s.tournamentRepo.TournamentByTimerID(ctx, timer.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch tournament for timer")
+	}
+
+	return poker.NewTimerSheet(timer, tournament), true
+
+}
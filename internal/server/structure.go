@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+
+	"github.com/google/uuid"
+)
+
+// structureForm is the generator form, the parameters along with where the structure is to be saved
+type structureForm struct {
+	poker.StructureParams
+	TimerID   string
+	TimerName string
+}
+
+func (s *server) handleGetDashboardTimersGenerate(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	timers, err := s.editableTimers(ctx, user)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to fetch editable timers")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	err = s.templates.DashboardStructureGeneratorComponent(ctx, &templates.DashboardStructureGeneratorProps{
+		Timers: timers,
+		Params: poker.DefaultStructureParams,
+		// The timer page links here to regenerate its own levels
+		TimerID: r.URL.Query().Get("timerID"),
+	}).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render structure generator")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimersGeneratePreview(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	props, ok := s.structureFromForm(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.templates.DashboardStructureGeneratorComponent(ctx, props).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render structure generator")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimersGenerate(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	props, ok := s.structureFromForm(w, r)
+	if !ok {
+		return
+	}
+
+	renderErrors := func(errors []string) {
+		props.Errors = errors
+		err := s.templates.DashboardStructureGeneratorComponent(ctx, props).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render structure generator")
+		}
+	}
+
+	if len(props.Errors) > 0 {
+		renderErrors(props.Errors)
+		return
+	}
+
+	var timer *poker.Timer
+	if props.TimerID == "" {
+		timer = &poker.Timer{
+			ID:     uuid.New().String(),
+			UserID: user.ID,
+			Name:   props.TimerName,
+		}
+
+		err := timer.Validate()
+		if err != nil {
+			entry.WithError(err).Error("failed to validate timer")
+			renderErrors([]string{err.Error()})
+			return
+		}
+	} else {
+		var err error
+		timer, err = s.timerRepo.Timer(ctx, props.TimerID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch timer")
+			_ = s.templates.ResourceUnavailable(ctx).Render(w)
+			return
+		}
+
+		// The timer is picked in the form rather than the path, so it isn't covered by timerAccess
+		if timer == nil || !timer.RoleOf(user.ID).Includes(poker.TimerRoleEditor) {
+			entry.WithField("timerID", props.TimerID).Error("timer not found or not editable by authenticated user")
+			renderErrors([]string{"the selected timer could not be found"})
+			return
+		}
+
+		// The old levels are gone, so so is any progress through them
+		timer.CurrentLevel = 0
+		timer.IsComplete = false
+		timer.StopClock()
+	}
+
+	for _, level := range props.Levels {
+		level.ID = uuid.New().String()
+		level.TimerID = timer.ID
+	}
+	timer.Levels = props.Levels
+
+	err := timer.ValidateChips()
+	if err != nil {
+		entry.WithError(err).Info("generated structure can't be made with the timer's chip set")
+		renderErrors([]string{err.Error()})
+		return
+	}
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		entry.WithError(err).Warn("timer was changed since it was loaded")
+		renderErrors([]string{conflict.Public().Error()})
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		renderErrors([]string{poker.ErrInternalServerErrorContactDeveloper.Error()})
+		return
+	}
+
+	if props.TimerID != "" {
+		s.publishTimerEvent(r, timer.ID, timerEventReset)
+	}
+
+	uri, _ := s.router.Get("dashboard-timer").URL("timerID", timer.ID)
+	w.Header().Set("HX-Push", uri.String())
+	err = s.templates.DashboardTimerFragment(ctx, timer).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+// structureFromForm decodes the generator form and generates the structure it describes. Problems with the
+// parameters are returned in Errors, false is only returned once a response has been written
+func (s *server) structureFromForm(w http.ResponseWriter, r *http.Request) (*templates.DashboardStructureGeneratorProps, bool) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+
+	var form = new(structureForm)
+	err = s.decoder.Decode(form, r.PostForm)
+	if err != nil {
+		entry.WithError(err).Error("failed to decode request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, false
+	}
+
+	timers, err := s.editableTimers(ctx, user)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch editable timers")
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, false
+	}
+
+	props := &templates.DashboardStructureGeneratorProps{
+		Timers:    timers,
+		Params:    form.StructureParams,
+		TimerID:   form.TimerID,
+		TimerName: form.TimerName,
+	}
+
+	props.Levels, err = poker.GenerateStructure(form.StructureParams)
+	if err != nil {
+		props.Errors = []string{err.Error()}
+	}
+
+	return props, true
+
+}
+
+// editableTimers are the timers the user may replace the levels of, their own followed by those shared with them
+// as an editor
+func (s *server) editableTimers(ctx context.Context, user *poker.User) ([]*poker.Timer, error) {
+
+	timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := s.timerRepo.TimersSharedWithUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, timer := range shared {
+		if timer.RoleOf(user.ID).Includes(poker.TimerRoleEditor) {
+			timers = append(timers, timer)
+		}
+	}
+
+	return timers, nil
+
+}
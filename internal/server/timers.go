@@ -2,33 +2,70 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"poker"
 	"poker/internal"
 	"poker/internal/templates"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// writeTimerConflict renders the reload prompt when err is a ConflictError and reports whether it did
+func (s *server) writeTimerConflict(ctx context.Context, w io.Writer, err error) bool {
+
+	var conflict *poker.ConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+
+	s.logger.WithContext(ctx).WithError(err).Warn("timer was changed since it was loaded")
+
+	rerr := s.templates.TimerConflict(ctx, conflict.Public()).Render(w)
+	if rerr != nil {
+		s.logger.WithContext(ctx).WithError(rerr).Error("failed to render timer conflict")
+	}
+
+	return true
+
+}
+
+// formTimerVersion checks the Version the form was rendered with against the timer as it was loaded, removing it from
+// the form so it isn't decoded into anything else. Forms sent without a Version aren't checked
+func formTimerVersion(r *http.Request, timer *poker.Timer) error {
+
+	value := r.Form.Get("Version")
+	r.Form.Del("Version")
+	r.PostForm.Del("Version")
+	if value == "" {
+		return nil
+	}
+
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse version: %w", err)
+	}
+
+	return timer.CheckVersion(uint(version))
+
+}
+
 func (s *server) handleDashboardTimers(w http.ResponseWriter, r *http.Request) {
 
 	var ctx = r.Context()
 
-	user := internal.UserFromContext(ctx)
-
-	timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	props, err := s.dashboardTimersProps(ctx)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to timers by user id")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	err = s.templates.DashboardTimers(ctx, &templates.DashboardTimersProps{
-		User:   internal.UserFromContext(ctx),
-		Timers: timers,
-	}).Render(w)
+	err = s.templates.DashboardTimers(ctx, props).Render(w)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to timers by user id")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -37,6 +74,29 @@ func (s *server) handleDashboardTimers(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// dashboardTimersProps lists the signed in user's own timers alongside those shared with them
+func (s *server) dashboardTimersProps(ctx context.Context) (*templates.DashboardTimersProps, error) {
+
+	user := internal.UserFromContext(ctx)
+
+	timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := s.timerRepo.TimersSharedWithUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &templates.DashboardTimersProps{
+		User:   user,
+		Timers: timers,
+		Shared: shared,
+	}, nil
+
+}
+
 func (s *server) handleGetDashboardTimerNew(w http.ResponseWriter, r *http.Request) {
 
 	var ctx = r.Context()
@@ -218,16 +278,16 @@ func (s *server) handleDeleteDashboardTimer(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	user := internal.UserFromContext(ctx)
+	s.deleteTimerWebhooks(ctx, timerID)
 
-	timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	props, err := s.dashboardTimersProps(ctx)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to fetch timers")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	err = s.templates.DashboardTimersFragment(ctx, timers).Render(w)
+	err = s.templates.DashboardTimersFragment(ctx, props).Render(w)
 	if err != nil {
 		s.logger.WithError(err).Error("failed to render dashboard timer")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -327,6 +387,19 @@ func (s *server) handlePostDashboardTimerLevelNew(w http.ResponseWriter, r *http
 		return
 	}
 
+	// Position isn't part of the level, it is where the level is inserted
+	var position uint
+	if value := r.PostForm.Get("Position"); value != "" {
+		p, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			entry.WithError(err).Error("failed to parse position")
+			_ = s.templates.ResourceUnavailable(ctx).Render(w)
+			return
+		}
+		position = uint(p)
+	}
+	r.PostForm.Del("Position")
+
 	level := new(poker.TimerLevel)
 
 	err = s.decoder.Decode(level, r.PostForm)
@@ -339,19 +412,34 @@ func (s *server) handlePostDashboardTimerLevelNew(w http.ResponseWriter, r *http
 	level.ID = uuid.New().String()
 	level.DurationSec = level.DurationMin * 60
 	level.TimerID = timerID
+	applyLevelFormAnte(level)
 
 	renderFunc := s.returnDashboardNewTimerLevelComponentErrorFunc(ctx, timerID, level.Type)
 
 	err = level.Validate()
+	if err == nil && timer.ChipSet != nil {
+		err = timer.ChipSet.ValidateLevel(level)
+	}
 	if err != nil {
 		entry.WithError(err).Error("failed to validate form")
 		renderFunc([]string{err.Error()}, w)
 		return
 	}
 
-	timer.Levels = append(timer.Levels, level)
+	err = timer.InsertLevel(level, position)
+	if err != nil {
+		entry.WithError(err).Error("failed to insert level")
+		renderFunc([]string{err.Error()}, w)
+		return
+	}
 
 	err = s.timerRepo.SaveTimer(ctx, timer)
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		entry.WithError(err).Warn("timer was changed since it was loaded")
+		renderFunc([]string{conflict.Public().Error()}, w)
+		return
+	}
 	if err != nil {
 		entry.WithError(err).Error("failed to save timer")
 		renderFunc([]string{
@@ -377,8 +465,6 @@ func (s *server) handleGetDashboardTimerLevelEdit(w http.ResponseWriter, r *http
 
 	entry := s.logger.WithContext(ctx)
 
-	user := internal.UserFromContext(ctx)
-
 	vars := mux.Vars(r)
 
 	timerID, ok := vars["timerID"]
@@ -415,15 +501,6 @@ func (s *server) handleGetDashboardTimerLevelEdit(w http.ResponseWriter, r *http
 		return
 	}
 
-	if timer.UserID != user.ID {
-		err = s.templates.DashboardTimerFragment(ctx, timer).Render(w)
-		if err != nil {
-			entry.WithError(err).Error("failed to render DashboardTimerFragment")
-			_ = s.templates.ResourceUnavailable(ctx).Render(w)
-		}
-		return
-	}
-
 	var level *poker.TimerLevel
 	for _, lvl := range timer.Levels {
 		if lvl.ID != levelID {
@@ -442,7 +519,7 @@ func (s *server) handleGetDashboardTimerLevelEdit(w http.ResponseWriter, r *http
 		return
 	}
 
-	err = s.templates.DashboardEditTimerLevelComponent(ctx, templates.NewDashboardEditTimerLevelProps(level, nil)).Render(w)
+	err = s.templates.DashboardEditTimerLevelComponent(ctx, templates.NewDashboardEditTimerLevelProps(level, timer.Version, nil)).Render(w)
 	if err != nil {
 		entry.WithError(err).Error("failed to render DashboardEditTimerLevelComponent")
 		_ = s.templates.ResourceUnavailable(ctx).Render(w)
@@ -497,16 +574,6 @@ func (s *server) handlePostDashboardTimerLevelEdit(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if timer.UserID != user.ID {
-		entry.Error("timer is not owned by authenticated user")
-		err = s.templates.DashboardTimerFragment(ctx, timer).Render(w)
-		if err != nil {
-			s.logger.WithError(err).Error("failed to render DashboardTimerFragment")
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-		return
-	}
-
 	var level *poker.TimerLevel
 	for _, lvl := range timer.Levels {
 		if lvl.ID != levelID {
@@ -533,6 +600,19 @@ func (s *server) handlePostDashboardTimerLevelEdit(w http.ResponseWriter, r *htt
 		return
 	}
 
+	err = formTimerVersion(r, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to check timer version")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	// Unticked event boxes aren't sent at all, so the events are always replaced by those in the form
+	level.Events = nil
+
 	err = s.decoder.Decode(level, r.PostForm)
 	if err != nil {
 		entry.WithError(err).Error("failed to decode form")
@@ -540,9 +620,14 @@ func (s *server) handlePostDashboardTimerLevelEdit(w http.ResponseWriter, r *htt
 		return
 	}
 
-	renderFunc := s.returnDashboardEditTimerLevelComponentErrorFunc(ctx, level)
+	applyLevelFormAnte(level)
+
+	renderFunc := s.returnDashboardEditTimerLevelComponentErrorFunc(ctx, level, timer.Version)
 
 	err = level.Validate()
+	if err == nil && timer.ChipSet != nil {
+		err = timer.ChipSet.ValidateLevel(level)
+	}
 	if err != nil {
 		entry.WithError(err).Error("failed to validate level")
 		renderFunc([]string{err.Error()}, w)
@@ -553,6 +638,12 @@ func (s *server) handlePostDashboardTimerLevelEdit(w http.ResponseWriter, r *htt
 	level.DurationStr = ""
 
 	err = s.timerRepo.SaveTimer(ctx, timer)
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		entry.WithError(err).Warn("timer was changed since it was loaded")
+		renderFunc([]string{conflict.Public().Error()}, w)
+		return
+	}
 	if err != nil {
 		entry.WithError(err).Error("failed to save timer")
 		_ = s.templates.ResourceUnavailable(ctx).Render(w)
@@ -568,13 +659,13 @@ func (s *server) handlePostDashboardTimerLevelEdit(w http.ResponseWriter, r *htt
 
 }
 
-func (s *server) returnDashboardEditTimerLevelComponentErrorFunc(ctx context.Context, level *poker.TimerLevel) func(errors []string, w io.Writer) {
+func (s *server) returnDashboardEditTimerLevelComponentErrorFunc(ctx context.Context, level *poker.TimerLevel, version uint) func(errors []string, w io.Writer) {
 
 	return func(errors []string, w io.Writer) {
 
 		err := s.templates.DashboardEditTimerLevelComponent(
 			ctx,
-			templates.NewDashboardEditTimerLevelProps(level, errors),
+			templates.NewDashboardEditTimerLevelProps(level, version, errors),
 		).Render(w)
 		if err != nil {
 			s.logger.WithError(err).Error("failed to render dashboard timer")
@@ -584,12 +675,29 @@ func (s *server) returnDashboardEditTimerLevelComponentErrorFunc(ctx context.Con
 	}
 }
 
+// applyLevelFormAnte makes the ante amount from a level form agree with the ante type chosen alongside it. The
+// amount is cleared when there is no ante and a big blind ante defaults to the big blind
+func applyLevelFormAnte(level *poker.TimerLevel) {
+
+	if level.Type != poker.LevelTypeBlind {
+		return
+	}
+
+	switch level.AnteType {
+	case poker.AnteTypeNone:
+		level.Ante = 0
+	case poker.AnteTypeBigBlind:
+		if level.Ante == 0 {
+			level.Ante = level.BigBlind
+		}
+	}
+
+}
+
 func (s *server) handleDeleteDashboardTimerLevel(w http.ResponseWriter, r *http.Request) {
 
 	var ctx = r.Context()
 
-	user := internal.UserFromContext(ctx)
-
 	vars := mux.Vars(r)
 
 	timerID, ok := vars["timerID"]
@@ -613,29 +721,41 @@ func (s *server) handleDeleteDashboardTimerLevel(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if timer.UserID != user.ID {
-		err = s.templates.DashboardTimer(ctx, &templates.DashboardTimerProps{
-			User:  internal.UserFromContext(ctx),
-			Timer: timer,
-		}).Render(w)
-		if err != nil {
-			s.logger.WithError(err).Error("failed to render dashboard timer")
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+	if timer == nil {
+		s.logger.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
 	}
 
-	var i int
-	for idx, level := range timer.Levels {
-		if level.ID != levelID {
-			continue
-		}
-		i = idx
+	// htmx sends the values of a DELETE in the query string
+	err = r.ParseForm()
+	if err != nil {
+		s.logger.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
 	}
 
-	timer.Levels = trimLevel(timer.Levels, i)
+	err = formTimerVersion(r, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("failed to check timer version")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = timer.RemoveLevel(levelID)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to remove level")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 
 	err = s.timerRepo.SaveTimer(ctx, timer)
+	if s.writeTimerConflict(ctx, w, err) {
+		return
+	}
 	if err != nil {
 		s.logger.WithError(err).Error("failed to save timer")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -654,22 +774,6 @@ func (s *server) handleDeleteDashboardTimerLevel(w http.ResponseWriter, r *http.
 
 }
 
-func trimLevel(levels []*poker.TimerLevel, i int) []*poker.TimerLevel {
-
-	// if i greater than the total number of levels, just bail and return the original
-	if i > len(levels) {
-		return levels
-	}
-
-	// if i equal the last index, return all but the last index
-	if i == len(levels)-1 {
-		return levels[:i]
-	}
-
-	return append(levels[:i], levels[i+1:]...)
-
-}
-
 // func (s *server) handlePartialDashboardTimers(w http.ResponseWriter, r *http.Request) {
 
 // var ctx = r.Context()
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (s *server) handleGetDashboardTokens(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	tokens, err := s.apiTokenRepo.APITokensByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to fetch api tokens by user id")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.templates.DashboardTokens(ctx, &templates.DashboardTokensProps{
+		User:   user,
+		Tokens: tokens,
+		// Most tokens are for reading and running a timer, so that is what is offered
+		Scopes: []poker.TokenScope{poker.TokenScopeRead, poker.TokenScopeControl},
+	}).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render dashboard tokens")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTokens(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	props := &templates.DashboardTokensProps{
+		Name: r.PostFormValue("Name"),
+	}
+	for _, scope := range r.PostForm["Scopes"] {
+		props.Scopes = append(props.Scopes, poker.TokenScope(scope))
+	}
+
+	token, secret, err := poker.NewAPIToken(user.ID, props.Name, props.Scopes, time.Now())
+	if err != nil {
+		props.Errors = []string{err.Error()}
+		s.renderDashboardTokens(ctx, w, props)
+		return
+	}
+
+	err = s.apiTokenRepo.SaveAPIToken(ctx, token)
+	if err != nil {
+		entry.WithError(err).Error("failed to save api token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	entry.WithField("tokenID", token.ID).Info("api token created")
+
+	// The form is cleared for the next token
+	s.renderDashboardTokens(ctx, w, &templates.DashboardTokensProps{Secret: secret})
+
+}
+
+func (s *server) handleDeleteDashboardToken(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	tokenID := mux.Vars(r)["tokenID"]
+
+	entry = entry.WithField("tokenID", tokenID)
+
+	token, err := s.apiTokenRepo.APIToken(ctx, tokenID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch api token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if token == nil || token.UserID != user.ID {
+		entry.Error("api token not found or does not belong to authenticated user")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = s.apiTokenRepo.DeleteAPIToken(ctx, token.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to delete api token")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.renderDashboardTokens(ctx, w, &templates.DashboardTokensProps{})
+
+}
+
+// renderDashboardTokens renders the user's tokens alongside the form in props
+func (s *server) renderDashboardTokens(ctx context.Context, w http.ResponseWriter, props *templates.DashboardTokensProps) {
+
+	user := internal.UserFromContext(ctx)
+
+	tokens, err := s.apiTokenRepo.APITokensByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to fetch api tokens by user id")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	props.User = user
+	props.Tokens = tokens
+
+	err = s.templates.DashboardTokensFragment(ctx, props).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render dashboard tokens")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
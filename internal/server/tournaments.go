@@ -0,0 +1,445 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func (s *server) handleDashboardTournaments(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	tournaments, err := s.tournamentRepo.TournamentsByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to fetch tournaments by user id")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.templates.DashboardTournaments(ctx, &templates.DashboardTournamentsProps{
+		User:        user,
+		Tournaments: tournaments,
+	}).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render dashboard tournaments")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
+
+func (s *server) handleGetDashboardTournamentNew(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	user := internal.UserFromContext(ctx)
+
+	timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to fetch timers by user id")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	err = s.templates.DashboardNewTournamentComponent(ctx, &templates.DashboardTournamentNewProps{
+		Timers: timers,
+	}).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render new tournament component")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTournamentNew(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	var tournament = new(poker.Tournament)
+	err = s.decoder.Decode(tournament, r.PostForm)
+	if err != nil {
+		entry.WithError(err).Error("failed to decode request form")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	tournament.ID = uuid.New().String()
+	tournament.UserID = user.ID
+
+	renderErrors := func(errors []string) {
+		timers, err := s.timerRepo.TimersByUserID(ctx, user.ID)
+		if err != nil {
+			entry.WithError(err).Error("failed to fetch timers by user id")
+			_ = s.templates.ResourceUnavailable(ctx).Render(w)
+			return
+		}
+
+		err = s.templates.DashboardNewTournamentComponent(ctx, &templates.DashboardTournamentNewProps{
+			Timers: timers,
+			Errors: errors,
+		}).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render new tournament component")
+		}
+	}
+
+	err = tournament.Validate()
+	if err != nil {
+		entry.WithError(err).Error("failed to validate tournament")
+		renderErrors([]string{err.Error()})
+		return
+	}
+
+	timer, err := s.timerRepo.Timer(ctx, tournament.TimerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	if timer == nil || timer.UserID != user.ID {
+		entry.Error("timer not found or not owned by authenticated user")
+		renderErrors([]string{"the selected timer could not be found"})
+		return
+	}
+
+	err = s.tournamentRepo.SaveTournament(ctx, tournament)
+	if err != nil {
+		entry.WithError(err).Error("failed to save tournament")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	s.publishTimerEvent(r, tournament.TimerID, timerEventTournament)
+
+	uri, _ := s.router.Get("dashboard-tournament").URL("tournamentID", tournament.ID)
+	w.Header().Set("HX-Push", uri.String())
+	err = s.templates.DashboardTournamentFragment(ctx, &templates.DashboardTournamentProps{
+		Tournament: tournament,
+		Timer:      timer,
+	}).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard tournament")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handleGetDashboardTournament(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	tournamentID := mux.Vars(r)["tournamentID"]
+
+	entry = entry.WithField("tournamentID", tournamentID)
+
+	tournament, err := s.tournamentRepo.Tournament(ctx, tournamentID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch tournament")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	if tournament == nil || tournament.UserID != user.ID {
+		entry.Error("tournament not found or not owned by authenticated user")
+		w.WriteHeader(http.StatusNotFound)
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
+		return
+	}
+
+	timer, err := s.timerRepo.Timer(ctx, tournament.TimerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	err = s.templates.DashboardTournament(ctx, &templates.DashboardTournamentProps{
+		User:       user,
+		Tournament: tournament,
+		Timer:      timer,
+	}).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard tournament")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handleDeleteDashboardTournament(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	tournamentID := mux.Vars(r)["tournamentID"]
+
+	entry = entry.WithField("tournamentID", tournamentID)
+
+	tournament, err := s.tournamentRepo.Tournament(ctx, tournamentID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch tournament")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if tournament == nil || tournament.UserID != user.ID {
+		entry.Error("tournament not found or not owned by authenticated user")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err = s.tournamentRepo.DeleteTournament(ctx, tournament.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to delete tournament")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.publishTimerEvent(r, tournament.TimerID, timerEventTournament)
+
+	tournaments, err := s.tournamentRepo.TournamentsByUserID(ctx, user.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch tournaments")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = s.templates.DashboardTournamentsFragment(ctx, tournaments).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard tournaments")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTournamentPlayers(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTournament(w, r, func(tournament *poker.Tournament, _ uint) error {
+		return tournament.Register(&poker.TournamentPlayer{
+			ID:           uuid.New().String(),
+			Name:         r.PostFormValue("Name"),
+			RegisteredAt: time.Now(),
+		})
+	})
+}
+
+func (s *server) handleDeleteDashboardTournamentPlayer(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTournament(w, r, func(tournament *poker.Tournament, _ uint) error {
+		return tournament.Unregister(mux.Vars(r)["playerID"])
+	})
+}
+
+func (s *server) handlePostDashboardTournamentPlayerAction(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTournament(w, r, func(tournament *poker.Tournament, level uint) error {
+
+		vars := mux.Vars(r)
+
+		switch vars["action"] {
+		case "rebuy":
+			return tournament.Rebuy(vars["playerID"], level)
+		case "addon":
+			return tournament.AddOn(vars["playerID"], level)
+		case "eliminate":
+			return tournament.Eliminate(vars["playerID"], time.Now())
+		}
+
+		return errors.New("unrecognized player action")
+
+	})
+}
+
+func (s *server) handlePostDashboardTournamentPayouts(w http.ResponseWriter, r *http.Request) {
+	s.updateDashboardTournament(w, r, func(tournament *poker.Tournament, _ uint) error {
+
+		var rules = poker.PayoutRules{
+			Scheme: poker.PayoutScheme(r.PostFormValue("Scheme")),
+		}
+
+		var err error
+		rules.TopPercent, err = parseOptionalFloat(r.PostFormValue("TopPercent"))
+		if err != nil {
+			return fmt.Errorf("field paid must be a number")
+		}
+
+		rules.RoundTo, err = parseOptionalFloat(r.PostFormValue("RoundTo"))
+		if err != nil {
+			return fmt.Errorf("round to must be a number")
+		}
+
+		// Custom percentages are only read when they're in use so a half typed table doesn't
+		// stop the director switching to another scheme
+		if rules.Scheme == poker.PayoutSchemeManual {
+			for i, field := range strings.Split(r.PostFormValue("Manual"), ",") {
+				percent, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+				if err != nil {
+					return fmt.Errorf("the payout for place %d must be a number", i+1)
+				}
+				rules.Manual = append(rules.Manual, percent)
+			}
+		}
+
+		err = rules.Validate()
+		if err != nil {
+			return err
+		}
+
+		tournament.Payout = rules
+
+		return nil
+
+	})
+}
+
+func parseOptionalFloat(value string) (float64, error) {
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+
+}
+
+// updateDashboardTournament applies fn to the requested tournament and saves it. fn is given the 1 based
+// number of the level the tournament's timer is on. Errors from fn are shown to the user alongside the
+// unchanged tournament
+func (s *server) updateDashboardTournament(w http.ResponseWriter, r *http.Request, fn func(tournament *poker.Tournament, level uint) error) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	tournamentID := mux.Vars(r)["tournamentID"]
+
+	entry = entry.WithField("tournamentID", tournamentID)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	tournament, err := s.tournamentRepo.Tournament(ctx, tournamentID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch tournament")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if tournament == nil {
+		entry.Error("tournament not found")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if tournament.UserID != user.ID {
+		entry.Error("tournament is not owned by authenticated user")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	timer, err := s.timerRepo.Timer(ctx, tournament.TimerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The level only matters for rebuy and add-on cut offs, so a timer that has since been
+	// deleted is treated as still being on its first level
+	var level uint = 1
+	if timer != nil {
+		timer.RollForward(time.Now())
+		level = timer.CurrentLevel + 1
+	}
+
+	props := &templates.DashboardTournamentProps{
+		Tournament: tournament,
+		Timer:      timer,
+	}
+
+	err = fn(tournament, level)
+	if err != nil {
+		entry.WithError(err).Info("tournament update rejected")
+		props.Errors = []string{err.Error()}
+
+		// fn may have partially applied the change, so show what is actually stored
+		props.Tournament, err = s.tournamentRepo.Tournament(ctx, tournamentID)
+		if err != nil || props.Tournament == nil {
+			entry.WithError(err).Error("failed to refetch tournament")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		s.renderDashboardTournamentFragment(w, r, props)
+		return
+	}
+
+	err = s.tournamentRepo.SaveTournament(ctx, tournament)
+	var conflict *poker.ConflictError
+	if errors.As(err, &conflict) {
+		entry.WithError(err).Warn("tournament was changed since it was loaded")
+		props.Errors = []string{conflict.Public().Error()}
+		s.renderDashboardTournamentFragment(w, r, props)
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Error("failed to save tournament")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.publishTimerEvent(r, tournament.TimerID, timerEventTournament)
+
+	s.renderDashboardTournamentFragment(w, r, props)
+
+}
+
+func (s *server) renderDashboardTournamentFragment(w http.ResponseWriter, r *http.Request, props *templates.DashboardTournamentProps) {
+
+	var ctx = r.Context()
+
+	err := s.templates.DashboardTournamentFragment(ctx, props).Render(w)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to render dashboard tournament")
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxImportSize is the largest timer file that can be uploaded, far more than any real structure needs
+const maxImportSize = 1 << 20
+
+var filenameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+func (s *server) handleGetDashboardTimerExport(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	vars := mux.Vars(r)
+
+	timerID, format := vars["timerID"], poker.TimerFormat(vars["format"])
+
+	entry = entry.WithField("timerID", timerID).WithField("format", format)
+
+	if !format.Valid() {
+		entry.Error("invalid export format")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timer, err := s.timerRepo.Timer(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch timer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if timer == nil {
+		entry.Error("timer not found")
+		w.WriteHeader(http.StatusNotFound)
+		_ = s.templates.ErrorNotFound(ctx).Render(w)
+		return
+	}
+
+	filename := strings.Trim(strings.ToLower(filenameUnsafe.ReplaceAllString(timer.Name, "-")), "-")
+	if filename == "" {
+		filename = "timer"
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	err = poker.EncodeTimer(w, timer, format)
+	if err != nil {
+		entry.WithError(err).Error("failed to export timer")
+		return
+	}
+
+}
+
+func (s *server) handleGetDashboardTimersImport(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	err := s.templates.DashboardImportTimerComponent(ctx, &templates.DashboardImportTimerProps{}).Render(w)
+	if err != nil {
+		s.logger.WithError(err).Error("failed to render import timer component")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
+
+func (s *server) handlePostDashboardTimersImport(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	renderErrors := func(errors []string) {
+		err := s.templates.DashboardImportTimerComponent(ctx, &templates.DashboardImportTimerProps{
+			Errors: errors,
+		}).Render(w)
+		if err != nil {
+			entry.WithError(err).Error("failed to render import timer component")
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	err := r.ParseMultipartForm(maxImportSize)
+	if err != nil {
+		entry.WithError(err).Error("failed to parse import form")
+		renderErrors([]string{"the file could not be uploaded, it must be smaller than 1MB"})
+		return
+	}
+
+	file, header, err := r.FormFile("File")
+	if err != nil {
+		entry.WithError(err).Error("failed to read uploaded file")
+		renderErrors([]string{"choose a file to import"})
+		return
+	}
+	defer file.Close()
+
+	format := poker.TimerFormat(r.PostFormValue("Format"))
+	if format == "" {
+		var ok bool
+		format, ok = poker.TimerFormatFromFilename(header.Filename)
+		if !ok {
+			renderErrors([]string{"the format could not be worked out from the file name, choose one"})
+			return
+		}
+	}
+
+	if !format.Valid() {
+		entry.WithField("format", format).Error("invalid import format")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	timer, err := poker.DecodeTimer(file, format)
+	var importErr *poker.ImportError
+	if errors.As(err, &importErr) {
+		entry.WithError(err).Info("imported timer has invalid levels")
+		rows := make([]string, 0, len(importErr.Rows))
+		for _, row := range importErr.Rows {
+			rows = append(rows, row.Error())
+		}
+		renderErrors(rows)
+		return
+	}
+	if err != nil {
+		entry.WithError(err).Info("failed to decode imported timer")
+		renderErrors([]string{err.Error()})
+		return
+	}
+
+	// A name given in the form wins over the one in the file, and CSV files don't carry one at all
+	if name := strings.TrimSpace(r.PostFormValue("Name")); name != "" {
+		timer.Name = name
+	}
+	if timer.Name == "" {
+		timer.Name = strings.TrimSuffix(header.Filename, filepath.Ext(header.Filename))
+	}
+	timer.UserID = user.ID
+
+	err = timer.Validate()
+	if err != nil {
+		entry.WithError(err).Error("failed to validate timer")
+		renderErrors([]string{err.Error()})
+		return
+	}
+
+	err = s.timerRepo.SaveTimer(ctx, timer)
+	if err != nil {
+		entry.WithError(err).Error("failed to save timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+	uri, _ := s.router.Get("dashboard-timer").URL("timerID", timer.ID)
+	w.Header().Set("HX-Push", uri.String())
+	err = s.templates.DashboardTimerFragment(ctx, timer).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render dashboard timer")
+		_ = s.templates.ResourceUnavailable(ctx).Render(w)
+		return
+	}
+
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"poker"
+	"poker/internal"
+	"poker/internal/templates"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// webhookLogLimit is how many of each webhook's deliveries are shown on the dashboard
+	webhookLogLimit = 10
+	// webhookTestTimeout keeps a test event inside the server's write timeout, a slow receiver is shown as failed
+	webhookTestTimeout = 2 * time.Second
+)
+
+// sendTimerWebhooks sends each event to every webhook of the timer subscribed to it. The webhooks are sent to at
+// the same time, and each is given its events in order. previous is the index of the level the timer was on before
+// the events, or -1
+func (s *server) sendTimerWebhooks(ctx context.Context, timer *poker.Timer, events []poker.WebhookEvent, previous int) {
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", timer.ID)
+
+	webhooks, err := s.webhookRepo.WebhooksByTimerID(ctx, timer.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhooks by timer id")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, webhook := range webhooks {
+		var subscribed []poker.WebhookEvent
+		for _, event := range events {
+			if webhook.Subscribes(event) {
+				subscribed = append(subscribed, event)
+			}
+		}
+
+		if len(subscribed) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(webhook *poker.Webhook, events []poker.WebhookEvent) {
+			defer wg.Done()
+			s.webhooks.Send(ctx, webhook, events, timer, previous)
+		}(webhook, subscribed)
+	}
+
+	wg.Wait()
+
+}
+
+// sendTimerTransitionWebhooks sends the events of the timer having moved on from where it was
+func (s *server) sendTimerTransitionWebhooks(ctx context.Context, timer *poker.Timer, from poker.PlayPosition) {
+
+	events := timer.WebhookEventsSince(from)
+	if len(events) == 0 {
+		return
+	}
+
+	previous := -1
+	if timer.CurrentLevel != from.Level {
+		previous = int(from.Level)
+	}
+
+	s.sendTimerWebhooks(ctx, timer, events, previous)
+
+}
+
+// deleteTimerWebhooks removes the webhooks of a deleted timer, which is already gone so a failure is only logged
+func (s *server) deleteTimerWebhooks(ctx context.Context, timerID string) {
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", timerID)
+
+	webhooks, err := s.webhookRepo.WebhooksByTimerID(ctx, timerID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhooks of deleted timer")
+		return
+	}
+
+	for _, webhook := range webhooks {
+		err = s.webhookRepo.DeleteWebhook(ctx, webhook.ID)
+		if err != nil {
+			entry.WithError(err).WithField("webhookID", webhook.ID).Error("failed to delete webhook of deleted timer")
+		}
+	}
+
+}
+
+func (s *server) handleGetDashboardTimerWebhooks(w http.ResponseWriter, r *http.Request) {
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	s.renderDashboardTimerWebhooks(w, r, &templates.DashboardTimerWebhooksProps{
+		Timer:  timer,
+		Events: poker.AllWebhookEvents,
+	})
+
+}
+
+func (s *server) handlePostDashboardTimerWebhooks(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	user := internal.UserFromContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return
+	}
+
+	entry = entry.WithField("timerID", timer.ID)
+
+	err := r.ParseForm()
+	if err != nil {
+		entry.WithError(err).Error("failed to parse request form")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	props := &templates.DashboardTimerWebhooksProps{
+		Timer: timer,
+		URL:   r.PostFormValue("URL"),
+	}
+
+	for _, event := range r.PostForm["Events"] {
+		props.Events = append(props.Events, poker.WebhookEvent(event))
+	}
+
+	existing, err := s.webhookRepo.WebhooksByTimerID(ctx, timer.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhooks by timer id")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if len(existing) >= poker.MaxTimerWebhooks {
+		props.Errors = []string{fmt.Sprintf("a timer can only have %d webhooks, delete one to add another", poker.MaxTimerWebhooks)}
+		s.renderDashboardTimerWebhooks(w, r, props)
+		return
+	}
+
+	webhook, err := poker.NewWebhook(timer.ID, user.ID, props.URL, props.Events, time.Now())
+	if err != nil {
+		props.Errors = []string{err.Error()}
+		s.renderDashboardTimerWebhooks(w, r, props)
+		return
+	}
+
+	err = s.webhookRepo.SaveWebhook(ctx, webhook)
+	if err != nil {
+		entry.WithError(err).Error("failed to save webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// The form is reset for the next webhook
+	s.renderDashboardTimerWebhooks(w, r, &templates.DashboardTimerWebhooksProps{
+		Timer:  timer,
+		Events: poker.AllWebhookEvents,
+	})
+
+}
+
+func (s *server) handleDeleteDashboardTimerWebhook(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, webhook, ok := s.dashboardRouteWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.webhookRepo.DeleteWebhook(ctx, webhook.ID)
+	if err != nil {
+		entry.WithError(err).WithField("webhookID", webhook.ID).Error("failed to delete webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.renderDashboardTimerWebhooks(w, r, &templates.DashboardTimerWebhooksProps{
+		Timer:  timer,
+		Events: poker.AllWebhookEvents,
+	})
+
+}
+
+// handlePostDashboardTimerWebhookTest sends the webhook a ping while the owner waits, so its outcome is in the log
+// that is rendered back
+func (s *server) handlePostDashboardTimerWebhookTest(w http.ResponseWriter, r *http.Request) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, webhook, ok := s.dashboardRouteWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	entry = entry.WithField("timerID", timer.ID).WithField("webhookID", webhook.ID)
+
+	props := &templates.DashboardTimerWebhooksProps{
+		Timer:  timer,
+		Events: poker.AllWebhookEvents,
+	}
+
+	_, err := s.webhooks.SendNow(ctx, webhookTestTimeout, webhook, poker.WebhookEventPing, timer, -1)
+	if err != nil {
+		entry.WithError(err).Error("failed to send test webhook")
+		props.Errors = []string{"failed to send the test event, please try again"}
+	}
+
+	s.renderDashboardTimerWebhooks(w, r, props)
+
+}
+
+// dashboardRouteWebhook loads the webhook named by the route, which must belong to the timer named by the route
+func (s *server) dashboardRouteWebhook(w http.ResponseWriter, r *http.Request) (*poker.Timer, *poker.Webhook, bool) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx)
+
+	timer, ok := s.dashboardRouteTimer(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	webhookID := mux.Vars(r)["webhookID"]
+
+	entry = entry.WithField("timerID", timer.ID).WithField("webhookID", webhookID)
+
+	webhook, err := s.webhookRepo.Webhook(ctx, webhookID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhook")
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	if webhook == nil || webhook.TimerID != timer.ID {
+		entry.Error("webhook not found")
+		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	return timer, webhook, true
+
+}
+
+// renderDashboardTimerWebhooks renders the timer's webhooks with their most recent deliveries
+func (s *server) renderDashboardTimerWebhooks(w http.ResponseWriter, r *http.Request, props *templates.DashboardTimerWebhooksProps) {
+
+	var ctx = r.Context()
+
+	entry := s.logger.WithContext(ctx).WithField("timerID", props.Timer.ID)
+
+	webhooks, err := s.webhookRepo.WebhooksByTimerID(ctx, props.Timer.ID)
+	if err != nil {
+		entry.WithError(err).Error("failed to fetch webhooks by timer id")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	props.Webhooks = webhooks
+	props.Deliveries = make(map[string][]*poker.WebhookDelivery, len(webhooks))
+
+	for _, webhook := range webhooks {
+		deliveries, err := s.webhookRepo.WebhookDeliveries(ctx, webhook.ID, webhookLogLimit)
+		if err != nil {
+			entry.WithError(err).WithField("webhookID", webhook.ID).Error("failed to fetch webhook deliveries")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		props.Deliveries[webhook.ID] = deliveries
+	}
+
+	err = s.templates.DashboardTimerWebhooksComponent(ctx, props).Render(w)
+	if err != nil {
+		entry.WithError(err).Error("failed to render timer webhooks component")
+	}
+
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"poker"
+	"poker/client"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWebhookSentWithinRequest moves a timer on and expects its webhook to have been delivered by the time the
+// response comes back, as nothing may run afterwards behind lambda
+func TestWebhookSentWithinRequest(t *testing.T) {
+
+	s, ts, tokens := newContractServer(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var received []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Header.Get(poker.WebhookEventHeader))
+		mu.Unlock()
+	}))
+	t.Cleanup(receiver.Close)
+
+	c, err := client.New(&client.Config{BaseURL: ts.URL, Token: tokens[""]})
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+
+	timer, err := c.CreateTimer(ctx, &poker.APITimerInput{
+		Name: "Webhooks",
+		Levels: []*poker.APILevel{
+			{Type: poker.LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 20},
+			{Type: poker.LevelTypeBreak, DurationMin: 10},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create timer: %s", err)
+	}
+
+	webhook, err := poker.NewWebhook(timer.ID, "contract-user", receiver.URL, []poker.WebhookEvent{poker.WebhookEventBreakStarted}, time.Now())
+	if err != nil {
+		t.Fatalf("failed to create webhook: %s", err)
+	}
+
+	err = s.webhookRepo.SaveWebhook(ctx, webhook)
+	if err != nil {
+		t.Fatalf("failed to save webhook: %s", err)
+	}
+
+	for _, action := range []poker.PlayAction{poker.PlayActionStart, poker.PlayActionNext} {
+		_, err = c.Play(ctx, timer.ID, action)
+		if err != nil {
+			t.Fatalf("failed to %s timer: %s", action, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != 1 || received[0] != poker.WebhookEventBreakStarted.String() {
+		t.Fatalf("expected the break starting to have been delivered before the response, got %v", received)
+	}
+
+	deliveries, err := s.webhookRepo.WebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to fetch deliveries: %s", err)
+	}
+
+	if len(deliveries) != 1 || !deliveries[0].Succeeded || deliveries[0].Attempts != 1 {
+		t.Errorf("expected one delivery that succeeded on its first attempt, got %+v", deliveries)
+	}
+
+}
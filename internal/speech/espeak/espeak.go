@@ -0,0 +1,81 @@
+package espeak
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"poker"
+	"strings"
+)
+
+var _ poker.SpeechSynthesizer = (*Synthesizer)(nil)
+
+type Config struct {
+	// Path to the espeak or espeak-ng binary, looked up on the PATH when empty
+	Path  string
+	Voice string
+}
+
+// Synthesizer generates speech by running a local espeak binary, so announcements work
+// without any network access
+type Synthesizer struct {
+	path  string
+	voice string
+}
+
+func New(cfg *Config) (*Synthesizer, error) {
+
+	s := &Synthesizer{
+		voice: "en-us",
+	}
+
+	if cfg != nil && cfg.Voice != "" {
+		s.voice = cfg.Voice
+	}
+
+	candidates := []string{"espeak-ng", "espeak"}
+	if cfg != nil && cfg.Path != "" {
+		candidates = []string{cfg.Path}
+	}
+
+	for _, candidate := range candidates {
+		path, err := exec.LookPath(candidate)
+		if err != nil {
+			continue
+		}
+		s.path = path
+		break
+	}
+
+	if s.path == "" {
+		return nil, fmt.Errorf("failed to locate espeak binary, tried %s", strings.Join(candidates, ", "))
+	}
+
+	return s, nil
+
+}
+
+func (s *Synthesizer) Key() string {
+	return fmt.Sprintf("espeak/%s", s.voice)
+}
+
+func (s *Synthesizer) SynthesizeSpeech(ctx context.Context, text string) (*poker.Speech, error) {
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, s.path, "--stdout", "-v", s.voice, text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w: %s", s.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return &poker.Speech{
+		Audio:       stdout.Bytes(),
+		ContentType: "audio/wav",
+	}, nil
+
+}
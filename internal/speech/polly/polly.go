@@ -0,0 +1,100 @@
+package polly
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"poker"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awspolly "github.com/aws/aws-sdk-go-v2/service/polly"
+	ptypes "github.com/aws/aws-sdk-go-v2/service/polly/types"
+)
+
+var _ poker.SpeechSynthesizer = (*Synthesizer)(nil)
+
+type Config struct {
+	Engine       string
+	LanguageCode string
+	VoiceID      string
+}
+
+// Synthesizer generates speech with AWS Polly
+type Synthesizer struct {
+	client *awspolly.Client
+
+	engine       ptypes.Engine
+	languageCode ptypes.LanguageCode
+	voiceID      ptypes.VoiceId
+}
+
+// New returns a Synthesizer for the client. Any value missing from cfg falls back to the
+// neural en-US Stephen voice
+func New(client *awspolly.Client, cfg *Config) *Synthesizer {
+
+	s := &Synthesizer{
+		client:       client,
+		engine:       ptypes.EngineNeural,
+		languageCode: ptypes.LanguageCodeEnUs,
+		voiceID:      ptypes.VoiceIdStephen,
+	}
+
+	if cfg == nil {
+		return s
+	}
+
+	if cfg.Engine != "" {
+		s.engine = ptypes.Engine(cfg.Engine)
+	}
+
+	if cfg.LanguageCode != "" {
+		s.languageCode = ptypes.LanguageCode(cfg.LanguageCode)
+	}
+
+	if cfg.VoiceID != "" {
+		s.voiceID = ptypes.VoiceId(cfg.VoiceID)
+	}
+
+	return s
+
+}
+
+// Key is empty for the neural en-US Stephen voice, which was the only voice before it could be configured, so the
+// audio already cached for it is still used
+func (s *Synthesizer) Key() string {
+
+	if s.engine == ptypes.EngineNeural && s.languageCode == ptypes.LanguageCodeEnUs && s.voiceID == ptypes.VoiceIdStephen {
+		return ""
+	}
+
+	return fmt.Sprintf("polly/%s/%s/%s", s.engine, s.languageCode, s.voiceID)
+
+}
+
+func (s *Synthesizer) SynthesizeSpeech(ctx context.Context, text string) (*poker.Speech, error) {
+
+	output, err := s.client.SynthesizeSpeech(ctx, &awspolly.SynthesizeSpeechInput{
+		Engine:       s.engine,
+		OutputFormat: ptypes.OutputFormatMp3,
+		LanguageCode: s.languageCode,
+		Text:         aws.String(text),
+		VoiceId:      s.voiceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+
+	defer output.AudioStream.Close()
+
+	var buffer = new(bytes.Buffer)
+	_, err = buffer.ReadFrom(output.AudioStream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio stream: %w", err)
+	}
+
+	return &poker.Speech{
+		Audio:       buffer.Bytes(),
+		ContentType: aws.ToString(output.ContentType),
+	}, nil
+
+}
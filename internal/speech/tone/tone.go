@@ -0,0 +1,59 @@
+package tone
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"mime"
+	"path"
+	"poker"
+)
+
+var _ poker.SpeechSynthesizer = (*Synthesizer)(nil)
+
+// audioTypes covers the extensions the standard library doesn't know about on systems without a mime.types file
+var audioTypes = map[string]string{
+	".mp3": "audio/mpeg",
+	".ogg": "audio/ogg",
+	".wav": "audio/wav",
+}
+
+// Synthesizer ignores the text it is given and returns the same bundled tone for every
+// announcement. It is for running the app locally and in tests without any speech backend
+type Synthesizer struct {
+	fsys fs.FS
+	path string
+}
+
+func New(fsys fs.FS, path string) *Synthesizer {
+	return &Synthesizer{
+		fsys: fsys,
+		path: path,
+	}
+}
+
+func (s *Synthesizer) Key() string {
+	return fmt.Sprintf("tone/%s", path.Base(s.path))
+}
+
+func (s *Synthesizer) SynthesizeSpeech(_ context.Context, _ string) (*poker.Speech, error) {
+
+	data, err := fs.ReadFile(s.fsys, s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tone %s: %w", s.path, err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(s.path))
+	if contentType == "" {
+		contentType = audioTypes[path.Ext(s.path)]
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &poker.Speech{
+		Audio:       data,
+		ContentType: contentType,
+	}, nil
+
+}
@@ -2,8 +2,10 @@ package dynamo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"poker"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,15 +15,26 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+var _ poker.TimerRepository = (*TimerRepository)(nil)
+
+// timerShareRecord indexes a share by the user it is with, so the timers shared with a user can be queried. The
+// share itself is kept on the timer
+type timerShareRecord struct {
+	UserID  string
+	TimerID string
+}
+
 type TimerRepository struct {
-	client    *dynamodb.Client
-	tableName string
+	client          *dynamodb.Client
+	tableName       string
+	sharesTableName string
 }
 
-func NewTimerRepository(client *dynamodb.Client, tableName string) *TimerRepository {
+func NewTimerRepository(client *dynamodb.Client, tableName, sharesTableName string) *TimerRepository {
 	return &TimerRepository{
-		client:    client,
-		tableName: tableName,
+		client:          client,
+		tableName:       tableName,
+		sharesTableName: sharesTableName,
 	}
 }
 
@@ -49,6 +62,8 @@ func (r *TimerRepository) Timer(ctx context.Context, id string) (*poker.Timer, e
 		return nil, fmt.Errorf("failed to decode ddb record: %w", err)
 	}
 
+	timer.UpgradeLateRegistration()
+
 	return timer, nil
 
 }
@@ -84,37 +99,200 @@ func (r *TimerRepository) TimersByUserID(ctx context.Context, userID string) ([]
 		return nil, fmt.Errorf("failed to decode ddb record: %w", err)
 	}
 
+	for _, timer := range timers {
+		timer.UpgradeLateRegistration()
+	}
+
+	return timers, nil
+
+}
+
+// TimersSharedWithUserID queries the shares table for the timers shared with the user, then fetches each of them
+func (r *TimerRepository) TimersSharedWithUserID(ctx context.Context, userID string) ([]*poker.Timer, error) {
+
+	keyExpr := expression.Key("UserID").Equal(expression.Value(userID))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyExpr).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression for timers shared with user query: %w", err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(r.sharesTableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	var timers = make([]*poker.Timer, 0)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch timers shared with user: %w", err)
+		}
+
+		var records []*timerShareRecord
+		err = attributevalue.UnmarshalListOfMaps(page.Items, &records)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+		}
+
+		for _, record := range records {
+			timer, err := r.Timer(ctx, record.TimerID)
+			if err != nil {
+				return nil, err
+			}
+
+			// The share record is written after the timer, so it can outlive a share that failed to be cleaned up
+			if timer == nil || timer.Share(userID) == nil {
+				continue
+			}
+
+			timers = append(timers, timer)
+		}
+	}
+
+	sort.Slice(timers, func(i, j int) bool {
+		return timers[i].CreatedAt.Before(timers[j].CreatedAt)
+	})
+
 	return timers, nil
 
 }
 
 func (r *TimerRepository) SaveTimer(ctx context.Context, timer *poker.Timer) error {
 
-	timer.CreatedAt = time.Now()
-	timer.UpdatedAt = time.Now()
+	now := time.Now()
+	if timer.CreatedAt.IsZero() {
+		timer.CreatedAt = now
+	}
+	timer.UpdatedAt = now
+
+	// Timers saved before versioning have no Version attribute, so they are treated the same as new timers
+	version := timer.Version
+	condition := expression.AttributeNotExists(expression.Name("Version"))
+	if version > 0 {
+		condition = expression.Name("Version").Equal(expression.Value(version))
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build condition for timer version: %w", err)
+	}
+
+	timer.Version = version + 1
 
 	item, err := attributevalue.MarshalMap(timer)
 	if err != nil {
+		timer.Version = version
 		return fmt.Errorf("failed to marshal timer: %w", err)
 	}
 
-	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(r.tableName),
-		Item:      item,
+	result, err := r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.tableName),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              types.ReturnValueAllOld,
 	})
+	if err != nil {
+		timer.Version = version
 
-	return err
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return &poker.ConflictError{Resource: "timer", ID: timer.ID}
+		}
+
+		return err
+	}
+
+	previous, err := previousShares(result.Attributes)
+	if err != nil {
+		return err
+	}
+
+	return r.saveShares(ctx, timer.ID, previous, timer.Shares)
 
 }
+
 func (r *TimerRepository) DeleteTimer(ctx context.Context, id string) error {
 
-	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+	result, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
 		TableName: aws.String(r.tableName),
 		Key: map[string]types.AttributeValue{
 			"ID": &types.AttributeValueMemberS{Value: id},
 		},
+		ReturnValues: types.ReturnValueAllOld,
 	})
+	if err != nil {
+		return err
+	}
+
+	previous, err := previousShares(result.Attributes)
+	if err != nil {
+		return err
+	}
+
+	return r.saveShares(ctx, id, previous, nil)
+
+}
+
+// previousShares decodes the shares of a timer as it was before being overwritten or deleted
+func previousShares(item map[string]types.AttributeValue) ([]*poker.TimerShare, error) {
+
+	if item == nil {
+		return nil, nil
+	}
+
+	var previous = new(poker.Timer)
+	err := attributevalue.UnmarshalMap(item, previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+	}
+
+	return previous.Shares, nil
+
+}
+
+// saveShares brings the share records of a timer in line with its shares. Every current share is written, not only
+// new ones, so a record that failed to be written before is put back on the next save
+func (r *TimerRepository) saveShares(ctx context.Context, timerID string, previous, shares []*poker.TimerShare) error {
+
+	current := make(map[string]bool, len(shares))
+	for _, share := range shares {
+		current[share.UserID] = true
+
+		item, err := attributevalue.MarshalMap(&timerShareRecord{UserID: share.UserID, TimerID: timerID})
+		if err != nil {
+			return fmt.Errorf("failed to marshal timer share: %w", err)
+		}
+
+		_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String(r.sharesTableName),
+			Item:      item,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save timer share: %w", err)
+		}
+	}
+
+	for _, share := range previous {
+		if current[share.UserID] {
+			continue
+		}
+
+		_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(r.sharesTableName),
+			Key: map[string]types.AttributeValue{
+				"UserID":  &types.AttributeValueMemberS{Value: share.UserID},
+				"TimerID": &types.AttributeValueMemberS{Value: timerID},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete timer share: %w", err)
+		}
+	}
 
-	return err
+	return nil
 
 }
@@ -0,0 +1,151 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"poker"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var _ poker.APITokenRepository = (*APITokenRepository)(nil)
+
+type APITokenRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewAPITokenRepository(client *dynamodb.Client, tableName string) *APITokenRepository {
+	return &APITokenRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *APITokenRepository) APIToken(ctx context.Context, id string) (*poker.APIToken, error) {
+
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api token: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var token = new(poker.APIToken)
+
+	err = attributevalue.UnmarshalMap(result.Item, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+	}
+
+	return token, nil
+
+}
+
+func (r *APITokenRepository) APITokenByHash(ctx context.Context, hash string) (*poker.APIToken, error) {
+
+	tokens, err := r.query(ctx, "hash-index", "Hash", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	return tokens[0], nil
+
+}
+
+func (r *APITokenRepository) APITokensByUserID(ctx context.Context, userID string) ([]*poker.APIToken, error) {
+
+	tokens, err := r.query(ctx, "user-id-index", "UserID", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+
+	return tokens, nil
+
+}
+
+func (r *APITokenRepository) query(ctx context.Context, index, key, value string) ([]*poker.APIToken, error) {
+
+	keyExpr := expression.Key(key).Equal(expression.Value(value))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyExpr).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression for api tokens by %s query: %w", key, err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	var tokens []*poker.APIToken
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch api tokens by %s: %w", key, err)
+		}
+
+		var items []*poker.APIToken
+		err = attributevalue.UnmarshalListOfMaps(page.Items, &items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+		}
+
+		tokens = append(tokens, items...)
+	}
+
+	return tokens, nil
+
+}
+
+func (r *APITokenRepository) SaveAPIToken(ctx context.Context, token *poker.APIToken) error {
+
+	item, err := attributevalue.MarshalMap(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api token: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+
+	return err
+
+}
+
+func (r *APITokenRepository) DeleteAPIToken(ctx context.Context, id string) error {
+
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	return err
+
+}
@@ -0,0 +1,177 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"poker"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var _ poker.TournamentRepository = (*TournamentRepository)(nil)
+
+type TournamentRepository struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewTournamentRepository(client *dynamodb.Client, tableName string) *TournamentRepository {
+	return &TournamentRepository{
+		client:    client,
+		tableName: tableName,
+	}
+}
+
+func (r *TournamentRepository) Tournament(ctx context.Context, id string) (*poker.Tournament, error) {
+
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tournament: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var tournament = new(poker.Tournament)
+
+	err = attributevalue.UnmarshalMap(result.Item, tournament)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+	}
+
+	return tournament, nil
+
+}
+
+func (r *TournamentRepository) TournamentByTimerID(ctx context.Context, timerID string) (*poker.Tournament, error) {
+
+	tournaments, err := r.query(ctx, "timer-id-index", "TimerID", timerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *poker.Tournament
+	for _, tournament := range tournaments {
+		if latest == nil || tournament.CreatedAt.After(latest.CreatedAt) {
+			latest = tournament
+		}
+	}
+
+	return latest, nil
+
+}
+
+func (r *TournamentRepository) TournamentsByUserID(ctx context.Context, userID string) ([]*poker.Tournament, error) {
+	return r.query(ctx, "user-id-index", "UserID", userID)
+}
+
+func (r *TournamentRepository) query(ctx context.Context, index, key, value string) ([]*poker.Tournament, error) {
+
+	keyExpr := expression.Key(key).Equal(expression.Value(value))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyExpr).Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expression for tournaments by %s query: %w", key, err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(r.tableName),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	var tournaments []*poker.Tournament
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tournaments by %s: %w", key, err)
+		}
+
+		var items []*poker.Tournament
+		err = attributevalue.UnmarshalListOfMaps(page.Items, &items)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+		}
+
+		tournaments = append(tournaments, items...)
+	}
+
+	return tournaments, nil
+
+}
+
+func (r *TournamentRepository) SaveTournament(ctx context.Context, tournament *poker.Tournament) error {
+
+	now := time.Now()
+	if tournament.CreatedAt.IsZero() {
+		tournament.CreatedAt = now
+	}
+	tournament.UpdatedAt = now
+
+	version := tournament.Version
+	condition := expression.AttributeNotExists(expression.Name("Version"))
+	if version > 0 {
+		condition = expression.Name("Version").Equal(expression.Value(version))
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build condition for tournament version: %w", err)
+	}
+
+	tournament.Version = version + 1
+
+	item, err := attributevalue.MarshalMap(tournament)
+	if err != nil {
+		tournament.Version = version
+		return fmt.Errorf("failed to marshal tournament: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(r.tableName),
+		Item:                      item,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if err != nil {
+		tournament.Version = version
+
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return &poker.ConflictError{Resource: "tournament", ID: tournament.ID}
+		}
+
+		return err
+	}
+
+	return nil
+
+}
+
+func (r *TournamentRepository) DeleteTournament(ctx context.Context, id string) error {
+
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	return err
+
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+var _ poker.UserRepository = (*UserRepository)(nil)
+
 type UserRepository struct {
 	client    *dynamodb.Client
 	tableName string
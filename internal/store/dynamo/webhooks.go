@@ -0,0 +1,216 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"poker"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var _ poker.WebhookRepository = (*WebhookRepository)(nil)
+
+// webhookDeliveryTTL is how long deliveries are kept before DynamoDB expires them
+const webhookDeliveryTTL = 7 * 24 * time.Hour
+
+// webhookDeliveryRecord is a delivery with the time DynamoDB expires it at, see the table's ttl
+type webhookDeliveryRecord struct {
+	poker.WebhookDelivery
+	// ExpiresAt is in unix seconds
+	ExpiresAt int64
+}
+
+type WebhookRepository struct {
+	client              *dynamodb.Client
+	tableName           string
+	deliveriesTableName string
+}
+
+func NewWebhookRepository(client *dynamodb.Client, tableName, deliveriesTableName string) *WebhookRepository {
+	return &WebhookRepository{
+		client:              client,
+		tableName:           tableName,
+		deliveriesTableName: deliveriesTableName,
+	}
+}
+
+func (r *WebhookRepository) Webhook(ctx context.Context, id string) (*poker.Webhook, error) {
+
+	result, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var webhook = new(poker.Webhook)
+
+	err = attributevalue.UnmarshalMap(result.Item, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ddb record: %w", err)
+	}
+
+	return webhook, nil
+
+}
+
+func (r *WebhookRepository) WebhooksByTimerID(ctx context.Context, timerID string) ([]*poker.Webhook, error) {
+
+	var webhooks []*poker.Webhook
+	err := r.query(ctx, r.tableName, "timer-id-index", "TimerID", timerID, &webhooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks by timer id: %w", err)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+
+}
+
+func (r *WebhookRepository) SaveWebhook(ctx context.Context, webhook *poker.Webhook) error {
+
+	item, err := attributevalue.MarshalMap(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.tableName),
+		Item:      item,
+	})
+
+	return err
+
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+
+	_, err := r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"ID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	var records []*webhookDeliveryRecord
+	err = r.query(ctx, r.deliveriesTableName, "webhook-id-index", "WebhookID", id, &records)
+	if err != nil {
+		return fmt.Errorf("failed to fetch deliveries of deleted webhook: %w", err)
+	}
+
+	for _, record := range records {
+		_, err = r.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(r.deliveriesTableName),
+			Key: map[string]types.AttributeValue{
+				"ID": &types.AttributeValueMemberS{Value: record.ID},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+
+}
+
+func (r *WebhookRepository) WebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*poker.WebhookDelivery, error) {
+
+	var records []*webhookDeliveryRecord
+	err := r.query(ctx, r.deliveriesTableName, "webhook-id-index", "WebhookID", webhookID, &records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	if len(records) > limit {
+		records = records[:limit]
+	}
+
+	deliveries := make([]*poker.WebhookDelivery, 0, len(records))
+	for _, record := range records {
+		deliveries = append(deliveries, &record.WebhookDelivery)
+	}
+
+	return deliveries, nil
+
+}
+
+// SaveWebhookDelivery saves the delivery, which DynamoDB expires a week after it was created
+func (r *WebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *poker.WebhookDelivery) error {
+
+	item, err := attributevalue.MarshalMap(&webhookDeliveryRecord{
+		WebhookDelivery: *delivery,
+		ExpiresAt:       delivery.CreatedAt.Add(webhookDeliveryTTL).Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+
+	_, err = r.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.deliveriesTableName),
+		Item:      item,
+	})
+
+	return err
+
+}
+
+// query reads every item of the table's index with the key, decoding them into out
+func (r *WebhookRepository) query(ctx context.Context, table, index, key, value string, out any) error {
+
+	keyExpr := expression.Key(key).Equal(expression.Value(value))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyExpr).Build()
+	if err != nil {
+		return fmt.Errorf("failed to build expression for %s query: %w", key, err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		IndexName:                 aws.String(index),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	var items []map[string]types.AttributeValue
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+
+		items = append(items, page.Items...)
+	}
+
+	err = attributevalue.UnmarshalListOfMaps(items, out)
+	if err != nil {
+		return fmt.Errorf("failed to decode ddb record: %w", err)
+	}
+
+	return nil
+
+}
@@ -0,0 +1,27 @@
+// Package memory implements the repositories with maps held in the process. Nothing survives a
+// restart, so it is meant for tests and trying the app out locally
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// clone deep copies a record through its JSON encoding, so callers can never modify the stored
+// copy without saving it, the same as with the other backends
+func clone[T any](v *T) (*T, error) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	var out = new(T)
+	err = json.Unmarshal(data, out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode record: %w", err)
+	}
+
+	return out, nil
+
+}
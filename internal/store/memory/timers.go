@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"poker"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ poker.TimerRepository = (*TimerRepository)(nil)
+
+type TimerRepository struct {
+	mu     sync.RWMutex
+	timers map[string]*poker.Timer
+}
+
+func NewTimerRepository() *TimerRepository {
+	return &TimerRepository{
+		timers: make(map[string]*poker.Timer),
+	}
+}
+
+func (r *TimerRepository) Timer(ctx context.Context, id string) (*poker.Timer, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	timer, ok := r.timers[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return clone(timer)
+
+}
+
+func (r *TimerRepository) TimersByUserID(ctx context.Context, userID string) ([]*poker.Timer, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var timers []*poker.Timer
+	for _, timer := range r.timers {
+		if timer.UserID != userID {
+			continue
+		}
+
+		timer, err := clone(timer)
+		if err != nil {
+			return nil, err
+		}
+
+		timers = append(timers, timer)
+	}
+
+	sort.Slice(timers, func(i, j int) bool {
+		return timers[i].CreatedAt.Before(timers[j].CreatedAt)
+	})
+
+	return timers, nil
+
+}
+
+func (r *TimerRepository) TimersSharedWithUserID(ctx context.Context, userID string) ([]*poker.Timer, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var timers []*poker.Timer
+	for _, timer := range r.timers {
+		if timer.Share(userID) == nil {
+			continue
+		}
+
+		timer, err := clone(timer)
+		if err != nil {
+			return nil, err
+		}
+
+		timers = append(timers, timer)
+	}
+
+	sort.Slice(timers, func(i, j int) bool {
+		return timers[i].CreatedAt.Before(timers[j].CreatedAt)
+	})
+
+	return timers, nil
+
+}
+
+func (r *TimerRepository) SaveTimer(ctx context.Context, timer *poker.Timer) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A timer that has been saved before has to still be stored at the same version, so a stale copy of a deleted
+	// timer isn't put back
+	existing, ok := r.timers[timer.ID]
+	if (ok && existing.Version != timer.Version) || (!ok && timer.Version > 0) {
+		return &poker.ConflictError{Resource: "timer", ID: timer.ID}
+	}
+
+	now := time.Now()
+	if timer.CreatedAt.IsZero() {
+		timer.CreatedAt = now
+	}
+	timer.UpdatedAt = now
+	timer.Version += 1
+
+	stored, err := clone(timer)
+	if err != nil {
+		timer.Version -= 1
+		return err
+	}
+
+	r.timers[timer.ID] = stored
+
+	return nil
+
+}
+
+func (r *TimerRepository) DeleteTimer(ctx context.Context, id string) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.timers, id)
+
+	return nil
+
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"poker"
+	"testing"
+)
+
+func TestSaveTimerVersion(t *testing.T) {
+
+	repo := NewTimerRepository()
+	ctx := context.Background()
+
+	timer := &poker.Timer{ID: "timer", UserID: "user", Name: "Friday Night"}
+	err := repo.SaveTimer(ctx, timer)
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	stale := *timer
+
+	err = repo.SaveTimer(ctx, timer)
+	if err != nil {
+		t.Fatalf("failed to save timer again: %s", err)
+	}
+
+	var conflict *poker.ConflictError
+
+	err = repo.SaveTimer(ctx, &stale)
+	if !errors.As(err, &conflict) {
+		t.Errorf("expected saving a stale copy to conflict, got %v", err)
+	}
+
+	// A stale copy of a deleted timer mustn't bring it back
+	err = repo.DeleteTimer(ctx, timer.ID)
+	if err != nil {
+		t.Fatalf("failed to delete timer: %s", err)
+	}
+
+	err = repo.SaveTimer(ctx, timer)
+	if !errors.As(err, &conflict) {
+		t.Errorf("expected saving a deleted timer to conflict, got %v", err)
+	}
+
+	got, err := repo.Timer(ctx, timer.ID)
+	if err != nil || got != nil {
+		t.Errorf("expected the timer to stay deleted, got %+v %v", got, err)
+	}
+
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"poker"
+	"sort"
+	"sync"
+)
+
+var _ poker.APITokenRepository = (*APITokenRepository)(nil)
+
+type APITokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]*poker.APIToken
+}
+
+func NewAPITokenRepository() *APITokenRepository {
+	return &APITokenRepository{
+		tokens: make(map[string]*poker.APIToken),
+	}
+}
+
+func (r *APITokenRepository) APIToken(ctx context.Context, id string) (*poker.APIToken, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return clone(token)
+
+}
+
+func (r *APITokenRepository) APITokenByHash(ctx context.Context, hash string) (*poker.APIToken, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.Hash == hash {
+			return clone(token)
+		}
+	}
+
+	return nil, nil
+
+}
+
+func (r *APITokenRepository) APITokensByUserID(ctx context.Context, userID string) ([]*poker.APIToken, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tokens []*poker.APIToken
+	for _, token := range r.tokens {
+		if token.UserID != userID {
+			continue
+		}
+
+		token, err := clone(token)
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+
+	return tokens, nil
+
+}
+
+func (r *APITokenRepository) SaveAPIToken(ctx context.Context, token *poker.APIToken) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := clone(token)
+	if err != nil {
+		return err
+	}
+
+	r.tokens[token.ID] = stored
+
+	return nil
+
+}
+
+func (r *APITokenRepository) DeleteAPIToken(ctx context.Context, id string) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tokens, id)
+
+	return nil
+
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"poker"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ poker.TournamentRepository = (*TournamentRepository)(nil)
+
+type TournamentRepository struct {
+	mu          sync.RWMutex
+	tournaments map[string]*poker.Tournament
+}
+
+func NewTournamentRepository() *TournamentRepository {
+	return &TournamentRepository{
+		tournaments: make(map[string]*poker.Tournament),
+	}
+}
+
+func (r *TournamentRepository) Tournament(ctx context.Context, id string) (*poker.Tournament, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tournament, ok := r.tournaments[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return clone(tournament)
+
+}
+
+func (r *TournamentRepository) TournamentByTimerID(ctx context.Context, timerID string) (*poker.Tournament, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *poker.Tournament
+	for _, tournament := range r.tournaments {
+		if tournament.TimerID != timerID {
+			continue
+		}
+
+		if latest == nil || tournament.CreatedAt.After(latest.CreatedAt) {
+			latest = tournament
+		}
+	}
+
+	if latest == nil {
+		return nil, nil
+	}
+
+	return clone(latest)
+
+}
+
+func (r *TournamentRepository) TournamentsByUserID(ctx context.Context, userID string) ([]*poker.Tournament, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tournaments []*poker.Tournament
+	for _, tournament := range r.tournaments {
+		if tournament.UserID != userID {
+			continue
+		}
+
+		tournament, err := clone(tournament)
+		if err != nil {
+			return nil, err
+		}
+
+		tournaments = append(tournaments, tournament)
+	}
+
+	sort.Slice(tournaments, func(i, j int) bool {
+		return tournaments[i].CreatedAt.Before(tournaments[j].CreatedAt)
+	})
+
+	return tournaments, nil
+
+}
+
+func (r *TournamentRepository) SaveTournament(ctx context.Context, tournament *poker.Tournament) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.tournaments[tournament.ID]; ok && existing.Version != tournament.Version {
+		return &poker.ConflictError{Resource: "tournament", ID: tournament.ID}
+	}
+
+	now := time.Now()
+	if tournament.CreatedAt.IsZero() {
+		tournament.CreatedAt = now
+	}
+	tournament.UpdatedAt = now
+	tournament.Version += 1
+
+	stored, err := clone(tournament)
+	if err != nil {
+		tournament.Version -= 1
+		return err
+	}
+
+	r.tournaments[tournament.ID] = stored
+
+	return nil
+
+}
+
+func (r *TournamentRepository) DeleteTournament(ctx context.Context, id string) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.tournaments, id)
+
+	return nil
+
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"poker"
+	"sync"
+)
+
+var _ poker.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*poker.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users: make(map[string]*poker.User),
+	}
+}
+
+func (r *UserRepository) User(ctx context.Context, id string) (*poker.User, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return clone(user)
+
+}
+
+func (r *UserRepository) UserByEmail(ctx context.Context, email string) (*poker.User, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return clone(user)
+		}
+	}
+
+	return nil, nil
+
+}
+
+func (r *UserRepository) SaveUser(ctx context.Context, user *poker.User) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := clone(user)
+	if err != nil {
+		return err
+	}
+
+	r.users[user.ID] = stored
+
+	return nil
+
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+
+	return nil
+
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"poker"
+	"sort"
+	"sync"
+)
+
+var _ poker.WebhookRepository = (*WebhookRepository)(nil)
+
+// webhookDeliveryLimit is how many deliveries are kept for each webhook
+const webhookDeliveryLimit = 50
+
+type WebhookRepository struct {
+	mu         sync.RWMutex
+	webhooks   map[string]*poker.Webhook
+	deliveries map[string][]*poker.WebhookDelivery
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		webhooks:   make(map[string]*poker.Webhook),
+		deliveries: make(map[string][]*poker.WebhookDelivery),
+	}
+}
+
+func (r *WebhookRepository) Webhook(ctx context.Context, id string) (*poker.Webhook, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return clone(webhook)
+
+}
+
+func (r *WebhookRepository) WebhooksByTimerID(ctx context.Context, timerID string) ([]*poker.Webhook, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var webhooks []*poker.Webhook
+	for _, webhook := range r.webhooks {
+		if webhook.TimerID != timerID {
+			continue
+		}
+
+		webhook, err := clone(webhook)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+
+}
+
+func (r *WebhookRepository) SaveWebhook(ctx context.Context, webhook *poker.Webhook) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := clone(webhook)
+	if err != nil {
+		return err
+	}
+
+	r.webhooks[webhook.ID] = stored
+
+	return nil
+
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.webhooks, id)
+	delete(r.deliveries, id)
+
+	return nil
+
+}
+
+func (r *WebhookRepository) WebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*poker.WebhookDelivery, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.deliveries[webhookID]
+	if len(stored) > limit {
+		stored = stored[:limit]
+	}
+
+	var deliveries []*poker.WebhookDelivery
+	for _, delivery := range stored {
+		delivery, err := clone(delivery)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+
+}
+
+func (r *WebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *poker.WebhookDelivery) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := clone(delivery)
+	if err != nil {
+		return err
+	}
+
+	deliveries := r.deliveries[delivery.WebhookID]
+	for i, d := range deliveries {
+		if d.ID == delivery.ID {
+			deliveries[i] = stored
+			return nil
+		}
+	}
+
+	// Deliveries are kept most recent first, dropping the oldest once there are too many
+	deliveries = append([]*poker.WebhookDelivery{stored}, deliveries...)
+	if len(deliveries) > webhookDeliveryLimit {
+		deliveries = deliveries[:webhookDeliveryLimit]
+	}
+
+	r.deliveries[delivery.WebhookID] = deliveries
+
+	return nil
+
+}
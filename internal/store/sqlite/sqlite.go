@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order, each exactly once. The index of the last applied migration
+// is tracked in the database's user_version, so never edit or reorder a released migration,
+// only append new ones
+var migrations = []string{
+	`CREATE TABLE timers (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX timers_user_id_idx ON timers (user_id);`,
+	`CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		email TEXT NOT NULL,
+		data TEXT NOT NULL
+	);
+	CREATE UNIQUE INDEX users_email_idx ON users (email);`,
+	`ALTER TABLE timers ADD COLUMN version INTEGER NOT NULL DEFAULT 0;`,
+	`CREATE TABLE tournaments (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		timer_id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		version INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX tournaments_user_id_idx ON tournaments (user_id);
+	CREATE INDEX tournaments_timer_id_idx ON tournaments (timer_id);`,
+	`CREATE TABLE timer_shares (
+		timer_id TEXT NOT NULL REFERENCES timers (id) ON DELETE CASCADE,
+		user_id TEXT NOT NULL,
+		PRIMARY KEY (timer_id, user_id)
+	);
+	CREATE INDEX timer_shares_user_id_idx ON timer_shares (user_id);`,
+	`CREATE TABLE api_tokens (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX api_tokens_user_id_idx ON api_tokens (user_id);
+	CREATE UNIQUE INDEX api_tokens_hash_idx ON api_tokens (hash);`,
+	`CREATE TABLE webhooks (
+		id TEXT PRIMARY KEY,
+		timer_id TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX webhooks_timer_id_idx ON webhooks (timer_id);
+	CREATE TABLE webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		webhook_id TEXT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+		data TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+	CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);`,
+}
+
+// Open opens the database at path, creating it if needed, and brings its schema up to date
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite only allows a single writer, queuing everything through one connection
+	// avoids busy errors when requests race to save
+	db.SetMaxOpenConns(1)
+
+	err = migrate(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+
+}
+
+func migrate(ctx context.Context, db *sql.DB) error {
+
+	var version int
+	err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start migration %d: %w", i+1, err)
+		}
+
+		_, err = tx.ExecContext(ctx, migrations[i])
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+
+		// PRAGMA doesn't accept bound parameters
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1))
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+
+}
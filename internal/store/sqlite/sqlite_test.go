@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMigrateEmptyDatabase(t *testing.T) {
+
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	var version int
+	err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version)
+	if err != nil {
+		t.Fatalf("failed to read schema version: %s", err)
+	}
+
+	if version != len(migrations) {
+		t.Errorf("expected every migration to have been applied, got version %d of %d", version, len(migrations))
+	}
+
+	for _, table := range []string{"timers", "users", "tournaments", "timer_shares", "api_tokens", "webhooks", "webhook_deliveries"} {
+		var name string
+		err = db.QueryRowContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&name)
+		if err != nil {
+			t.Errorf("expected the %s table to have been created, got %s", table, err)
+		}
+	}
+
+	// Opening a database that is up to date applies nothing
+	err = migrate(ctx, db)
+	if err != nil {
+		t.Errorf("expected migrating an up to date database to do nothing, got %s", err)
+	}
+
+	_, err = db.ExecContext(ctx, "PRAGMA user_version = 999")
+	if err != nil {
+		t.Fatalf("failed to set schema version: %s", err)
+	}
+
+	err = migrate(ctx, db)
+	if err == nil || !strings.Contains(err.Error(), "newer than this build supports") {
+		t.Errorf("expected a database from a newer build to be refused, got %v", err)
+	}
+
+}
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"poker"
+	"time"
+)
+
+var _ poker.TimerRepository = (*TimerRepository)(nil)
+
+// TimerRepository keeps each timer as a JSON document, with the columns it is looked up by alongside. The users a
+// timer is shared with are copied into timer_shares on every save so shared timers can be found by user
+type TimerRepository struct {
+	db *sql.DB
+}
+
+func NewTimerRepository(db *sql.DB) *TimerRepository {
+	return &TimerRepository{
+		db: db,
+	}
+}
+
+func (r *TimerRepository) Timer(ctx context.Context, id string) (*poker.Timer, error) {
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx, "SELECT data FROM timers WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timer: %w", err)
+	}
+
+	var timer = new(poker.Timer)
+
+	err = json.Unmarshal(data, timer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode timer record: %w", err)
+	}
+
+	timer.UpgradeLateRegistration()
+
+	return timer, nil
+
+}
+
+func (r *TimerRepository) TimersByUserID(ctx context.Context, userID string) ([]*poker.Timer, error) {
+
+	rows, err := r.db.QueryContext(ctx, "SELECT data FROM timers WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timers by user id: %w", err)
+	}
+
+	return scanTimers(rows)
+
+}
+
+func (r *TimerRepository) TimersSharedWithUserID(ctx context.Context, userID string) ([]*poker.Timer, error) {
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT timers.data FROM timers
+		INNER JOIN timer_shares ON timer_shares.timer_id = timers.id
+		WHERE timer_shares.user_id = ? ORDER BY timers.created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timers shared with user id: %w", err)
+	}
+
+	return scanTimers(rows)
+
+}
+
+func scanTimers(rows *sql.Rows) ([]*poker.Timer, error) {
+
+	defer rows.Close()
+
+	var timers []*poker.Timer
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan timer record: %w", err)
+		}
+
+		var timer = new(poker.Timer)
+		err = json.Unmarshal(data, timer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode timer record: %w", err)
+		}
+
+		timer.UpgradeLateRegistration()
+
+		timers = append(timers, timer)
+	}
+
+	return timers, rows.Err()
+
+}
+
+func (r *TimerRepository) SaveTimer(ctx context.Context, timer *poker.Timer) error {
+
+	now := time.Now()
+	if timer.CreatedAt.IsZero() {
+		timer.CreatedAt = now
+	}
+	timer.UpdatedAt = now
+
+	version := timer.Version
+	timer.Version = version + 1
+
+	data, err := json.Marshal(timer)
+	if err != nil {
+		timer.Version = version
+		return fmt.Errorf("failed to marshal timer: %w", err)
+	}
+
+	err = r.saveTimer(ctx, timer, data, version)
+	if err != nil {
+		timer.Version = version
+		return err
+	}
+
+	return nil
+
+}
+
+func (r *TimerRepository) saveTimer(ctx context.Context, timer *poker.Timer, data []byte, version uint) error {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start saving timer: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// A timer that has been saved before is only updated while the stored version hasn't moved on, and isn't put back
+	// if it has since been deleted. Either leaves no rows affected
+	query := `UPDATE timers SET user_id = ?, data = ?, version = ?, updated_at = ? WHERE id = ? AND version = ?`
+	args := []any{timer.UserID, data, timer.Version, timer.UpdatedAt, timer.ID, version}
+	if version == 0 {
+		query = `INSERT INTO timers (id, user_id, data, version, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, data = excluded.data, version = excluded.version, updated_at = excluded.updated_at
+		WHERE timers.version = 0`
+		args = []any{timer.ID, timer.UserID, data, timer.Version, timer.CreatedAt, timer.UpdatedAt}
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check timer was saved: %w", err)
+	}
+
+	if affected == 0 {
+		return &poker.ConflictError{Resource: "timer", ID: timer.ID}
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM timer_shares WHERE timer_id = ?", timer.ID)
+	if err != nil {
+		return fmt.Errorf("failed to clear timer shares: %w", err)
+	}
+
+	for _, share := range timer.Shares {
+		_, err = tx.ExecContext(ctx, "INSERT INTO timer_shares (timer_id, user_id) VALUES (?, ?)", timer.ID, share.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to save timer share: %w", err)
+		}
+	}
+
+	return tx.Commit()
+
+}
+
+func (r *TimerRepository) DeleteTimer(ctx context.Context, id string) error {
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM timers WHERE id = ?", id)
+
+	return err
+
+}
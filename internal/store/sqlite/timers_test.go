@@ -0,0 +1,185 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"poker"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestDB opens a migrated database that only lives in memory
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := Open(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %s", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func expectConflict(t *testing.T, err error, action string) {
+	t.Helper()
+
+	var conflict *poker.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Errorf("expected %s to conflict, got %v", action, err)
+	}
+}
+
+// TestSaveDeletedTimer saves a stale copy of a timer that has since been deleted, which mustn't bring it back
+func TestSaveDeletedTimer(t *testing.T) {
+
+	repo := NewTimerRepository(openTestDB(t))
+	ctx := context.Background()
+
+	timer := &poker.Timer{ID: "timer", UserID: "user", Name: "Friday Night"}
+	err := repo.SaveTimer(ctx, timer)
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	stale := *timer
+
+	err = repo.DeleteTimer(ctx, timer.ID)
+	if err != nil {
+		t.Fatalf("failed to delete timer: %s", err)
+	}
+
+	expectConflict(t, repo.SaveTimer(ctx, &stale), "saving a deleted timer")
+
+	if stale.Version != timer.Version {
+		t.Errorf("expected the version to be left at %d after the conflict, got %d", timer.Version, stale.Version)
+	}
+
+	got, err := repo.Timer(ctx, timer.ID)
+	if err != nil || got != nil {
+		t.Errorf("expected the timer to stay deleted, got %+v %v", got, err)
+	}
+
+}
+
+func TestSaveTimerVersionConflict(t *testing.T) {
+
+	repo := NewTimerRepository(openTestDB(t))
+	ctx := context.Background()
+
+	timer := &poker.Timer{ID: "timer", UserID: "user", Name: "Friday Night"}
+	err := repo.SaveTimer(ctx, timer)
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	// Two displays load the same version, the first to save wins
+	first, err := repo.Timer(ctx, timer.ID)
+	if err != nil || first == nil {
+		t.Fatalf("failed to fetch timer: %v", err)
+	}
+	second := *first
+
+	first.Name = "Saturday Night"
+	err = repo.SaveTimer(ctx, first)
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	second.Name = "Sunday Night"
+	expectConflict(t, repo.SaveTimer(ctx, &second), "saving a stale timer")
+
+	// A new timer with an id that is already taken doesn't replace it either
+	expectConflict(t, repo.SaveTimer(ctx, &poker.Timer{ID: timer.ID, UserID: "other", Name: "Taken"}), "saving a new timer over another")
+
+	got, err := repo.Timer(ctx, timer.ID)
+	if err != nil || got == nil {
+		t.Fatalf("failed to fetch timer: %v", err)
+	}
+
+	if got.Name != "Saturday Night" || got.Version != 2 || got.UserID != "user" {
+		t.Errorf("expected the first save to be kept at version 2, got %q at version %d owned by %s", got.Name, got.Version, got.UserID)
+	}
+
+	// Once refreshed the second display can save
+	err = repo.SaveTimer(ctx, got)
+	if err != nil {
+		t.Errorf("expected the latest version to save, got %s", err)
+	}
+
+}
+
+func TestTimersSharedWithUserID(t *testing.T) {
+
+	repo := NewTimerRepository(openTestDB(t))
+	ctx := context.Background()
+
+	now := time.Now()
+	alice := &poker.User{ID: "alice", Email: "alice@poker.test"}
+
+	var timers []*poker.Timer
+	for i, name := range []string{"Accepted", "Invited", "Not shared"} {
+		timer := &poker.Timer{ID: name, UserID: "owner", Name: name, CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		if name != "Not shared" {
+			err := timer.Invite(alice, poker.TimerRoleViewer, now)
+			if err != nil {
+				t.Fatalf("failed to invite: %s", err)
+			}
+		}
+		if name == "Accepted" {
+			_ = timer.AcceptInvitation(alice.ID, now)
+		}
+
+		err := repo.SaveTimer(ctx, timer)
+		if err != nil {
+			t.Fatalf("failed to save timer: %s", err)
+		}
+		timers = append(timers, timer)
+	}
+
+	expectShared := func(want ...string) {
+		t.Helper()
+
+		shared, err := repo.TimersSharedWithUserID(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("failed to fetch shared timers: %s", err)
+		}
+
+		var got []string
+		for _, timer := range shared {
+			got = append(got, timer.ID)
+		}
+
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("expected the timers shared with alice to be %v, got %v", want, got)
+		}
+	}
+
+	// Invitations are listed whether or not they have been accepted, oldest timer first
+	expectShared("Accepted", "Invited")
+
+	owned, err := repo.TimersByUserID(ctx, alice.ID)
+	if err != nil || len(owned) != 0 {
+		t.Errorf("expected alice to own no timers, got %d %v", len(owned), err)
+	}
+
+	// Saving without the share removes it
+	timers[1].Unshare(alice.ID)
+	err = repo.SaveTimer(ctx, timers[1])
+	if err != nil {
+		t.Fatalf("failed to save timer: %s", err)
+	}
+
+	expectShared("Accepted")
+
+	// Deleting the timer removes its shares with it
+	err = repo.DeleteTimer(ctx, timers[0].ID)
+	if err != nil {
+		t.Fatalf("failed to delete timer: %s", err)
+	}
+
+	expectShared()
+
+}
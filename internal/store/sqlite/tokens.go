@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"poker"
+)
+
+var _ poker.APITokenRepository = (*APITokenRepository)(nil)
+
+type APITokenRepository struct {
+	db *sql.DB
+}
+
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{
+		db: db,
+	}
+}
+
+func (r *APITokenRepository) APIToken(ctx context.Context, id string) (*poker.APIToken, error) {
+	return r.token(ctx, "SELECT data FROM api_tokens WHERE id = ?", id)
+}
+
+func (r *APITokenRepository) APITokenByHash(ctx context.Context, hash string) (*poker.APIToken, error) {
+	return r.token(ctx, "SELECT data FROM api_tokens WHERE hash = ?", hash)
+}
+
+func (r *APITokenRepository) token(ctx context.Context, query string, arg any) (*poker.APIToken, error) {
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api token: %w", err)
+	}
+
+	var token = new(poker.APIToken)
+
+	err = json.Unmarshal(data, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode api token record: %w", err)
+	}
+
+	return token, nil
+
+}
+
+func (r *APITokenRepository) APITokensByUserID(ctx context.Context, userID string) ([]*poker.APIToken, error) {
+
+	rows, err := r.db.QueryContext(ctx, "SELECT data FROM api_tokens WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api tokens by user id: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*poker.APIToken
+	for rows.Next() {
+		var data []byte
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token record: %w", err)
+		}
+
+		var token = new(poker.APIToken)
+		err = json.Unmarshal(data, token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode api token record: %w", err)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+
+}
+
+func (r *APITokenRepository) SaveAPIToken(ctx context.Context, token *poker.APIToken) error {
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api token: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO api_tokens (id, user_id, hash, data, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		token.ID, token.UserID, token.Hash, data, token.CreatedAt,
+	)
+
+	return err
+
+}
+
+func (r *APITokenRepository) DeleteAPIToken(ctx context.Context, id string) error {
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM api_tokens WHERE id = ?", id)
+
+	return err
+
+}
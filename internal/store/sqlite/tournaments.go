@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"poker"
+	"time"
+)
+
+var _ poker.TournamentRepository = (*TournamentRepository)(nil)
+
+type TournamentRepository struct {
+	db *sql.DB
+}
+
+func NewTournamentRepository(db *sql.DB) *TournamentRepository {
+	return &TournamentRepository{
+		db: db,
+	}
+}
+
+func (r *TournamentRepository) Tournament(ctx context.Context, id string) (*poker.Tournament, error) {
+	return r.tournament(ctx, "SELECT data FROM tournaments WHERE id = ?", id)
+}
+
+func (r *TournamentRepository) TournamentByTimerID(ctx context.Context, timerID string) (*poker.Tournament, error) {
+	return r.tournament(ctx, "SELECT data FROM tournaments WHERE timer_id = ? ORDER BY created_at DESC LIMIT 1", timerID)
+}
+
+func (r *TournamentRepository) tournament(ctx context.Context, query string, arg any) (*poker.Tournament, error) {
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tournament: %w", err)
+	}
+
+	var tournament = new(poker.Tournament)
+
+	err = json.Unmarshal(data, tournament)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tournament record: %w", err)
+	}
+
+	return tournament, nil
+
+}
+
+func (r *TournamentRepository) TournamentsByUserID(ctx context.Context, userID string) ([]*poker.Tournament, error) {
+
+	rows, err := r.db.QueryContext(ctx, "SELECT data FROM tournaments WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tournaments by user id: %w", err)
+	}
+	defer rows.Close()
+
+	var tournaments []*poker.Tournament
+	for rows.Next() {
+		var data []byte
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tournament record: %w", err)
+		}
+
+		var tournament = new(poker.Tournament)
+		err = json.Unmarshal(data, tournament)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tournament record: %w", err)
+		}
+
+		tournaments = append(tournaments, tournament)
+	}
+
+	return tournaments, rows.Err()
+
+}
+
+func (r *TournamentRepository) SaveTournament(ctx context.Context, tournament *poker.Tournament) error {
+
+	now := time.Now()
+	if tournament.CreatedAt.IsZero() {
+		tournament.CreatedAt = now
+	}
+	tournament.UpdatedAt = now
+
+	version := tournament.Version
+	tournament.Version = version + 1
+
+	data, err := json.Marshal(tournament)
+	if err != nil {
+		tournament.Version = version
+		return fmt.Errorf("failed to marshal tournament: %w", err)
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO tournaments (id, user_id, timer_id, data, version, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, timer_id = excluded.timer_id, data = excluded.data, version = excluded.version, updated_at = excluded.updated_at
+		WHERE tournaments.version = ?`,
+		tournament.ID, tournament.UserID, tournament.TimerID, data, tournament.Version, tournament.CreatedAt, tournament.UpdatedAt, version,
+	)
+	if err != nil {
+		tournament.Version = version
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tournament.Version = version
+		return fmt.Errorf("failed to check tournament was saved: %w", err)
+	}
+
+	if affected == 0 {
+		tournament.Version = version
+		return &poker.ConflictError{Resource: "tournament", ID: tournament.ID}
+	}
+
+	return nil
+
+}
+
+func (r *TournamentRepository) DeleteTournament(ctx context.Context, id string) error {
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM tournaments WHERE id = ?", id)
+
+	return err
+
+}
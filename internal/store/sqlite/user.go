@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"poker"
+)
+
+var _ poker.UserRepository = (*UserRepository)(nil)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+func (r *UserRepository) User(ctx context.Context, id string) (*poker.User, error) {
+	return r.user(ctx, "SELECT data FROM users WHERE id = ?", id)
+}
+
+func (r *UserRepository) UserByEmail(ctx context.Context, email string) (*poker.User, error) {
+	return r.user(ctx, "SELECT data FROM users WHERE email = ?", email)
+}
+
+func (r *UserRepository) user(ctx context.Context, query string, arg any) (*poker.User, error) {
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	var user = new(poker.User)
+
+	err = json.Unmarshal(data, user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode user record: %w", err)
+	}
+
+	return user, nil
+
+}
+
+func (r *UserRepository) SaveUser(ctx context.Context, user *poker.User) error {
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO users (id, email, data) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET email = excluded.email, data = excluded.data`,
+		user.ID, user.Email, data,
+	)
+
+	return err
+
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id = ?", id)
+
+	return err
+
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"poker"
+)
+
+var _ poker.WebhookRepository = (*WebhookRepository)(nil)
+
+// webhookDeliveryLimit is how many deliveries are kept for each webhook
+const webhookDeliveryLimit = 50
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookRepository) Webhook(ctx context.Context, id string) (*poker.Webhook, error) {
+
+	var data []byte
+	err := r.db.QueryRowContext(ctx, "SELECT data FROM webhooks WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook: %w", err)
+	}
+
+	var webhook = new(poker.Webhook)
+
+	err = json.Unmarshal(data, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode webhook record: %w", err)
+	}
+
+	return webhook, nil
+
+}
+
+func (r *WebhookRepository) WebhooksByTimerID(ctx context.Context, timerID string) ([]*poker.Webhook, error) {
+
+	rows, err := r.db.QueryContext(ctx, "SELECT data FROM webhooks WHERE timer_id = ? ORDER BY created_at", timerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks by timer id: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*poker.Webhook
+	for rows.Next() {
+		var data []byte
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook record: %w", err)
+		}
+
+		var webhook = new(poker.Webhook)
+		err = json.Unmarshal(data, webhook)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode webhook record: %w", err)
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+
+}
+
+func (r *WebhookRepository) SaveWebhook(ctx context.Context, webhook *poker.Webhook) error {
+
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO webhooks (id, timer_id, data, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		webhook.ID, webhook.TimerID, data, webhook.CreatedAt,
+	)
+
+	return err
+
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+
+	// Deliveries are removed along with the webhook by their foreign key
+	_, err := r.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+
+	return err
+
+}
+
+func (r *WebhookRepository) WebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*poker.WebhookDelivery, error) {
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT data FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at DESC LIMIT ?",
+		webhookID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*poker.WebhookDelivery
+	for rows.Next() {
+		var data []byte
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery record: %w", err)
+		}
+
+		var delivery = new(poker.WebhookDelivery)
+		err = json.Unmarshal(data, delivery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode webhook delivery record: %w", err)
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+
+}
+
+func (r *WebhookRepository) SaveWebhookDelivery(ctx context.Context, delivery *poker.WebhookDelivery) error {
+
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook delivery: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO webhook_deliveries (id, webhook_id, data, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		delivery.ID, delivery.WebhookID, data, delivery.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Only the most recent deliveries are kept
+	_, err = r.db.ExecContext(
+		ctx,
+		`DELETE FROM webhook_deliveries WHERE webhook_id = ? AND id NOT IN (
+			SELECT id FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at DESC LIMIT ?
+		)`,
+		delivery.WebhookID, delivery.WebhookID, webhookDeliveryLimit,
+	)
+
+	return err
+
+}
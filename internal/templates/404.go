@@ -56,3 +56,18 @@ func (s *Service) ResourceUnavailable(ctx context.Context) g.Node {
 		),
 	)
 }
+
+// TimerConflict is swapped in place of whatever a request was updating when the timer was saved by
+// someone else first, asking the user to reload rather than overwrite their change
+func (s *Service) TimerConflict(_ context.Context, err error) g.Node {
+	return Div(
+		Class("alert alert-warning d-flex align-items-center justify-content-between"),
+		Strong(g.Text(err.Error())),
+		Button(
+			Type("button"),
+			Class("btn btn-warning ms-3"),
+			g.Attr("onclick", "window.location.reload()"),
+			g.Text("Reload"),
+		),
+	)
+}
@@ -0,0 +1,206 @@
+package templates
+
+import (
+	"context"
+	"poker"
+	"strings"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+// chipSetBlankRows is how many empty rows the chip set form has for adding denominations
+const chipSetBlankRows = 2
+
+type DashboardTimerChipSetProps struct {
+	Timer *poker.Timer
+	// ChipSet is the chip set shown in the form, which is the one submitted when it has errors
+	ChipSet *poker.ChipSet
+	Errors  []string
+}
+
+// DashboardTimerChipSetComponent edits the chip set attached to a timer and lists the color ups it leads to
+func (s *Service) DashboardTimerChipSetComponent(ctx context.Context, props *DashboardTimerChipSetProps) g.Node {
+
+	timer, chipSet := props.Timer, props.ChipSet
+
+	var route = s.buildRoute("dashboard-timer-chips", "timerID", timer.ID)
+
+	var name string
+	var chips []*poker.Chip
+	if chipSet != nil {
+		name, chips = chipSet.Name, chipSet.Chips
+	}
+
+	blank := chipSetBlankRows
+	if len(chips) == 0 {
+		blank = 5
+	}
+
+	rows := make([]g.Node, 0, len(chips)+blank)
+	for _, chip := range chips {
+		rows = append(rows, chipSetRow(chip))
+	}
+	for i := 0; i < blank; i++ {
+		rows = append(rows, chipSetRow(nil))
+	}
+
+	return Div(
+		ID("chip-set-container"),
+		Class("row mt-4"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("card"),
+				Div(
+					Class("card-header text-center"),
+					g.Text("Chip Set"),
+				),
+				Div(
+					Class("card-body"),
+					s.renderErrorAlert(props.Errors),
+					g.If(
+						timer.ChipSet == nil,
+						P(
+							Class("text-center"),
+							g.Text("Add the chips you play with to check every blind can be made and get told when to color up"),
+						),
+					),
+					FormEl(
+						htmx.Post(route), htmx.Target("#chip-set-container"), htmx.Swap("outerHTML"),
+						Div(
+							Class("mb-3"),
+							Label(Class("form-label"), g.Text("Name")),
+							Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("Name"), Value(name)),
+						),
+						Table(
+							Class("table table-sm"),
+							THead(
+								Tr(
+									Th(g.Text("Color")),
+									Th(g.Text("Denomination")),
+									Th(g.Text("Count")),
+								),
+							),
+							TBody(rows...),
+						),
+						Div(
+							Class("d-flex justify-content-center"),
+							Button(Type("submit"), Class("btn btn-sm btn-primary"), g.Text("Save Chip Set")),
+							g.If(
+								timer.ChipSet != nil,
+								A(
+									Class("btn btn-sm btn-outline-primary ms-2"),
+									Href(s.buildRoute("dashboard-timer-chips-distribution", "timerID", timer.ID)),
+									g.Text("Starting Stacks"),
+								),
+							),
+							g.If(
+								timer.ChipSet != nil,
+								Button(
+									Type("button"), Class("btn btn-sm btn-danger ms-2"),
+									htmx.Delete(route), htmx.Target("#chip-set-container"), htmx.Swap("outerHTML"),
+									g.Attr("hx-confirm", "Remove the chip set from this timer?"),
+									g.Text("Remove Chip Set"),
+								),
+							),
+						),
+					),
+					s.chipSetColorUps(timer),
+				),
+			),
+		),
+	)
+
+}
+
+func chipSetRow(chip *poker.Chip) g.Node {
+
+	var color, denomination, count string
+	if chip != nil {
+		color = chip.Color
+		if chip.Denomination != 0 {
+			denomination = format(chip.Denomination)
+		}
+		if chip.Count != 0 {
+			count = format(chip.Count)
+		}
+	}
+
+	return Tr(
+		Td(Input(Class("form-control form-control-sm"), Type("text"), AutoComplete("off"), Name("Color"), Value(color))),
+		Td(Input(Class("form-control form-control-sm"), Type("number"), Name("Denomination"), Min("0"), Step("any"), Value(denomination))),
+		Td(Input(Class("form-control form-control-sm"), Type("number"), Name("Count"), Min("0"), Step("1"), Value(count))),
+	)
+
+}
+
+// chipSetColorUps lists the breaks of the timer where chips are colored up
+func (s *Service) chipSetColorUps(timer *poker.Timer) g.Node {
+
+	if timer.ChipSet == nil {
+		return nil
+	}
+
+	colorUps := timer.ChipSet.ColorUps(timer.Levels)
+
+	items := make([]g.Node, 0, len(colorUps))
+	for i, level := range timer.Levels {
+		chips, ok := colorUps[level.ID]
+		if !ok {
+			continue
+		}
+
+		items = append(items, Li(
+			Class("list-group-item"),
+			Strong(g.Textf("Break at level %d: ", i+1)),
+			g.Textf("color up %s", formatChips(chips)),
+		))
+	}
+
+	if len(items) == 0 {
+		return P(Class("text-center text-body-secondary mt-3 mb-0"), g.Text("No chips need coloring up at any break"))
+	}
+
+	return Ul(Class("list-group mt-3"), g.Group(items))
+
+}
+
+// formatChips lists chips such as "white 25, red 100"
+func formatChips(chips []*poker.Chip) string {
+
+	names := make([]string, 0, len(chips))
+	for _, chip := range chips {
+		names = append(names, chip.String())
+	}
+
+	return strings.Join(names, ", ")
+
+}
+
+// formatColorUps tells the table which chips to color up when the current level is a break that has them
+func (s *Service) formatColorUps(_ context.Context, timer *poker.Timer, level *poker.TimerLevel) g.Node {
+
+	if level == nil || level.Type != poker.LevelTypeBreak {
+		return nil
+	}
+
+	chips := timer.ColorUpsAt(level.ID)
+	if len(chips) == 0 {
+		return nil
+	}
+
+	return Div(
+		Class("row mt-3"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("alert alert-warning text-center fs-4"),
+				I(Class("fa-solid fa-coins me-2")),
+				g.Textf("Color up the %s chips", formatChips(chips)),
+			),
+		),
+	)
+
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"poker"
+	"poker/internal"
+	"strconv"
+	"time"
 
 	g "github.com/maragudk/gomponents"
 	htmx "github.com/maragudk/gomponents-htmx"
@@ -46,6 +49,8 @@ func (s *Service) dashboardUserMenuComponent(ctx context.Context) g.Node {
 			Class("list-group"),
 			A(Href(s.buildRoute("dashboard")), Class("list-group-item list-group-item-action"), g.Text("Dashboard")),
 			A(Href(s.buildRoute("dashboard-timers")), Class("list-group-item list-group-item-action"), g.Text("My Timers")),
+			A(Href(s.buildRoute("dashboard-tournaments")), Class("list-group-item list-group-item-action"), g.Text("My Tournaments")),
+			A(Href(s.buildRoute("dashboard-tokens")), Class("list-group-item list-group-item-action"), g.Text("API Tokens")),
 		),
 	})
 }
@@ -81,6 +86,8 @@ func (s *Service) dashboardUserCallout(ctx context.Context, user *poker.User) g.
 type DashboardTimersProps struct {
 	User   *poker.User
 	Timers []*poker.Timer
+	// Shared are the timers other users have shared with the user, including invitations not yet accepted
+	Shared []*poker.Timer
 }
 
 func (s *Service) DashboardTimers(ctx context.Context, props *DashboardTimersProps) g.Node {
@@ -101,7 +108,7 @@ func (s *Service) DashboardTimers(ctx context.Context, props *DashboardTimersPro
 						),
 						Div(
 							Class("col-9"),
-							s.DashboardTimersFragment(ctx, props.Timers),
+							s.DashboardTimersFragment(ctx, props),
 						),
 					),
 				),
@@ -113,11 +120,14 @@ func (s *Service) DashboardTimers(ctx context.Context, props *DashboardTimersPro
 
 }
 
-func (s *Service) DashboardTimersFragment(ctx context.Context, timers []*poker.Timer) g.Node {
-	return s.dashboardTimersFragment(ctx, timers)
+func (s *Service) DashboardTimersFragment(ctx context.Context, props *DashboardTimersProps) g.Node {
+	return s.dashboardTimersFragment(ctx, props)
 }
 
-func (s *Service) dashboardTimersFragment(ctx context.Context, timers []*poker.Timer) g.Node {
+func (s *Service) dashboardTimersFragment(ctx context.Context, props *DashboardTimersProps) g.Node {
+
+	timers := props.Timers
+
 	return Div(
 		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
 		Div(
@@ -159,25 +169,106 @@ func (s *Service) dashboardTimersFragment(ctx context.Context, timers []*poker.T
 							Div(
 								Class("d-flex justify-content-center mt-2"),
 								Button(
-									Class("btn btn-primary"), htmx.Get(s.buildRoute("dashboard-timers-new")), htmx.Target("#dashboard-section"),
+									Class("btn btn-primary me-2"), htmx.Get(s.buildRoute("dashboard-timers-new")), htmx.Target("#dashboard-section"),
 									g.Text("Create New Timer"),
 								),
+								Button(
+									Class("btn btn-outline-primary me-2"), htmx.Get(s.buildRoute("dashboard-timers-templates")), htmx.Target("#dashboard-section"),
+									g.Text("From a Template"),
+								),
+								Button(
+									Class("btn btn-outline-primary me-2"), htmx.Get(s.buildRoute("dashboard-timers-generate")), htmx.Target("#dashboard-section"),
+									g.Text("Generate Structure"),
+								),
+								Button(
+									Class("btn btn-outline-primary"), htmx.Get(s.buildRoute("dashboard-timers-import")), htmx.Target("#dashboard-section"),
+									g.Text("Import Timer"),
+								),
 							),
 						),
 					),
 				),
 			),
 		),
+		s.dashboardSharedTimers(ctx, props.Shared),
+	)
+}
+
+// dashboardSharedTimers lists the invitations waiting to be answered and the timers other users have shared
+func (s *Service) dashboardSharedTimers(ctx context.Context, timers []*poker.Timer) g.Node {
+
+	user := internal.UserFromContext(ctx)
+	if user == nil || len(timers) == 0 {
+		return nil
+	}
+
+	invitations := make([]g.Node, 0)
+	shared := make([]g.Node, 0)
+	for _, timer := range timers {
+		share := timer.Share(user.ID)
+		if share == nil {
+			continue
+		}
+
+		if !share.IsAccepted() {
+			invitations = append(invitations, s.dashboardTimerInvitationItem(ctx, timer, share))
+			continue
+		}
+
+		shared = append(shared, s.dashboardTimerListItemFragment(ctx, timer))
+	}
+
+	section := func(title string, items []g.Node) g.Node {
+		if len(items) == 0 {
+			return nil
+		}
+
+		return Div(
+			Class("row mb-3"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text(title)),
+				Hr(),
+				Div(Class("list-group"), g.Group(items)),
+			),
+		)
+	}
+
+	return group(
+		section("Invitations", invitations),
+		section("Shared With Me", shared),
 	)
+
 }
 
 func (s *Service) dashboardTimerListItemFragment(ctx context.Context, timer *poker.Timer) g.Node {
 
+	role := timerRole(ctx, timer)
+
+	// The owner deletes the timer, anyone it was shared with can only leave it
+	remove := Button(
+		Class("btn btn-sm btn-danger"), Type("button"), g.Attr("hx-delete", s.buildRoute("dashboard-timer", "timerID", timer.ID)),
+		g.Attr("hx-confirm", "Are you sure you want to delete this timer?"),
+		I(Class("fa-solid fa-trash")),
+	)
+	if role != poker.TimerRoleOwner {
+		remove = Button(
+			Class("btn btn-sm btn-outline-danger"), Type("button"), TitleAttr("Leave"),
+			htmx.Delete(s.buildRoute("dashboard-timer-invitation", "timerID", timer.ID)),
+			g.Attr("hx-confirm", "You will lose access to this timer, continue?"),
+			I(Class("fa-solid fa-right-from-bracket")),
+		)
+	}
+
 	return Div(
 		Class("list-group-item"),
 		Div(
 			Class("d-flex justify-content-between"),
-			Div(g.Text(timer.Name)),
+			Div(
+				g.Text(timer.Name),
+				g.If(timer.IsTemplate && role == poker.TimerRoleOwner, Span(Class("badge text-bg-warning ms-2"), g.Text("Template"))),
+				g.If(role != poker.TimerRoleOwner, Span(Class("badge text-bg-secondary ms-2"), g.Text(timerRoleLabel(role)))),
+			),
 			Div(
 				Div(
 					Class("btn-group"), Role("group"),
@@ -189,11 +280,7 @@ func (s *Service) dashboardTimerListItemFragment(ctx context.Context, timer *pok
 						Class("btn btn-sm btn-info"), Href(s.buildRoute("dashboard-timer", "timerID", timer.ID)),
 						I(Class("fa-solid fa-pencil")),
 					),
-					Button(
-						Class("btn btn-sm btn-danger"), Type("button"), g.Attr("hx-delete", s.buildRoute("dashboard-timer", "timerID", timer.ID)),
-						g.Attr("hx-confirm", "Are you sure you want to delete this timer?"),
-						I(Class("fa-solid fa-trash")),
-					),
+					remove,
 				),
 			),
 		),
@@ -314,9 +401,18 @@ func (s *Service) DashboardTimerFragment(ctx context.Context, timer *poker.Timer
 
 func (s *Service) dashboardTimer(ctx context.Context, timer *poker.Timer) g.Node {
 
+	// Controls the user's role doesn't allow are left off rather than shown to fail
+	role := timerRole(ctx, timer)
+	canEdit := role.Includes(poker.TimerRoleEditor)
+
 	levelNodes := make([]g.Node, 0, len(timer.Levels))
 	for idx, level := range timer.Levels {
-		levelNodes = append(levelNodes, s.dashboardTimerLevelComponent(ctx, idx+1, level))
+		levelNodes = append(levelNodes, s.dashboardTimerLevelComponent(ctx, idx+1, level, canEdit))
+	}
+
+	play := "Start Timer"
+	if !role.Includes(poker.TimerRoleOperator) {
+		play = "Watch Timer"
 	}
 
 	return Div(
@@ -333,59 +429,109 @@ func (s *Service) dashboardTimer(ctx context.Context, timer *poker.Timer) g.Node
 			Class("row mb-2"),
 			Div(
 				Class("col"),
-				Table(
-
-					ID("levels-table"),
-					Class("table table-bordered"),
-					THead(
-						Class("table-secondary"),
-						Tr(
-							Th(
-								g.Text("#")),
-							Th(
-								Width("20%"),
-								Class("text-center"),
-								g.Text("Small Blind")),
-							Th(
-								Width("20%"),
-								Class("text-center"),
-								g.Text("Big Blind")),
-							Th(
-								Width("20%"),
-								Class("text-center"),
-								g.Text("Ante")),
-							Th(
-								Width("20%"),
-								Class("text-center"),
-								g.Text("Duration (minutes)"),
+				FormEl(
+					g.If(canEdit, htmx.Post(s.buildRoute("dashboard-timer-levels-reorder", "timerID", timer.ID))),
+					g.If(canEdit, htmx.Trigger("end")),
+					// The level buttons are inside the form, so every change they make carries the version too
+					g.If(canEdit, timerVersionInput(timer.Version)),
+					Table(
+
+						ID("levels-table"),
+						Class("table table-bordered"),
+						THead(
+							Class("table-secondary"),
+							Tr(
+								Th(
+									g.Text("#")),
+								Th(
+									Width("20%"),
+									Class("text-center"),
+									g.Text("Small Blind")),
+								Th(
+									Width("20%"),
+									Class("text-center"),
+									g.Text("Big Blind")),
+								Th(
+									Width("20%"),
+									Class("text-center"),
+									g.Text("Ante")),
+								Th(
+									Width("20%"),
+									Class("text-center"),
+									g.Text("Duration (minutes)"),
+								),
+								Th(),
 							),
-							Th(),
 						),
+						TBody(g.If(canEdit, Class("sortable")), g.Group(levelNodes)),
 					),
-					TBody(levelNodes...),
 				),
 			),
 		),
+		g.If(
+			canEdit,
+			group(
+				Div(
+					ID("modify-container"),
+					Class("row"),
+					Div(
+						Class("col-6 offset-3"),
+						Div(
+							Class("d-flex justify-content-around"),
+							Button(
+								Class("btn btn-primary btn-sm"),
+								htmx.Get(fmt.Sprintf("%s?type=%s", s.buildRoute("dashboard-timer-levels", "timerID", timer.ID), "blind")),
+								htmx.Target("#modify-container"),
+								htmx.Swap("outerHTML"),
+								g.Text("Add Blind"),
+							),
+							Button(
+								Class("btn btn-primary btn-sm"),
+								htmx.Get(fmt.Sprintf("%s?type=%s", s.buildRoute("dashboard-timer-levels", "timerID", timer.ID), "break")),
+								htmx.Target("#modify-container"),
+								g.Text("Add Break"),
+							),
+							Button(
+								Class("btn btn-outline-primary btn-sm"),
+								htmx.Get(fmt.Sprintf("%s?timerID=%s", s.buildRoute("dashboard-timers-generate"), timer.ID)),
+								htmx.Target("#dashboard-section"),
+								g.Text("Generate Levels"),
+							),
+						),
+					),
+				),
+				s.DashboardTimerLevelsBulkComponent(ctx, &DashboardTimerLevelsBulkProps{
+					TimerID: timer.ID,
+					Version: timer.Version,
+					Edit:    &poker.LevelBulkEdit{},
+				}),
+			),
+		),
 		Div(
-			ID("modify-container"),
-			Class("row"),
+			Class("row mt-3"),
+			Div(
+				Class("col-6 offset-3"),
+				Div(
+					Class("d-flex justify-content-around"),
+					A(Href(s.buildRoute("play-timer", "timerID", timer.ID)), Class("btn btn-sm btn-success"), g.Text(play)),
+					s.timerExportButtons(ctx, timer),
+				),
+			),
+		),
+		Div(
+			Class("row mt-3"),
 			Div(
 				Class("col-6 offset-3"),
 				Div(
 					Class("d-flex justify-content-around"),
 					Button(
-						Class("btn btn-primary btn-sm"),
-						htmx.Get(fmt.Sprintf("%s?type=%s", s.buildRoute("dashboard-timer-levels", "timerID", timer.ID), "blind")),
-						htmx.Target("#modify-container"),
-						htmx.Swap("outerHTML"),
-						g.Text("Add Blind"),
-					),
-					Button(
-						Class("btn btn-primary btn-sm"),
-						htmx.Get(fmt.Sprintf("%s?type=%s", s.buildRoute("dashboard-timer-levels", "timerID", timer.ID), "break")),
-						htmx.Target("#modify-container"),
-						g.Text("Add Break"),
+						Class("btn btn-sm btn-outline-primary"), Type("button"),
+						htmx.Post(s.buildRoute("dashboard-timer-duplicate", "timerID", timer.ID)),
+						htmx.Target("#dashboard-section"),
+						I(Class("fa-solid fa-clone me-1")),
+						g.Text("Duplicate Timer"),
 					),
+					g.If(role == poker.TimerRoleOwner, s.DashboardTimerTemplateComponent(ctx, timer)),
 				),
 			),
 		),
@@ -395,30 +541,127 @@ func (s *Service) dashboardTimer(ctx context.Context, timer *poker.Timer) g.Node
 				Class("col-6 offset-3"),
 				Div(
 					Class("d-flex justify-content-around"),
-					A(Href(s.buildRoute("play-timer", "timerID", timer.ID)), Class("btn btn-sm btn-success"), g.Text("Start Timer")),
+					A(
+						Class("btn btn-sm btn-outline-secondary"), Target("_blank"),
+						Href(s.buildRoute("dashboard-timer-sheet", "timerID", timer.ID)),
+						I(Class("fa-solid fa-print me-1")),
+						g.Text("Print Blind Sheet"),
+					),
+					A(
+						Class("btn btn-sm btn-outline-secondary"),
+						Href(s.buildRoute("dashboard-timer-sheet-pdf", "timerID", timer.ID)),
+						I(Class("fa-solid fa-file-pdf me-1")),
+						g.Text("Download PDF"),
+					),
+				),
+			),
+		),
+		s.DashboardTimerScheduleComponent(ctx, &DashboardTimerScheduleProps{
+			Timer:    timer,
+			Schedule: timer.Schedule(time.Now()),
+		}),
+		g.If(canEdit, s.DashboardTimerChipSetComponent(ctx, &DashboardTimerChipSetProps{
+			Timer:   timer,
+			ChipSet: timer.ChipSet,
+		})),
+		g.If(role == poker.TimerRoleOwner, s.DashboardTimerDisplayComponent(ctx, timer)),
+		g.If(role == poker.TimerRoleOwner, s.DashboardTimerSharingComponent(ctx, &DashboardTimerSharingProps{Timer: timer})),
+		g.If(role == poker.TimerRoleOwner, s.DashboardTimerWebhooksPlaceholder(ctx, timer)),
+	)
+}
+
+// DashboardTimerDisplayComponent renders the public display link for a timer along with
+// the buttons to share, regenerate or revoke it
+func (s *Service) DashboardTimerDisplayComponent(ctx context.Context, timer *poker.Timer) g.Node {
+
+	var route = s.buildRoute("dashboard-timer-display", "timerID", timer.ID)
+
+	var body g.Node
+	if timer.DisplayToken == "" {
+		body = group(
+			P(
+				Class("text-center"),
+				g.Text("Share a read only link so players can follow the blinds on their own phones"),
+			),
+			Div(
+				Class("d-flex justify-content-center"),
+				Button(
+					Class("btn btn-sm btn-primary"), Type("button"),
+					htmx.Post(route), htmx.Target("#display-link-container"), htmx.Swap("outerHTML"),
+					g.Text("Create Display Link"),
+				),
+			),
+		)
+	} else {
+		var link = s.appURL + s.buildRoute("display-timer", "timerID", timer.ID, "token", timer.DisplayToken)
+		body = group(
+			Div(
+				Class("input-group mb-2"),
+				Input(
+					Class("form-control"), Type("text"), ReadOnly(), Value(link),
+				),
+				A(
+					Class("btn btn-outline-secondary"), Href(link), Target("_blank"),
+					I(Class("fa-solid fa-arrow-up-right-from-square")),
+				),
+			),
+			Div(
+				Class("d-flex justify-content-center"),
+				Button(
+					Class("btn btn-sm btn-warning me-2"), Type("button"),
+					htmx.Post(route), htmx.Target("#display-link-container"), htmx.Swap("outerHTML"),
+					g.Attr("hx-confirm", "Anyone using the current link will lose access, continue?"),
+					g.Text("Regenerate Link"),
+				),
+				Button(
+					Class("btn btn-sm btn-danger"), Type("button"),
+					htmx.Delete(route), htmx.Target("#display-link-container"), htmx.Swap("outerHTML"),
+					g.Attr("hx-confirm", "Anyone using the current link will lose access, continue?"),
+					g.Text("Revoke Link"),
+				),
+			),
+		)
+	}
+
+	return Div(
+		ID("display-link-container"),
+		Class("row mt-4"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("card"),
+				Div(
+					Class("card-header text-center"),
+					g.Text("Public Display"),
+				),
+				Div(
+					Class("card-body"),
+					body,
 				),
 			),
 		),
 	)
+
 }
 
-func (s *Service) dashboardTimerLevelComponent(ctx context.Context, idx int, level *poker.TimerLevel) g.Node {
+// dashboardTimerLevelComponent is a row of the levels table, with the buttons to change the level when editable
+func (s *Service) dashboardTimerLevelComponent(ctx context.Context, idx int, level *poker.TimerLevel, editable bool) g.Node {
 
 	return Tr(
 		g.If(
 			level.Type == "blind",
 			group(
-				Td(g.Textf("%v", idx)),
+				s.dashboardTimerLevelPosition(idx, level, editable),
 				Td(g.Textf("%v", level.SmallBlind)),
 				Td(g.Textf("%v", level.BigBlind)),
-				Td(g.Textf("%v", level.Ante)),
+				Td(g.Text(dashboardLevelAnte(level))),
 				Td(g.Textf("%v", level.DurationMin)),
 			),
 		),
 		g.If(
 			level.Type == "break",
 			group(
-				Td(g.Textf("%v", idx)),
+				s.dashboardTimerLevelPosition(idx, level, editable),
 				Td(
 					ColSpan("3"), Class("text-center"),
 					Strong(Em(g.Text("BREAK!"))),
@@ -426,17 +669,173 @@ func (s *Service) dashboardTimerLevelComponent(ctx context.Context, idx int, lev
 				Td(g.Textf("%v", level.DurationMin)),
 			),
 		),
-		Td(
-			Button(
-				htmx.Get(s.buildRoute("dashboard-timer-level", "timerID", level.TimerID, "levelID", level.ID)),
-				htmx.Target("#modify-container"),
-				Class("btn btn-primary me-2"),
-				I(Class("fa-solid fa-pencil")),
-			),
-			Button(
-				htmx.Delete(s.buildRoute("dashboard-timer-level", "timerID", level.TimerID, "levelID", level.ID)),
-				Class("btn btn-danger"),
-				I(Class("fa-solid fa-trash")),
+		g.If(
+			editable,
+			Td(
+				Class("text-nowrap"),
+				Button(
+					Type("button"),
+					htmx.Post(s.buildRoute("dashboard-timer-level-action", "timerID", level.TimerID, "levelID", level.ID, "action", "up")),
+					Class("btn btn-sm btn-outline-secondary me-1"), TitleAttr("Move up"),
+					I(Class("fa-solid fa-arrow-up")),
+				),
+				Button(
+					Type("button"),
+					htmx.Post(s.buildRoute("dashboard-timer-level-action", "timerID", level.TimerID, "levelID", level.ID, "action", "down")),
+					Class("btn btn-sm btn-outline-secondary me-2"), TitleAttr("Move down"),
+					I(Class("fa-solid fa-arrow-down")),
+				),
+				Button(
+					Type("button"),
+					htmx.Post(s.buildRoute("dashboard-timer-level-action", "timerID", level.TimerID, "levelID", level.ID, "action", "duplicate")),
+					Class("btn btn-sm btn-outline-primary me-2"), TitleAttr("Duplicate"),
+					I(Class("fa-solid fa-clone")),
+				),
+				Button(
+					Type("button"),
+					htmx.Get(s.buildRoute("dashboard-timer-level", "timerID", level.TimerID, "levelID", level.ID)),
+					htmx.Target("#modify-container"),
+					Class("btn btn-sm btn-primary me-2"),
+					I(Class("fa-solid fa-pencil")),
+				),
+				Button(
+					Type("button"),
+					htmx.Delete(s.buildRoute("dashboard-timer-level", "timerID", level.TimerID, "levelID", level.ID)),
+					Class("btn btn-sm btn-danger"),
+					I(Class("fa-solid fa-trash")),
+				),
+			),
+		),
+	)
+
+}
+
+// dashboardTimerLevelPosition is the level's number along with the handle used to drag it to a new position. The
+// hidden id is posted in table order when the levels are reordered
+func (s *Service) dashboardTimerLevelPosition(idx int, level *poker.TimerLevel, editable bool) g.Node {
+	return Td(
+		Class("text-nowrap"),
+		g.If(editable, I(Class("fa-solid fa-grip-vertical drag-handle text-secondary me-2"), StyleAttr("cursor: grab"))),
+		g.Textf("%v", idx),
+		Input(Type("hidden"), Name("LevelID"), Value(level.ID)),
+	)
+}
+
+type DashboardTimerLevelsBulkProps struct {
+	TimerID string
+	// Version is the version of the timer the form is rendered from
+	Version uint
+	Edit    *poker.LevelBulkEdit
+	Errors  []string
+}
+
+// DashboardTimerLevelsBulkComponent changes a range of levels at once, fields left empty are not changed
+func (s *Service) DashboardTimerLevelsBulkComponent(ctx context.Context, props *DashboardTimerLevelsBulkProps) g.Node {
+
+	edit := props.Edit
+
+	// Zero means "not set" for every field, so it is shown as empty
+	value := func(v float64) g.Node {
+		if v == 0 {
+			return nil
+		}
+		return Value(format(v))
+	}
+
+	field := func(label, name string, v float64, step string) g.Node {
+		return Div(
+			Class("col"),
+			Label(Class("form-label"), g.Text(label)),
+			Input(Class("form-control"), Type("number"), Name(name), Min("0"), Step(step), value(v)),
+		)
+	}
+
+	return Div(
+		ID("bulk-edit-container"),
+		Class("row mt-4"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("card"),
+				Div(
+					Class("card-header text-center"),
+					g.Text("Bulk Edit Levels"),
+				),
+				Div(
+					Class("card-body"),
+					s.renderErrorAlert(props.Errors),
+					FormEl(
+						htmx.Post(s.buildRoute("dashboard-timer-levels-bulk", "timerID", props.TimerID)),
+						htmx.Target("#bulk-edit-container"), htmx.Swap("outerHTML"),
+						timerVersionInput(props.Version),
+						Div(
+							Class("row mb-3"),
+							field("From Level", "FromLevel", float64(edit.FromLevel), "1"),
+							field("To Level", "ToLevel", float64(edit.ToLevel), "1"),
+						),
+						Div(
+							Class("row mb-3"),
+							field("Multiply Blinds By", "BlindMultiplier", edit.BlindMultiplier, "any"),
+							field("Level Duration (minutes)", "DurationMin", edit.DurationMin, "any"),
+							field("Break Duration (minutes)", "BreakDurationMin", edit.BreakDurationMin, "any"),
+						),
+						P(
+							Class("form-text"),
+							g.Text("Leave the levels empty to start at the first or carry on to the last. Empty fields are left as they are."),
+						),
+						Div(
+							Class("d-flex justify-content-center"),
+							Button(Type("submit"), Class("btn btn-sm btn-primary"), g.Text("Apply")),
+						),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+// dashboardLevelAnte is the ante column of the levels table, big blind antes are marked with BB
+func dashboardLevelAnte(level *poker.TimerLevel) string {
+
+	switch level.EffectiveAnteType() {
+	case poker.AnteTypeTraditional:
+		return format(level.Ante)
+	case poker.AnteTypeBigBlind:
+		return fmt.Sprintf("%v (BB)", level.Ante)
+	}
+
+	return "-"
+
+}
+
+// dashboardLevelAnteInputs are the ante type and amount fields of the blind level forms
+func (s *Service) dashboardLevelAnteInputs(anteType poker.AnteType, ante float64) g.Node {
+
+	option := func(value poker.AnteType, label string) g.Node {
+		return Option(Value(value.String()), g.If(value == anteType, Selected()), g.Text(label))
+	}
+
+	return group(
+		Div(
+			Class("col-12"),
+			Div(
+				Label(g.Text("Ante Type")),
+				Select(
+					Class("form-select"), Name("AnteType"),
+					option(poker.AnteTypeNone, "No Ante"),
+					option(poker.AnteTypeTraditional, "Every Player"),
+					option(poker.AnteTypeBigBlind, "Big Blind Ante"),
+				),
+			),
+		),
+		Div(
+			Class("col-12"),
+			Div(
+				Label(g.Text("Ante")),
+				Input(
+					Class("form-control"), Type("number"), Name("Ante"), Min("0"), Value(format(ante)),
+				),
 			),
 		),
 	)
@@ -494,15 +893,7 @@ func (s *Service) DashboardNewTimerLevelComponent(ctx context.Context, props *Da
 											),
 										),
 									),
-									// Div(
-									// 	Class("col-12"),
-									// 	Div(
-									// 		Label(g.Text("Small Blind")),
-									// 		Input(
-									// 			Class("form-control"), Type("number"), Name("Ante"), Placeholder("Ante"),
-									// 		),
-									// 	),
-									// ),
+									s.dashboardLevelAnteInputs(poker.AnteTypeNone, 0),
 									Div(
 										Class("col-12"),
 										Div(
@@ -531,6 +922,18 @@ func (s *Service) DashboardNewTimerLevelComponent(ctx context.Context, props *Da
 								),
 							),
 						),
+						dashboardLevelEventInputs(nil),
+						Div(
+							Class("row justify-content-center mt-2"),
+							Div(
+								Class("col-lg-4"),
+								Label(g.Text("Position")),
+								Input(
+									Class("form-control"), Type("number"), Name("Position"), Min("1"), Step("1"),
+									Placeholder("End of the timer"),
+								),
+							),
+						),
 						Div(
 							Class("row"),
 							Div(
@@ -559,18 +962,20 @@ func (s *Service) DashboardNewTimerLevelComponent(ctx context.Context, props *Da
 
 }
 
-func NewDashboardEditTimerLevelProps(level *poker.TimerLevel, errors []string) *DashboardEditTimerLevelProps {
-	return &DashboardEditTimerLevelProps{level, errors}
+func NewDashboardEditTimerLevelProps(level *poker.TimerLevel, version uint, errors []string) *DashboardEditTimerLevelProps {
+	return &DashboardEditTimerLevelProps{level, version, errors}
 }
 
 type DashboardEditTimerLevelProps struct {
-	level  *poker.TimerLevel
-	errors []string
+	level *poker.TimerLevel
+	// version is the version of the timer the form was rendered from
+	version uint
+	errors  []string
 }
 
 func (s *Service) DashboardEditTimerLevelComponent(ctx context.Context, props *DashboardEditTimerLevelProps) g.Node {
 
-	level, errors := props.level, props.errors
+	level, version, errors := props.level, props.version, props.errors
 
 	return Div(
 		Class("row"),
@@ -610,6 +1015,7 @@ func (s *Service) DashboardEditTimerLevelComponent(ctx context.Context, props *D
 											Class("form-control"), Type("number"), Name("BigBlind"), Value(format(level.BigBlind)),
 										),
 									),
+									s.dashboardLevelAnteInputs(level.EffectiveAnteType(), level.Ante),
 
 									Div(
 										Class("col-12"),
@@ -635,6 +1041,7 @@ func (s *Service) DashboardEditTimerLevelComponent(ctx context.Context, props *D
 								),
 							),
 						),
+						dashboardLevelEventInputs(level),
 						Div(
 							Class("row"),
 							Div(
@@ -642,6 +1049,7 @@ func (s *Service) DashboardEditTimerLevelComponent(ctx context.Context, props *D
 								Input(
 									Type("hidden"), Name("Type"), Value(level.Type.String()),
 								),
+								timerVersionInput(version),
 								Button(
 									Type("submit"),
 									Class("btn btn-sm btn-primary mt-3 text-capitalize"),
@@ -663,6 +1071,12 @@ func (s *Service) DashboardEditTimerLevelComponent(ctx context.Context, props *D
 
 }
 
+// timerVersionInput carries the version of the timer a form was rendered from, so saving it over a newer copy
+// is refused rather than overwriting what was saved since
+func timerVersionInput(version uint) g.Node {
+	return Input(Type("hidden"), Name("Version"), Value(strconv.FormatUint(uint64(version), 10)))
+}
+
 func group(nodes ...g.Node) g.Node {
 	return g.Group(nodes)
 }
@@ -0,0 +1,256 @@
+package templates
+
+import (
+	"context"
+	"math"
+	"poker"
+	"strconv"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardTournamentDealProps struct {
+	User       *poker.User
+	Tournament *poker.Tournament
+	// Players, Prizes and HeldBack are what the deal is worked out from, they fill in the form
+	Players  []*poker.DealPlayer
+	Prizes   []float64
+	HeldBack float64
+	// Deal is nil until the form has been submitted without errors
+	Deal   *poker.Deal
+	Errors []string
+}
+
+func (s *Service) DashboardTournamentDeal(ctx context.Context, props *DashboardTournamentDealProps) g.Node {
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				s.gnavbar(ctx),
+				Div(
+					Class("container"),
+					s.dashboardUserCallout(ctx, props.User),
+					Div(
+						Class("row"),
+						Div(
+							Class("col-3"),
+							s.dashboardUserMenuComponent(ctx),
+						),
+						Div(
+							Class("col-9"),
+							s.DashboardTournamentDealFragment(ctx, props),
+						),
+					),
+				),
+				s.gbottom(),
+			),
+		),
+	)
+}
+
+func (s *Service) DashboardTournamentDealFragment(ctx context.Context, props *DashboardTournamentDealProps) g.Node {
+
+	tournament := props.Tournament
+
+	formatInput := func(value float64) string {
+		if value == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	playerRows := make([]g.Node, 0, len(props.Players))
+	for _, player := range props.Players {
+		playerRows = append(playerRows, Tr(
+			Td(Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("PlayerName"), Value(player.Name))),
+			Td(Input(Class("form-control"), Type("number"), Step("1"), Min("0"), Name("Chips"), Value(formatInput(player.Chips)))),
+		))
+	}
+
+	prizeInputs := make([]g.Node, 0, len(props.Prizes))
+	for i, prize := range props.Prizes {
+		prizeInputs = append(prizeInputs, Div(
+			Class("col-3 mb-2"),
+			Label(Class("form-label"), g.Text(ordinal(uint(i+1)))),
+			Input(Class("form-control"), Type("number"), Step("0.01"), Min("0"), Name("Prize"), Value(formatInput(prize))),
+		))
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Textf("%s Deal", tournament.Name)),
+				P(
+					Class("text-center"),
+					A(Href(s.buildRoute("dashboard-tournament", "tournamentID", tournament.ID)), g.Text("Back to the tournament")),
+				),
+				Hr(),
+			),
+		),
+		s.renderErrorAlert(props.Errors),
+		FormEl(
+			Method("post"),
+			Div(
+				Class("row mb-3"),
+				Div(
+					Class("col"),
+					Table(
+						Class("table align-middle"),
+						THead(
+							Tr(
+								Th(g.Text("Player")),
+								Th(g.Text("Chips")),
+							),
+						),
+						TBody(playerRows...),
+					),
+				),
+			),
+			H6(g.Text("Prizes Still To Be Won")),
+			Div(append([]g.Node{Class("row mb-3")}, prizeInputs...)...),
+			Div(
+				Class("row mb-3"),
+				Div(
+					Class("col-4"),
+					Label(Class("form-label"), g.Text("Held Back For The Winner")),
+					Input(Class("form-control"), Type("number"), Step("0.01"), Min("0"), Name("HeldBack"), Value(formatInput(props.HeldBack))),
+				),
+			),
+			P(
+				Class("form-text"),
+				g.Text("The amount held back comes out of first place and is left for the players to play for."),
+			),
+			Div(
+				Class("d-flex justify-content-center gap-2"),
+				Button(
+					Type("button"), Class("btn btn-primary"),
+					htmx.Post(s.buildRoute("dashboard-tournament-deal", "tournamentID", tournament.ID)),
+					htmx.Target("#dashboard-section"),
+					g.Text("Calculate Deal"),
+				),
+				g.If(
+					props.Deal != nil,
+					Button(
+						Type("submit"), Class("btn btn-outline-secondary"),
+						g.Attr("formaction", s.buildRoute("dashboard-tournament-deal-print", "tournamentID", tournament.ID)),
+						g.Attr("formtarget", "_blank"),
+						I(Class("fa-solid fa-print me-1")),
+						g.Text("Print Deal"),
+					),
+				),
+			),
+		),
+		s.tournamentDealTable(ctx, props.Deal),
+	)
+
+}
+
+// DashboardTournamentDealPrint is the agreed deal on a page of its own, without the navigation, to be printed
+// and signed at the table
+func (s *Service) DashboardTournamentDealPrint(ctx context.Context, props *DashboardTournamentDealProps) g.Node {
+
+	signatures := make([]g.Node, 0)
+	if props.Deal != nil {
+		for _, share := range props.Deal.Shares {
+			signatures = append(signatures, Div(
+				Class("col-6 mt-5"),
+				Div(Class("border-bottom")),
+				Small(g.Text(share.Name)),
+			))
+		}
+	}
+
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				Div(
+					Class("container mt-4"),
+					H3(Class("text-center"), g.Textf("%s Deal", props.Tournament.Name)),
+					P(Class("text-center text-body-secondary"), g.Text("Each player signs against the split that was agreed")),
+					s.renderErrorAlert(props.Errors),
+					s.tournamentDealTable(ctx, props.Deal),
+					Div(append([]g.Node{Class("row")}, signatures...)...),
+					Div(
+						Class("d-flex justify-content-center mt-4 d-print-none"),
+						Button(
+							Type("button"), Class("btn btn-primary"),
+							g.Attr("onclick", "window.print()"),
+							I(Class("fa-solid fa-print me-1")),
+							g.Text("Print"),
+						),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+// tournamentDealTable compares what each player takes under ICM and a straight chip chop
+func (s *Service) tournamentDealTable(_ context.Context, deal *poker.Deal) g.Node {
+
+	if deal == nil {
+		return nil
+	}
+
+	rows := make([]g.Node, 0, len(deal.Shares))
+	for _, share := range deal.Shares {
+		rows = append(rows, Tr(
+			Td(g.Text(share.Name)),
+			Td(Class("text-end"), g.Text(strconv.FormatFloat(share.Chips, 'f', -1, 64))),
+			Td(Class("text-end"), g.Textf("%.1f%%", share.ChipPercent)),
+			Td(Class("text-end"), g.Text(formatAmount(roundCents(share.ICM)))),
+			Td(Class("text-end"), g.Text(formatAmount(roundCents(share.ChipChop)))),
+		))
+	}
+
+	var heldBack g.Node
+	if deal.HeldBack > 0 {
+		heldBack = P(
+			Class("text-center"),
+			g.Textf("%s is held back and goes to the winner.", formatAmount(deal.HeldBack)),
+		)
+	}
+
+	return Div(
+		Class("row mt-4"),
+		Div(
+			Class("col"),
+			Table(
+				Class("table table-bordered align-middle"),
+				THead(
+					Class("table-secondary"),
+					Tr(
+						Th(g.Text("Player")),
+						Th(Class("text-end"), g.Text("Chips")),
+						Th(Class("text-end"), g.Text("Chip Share")),
+						Th(Class("text-end"), g.Text("ICM")),
+						Th(Class("text-end"), g.Text("Chip Chop")),
+					),
+				),
+				TBody(rows...),
+				TFoot(
+					Tr(
+						Th(ColSpan("3"), g.Text("Dealt")),
+						Th(Class("text-end"), g.Text(formatAmount(roundCents(deal.Dealt)))),
+						Th(Class("text-end"), g.Text(formatAmount(roundCents(deal.Dealt)))),
+					),
+				),
+			),
+			heldBack,
+		),
+	)
+
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,249 @@
+package templates
+
+import (
+	"context"
+	"poker"
+	"strconv"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardTimerChipsDistributionProps struct {
+	User  *poker.User
+	Timer *poker.Timer
+	// Players and StartingStack are what the distribution is worked out from, they fill in the form
+	Players       uint
+	StartingStack float64
+	// Distribution is nil when the chips couldn't be split between the players
+	Distribution *poker.ChipDistribution
+	Errors       []string
+}
+
+func (s *Service) DashboardTimerChipsDistribution(ctx context.Context, props *DashboardTimerChipsDistributionProps) g.Node {
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				s.gnavbar(ctx),
+				Div(
+					Class("container"),
+					s.dashboardUserCallout(ctx, props.User),
+					Div(
+						Class("row"),
+						Div(
+							Class("col-3"),
+							s.dashboardUserMenuComponent(ctx),
+						),
+						Div(
+							Class("col-9"),
+							s.DashboardTimerChipsDistributionFragment(ctx, props),
+						),
+					),
+				),
+				s.gbottom(),
+			),
+		),
+	)
+}
+
+func (s *Service) DashboardTimerChipsDistributionFragment(ctx context.Context, props *DashboardTimerChipsDistributionProps) g.Node {
+
+	timer := props.Timer
+
+	var players, stack string
+	if props.Players > 0 {
+		players = format(props.Players)
+	}
+	if props.StartingStack > 0 {
+		stack = strconv.FormatFloat(props.StartingStack, 'f', -1, 64)
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Textf("%s Starting Stacks", timer.Name)),
+				P(
+					Class("text-center"),
+					A(Href(s.buildRoute("dashboard-timer", "timerID", timer.ID)), g.Text("Back to the timer")),
+				),
+				Hr(),
+			),
+		),
+		s.renderErrorAlert(props.Errors),
+		FormEl(
+			Method("post"),
+			Div(
+				Class("row mb-3"),
+				Div(
+					Class("col"),
+					Label(Class("form-label"), g.Text("Players")),
+					Input(Class("form-control"), Type("number"), Step("1"), Min("1"), Name("Players"), Value(players)),
+				),
+				Div(
+					Class("col"),
+					Label(Class("form-label"), g.Text("Starting Stack")),
+					Input(Class("form-control"), Type("number"), Step("any"), Min("0"), Name("StartingStack"), Value(stack)),
+				),
+			),
+			Div(
+				Class("d-flex justify-content-center gap-2"),
+				Button(
+					Type("button"), Class("btn btn-primary"),
+					htmx.Post(s.buildRoute("dashboard-timer-chips-distribution", "timerID", timer.ID)),
+					htmx.Target("#dashboard-section"),
+					g.Text("Calculate Stacks"),
+				),
+				g.If(
+					props.Distribution != nil,
+					Button(
+						Type("submit"), Class("btn btn-outline-secondary"),
+						g.Attr("formaction", s.buildRoute("dashboard-timer-chips-distribution-print", "timerID", timer.ID)),
+						g.Attr("formtarget", "_blank"),
+						I(Class("fa-solid fa-print me-1")),
+						g.Text("Print Card"),
+					),
+				),
+			),
+		),
+		s.chipDistributionTables(ctx, props.Distribution),
+	)
+
+}
+
+// DashboardTimerChipsDistributionPrint is the starting stack on a card of its own, without the navigation, to be
+// printed and left with the chips while they are counted out
+func (s *Service) DashboardTimerChipsDistributionPrint(ctx context.Context, props *DashboardTimerChipsDistributionProps) g.Node {
+
+	var card g.Node
+	if props.Distribution != nil {
+		card = Div(
+			Class("card mx-auto"), StyleAttr("max-width: 28rem"),
+			Div(
+				Class("card-body"),
+				H4(Class("card-title text-center"), g.Text(props.Timer.Name)),
+				P(
+					Class("text-center"),
+					g.Textf("Starting stack of %s for each of %d players", formatAmount(props.Distribution.StartingStack), props.Distribution.Players),
+				),
+				chipCountTable(props.Distribution.Stack, "Each Player"),
+			),
+		)
+	}
+
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				Div(
+					Class("container mt-4"),
+					s.renderErrorAlert(props.Errors),
+					card,
+					Div(
+						Class("d-flex justify-content-center mt-4 d-print-none"),
+						Button(
+							Type("button"), Class("btn btn-primary"),
+							g.Attr("onclick", "window.print()"),
+							I(Class("fa-solid fa-print me-1")),
+							g.Text("Print"),
+						),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+// chipDistributionTables show each player's stack alongside what is left over for rebuys and add-ons
+func (s *Service) chipDistributionTables(_ context.Context, distribution *poker.ChipDistribution) g.Node {
+
+	if distribution == nil {
+		return nil
+	}
+
+	var warnings g.Node
+	if len(distribution.Warnings) > 0 {
+		items := make([]g.Node, 0, len(distribution.Warnings))
+		for _, warning := range distribution.Warnings {
+			items = append(items, Li(g.Text(warning)))
+		}
+		warnings = Div(
+			Class("alert alert-warning"),
+			Ul(Class("mb-0"), g.Group(items)),
+		)
+	}
+
+	return Div(
+		Class("row mt-4"),
+		Div(
+			Class("col"),
+			warnings,
+			Div(
+				Class("row"),
+				Div(
+					Class("col"),
+					chipCountTable(distribution.Stack, "Each Player"),
+				),
+				Div(
+					Class("col"),
+					chipCountTable(distribution.Reserve, "Left Over"),
+				),
+			),
+			P(
+				Class("text-center"),
+				g.Textf(
+					"%s of chips are left over, enough for %d more starting stacks for rebuys and add-ons.",
+					formatAmount(distribution.ReserveValue), distribution.ExtraStacks,
+				),
+			),
+		),
+	)
+
+}
+
+func chipCountTable(counts []*poker.ChipCount, heading string) g.Node {
+
+	var total float64
+	var chips uint
+	rows := make([]g.Node, 0, len(counts))
+	for _, count := range counts {
+		total += count.Value()
+		chips += count.Count
+		rows = append(rows, Tr(
+			Td(g.Text(count.Chip.Color)),
+			Td(Class("text-end"), g.Text(formatAmount(count.Chip.Denomination))),
+			Td(Class("text-end"), g.Text(format(count.Count))),
+			Td(Class("text-end"), g.Text(formatAmount(count.Value()))),
+		))
+	}
+
+	return Table(
+		Class("table table-bordered align-middle"),
+		THead(
+			Class("table-secondary"),
+			Tr(Th(ColSpan("4"), Class("text-center"), g.Text(heading))),
+			Tr(
+				Th(g.Text("Color")),
+				Th(Class("text-end"), g.Text("Denomination")),
+				Th(Class("text-end"), g.Text("Chips")),
+				Th(Class("text-end"), g.Text("Value")),
+			),
+		),
+		TBody(rows...),
+		TFoot(
+			Tr(
+				Th(ColSpan("2"), g.Text("Total")),
+				Th(Class("text-end"), g.Text(format(chips))),
+				Th(Class("text-end"), g.Text(formatAmount(total))),
+			),
+		),
+	)
+
+}
@@ -1,7 +1,73 @@
 package templates
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"poker"
+	"strconv"
+)
 
 func format(a any) string {
 	return fmt.Sprintf("%v", a)
 }
+
+// formatAmount renders an amount of money, leaving off the cents when there aren't any
+func formatAmount(amount float64) string {
+	if amount == math.Trunc(amount) {
+		return strconv.FormatFloat(amount, 'f', 0, 64)
+	}
+
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// ordinal renders a finishing position such as 1st or 22nd
+func ordinal(n uint) string {
+
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+
+	return fmt.Sprintf("%d%s", n, suffix)
+
+}
+
+// formatMinutes renders a number of minutes as hours and minutes such as 3h 40m
+func formatMinutes(minutes float64) string {
+
+	total := int(math.Round(minutes))
+	hours, mins := total/60, total%60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", mins)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, mins)
+
+}
+
+// formatAnte renders a blind level's ante such as "Ante 25" or "BB Ante 200", empty when the level has no ante
+func formatAnte(level *poker.TimerLevel) string {
+
+	if level.Type != poker.LevelTypeBlind {
+		return ""
+	}
+
+	switch level.EffectiveAnteType() {
+	case poker.AnteTypeTraditional:
+		return fmt.Sprintf("Ante %.0f", level.Ante)
+	case poker.AnteTypeBigBlind:
+		return fmt.Sprintf("BB Ante %.0f", level.Ante)
+	}
+
+	return ""
+
+}
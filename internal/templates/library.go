@@ -0,0 +1,128 @@
+package templates
+
+import (
+	"context"
+	"poker"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardTimerTemplatesProps struct {
+	Builtin  []*poker.BuiltinStructure
+	Personal []*poker.Timer
+	Errors   []string
+}
+
+func (s *Service) DashboardTimerTemplatesComponent(ctx context.Context, props *DashboardTimerTemplatesProps) g.Node {
+
+	builtin := make([]g.Node, 0, len(props.Builtin))
+	for _, structure := range props.Builtin {
+		builtin = append(builtin, s.timerTemplateCard(structure.Timer, structure.Description, "builtin", structure.Slug))
+	}
+
+	personal := make([]g.Node, 0, len(props.Personal))
+	for _, timer := range props.Personal {
+		personal = append(personal, s.timerTemplateCard(timer, "", "timer", timer.ID))
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text("New Timer From a Template")),
+				Hr(),
+				s.renderErrorAlert(props.Errors),
+			),
+		),
+		H6(g.Text("Built-in Structures")),
+		Div(
+			Class("row row-cols-1 row-cols-lg-2 g-3 mb-4"),
+			g.Group(builtin),
+		),
+		H6(g.Text("My Templates")),
+		g.If(
+			len(personal) > 0,
+			Div(
+				Class("row row-cols-1 row-cols-lg-2 g-3 mb-4"),
+				g.Group(personal),
+			),
+		),
+		g.If(
+			len(personal) == 0,
+			Div(
+				Class("alert alert-info text-center"),
+				g.Text("You don't have any templates. Open one of your timers and click Save as Template to add it here"),
+			),
+		),
+	)
+
+}
+
+// timerTemplateCard summarises a template and holds the form that copies it into a new timer
+func (s *Service) timerTemplateCard(timer *poker.Timer, description, source, id string) g.Node {
+
+	var totalMin float64
+	for _, level := range timer.Levels {
+		totalMin += level.DurationMin
+	}
+
+	return Div(
+		Class("col"),
+		Div(
+			Class("card h-100"),
+			Div(
+				Class("card-body d-flex flex-column"),
+				H6(Class("card-title"), g.Text(timer.Name)),
+				g.If(description != "", P(Class("card-text"), g.Text(description))),
+				P(
+					Class("card-text text-body-secondary"),
+					g.Textf("%d levels, %s of play in total", len(timer.Levels), formatMinutes(totalMin)),
+				),
+				FormEl(
+					Class("mt-auto"),
+					htmx.Post(s.buildRoute("dashboard-timers-templates")), htmx.Target("#dashboard-section"),
+					Input(Type("hidden"), Name("Source"), Value(source)),
+					Input(Type("hidden"), Name("ID"), Value(id)),
+					Div(
+						Class("input-group input-group-sm"),
+						Input(
+							Class("form-control"), Type("text"), AutoComplete("off"), Name("Name"),
+							Placeholder(timer.Name),
+						),
+						Button(Type("submit"), Class("btn btn-primary"), g.Text("Use Template")),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+// DashboardTimerTemplateComponent toggles whether the timer is one of its owner's templates
+func (s *Service) DashboardTimerTemplateComponent(_ context.Context, timer *poker.Timer) g.Node {
+
+	var route = s.buildRoute("dashboard-timer-template", "timerID", timer.ID)
+
+	if timer.IsTemplate {
+		return Button(
+			ID("timer-template-button"),
+			Class("btn btn-sm btn-outline-warning"), Type("button"),
+			htmx.Delete(route), htmx.Target("#timer-template-button"), htmx.Swap("outerHTML"),
+			I(Class("fa-solid fa-star me-1")),
+			g.Text("Remove Template"),
+		)
+	}
+
+	return Button(
+		ID("timer-template-button"),
+		Class("btn btn-sm btn-outline-secondary"), Type("button"),
+		htmx.Post(route), htmx.Target("#timer-template-button"), htmx.Swap("outerHTML"),
+		I(Class("fa-regular fa-star me-1")),
+		g.Text("Save as Template"),
+	)
+
+}
@@ -0,0 +1,150 @@
+package templates
+
+import (
+	"context"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+)
+
+// LoginProps says which sign in methods are enabled, each one gets its own section of the page
+type LoginProps struct {
+	OIDC      bool
+	Password  bool
+	MagicLink bool
+	// Email is kept in the forms after an error
+	Email  string
+	Errors []string
+	// Notice is shown once a sign in or registration link has been sent
+	Notice string
+}
+
+// Login offers every enabled way of signing in
+func (s *Service) Login(ctx context.Context, props *LoginProps) g.Node {
+
+	sections := make([]g.Node, 0, 3)
+
+	if props.OIDC {
+		sections = append(sections, Div(
+			Class("d-grid"),
+			A(Class("btn btn-primary"), Href(s.buildRoute("login-oidc")), g.Text("Sign in with Single Sign-On")),
+		))
+	}
+
+	if props.Password {
+		sections = append(sections, FormEl(
+			Method("post"), Action(s.buildRoute("login-password")),
+			Div(
+				Class("mb-3"),
+				Label(Class("form-label"), For("login-password-email"), g.Text("Email")),
+				Input(ID("login-password-email"), Class("form-control"), Type("email"), Name("Email"), AutoComplete("username"), Required(), Value(props.Email)),
+			),
+			Div(
+				Class("mb-3"),
+				Label(Class("form-label"), For("login-password-password"), g.Text("Password")),
+				Input(ID("login-password-password"), Class("form-control"), Type("password"), Name("Password"), AutoComplete("current-password"), Required()),
+			),
+			Div(
+				Class("d-flex justify-content-between align-items-center"),
+				Button(Type("submit"), Class("btn btn-primary"), g.Text("Sign In")),
+				A(Href(s.buildRoute("register")), g.Text("Create an account")),
+			),
+		))
+	}
+
+	if props.MagicLink {
+		sections = append(sections, FormEl(
+			Method("post"), Action(s.buildRoute("login-magic-link")),
+			P(Class("text-body-secondary"), g.Text("Or get a link to sign in emailed to you")),
+			Div(
+				Class("input-group"),
+				Input(Class("form-control"), Type("email"), Name("Email"), AutoComplete("email"), Required(), Placeholder("you@example.com"), Value(props.Email)),
+				Button(Type("submit"), Class("btn btn-outline-primary"), g.Text("Email Me a Link")),
+			),
+		))
+	}
+
+	// Sections are divided by a rule rather than each having a card of its own
+	body := make([]g.Node, 0, len(sections)*2)
+	for i, section := range sections {
+		if i > 0 {
+			body = append(body, Hr(Class("my-4")))
+		}
+		body = append(body, section)
+	}
+
+	var notice g.Node
+	if props.Notice != "" {
+		notice = Div(Class("alert alert-success"), g.Text(props.Notice))
+	}
+
+	return s.authPage(ctx, "Sign In", group(s.renderErrorAlert(props.Errors), notice, g.Group(body)))
+
+}
+
+type RegisterProps struct {
+	// Name and Email are kept in the form after an error, the password never is
+	Name   string
+	Email  string
+	Errors []string
+}
+
+// Register creates an account that signs in with a password
+func (s *Service) Register(ctx context.Context, props *RegisterProps) g.Node {
+
+	return s.authPage(ctx, "Create an Account", group(
+		s.renderErrorAlert(props.Errors),
+		FormEl(
+			Method("post"), Action(s.buildRoute("register")),
+			Div(
+				Class("mb-3"),
+				Label(Class("form-label"), For("register-name"), g.Text("Name")),
+				Input(ID("register-name"), Class("form-control"), Type("text"), Name("Name"), AutoComplete("name"), Required(), Value(props.Name)),
+			),
+			Div(
+				Class("mb-3"),
+				Label(Class("form-label"), For("register-email"), g.Text("Email")),
+				Input(ID("register-email"), Class("form-control"), Type("email"), Name("Email"), AutoComplete("username"), Required(), Value(props.Email)),
+			),
+			Div(
+				Class("mb-3"),
+				Label(Class("form-label"), For("register-password"), g.Text("Password")),
+				Input(ID("register-password"), Class("form-control"), Type("password"), Name("Password"), AutoComplete("new-password"), Required()),
+			),
+			Div(
+				Class("d-flex justify-content-between align-items-center"),
+				Button(Type("submit"), Class("btn btn-primary"), g.Text("Create Account")),
+				A(Href(s.buildRoute("login")), g.Text("I already have an account")),
+			),
+		),
+	))
+
+}
+
+// authPage is a narrow card in the middle of the page, used for signing in and creating an account
+func (s *Service) authPage(ctx context.Context, title string, body g.Node) g.Node {
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				s.gnavbar(ctx),
+				Div(
+					Class("container"),
+					Div(
+						Class("row mt-5"),
+						Div(
+							Class("col-md-6 offset-md-3 col-lg-4 offset-lg-4"),
+							Div(
+								Class("card"),
+								Div(Class("card-header text-center"), g.Text(title)),
+								Div(Class("card-body"), body),
+							),
+						),
+					),
+				),
+				s.gbottom(),
+			),
+		),
+	)
+}
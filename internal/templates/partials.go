@@ -134,6 +134,68 @@ func (s *Service) gbottom() g.Node {
 			g.Attr("integrity", "sha384-HwwvtgBNo3bZJJLYd8oVXjrBZt8cqVSpeBNS5n7C8IVInixGAoxmnlMuBnhbgrkm"),
 			g.Attr("crossorigin", "anonymous"),
 		),
+		Script(
+			Src("https://cdn.jsdelivr.net/npm/sortablejs@1.15.0/Sortable.min.js"),
+		),
+		s.gsortable(),
+		s.glocaltime(),
+		s.gcountdowns(),
 		s.ghtmxDebug(),
 	})
 }
+
+// gsortable makes the rows of any .sortable element draggable by their .drag-handle, including content swapped in
+// by htmx. The element's form is submitted on the end event so the new order can be saved
+func (s *Service) gsortable() g.Node {
+	return Script(
+		g.Raw(`
+htmx.onLoad(function (content) {
+	content.querySelectorAll(".sortable").forEach(function (el) {
+		new Sortable(el, { animation: 150, handle: ".drag-handle" });
+	});
+});
+		`),
+	)
+}
+
+// glocaltime rewrites times rendered by the server, which only knows them in UTC, into the browser's own time zone.
+// time elements with data-local-time get their text replaced and inputs with data-local-value are filled in
+func (s *Service) glocaltime() g.Node {
+	return Script(
+		g.Raw(`
+htmx.onLoad(function (content) {
+	content.querySelectorAll("time[data-local-time]").forEach(function (el) {
+		el.textContent = new Date(el.dateTime).toLocaleTimeString([], { hour: "numeric", minute: "2-digit" });
+	});
+	content.querySelectorAll("input[data-local-value]").forEach(function (el) {
+		var date = new Date(el.dataset.localValue);
+		date.setMinutes(date.getMinutes() - date.getTimezoneOffset());
+		el.value = date.toISOString().slice(0, 16);
+	});
+});
+		`),
+	)
+}
+
+// gcountdowns keeps elements with data-countdown-sec counting down while data-countdown-running is true. The
+// seconds left are measured from when the element was loaded so the browser's clock doesn't need to match the
+// server's
+func (s *Service) gcountdowns() g.Node {
+	return Script(
+		g.Raw(`
+htmx.onLoad(function (content) {
+	content.querySelectorAll("[data-countdown-sec]").forEach(function (el) {
+		el.dataset.countdownEnd = Date.now() + parseInt(el.dataset.countdownSec, 10) * 1000;
+	});
+});
+setInterval(function () {
+	document.querySelectorAll("[data-countdown-end][data-countdown-running=true]").forEach(function (el) {
+		var left = Math.max(0, Math.round((parseInt(el.dataset.countdownEnd, 10) - Date.now()) / 1000));
+		var hours = Math.floor(left / 3600), minutes = Math.floor(left / 60) % 60, seconds = left % 60;
+		var pad = function (n) { return String(n).padStart(2, "0"); };
+		el.textContent = hours > 0 ? hours + ":" + pad(minutes) + ":" + pad(seconds) : minutes + ":" + pad(seconds);
+	});
+}, 1000);
+		`),
+	)
+}
@@ -13,20 +13,33 @@ import (
 
 type PlayProps struct {
 	User         *poker.User
-	Timer        *poker.Timer
-	Level        *poker.TimerLevel
+	Masthead     *MastheadProps
 	CurrentLevel uint
+	// ClientID identifies this page to the server so it is not sent back its own changes
+	ClientID string
 }
 
 func (s *Service) Play(ctx context.Context, props *PlayProps) g.Node {
 
+	timer, displayToken := props.Masthead.Timer, props.Masthead.DisplayToken
+
+	eventsURI := s.buildRoute("play-timer-events", "timerID", timer.ID)
+	mastheadURI := s.buildRoute("play-timer-masthead", "timerID", timer.ID)
+	if displayToken != "" {
+		eventsURI = s.buildRoute("display-timer-events", "timerID", timer.ID, "token", displayToken)
+		mastheadURI = s.buildRoute("display-timer-masthead", "timerID", timer.ID, "token", displayToken)
+	}
+
 	return Doctype(
 		HTML(
 			Lang("en"),
 			s.gtop(ctx),
 			Body(
+				g.Attr("hx-headers", fmt.Sprintf(`{"X-Poker-Client": %q}`, props.ClientID)),
+				DataAttr("events-uri", fmt.Sprintf("%s?client=%s", eventsURI, props.ClientID)),
+				DataAttr("masthead-uri", mastheadURI),
 				s.gnavbar(ctx),
-				s.TimerMasthead(ctx, props.Timer, props.Level),
+				s.TimerMasthead(ctx, props.Masthead),
 				s.gbottom(),
 				Script(
 					Src(fmt.Sprintf("%s/js/countdown.js?v=%d", s.buildRoute("static"), time.Now().Unix())),
@@ -37,39 +50,44 @@ func (s *Service) Play(ctx context.Context, props *PlayProps) g.Node {
 
 }
 
-func (s *Service) TimerMasthead(ctx context.Context, timer *poker.Timer, level *poker.TimerLevel) g.Node {
+type MastheadProps struct {
+	Timer *poker.Timer
+	Level *poker.TimerLevel
+	// Tournament is the tournament being played on the timer, if there is one
+	Tournament *poker.Tournament
+	// Schedule is when the rest of the levels are expected to be played
+	Schedule *poker.Schedule
+	// DisplayToken is set when the masthead is being viewed through a public display link,
+	// in which case it is read only
+	DisplayToken string
+	// ReadOnly is set for a signed in user who may watch the timer but not run the clock
+	ReadOnly bool
+}
+
+func (s *Service) TimerMasthead(ctx context.Context, props *MastheadProps) g.Node {
+
+	timer, level, displayToken := props.Timer, props.Level, props.DisplayToken
+
+	var readOnly = displayToken != "" || props.ReadOnly
 
 	var nextLevel *poker.TimerLevel = nil
 	if int(timer.CurrentLevel+1) <= len(timer.Levels)-1 {
 		nextLevel = timer.Levels[timer.CurrentLevel+1]
 	}
 
+	// A display can't move the timer on itself, so rather than the audio and controls
+	// it is told where to ask the server for the state of the clock
+	var displayNode = s.timerAudio(level)
+	switch {
+	case displayToken != "":
+		displayNode = DataAttr("refresh-uri", s.buildRoute("display-timer-masthead", "timerID", timer.ID, "token", displayToken))
+	case readOnly:
+		displayNode = DataAttr("refresh-uri", s.buildRoute("play-timer-masthead", "timerID", timer.ID))
+	}
+
 	return Div(
 		ID("timer-container"), Class("container"), htmx.SwapOOB("true"),
-		Div(
-			Audio(
-				ID("audio-play"),
-				Source(
-					Src(s.buildRoute("dashboard-timer-level-audio", "timerID", level.TimerID, "levelID", level.ID, "action", "play")),
-					Type("audio/mpeg"),
-				),
-				// DataAttr("continue-audio", s.buildRoute("dashboard-timer-level-audio", "timerID", level.TimerID, "levelID", level.ID, "action", "contiue")),
-			),
-			Audio(
-				ID("audio-continue"),
-				Source(
-					Src(s.buildRoute("dashboard-timer-level-audio", "timerID", level.TimerID, "levelID", level.ID, "action", "continue")),
-					Type("audio/mpeg"),
-				),
-			),
-			Audio(
-				ID("audio-beep"),
-				Source(
-					Src("/static/audio/10_sec_beep_countdown.mp3"),
-					Type("audio/mpeg"),
-				),
-			),
-		),
+		displayNode,
 		Div(
 			Class("row"),
 			Div(
@@ -95,7 +113,10 @@ func (s *Service) TimerMasthead(ctx context.Context, timer *poker.Timer, level *
 							g.If(
 								!timer.IsComplete,
 								Div(
-									ID("timer"), Class("timer-large-font"), DataAttr("level-duration-sec", fmt.Sprintf("%v", level.DurationSec)),
+									ID("timer"), Class("timer-large-font"),
+									DataAttr("level-duration-sec", fmt.Sprintf("%v", level.DurationSec)),
+									DataAttr("level-remaining-sec", fmt.Sprintf("%.0f", timer.Remaining(time.Now()).Seconds())),
+									DataAttr("clock-running", fmt.Sprintf("%t", timer.IsRunning())),
 									g.Text(level.DurationStr),
 								),
 							),
@@ -113,12 +134,15 @@ func (s *Service) TimerMasthead(ctx context.Context, timer *poker.Timer, level *
 					),
 					Div(
 						Class("col-4"),
-						Div(
-							Class("row"),
-
+						g.If(
+							!readOnly,
 							Div(
-								Class("row mt-2"),
-								s.formatTimerButtons(ctx, timer, level),
+								Class("row"),
+
+								Div(
+									Class("row mt-2"),
+									s.formatTimerButtons(ctx, timer, level),
+								),
 							),
 						),
 					),
@@ -130,6 +154,89 @@ func (s *Service) TimerMasthead(ctx context.Context, timer *poker.Timer, level *
 						),
 					),
 				),
+				s.formatColorUps(ctx, timer, level),
+				s.formatSchedule(ctx, props.Schedule, timer.IsRunning()),
+				s.formatTournamentSummary(ctx, props.Tournament),
+				s.formatTournamentPayouts(ctx, props.Tournament),
+			),
+		),
+	)
+}
+
+// formatTournamentSummary shows the entrants and prize pool of the tournament being played on the timer
+func (s *Service) formatTournamentSummary(_ context.Context, tournament *poker.Tournament) g.Node {
+
+	if tournament == nil {
+		return nil
+	}
+
+	stat := func(header, value string) g.Node {
+		return Div(
+			Class("col text-center"),
+			H5(Class("text-body-secondary"), g.Text(header)),
+			H2(g.Text(value)),
+		)
+	}
+
+	stats := []g.Node{
+		Class("row mt-4 pt-3 border-top"),
+		ID("tournament-summary"),
+		stat("Players", fmt.Sprintf("%d / %d", tournament.Remaining(), tournament.Entrants())),
+	}
+
+	if tournament.RebuyAmount > 0 {
+		stats = append(stats, stat("Rebuys", format(tournament.TotalRebuys())))
+	}
+
+	if tournament.AddOnAmount > 0 {
+		stats = append(stats, stat("Add-ons", format(tournament.TotalAddOns())))
+	}
+
+	stats = append(stats, stat("Prize Pool", formatAmount(tournament.PrizePool())))
+
+	return Div(stats...)
+
+}
+
+// formatTournamentPayouts shows what each place is paid, filling in the players as they are eliminated
+func (s *Service) formatTournamentPayouts(ctx context.Context, tournament *poker.Tournament) g.Node {
+
+	if tournament == nil || tournament.Entrants() == 0 {
+		return nil
+	}
+
+	return Div(
+		Class("row mt-3"),
+		ID("tournament-payouts"),
+		Div(
+			Class("col-6 offset-3"),
+			H5(Class("text-center text-body-secondary"), g.Text("Payouts")),
+			s.tournamentPayoutsTable(ctx, tournament),
+		),
+	)
+
+}
+
+func (s *Service) timerAudio(level *poker.TimerLevel) g.Node {
+	return Div(
+		Audio(
+			ID("audio-play"),
+			Source(
+				Src(s.buildRoute("dashboard-timer-level-audio", "timerID", level.TimerID, "levelID", level.ID, "action", "play")),
+			),
+			// DataAttr("continue-audio", s.buildRoute("dashboard-timer-level-audio", "timerID", level.TimerID, "levelID", level.ID, "action", "contiue")),
+		),
+		Audio(
+			ID("audio-continue"),
+			Source(
+				Src(s.buildRoute("dashboard-timer-level-audio", "timerID", level.TimerID, "levelID", level.ID, "action", "continue")),
+			),
+		),
+		Audio(
+			ID("audio-beep"),
+			Source(
+				Src("/static/audio/10_sec_beep_countdown.mp3"),
+				Type("audio/mpeg"),
 			),
 		),
 	)
@@ -166,14 +273,35 @@ func (s *Service) formatTimerButtons(ctx context.Context, timer *poker.Timer, le
 		)
 	}
 
+	// The clock is owned by the server, so the toggle asks it to start, pause or resume
+	// rather than flipping the countdown locally
+	var toggle g.Node
+	switch {
+	case timer.IsRunning():
+		toggle = I(
+			ID("toggle-timer-button"),
+			Class("fa-solid fa-circle-stop fa-3x"),
+			htmx.Get(s.buildRoute("play-timer-clock-pause", "timerID", level.TimerID)),
+		)
+	case timer.IsPaused():
+		toggle = I(
+			ID("toggle-timer-button"),
+			Class("fa-solid fa-circle-play fa-3x"),
+			htmx.Get(s.buildRoute("play-timer-clock-resume", "timerID", level.TimerID)),
+		)
+	default:
+		toggle = I(
+			ID("toggle-timer-button"),
+			Class("fa-solid fa-circle-play fa-3x"),
+			htmx.Get(s.buildRoute("play-timer-clock-start", "timerID", level.TimerID)),
+		)
+	}
+
 	nodes = append(
 		nodes,
 		Div(
 			Class("col text-center"),
-			I(
-				ID("toggle-timer-button"),
-				Class("fa-solid fa-circle-play fa-3x"),
-			),
+			toggle,
 		),
 	)
 
@@ -213,6 +341,13 @@ func (s *Service) formatPlayLevelDisplay(ctx context.Context, header string, lev
 					level.Type == poker.LevelTypeBlind,
 					g.Textf("%.0f / %.0f", level.SmallBlind, level.BigBlind),
 				),
+				g.If(
+					formatAnte(level) != "",
+					group(
+						Br(),
+						Small(Class("fs-3 text-body-secondary"), g.Text(formatAnte(level))),
+					),
+				),
 				g.If(
 					level.Type == poker.LevelTypeBreak,
 					g.Text("Break"),
@@ -0,0 +1,295 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"poker"
+	"time"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardTimerScheduleProps struct {
+	Timer *poker.Timer
+	// Schedule is nil when the timer is complete or has no levels
+	Schedule *poker.Schedule
+	Errors   []string
+}
+
+// DashboardTimerScheduleComponent sets when play is planned to start and lists when each level, and the events
+// marked on them, are expected to be played
+func (s *Service) DashboardTimerScheduleComponent(ctx context.Context, props *DashboardTimerScheduleProps) g.Node {
+
+	timer, schedule := props.Timer, props.Schedule
+
+	var start g.Node
+	if timer.ScheduledStart != nil {
+		start = DataAttr("local-value", timer.ScheduledStart.UTC().Format(time.RFC3339))
+	}
+
+	return Div(
+		ID("timer-schedule-container"),
+		Class("row mt-4"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("card"),
+				Div(
+					Class("card-header text-center"),
+					g.Text("Schedule"),
+				),
+				Div(
+					Class("card-body"),
+					s.renderErrorAlert(props.Errors),
+					g.If(timerRole(ctx, timer).Includes(poker.TimerRoleOperator), FormEl(
+						htmx.Post(s.buildRoute("dashboard-timer-schedule", "timerID", timer.ID)),
+						htmx.Target("#timer-schedule-container"), htmx.Swap("outerHTML"),
+						// The browser's offset from UTC, so the start time can be read in the zone it was entered in
+						htmx.Vals(`js:{TimezoneOffset: new Date().getTimezoneOffset()}`),
+						Div(
+							Class("row align-items-end"),
+							Div(
+								Class("col"),
+								Label(Class("form-label"), g.Text("Planned Start")),
+								Input(Class("form-control"), Type("datetime-local"), Name("ScheduledStart"), start),
+							),
+							Div(
+								Class("col-auto"),
+								Button(Type("submit"), Class("btn btn-primary"), g.Text("Save Planned Start")),
+							),
+						),
+					)),
+					s.timerScheduleTable(ctx, schedule),
+				),
+			),
+		),
+	)
+
+}
+
+func (s *Service) timerScheduleTable(_ context.Context, schedule *poker.Schedule) g.Node {
+
+	if schedule == nil {
+		return nil
+	}
+
+	var description string
+	switch {
+	case schedule.IsLive:
+		description = "Projected from the clock, so it moves as levels are paused, skipped or changed"
+	case schedule.IsPlanned:
+		description = "Projected from the planned start"
+	default:
+		description = "Projected as if the timer were started now"
+	}
+
+	rows := make([]g.Node, 0, len(schedule.Levels))
+	for _, level := range schedule.Levels {
+		var class string
+		switch {
+		case level.IsCurrent:
+			class = "table-primary"
+		case level.IsPlayed:
+			class = "text-body-secondary"
+		case level.Level.Type == poker.LevelTypeBreak:
+			class = "table-secondary"
+		}
+
+		name := g.Textf("%d", level.Number)
+		if level.Level.Type == poker.LevelTypeBreak {
+			name = g.Textf("%d Break", level.Number)
+		}
+
+		rows = append(rows, Tr(
+			g.If(class != "", Class(class)),
+			Td(name, levelEventBadges(level.Level)),
+			Td(localTime(level.StartsAt)),
+			Td(localTime(level.EndsAt)),
+		))
+	}
+
+	return Div(
+		Class("mt-3"),
+		P(Class("text-center text-body-secondary small"), g.Text(description)),
+		Table(
+			Class("table table-sm"),
+			THead(
+				Tr(
+					Th(g.Text("Level")),
+					Th(g.Text("Starts")),
+					Th(g.Text("Ends")),
+				),
+			),
+			TBody(rows...),
+		),
+		P(Class("text-center mb-0"), g.Text("Play finishes around "), localTime(schedule.EndsAt)),
+	)
+
+}
+
+// formatSchedule tells the table what the current level's events mean, counts down to the events still to come
+// and says when the next break is and when play should end
+func (s *Service) formatSchedule(_ context.Context, schedule *poker.Schedule, running bool) g.Node {
+
+	if schedule == nil {
+		return nil
+	}
+
+	var current g.Node
+	for _, level := range schedule.Levels {
+		if !level.IsCurrent || len(level.Level.Events) == 0 {
+			continue
+		}
+
+		happening := make([]g.Node, 0, len(level.Level.Events))
+		for _, event := range level.Level.Events {
+			happening = append(happening, Div(g.Text(levelEventHappened(event))))
+		}
+
+		current = Div(
+			Class("row mt-3"),
+			Div(
+				Class("col-8 offset-2"),
+				Div(Class("alert alert-info text-center fs-4"), g.Group(happening)),
+			),
+		)
+	}
+
+	items := make([]g.Node, 0)
+
+	for _, upcoming := range schedule.UpcomingEvents() {
+		items = append(items, Span(
+			Class("mx-3 text-nowrap"),
+			g.Textf("%s in ", levelEventLabel(upcoming.Event)),
+			countdownTo(upcoming.Level.StartsAt, running),
+		))
+	}
+
+	if next := schedule.NextBreak(); next != nil {
+		items = append(items, Span(Class("mx-3 text-nowrap"), g.Text("Next break "), localTime(next.StartsAt)))
+	}
+
+	items = append(items, Span(Class("mx-3 text-nowrap"), g.Text("Finishes around "), localTime(schedule.EndsAt)))
+
+	return group(
+		current,
+		Div(
+			Class("row mt-3"),
+			Div(
+				Class("col text-center text-body-secondary fs-5"),
+				g.Group(items),
+			),
+		),
+	)
+
+}
+
+// levelEventLabel is how an event is shown before it happens
+func levelEventLabel(event poker.LevelEvent) string {
+
+	switch event {
+	case poker.LevelEventLateRegistrationEnds:
+		return "Late registration closes"
+	case poker.LevelEventRebuysEnd:
+		return "Rebuys end"
+	case poker.LevelEventAddOnAvailable:
+		return "Add-on available"
+	}
+
+	return event.String()
+
+}
+
+// levelEventHappened is how an event is shown during the level it happened at the start of
+func levelEventHappened(event poker.LevelEvent) string {
+
+	switch event {
+	case poker.LevelEventLateRegistrationEnds:
+		return "Late registration is now closed"
+	case poker.LevelEventRebuysEnd:
+		return "Rebuys are now closed"
+	case poker.LevelEventAddOnAvailable:
+		return "The add-on is now available"
+	}
+
+	return event.String()
+
+}
+
+// levelEventBadges mark the events that happen as a level starts
+func levelEventBadges(level *poker.TimerLevel) g.Node {
+
+	badges := make([]g.Node, 0, len(level.Events))
+	for _, event := range level.Events {
+		badges = append(badges, Span(Class("badge text-bg-warning ms-2"), g.Text(levelEventLabel(event))))
+	}
+
+	return g.Group(badges)
+
+}
+
+// dashboardLevelEventInputs are the checkboxes marking a level's events on the level forms
+func dashboardLevelEventInputs(level *poker.TimerLevel) g.Node {
+
+	boxes := make([]g.Node, 0, len(poker.AllLevelEvents))
+	for _, event := range poker.AllLevelEvents {
+		id := "level-event-" + event.String()
+		boxes = append(boxes, Div(
+			Class("form-check form-check-inline"),
+			Input(
+				Class("form-check-input"), Type("checkbox"), ID(id), Name("Events"), Value(event.String()),
+				g.If(level != nil && level.HasEvent(event), g.Attr("checked")),
+			),
+			Label(Class("form-check-label"), For(id), g.Text(levelEventLabel(event))),
+		))
+	}
+
+	return Div(
+		Class("row justify-content-center mt-2"),
+		Div(
+			Class("col-auto"),
+			Label(Class("me-2"), g.Text("As this level starts:")),
+			g.Group(boxes),
+		),
+	)
+
+}
+
+// countdownTo renders the time left until at, which counts down in the browser while the clock is running
+func countdownTo(at time.Time, running bool) g.Node {
+
+	remaining := time.Until(at)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Span(
+		DataAttr("countdown-sec", fmt.Sprintf("%.0f", remaining.Seconds())),
+		DataAttr("countdown-running", fmt.Sprintf("%t", running)),
+		g.Text(formatCountdown(remaining)),
+	)
+
+}
+
+// formatCountdown renders a duration such as 23:10 or 1:05:09
+func formatCountdown(d time.Duration) string {
+
+	total := int(d.Round(time.Second).Seconds())
+	hours, minutes, seconds := total/3600, total/60%60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+
+}
+
+// localTime renders a time of day, the text is replaced with the viewer's local time once the page loads
+func localTime(t time.Time) g.Node {
+	return Time(
+		g.Attr("datetime", t.UTC().Format(time.RFC3339)), DataAttr("local-time", ""),
+		g.Text(t.UTC().Format("15:04 UTC")),
+	)
+}
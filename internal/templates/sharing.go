@@ -0,0 +1,183 @@
+package templates
+
+import (
+	"context"
+	"poker"
+	"poker/internal"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+// timerRole is the signed in user's role on the timer, which decides the controls they are shown
+func timerRole(ctx context.Context, timer *poker.Timer) poker.TimerRole {
+
+	user := internal.UserFromContext(ctx)
+	if user == nil {
+		return ""
+	}
+
+	return timer.RoleOf(user.ID)
+
+}
+
+func timerRoleLabel(role poker.TimerRole) string {
+
+	switch role {
+	case poker.TimerRoleViewer:
+		return "Viewer"
+	case poker.TimerRoleOperator:
+		return "Operator"
+	case poker.TimerRoleEditor:
+		return "Editor"
+	case poker.TimerRoleOwner:
+		return "Owner"
+	}
+
+	return role.String()
+
+}
+
+// timerRoleDescription explains what a role may do where it is chosen
+func timerRoleDescription(role poker.TimerRole) string {
+
+	switch role {
+	case poker.TimerRoleViewer:
+		return "Viewer, can watch the timer and read its levels"
+	case poker.TimerRoleOperator:
+		return "Operator, can also run the clock"
+	case poker.TimerRoleEditor:
+		return "Editor, can also change the levels, chips and schedule"
+	}
+
+	return timerRoleLabel(role)
+
+}
+
+type DashboardTimerSharingProps struct {
+	Timer *poker.Timer
+	// Email and Role are the invitation shown in the form, which is the one submitted when it has errors
+	Email  string
+	Role   poker.TimerRole
+	Errors []string
+}
+
+// DashboardTimerSharingComponent invites other users to the timer by email address and lists who it has been shared
+// with, for the owner to change or take away
+func (s *Service) DashboardTimerSharingComponent(_ context.Context, props *DashboardTimerSharingProps) g.Node {
+
+	timer := props.Timer
+
+	selected := props.Role
+	if selected == "" {
+		selected = poker.TimerRoleViewer
+	}
+
+	options := make([]g.Node, 0, len(poker.AllTimerRoles))
+	for _, role := range poker.AllTimerRoles {
+		options = append(options, Option(Value(role.String()), g.If(role == selected, Selected()), g.Text(timerRoleDescription(role))))
+	}
+
+	var shares g.Node
+	if len(timer.Shares) > 0 {
+		items := make([]g.Node, 0, len(timer.Shares))
+		for _, share := range timer.Shares {
+			items = append(items, Li(
+				Class("list-group-item d-flex justify-content-between align-items-center"),
+				Div(
+					g.Text(share.Email),
+					Span(Class("badge text-bg-secondary ms-2"), g.Text(timerRoleLabel(share.Role))),
+					g.If(!share.IsAccepted(), Span(Class("badge text-bg-warning ms-2"), g.Text("Invited"))),
+				),
+				Button(
+					Type("button"), Class("btn btn-sm btn-outline-danger"),
+					htmx.Delete(s.buildRoute("dashboard-timer-share", "timerID", timer.ID, "userID", share.UserID)),
+					htmx.Target("#timer-sharing-container"), htmx.Swap("outerHTML"),
+					g.Attr("hx-confirm", "Remove this person's access to the timer?"),
+					g.Text("Remove"),
+				),
+			))
+		}
+
+		shares = Ul(Class("list-group mb-3"), g.Group(items))
+	}
+
+	return Div(
+		ID("timer-sharing-container"),
+		Class("row mt-4"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("card"),
+				Div(
+					Class("card-header text-center"),
+					g.Text("Sharing"),
+				),
+				Div(
+					Class("card-body"),
+					s.renderErrorAlert(props.Errors),
+					g.If(
+						len(timer.Shares) == 0,
+						P(
+							Class("text-center"),
+							g.Text("Invite the people running the tournament with you, they get access once they accept"),
+						),
+					),
+					shares,
+					FormEl(
+						htmx.Post(s.buildRoute("dashboard-timer-shares", "timerID", timer.ID)),
+						htmx.Target("#timer-sharing-container"), htmx.Swap("outerHTML"),
+						Div(
+							Class("row align-items-end"),
+							Div(
+								Class("col"),
+								Label(Class("form-label"), g.Text("Email")),
+								Input(Class("form-control"), Type("email"), AutoComplete("off"), Name("Email"), Value(props.Email)),
+							),
+							Div(
+								Class("col"),
+								Label(Class("form-label"), g.Text("Role")),
+								Select(append([]g.Node{Class("form-select"), Name("Role")}, options...)...),
+							),
+							Div(
+								Class("col-auto"),
+								Button(Type("submit"), Class("btn btn-primary"), g.Text("Invite")),
+							),
+						),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+// dashboardTimerInvitationItem is a timer the user has been invited to, waiting for them to accept or decline
+func (s *Service) dashboardTimerInvitationItem(_ context.Context, timer *poker.Timer, share *poker.TimerShare) g.Node {
+
+	var route = s.buildRoute("dashboard-timer-invitation", "timerID", timer.ID)
+
+	return Div(
+		Class("list-group-item"),
+		Div(
+			Class("d-flex justify-content-between align-items-center"),
+			Div(
+				g.Text(timer.Name),
+				Span(Class("badge text-bg-secondary ms-2"), g.Text(timerRoleLabel(share.Role))),
+			),
+			Div(
+				Class("btn-group"), Role("group"),
+				Button(
+					Class("btn btn-sm btn-success"), Type("button"), htmx.Post(route),
+					g.Text("Accept"),
+				),
+				Button(
+					Class("btn btn-sm btn-outline-danger"), Type("button"), htmx.Delete(route),
+					g.Text("Decline"),
+				),
+			),
+		),
+	)
+
+}
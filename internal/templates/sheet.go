@@ -0,0 +1,95 @@
+package templates
+
+import (
+	"context"
+	"poker"
+
+	g "github.com/maragudk/gomponents"
+	. "github.com/maragudk/gomponents/html"
+)
+
+// DashboardTimerSheetPrint is the timer's structure on a page of its own, without the navigation, to be printed
+// and left on the table
+func (s *Service) DashboardTimerSheetPrint(ctx context.Context, sheet *poker.TimerSheet) g.Node {
+
+	rows := make([]g.Node, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		elapsed := g.Textf("%s - %s", poker.FormatSheetMinutes(row.StartMin), poker.FormatSheetMinutes(row.EndMin))
+
+		if row.Level.Type == poker.LevelTypeBreak {
+			rows = append(rows, Tr(
+				Class("table-secondary"),
+				Td(Class("text-center"), g.Text(format(row.Number))),
+				Td(ColSpan("2"), Class("text-center"), Strong(Em(g.Text("Break")))),
+				Td(Class("text-center"), g.Text(format(row.Level.DurationMin))),
+				Td(Class("text-center"), elapsed),
+			))
+			continue
+		}
+
+		rows = append(rows, Tr(
+			Td(Class("text-center"), g.Text(format(row.Number))),
+			Td(Class("text-center"), g.Text(row.Blinds())),
+			Td(Class("text-center"), g.Text(row.Ante())),
+			Td(Class("text-center"), g.Text(format(row.Level.DurationMin))),
+			Td(Class("text-center"), elapsed),
+		))
+	}
+
+	var tournament g.Node
+	if sheet.Tournament != nil {
+		details := make([]g.Node, 0)
+		for _, detail := range sheet.Details() {
+			details = append(details, Span(Class("mx-2"), Strong(g.Textf("%s: ", detail.Label)), g.Text(detail.Value)))
+		}
+
+		tournament = group(
+			H5(Class("text-center"), g.Text(sheet.Tournament.Name)),
+			P(Class("text-center small"), g.Group(details)),
+		)
+	}
+
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				Div(
+					Class("container mt-4"),
+					H3(Class("text-center"), g.Text(sheet.Timer.Name)),
+					tournament,
+					Table(
+						Class("table table-sm table-bordered align-middle"),
+						THead(
+							Tr(
+								Th(Class("text-center"), g.Text("Level")),
+								Th(Class("text-center"), g.Text("Blinds")),
+								Th(Class("text-center"), g.Text("Ante")),
+								Th(Class("text-center"), g.Text("Minutes")),
+								Th(Class("text-center"), g.Text("Elapsed")),
+							),
+						),
+						TBody(rows...),
+					),
+					P(Class("text-end small"), g.Textf("Total playing time %s", poker.FormatSheetMinutes(sheet.TotalMin))),
+					Div(
+						Class("d-flex justify-content-center gap-2 mt-4 d-print-none"),
+						Button(
+							Type("button"), Class("btn btn-primary"),
+							g.Attr("onclick", "window.print()"),
+							I(Class("fa-solid fa-print me-1")),
+							g.Text("Print"),
+						),
+						A(
+							Class("btn btn-outline-secondary"),
+							Href(s.buildRoute("dashboard-timer-sheet-pdf", "timerID", sheet.Timer.ID)),
+							I(Class("fa-solid fa-file-pdf me-1")),
+							g.Text("Download PDF"),
+						),
+					),
+				),
+			),
+		),
+	)
+
+}
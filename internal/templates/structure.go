@@ -0,0 +1,169 @@
+package templates
+
+import (
+	"context"
+	"poker"
+	"strconv"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardStructureGeneratorProps struct {
+	// Timers are the user's timers the structure can be saved into
+	Timers []*poker.Timer
+	Params poker.StructureParams
+	// TimerID is the timer the structure will replace the levels of, empty to create a new timer named TimerName
+	TimerID   string
+	TimerName string
+	// Levels is the generated structure being previewed, nil until the form has been previewed
+	Levels []*poker.TimerLevel
+	Errors []string
+}
+
+func (s *Service) DashboardStructureGeneratorComponent(ctx context.Context, props *DashboardStructureGeneratorProps) g.Node {
+
+	params := props.Params
+
+	number := func(label, name string, value float64, step string) g.Node {
+		return Div(
+			Class("col"),
+			Label(Class("form-label"), g.Text(label)),
+			Input(Class("form-control"), Type("number"), Step(step), Min("0"), Name(name), Value(strconv.FormatFloat(value, 'f', -1, 64))),
+		)
+	}
+
+	timerOptions := []g.Node{
+		Class("form-select"), Name("TimerID"),
+		Option(Value(""), g.Text("A new timer")),
+	}
+	for _, timer := range props.Timers {
+		timerOptions = append(timerOptions, Option(Value(timer.ID), g.If(timer.ID == props.TimerID, Selected()), g.Text(timer.Name)))
+	}
+
+	var saveButton g.Node
+	if len(props.Levels) > 0 {
+		saveButton = Button(
+			Type("button"), Class("btn btn-success"),
+			htmx.Post(s.buildRoute("dashboard-timers-generate")), htmx.Target("#dashboard-section"),
+			g.Attr("hx-confirm", "Saving into an existing timer replaces all of its levels and resets its clock, continue?"),
+			g.Text("Save Structure"),
+		)
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text("Generate Blind Structure")),
+				Hr(),
+			),
+		),
+		s.renderErrorAlert(props.Errors),
+		FormEl(
+			htmx.Post(s.buildRoute("dashboard-timers-generate-preview")), htmx.Target("#dashboard-section"),
+			Div(
+				Class("row mb-3"),
+				number("Starting Stack", "StartingStack", params.StartingStack, "1"),
+				number("Players", "Players", float64(params.Players), "1"),
+				number("Length (hours)", "LengthHours", params.LengthHours, "0.25"),
+				number("Level Duration (minutes)", "LevelMin", params.LevelMin, "1"),
+			),
+			Div(
+				Class("row mb-3"),
+				number("Smallest Chip", "SmallestChip", params.SmallestChip, "any"),
+				number("Break Every (levels)", "BreakEvery", float64(params.BreakEvery), "1"),
+				number("Break Duration (minutes)", "BreakMin", params.BreakMin, "1"),
+				number("Antes From Level", "AnteFromLevel", float64(params.AnteFromLevel), "1"),
+			),
+			P(
+				Class("form-text"),
+				g.Text("Set break every or antes from level to 0 to leave out breaks or antes. Length is how long play should take to reach heads up."),
+			),
+			Div(
+				Class("row mb-3"),
+				Div(
+					Class("col"),
+					Label(Class("form-label"), g.Text("Save Into")),
+					Select(timerOptions...),
+				),
+				Div(
+					Class("col"),
+					Label(Class("form-label"), g.Text("New Timer Name")),
+					Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("TimerName"), Value(props.TimerName)),
+				),
+			),
+			Div(
+				Class("d-flex justify-content-center gap-2"),
+				Button(
+					Type("submit"), Class("btn btn-primary"),
+					g.If(len(props.Levels) == 0, g.Text("Preview Structure")),
+					g.If(len(props.Levels) > 0, g.Text("Regenerate")),
+				),
+				saveButton,
+			),
+		),
+		s.structurePreview(ctx, props.Levels),
+	)
+
+}
+
+// structurePreview lists the generated levels the same way a timer's levels are listed
+func (s *Service) structurePreview(_ context.Context, levels []*poker.TimerLevel) g.Node {
+
+	if len(levels) == 0 {
+		return nil
+	}
+
+	var totalMin float64
+	rows := make([]g.Node, 0, len(levels))
+	for idx, level := range levels {
+		totalMin += level.DurationMin
+
+		if level.Type == poker.LevelTypeBreak {
+			rows = append(rows, Tr(
+				Td(g.Textf("%v", idx+1)),
+				Td(ColSpan("3"), Class("text-center"), Strong(Em(g.Text("BREAK!")))),
+				Td(Class("text-center"), g.Textf("%v", level.DurationMin)),
+			))
+			continue
+		}
+
+		rows = append(rows, Tr(
+			Td(g.Textf("%v", idx+1)),
+			Td(Class("text-center"), g.Textf("%v", level.SmallBlind)),
+			Td(Class("text-center"), g.Textf("%v", level.BigBlind)),
+			Td(Class("text-center"), g.Textf("%v", level.Ante)),
+			Td(Class("text-center"), g.Textf("%v", level.DurationMin)),
+		))
+	}
+
+	return Div(
+		Class("row mt-4"),
+		Div(
+			Class("col"),
+			Table(
+				Class("table table-bordered table-sm"),
+				THead(
+					Class("table-secondary"),
+					Tr(
+						Th(g.Text("#")),
+						Th(Class("text-center"), g.Text("Small Blind")),
+						Th(Class("text-center"), g.Text("Big Blind")),
+						Th(Class("text-center"), g.Text("Ante")),
+						Th(Class("text-center"), g.Text("Duration (minutes)")),
+					),
+				),
+				TBody(rows...),
+			),
+			P(
+				Class("text-center text-body-secondary"),
+				g.Textf("%d levels, %s of play in total", len(levels), formatMinutes(totalMin)),
+			),
+		),
+	)
+
+}
@@ -2,7 +2,6 @@ package templates
 
 import (
 	"poker"
-	"poker/internal/store/dynamo"
 
 	"github.com/sirupsen/logrus"
 )
@@ -10,12 +9,13 @@ import (
 type Service struct {
 	logger      *logrus.Logger
 	environment poker.Environment
+	appURL      string
 
 	funcs struct {
 		buildRoute func(string, ...any) (string, error)
 	}
 
-	timerRepo *dynamo.TimerRepository
+	timerRepo poker.TimerRepository
 }
 
 type ViewData struct {
@@ -29,13 +29,15 @@ type Template struct {
 
 func New(
 	env poker.Environment,
+	appURL string,
 	logger *logrus.Logger,
 
-	timerRepo *dynamo.TimerRepository,
+	timerRepo poker.TimerRepository,
 
 ) (*Service, error) {
 	s := &Service{
 		environment: env,
+		appURL:      appURL,
 		logger:      logger,
 		timerRepo:   timerRepo,
 	}
@@ -0,0 +1,190 @@
+package templates
+
+import (
+	"context"
+	"poker"
+	"strings"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardTokensProps struct {
+	User   *poker.User
+	Tokens []*poker.APIToken
+	// Name and Scopes are the token shown in the form, which is the one submitted when it has errors
+	Name   string
+	Scopes []poker.TokenScope
+	// Secret is the token that was just created, it is only ever shown this once
+	Secret string
+	Errors []string
+}
+
+func (s *Service) DashboardTokens(ctx context.Context, props *DashboardTokensProps) g.Node {
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				s.gnavbar(ctx),
+				Div(
+					Class("container"),
+					s.dashboardUserCallout(ctx, props.User),
+					Div(
+						Class("row"),
+						Div(
+							Class("col-3"),
+							s.dashboardUserMenuComponent(ctx),
+						),
+						Div(
+							Class("col-9"),
+							s.DashboardTokensFragment(ctx, props),
+						),
+					),
+				),
+				s.gbottom(),
+			),
+		),
+	)
+}
+
+// DashboardTokensFragment lists the user's API tokens with a form to create another
+func (s *Service) DashboardTokensFragment(_ context.Context, props *DashboardTokensProps) g.Node {
+
+	var list g.Node = Div(
+		Class("alert alert-info text-center"),
+		g.Text("You don't have any API tokens. Create one below to use the API from a script"),
+	)
+	if len(props.Tokens) > 0 {
+		items := make([]g.Node, 0, len(props.Tokens))
+		for _, token := range props.Tokens {
+			items = append(items, s.dashboardTokenListItem(token))
+		}
+
+		list = Div(Class("list-group"), g.Group(items))
+	}
+
+	var secret g.Node
+	if props.Secret != "" {
+		secret = Div(
+			Class("alert alert-success"),
+			P(g.Text("Your new token is below. Copy it now, it won't be shown again")),
+			Input(Class("form-control font-monospace"), Type("text"), ReadOnly(), Value(props.Secret), g.Attr("onclick", "this.select()")),
+		)
+	}
+
+	scopes := make([]g.Node, 0, len(poker.AllTokenScopes))
+	for _, scope := range poker.AllTokenScopes {
+		id := "token-scope-" + scope.String()
+		scopes = append(scopes, Div(
+			Class("form-check"),
+			Input(
+				ID(id), Class("form-check-input"), Type("checkbox"), Name("Scopes"), Value(scope.String()),
+				g.If(hasTokenScope(props.Scopes, scope), g.Attr("checked")),
+			),
+			Label(Class("form-check-label"), For(id), g.Text(tokenScopeDescription(scope))),
+		))
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text("API Tokens")),
+				Hr(),
+				P(
+					Class("text-body-secondary"),
+					g.Text("Tokens let scripts use the API as you. The API is described by its "),
+					A(Href(s.buildRoute("api-openapi")), g.Text("OpenAPI document")),
+				),
+			),
+		),
+		secret,
+		Div(Class("row mb-3"), Div(Class("col"), list)),
+		Div(
+			Class("card"),
+			Div(Class("card-header"), g.Text("Create a Token")),
+			Div(
+				Class("card-body"),
+				s.renderErrorAlert(props.Errors),
+				FormEl(
+					htmx.Post(s.buildRoute("dashboard-tokens")),
+					Div(
+						Class("mb-3"),
+						Label(Class("form-label"), For("token-name"), g.Text("Name")),
+						Input(ID("token-name"), Class("form-control"), Type("text"), Name("Name"), AutoComplete("off"), Placeholder("Stream deck"), Value(props.Name)),
+					),
+					Div(
+						Class("mb-3"),
+						Label(Class("form-label"), g.Text("Scopes")),
+						g.Group(scopes),
+					),
+					Button(Type("submit"), Class("btn btn-primary"), g.Text("Create Token")),
+				),
+			),
+		),
+	)
+
+}
+
+func (s *Service) dashboardTokenListItem(token *poker.APIToken) g.Node {
+
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, scope.String())
+	}
+
+	lastUsed := "never used"
+	if token.LastUsedAt != nil {
+		lastUsed = "last used " + token.LastUsedAt.UTC().Format("2 Jan 2006")
+	}
+
+	return Div(
+		Class("list-group-item d-flex justify-content-between align-items-center"),
+		Div(
+			g.Text(token.Name),
+			Code(Class("ms-2"), g.Textf("%s…", token.Hint)),
+			Span(Class("badge text-bg-secondary ms-2"), g.Text(strings.Join(scopes, ", "))),
+			Div(
+				Small(
+					Class("text-body-secondary"),
+					g.Textf("Created %s, %s", token.CreatedAt.UTC().Format("2 Jan 2006"), lastUsed),
+				),
+			),
+		),
+		Button(
+			Class("btn btn-sm btn-outline-danger"), Type("button"), htmx.Delete(s.buildRoute("dashboard-token", "tokenID", token.ID)),
+			g.Attr("hx-confirm", "Revoke this token? Anything using it will stop working"),
+			g.Text("Revoke"),
+		),
+	)
+
+}
+
+// tokenScopeDescription explains what a scope allows where it is chosen
+func tokenScopeDescription(scope poker.TokenScope) string {
+
+	switch scope {
+	case poker.TokenScopeRead:
+		return "Read, can list timers and their levels"
+	case poker.TokenScopeControl:
+		return "Control, can start, pause and change the level of a timer"
+	case poker.TokenScopeWrite:
+		return "Write, can create, change and delete timers and levels"
+	}
+
+	return scope.String()
+
+}
+
+func hasTokenScope(scopes []poker.TokenScope, scope poker.TokenScope) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
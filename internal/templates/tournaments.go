@@ -0,0 +1,545 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"poker"
+	"strconv"
+	"strings"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardTournamentsProps struct {
+	User        *poker.User
+	Tournaments []*poker.Tournament
+}
+
+func (s *Service) DashboardTournaments(ctx context.Context, props *DashboardTournamentsProps) g.Node {
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				s.gnavbar(ctx),
+				Div(
+					Class("container"),
+					s.dashboardUserCallout(ctx, props.User),
+					Div(
+						Class("row"),
+						Div(
+							Class("col-3"),
+							s.dashboardUserMenuComponent(ctx),
+						),
+						Div(
+							Class("col-9"),
+							s.DashboardTournamentsFragment(ctx, props.Tournaments),
+						),
+					),
+				),
+				s.gbottom(),
+			),
+		),
+	)
+}
+
+func (s *Service) DashboardTournamentsFragment(ctx context.Context, tournaments []*poker.Tournament) g.Node {
+
+	items := make([]g.Node, 0, len(tournaments))
+	for _, tournament := range tournaments {
+		items = append(items, s.dashboardTournamentListItem(ctx, tournament))
+	}
+
+	var list g.Node = g.Group(items)
+	if len(tournaments) == 0 {
+		list = Div(
+			Class("alert alert-info text-center"),
+			g.Text("You don't have any tournaments. Click below to create one now"),
+		)
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text("My Tournaments")),
+				Hr(),
+			),
+		),
+		Div(
+			Class("row mb-3"),
+			Div(
+				Class("col"),
+				Div(
+					Class("list-group"),
+					list,
+				),
+				Div(
+					Class("d-flex justify-content-center mt-2"),
+					Button(
+						Class("btn btn-primary"), htmx.Get(s.buildRoute("dashboard-tournaments-new")), htmx.Target("#dashboard-section"),
+						g.Text("Create New Tournament"),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+func (s *Service) dashboardTournamentListItem(ctx context.Context, tournament *poker.Tournament) g.Node {
+
+	return Div(
+		Class("list-group-item"),
+		Div(
+			Class("d-flex justify-content-between align-items-center"),
+			Div(
+				g.Text(tournament.Name),
+				Small(
+					Class("text-body-secondary ms-2"),
+					g.Textf("%d entrants, prize pool %s", tournament.Entrants(), formatAmount(tournament.PrizePool())),
+				),
+			),
+			Div(
+				Class("btn-group"), Role("group"),
+				A(
+					Class("btn btn-sm btn-success"), Href(s.buildRoute("play-timer", "timerID", tournament.TimerID)),
+					I(Class("fa-solid fa-play")),
+				),
+				A(
+					Class("btn btn-sm btn-info"), Href(s.buildRoute("dashboard-tournament", "tournamentID", tournament.ID)),
+					I(Class("fa-solid fa-pencil")),
+				),
+				Button(
+					Class("btn btn-sm btn-danger"), Type("button"), htmx.Delete(s.buildRoute("dashboard-tournament", "tournamentID", tournament.ID)),
+					g.Attr("hx-confirm", "Are you sure you want to delete this tournament?"),
+					I(Class("fa-solid fa-trash")),
+				),
+			),
+		),
+	)
+
+}
+
+type DashboardTournamentNewProps struct {
+	Timers []*poker.Timer
+	Errors []string
+}
+
+func (s *Service) DashboardNewTournamentComponent(ctx context.Context, props *DashboardTournamentNewProps) g.Node {
+
+	if len(props.Timers) == 0 {
+		return Div(
+			ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+			Div(
+				Class("alert alert-info text-center"),
+				g.Text("A tournament is played on one of your blind timers, "),
+				A(Href(s.buildRoute("dashboard-timers")), g.Text("create a timer")),
+				g.Text(" first."),
+			),
+		)
+	}
+
+	options := make([]g.Node, 0, len(props.Timers))
+	for _, timer := range props.Timers {
+		options = append(options, Option(Value(timer.ID), g.Text(timer.Name)))
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text("Create New Tournament")),
+				Hr(),
+			),
+		),
+		Div(
+			Class("row mb-3"),
+			Div(
+				Class("col-8 offset-2"),
+				Div(
+					Class("card"),
+					Div(
+						Class("card-body"),
+						s.renderErrorAlert(props.Errors),
+						FormEl(
+							htmx.Post(s.buildRoute("dashboard-tournaments-new")), htmx.Target("#dashboard-section"),
+							Div(
+								Class("row mb-3"),
+								Div(
+									Class("col"),
+									Label(Class("form-label"), g.Text("Tournament Name")),
+									Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("Name")),
+								),
+								Div(
+									Class("col"),
+									Label(Class("form-label"), g.Text("Timer")),
+									Select(append([]g.Node{Class("form-select"), Name("TimerID")}, options...)...),
+								),
+							),
+							Div(
+								Class("row mb-3"),
+								Div(
+									Class("col-4"),
+									tournamentAmountInput("Buy In", "BuyIn"),
+								),
+							),
+							Div(
+								Class("row mb-3"),
+								Div(Class("col"), tournamentAmountInput("Rebuy Cost", "RebuyAmount")),
+								Div(Class("col"), tournamentCountInput("Rebuys Per Player", "RebuyLimit")),
+								Div(Class("col"), tournamentCountInput("Rebuys Until Level", "RebuyUntilLevel")),
+							),
+							Div(
+								Class("row mb-3"),
+								Div(Class("col"), tournamentAmountInput("Add-on Cost", "AddOnAmount")),
+								Div(Class("col"), tournamentCountInput("Add-ons Per Player", "AddOnLimit")),
+								Div(Class("col"), tournamentCountInput("Add-ons Until Level", "AddOnUntilLevel")),
+							),
+							Div(
+								Class("row mb-3"),
+								Div(Class("col"), tournamentAmountInput("Rake (%)", "RakePercent")),
+								Div(Class("col"), tournamentAmountInput("Fixed Rake", "RakeFixed")),
+							),
+							P(
+								Class("form-text"),
+								g.Text("Leave a cost at 0 to turn rebuys or add-ons off, and a limit at 0 for no limit."),
+							),
+							Div(
+								Class("d-flex justify-content-center"),
+								Button(Type("submit"), Class("btn btn-primary"), g.Text("Create Tournament")),
+							),
+						),
+					),
+				),
+			),
+		),
+	)
+
+}
+
+func tournamentAmountInput(label, name string) g.Node {
+	return group(
+		Label(Class("form-label"), g.Text(label)),
+		Input(Class("form-control"), Type("number"), Step("0.01"), Min("0"), Name(name), Value("0")),
+	)
+}
+
+func tournamentCountInput(label, name string) g.Node {
+	return group(
+		Label(Class("form-label"), g.Text(label)),
+		Input(Class("form-control"), Type("number"), Step("1"), Min("0"), Name(name), Value("0")),
+	)
+}
+
+type DashboardTournamentProps struct {
+	User       *poker.User
+	Tournament *poker.Tournament
+	// Timer is the timer the tournament is played on, nil if it has been deleted
+	Timer  *poker.Timer
+	Errors []string
+}
+
+func (s *Service) DashboardTournament(ctx context.Context, props *DashboardTournamentProps) g.Node {
+	return Doctype(
+		HTML(
+			Lang("en"),
+			s.gtop(ctx),
+			Body(
+				s.gnavbar(ctx),
+				Div(
+					Class("container"),
+					s.dashboardUserCallout(ctx, props.User),
+					Div(
+						Class("row"),
+						Div(
+							Class("col-3"),
+							s.dashboardUserMenuComponent(ctx),
+						),
+						Div(
+							Class("col-9"),
+							s.DashboardTournamentFragment(ctx, props),
+						),
+					),
+				),
+				s.gbottom(),
+			),
+		),
+	)
+}
+
+func (s *Service) DashboardTournamentFragment(ctx context.Context, props *DashboardTournamentProps) g.Node {
+
+	tournament := props.Tournament
+
+	var timerNode g.Node = Em(g.Text("The timer for this tournament has been deleted"))
+	if props.Timer != nil {
+		timerNode = group(
+			g.Text("Played on "),
+			A(Href(s.buildRoute("play-timer", "timerID", props.Timer.ID)), g.Text(props.Timer.Name)),
+		)
+	}
+
+	rows := make([]g.Node, 0, len(tournament.Players))
+	for _, player := range tournament.Players {
+		rows = append(rows, s.dashboardTournamentPlayerRow(ctx, tournament, player))
+	}
+
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text(tournament.Name)),
+				P(Class("text-center text-body-secondary"), timerNode),
+				g.If(
+					tournament.Remaining() >= 2,
+					P(
+						Class("text-center"),
+						A(
+							Class("btn btn-sm btn-outline-primary"), Href(s.buildRoute("dashboard-tournament-deal", "tournamentID", tournament.ID)),
+							I(Class("fa-solid fa-handshake me-1")),
+							g.Text("Deal Calculator"),
+						),
+					),
+				),
+				Hr(),
+			),
+		),
+		s.renderErrorAlert(props.Errors),
+		Div(
+			Class("row mb-3 text-center"),
+			dashboardTournamentStat("Entrants", format(tournament.Entrants())),
+			dashboardTournamentStat("Remaining", format(tournament.Remaining())),
+			dashboardTournamentStat("Collected", formatAmount(tournament.Collected())),
+			dashboardTournamentStat("Rake", formatAmount(tournament.Rake())),
+			dashboardTournamentStat("Prize Pool", formatAmount(tournament.PrizePool())),
+		),
+		Div(
+			Class("row mb-3"),
+			Div(
+				Class("col"),
+				Table(
+					Class("table table-bordered align-middle"),
+					THead(
+						Class("table-secondary"),
+						Tr(
+							Th(g.Text("Player")),
+							Th(Class("text-center"), g.Text("Rebuys")),
+							Th(Class("text-center"), g.Text("Add-ons")),
+							Th(Class("text-center"), g.Text("Finished")),
+							Th(),
+						),
+					),
+					TBody(rows...),
+				),
+			),
+		),
+		Div(
+			Class("row mb-4"),
+			Div(
+				Class("col-6 offset-3"),
+				FormEl(
+					htmx.Post(s.buildRoute("dashboard-tournament-players", "tournamentID", tournament.ID)),
+					htmx.Target("#dashboard-section"),
+					Div(
+						Class("input-group"),
+						Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("Name"), Placeholder("Player name")),
+						Button(Type("submit"), Class("btn btn-primary"), g.Text("Register Player")),
+					),
+				),
+			),
+		),
+		s.dashboardTournamentPayouts(ctx, tournament),
+	)
+
+}
+
+// dashboardTournamentPayouts lists what each place is paid alongside the form to change how the pool is split
+func (s *Service) dashboardTournamentPayouts(ctx context.Context, tournament *poker.Tournament) g.Node {
+
+	rules := tournament.Payout
+
+	scheme := rules.Scheme
+	if scheme == "" {
+		scheme = poker.PayoutSchemeTable
+	}
+
+	schemeOption := func(value poker.PayoutScheme, label string) g.Node {
+		return Option(Value(value.String()), g.If(value == scheme, Selected()), g.Text(label))
+	}
+
+	manual := make([]string, 0, len(rules.Manual))
+	for _, percent := range rules.Manual {
+		manual = append(manual, strconv.FormatFloat(percent, 'f', -1, 64))
+	}
+
+	var topPercent = ""
+	if rules.TopPercent > 0 {
+		topPercent = strconv.FormatFloat(rules.TopPercent, 'f', -1, 64)
+	}
+
+	return Div(
+		Class("row"),
+		Div(
+			Class("col-12"),
+			H5(Class("text-center"), g.Text("Payouts")),
+			Hr(),
+		),
+		Div(
+			Class("col-6"),
+			s.tournamentPayoutsTable(ctx, tournament),
+		),
+		Div(
+			Class("col-6"),
+			FormEl(
+				htmx.Post(s.buildRoute("dashboard-tournament-payouts", "tournamentID", tournament.ID)),
+				htmx.Target("#dashboard-section"),
+				Div(
+					Class("mb-3"),
+					Label(Class("form-label"), g.Text("Scheme")),
+					Select(
+						Class("form-select"), Name("Scheme"),
+						schemeOption(poker.PayoutSchemeTable, "Standard table for the field size"),
+						schemeOption(poker.PayoutSchemeTop, "Pay the top percent of the field"),
+						schemeOption(poker.PayoutSchemeManual, "Custom percentages"),
+					),
+				),
+				Div(
+					Class("row mb-3"),
+					Div(
+						Class("col"),
+						Label(Class("form-label"), g.Text("Field Paid (%)")),
+						Input(Class("form-control"), Type("number"), Step("0.1"), Min("0"), Max("100"), Name("TopPercent"), Value(topPercent)),
+					),
+					Div(
+						Class("col"),
+						Label(Class("form-label"), g.Text("Round To")),
+						Input(Class("form-control"), Type("number"), Step("0.01"), Min("0"), Name("RoundTo"), Value(strconv.FormatFloat(rules.RoundTo, 'f', -1, 64))),
+					),
+				),
+				Div(
+					Class("mb-3"),
+					Label(Class("form-label"), g.Text("Custom Percentages")),
+					Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("Manual"), Placeholder("50, 30, 20"), Value(strings.Join(manual, ", "))),
+				),
+				P(
+					Class("form-text"),
+					g.Text("Field paid is only used when paying the top percent, and custom percentages start with first place and must add up to 100. "),
+					g.Text("Every place but first is rounded down to the nearest multiple of round to, with first taking what is left over."),
+				),
+				Div(
+					Class("d-flex justify-content-center"),
+					Button(Type("submit"), Class("btn btn-primary"), g.Text("Save Payouts")),
+				),
+			),
+		),
+	)
+
+}
+
+// tournamentPayoutsTable lists each paid place with who finished there once they have been eliminated
+func (s *Service) tournamentPayoutsTable(_ context.Context, tournament *poker.Tournament) g.Node {
+
+	payouts, err := tournament.Payouts()
+	if err != nil {
+		return Div(Class("alert alert-warning"), g.Text(err.Error()))
+	}
+
+	if len(payouts) == 0 {
+		return P(Class("text-center text-body-secondary"), g.Text("Payouts are worked out once players have registered."))
+	}
+
+	rows := make([]g.Node, 0, len(payouts))
+	for _, payout := range payouts {
+		var name = "-"
+		if player := tournament.PlayerInPosition(uint(payout.Place)); player != nil {
+			name = player.Name
+		}
+
+		rows = append(rows, Tr(
+			Td(g.Text(ordinal(uint(payout.Place)))),
+			Td(g.Text(name)),
+			Td(Class("text-end"), g.Textf("%.1f%%", payout.Percent)),
+			Td(Class("text-end"), g.Text(formatAmount(payout.Amount))),
+		))
+	}
+
+	return Table(
+		Class("table table-sm align-middle"),
+		THead(
+			Tr(
+				Th(g.Text("Place")),
+				Th(g.Text("Player")),
+				Th(Class("text-end"), g.Text("Share")),
+				Th(Class("text-end"), g.Text("Payout")),
+			),
+		),
+		TBody(rows...),
+	)
+
+}
+
+func dashboardTournamentStat(header, value string) g.Node {
+	return Div(
+		Class("col"),
+		Div(Class("text-body-secondary"), g.Text(header)),
+		H4(g.Text(value)),
+	)
+}
+
+func (s *Service) dashboardTournamentPlayerRow(ctx context.Context, tournament *poker.Tournament, player *poker.TournamentPlayer) g.Node {
+
+	action := func(name, class, icon, title string) g.Node {
+		return Button(
+			Type("button"), Class(fmt.Sprintf("btn btn-sm %s", class)), TitleAttr(title),
+			htmx.Post(s.buildRoute("dashboard-tournament-player-action", "tournamentID", tournament.ID, "playerID", player.ID, "action", name)),
+			htmx.Target("#dashboard-section"),
+			I(Class(icon)),
+		)
+	}
+
+	buttons := make([]g.Node, 0, 4)
+	if tournament.RebuyAmount > 0 {
+		buttons = append(buttons, action("rebuy", "btn-outline-primary", "fa-solid fa-rotate-right", "Rebuy"))
+	}
+	if tournament.AddOnAmount > 0 && player.IsPlaying() {
+		buttons = append(buttons, action("addon", "btn-outline-primary", "fa-solid fa-plus", "Add-on"))
+	}
+	if player.IsPlaying() {
+		buttons = append(buttons, action("eliminate", "btn-outline-danger", "fa-solid fa-skull", "Eliminate"))
+	}
+	if player.IsPlaying() && player.Rebuys == 0 && player.AddOns == 0 {
+		buttons = append(buttons, Button(
+			Type("button"), Class("btn btn-sm btn-outline-secondary"), TitleAttr("Remove"),
+			htmx.Delete(s.buildRoute("dashboard-tournament-player", "tournamentID", tournament.ID, "playerID", player.ID)),
+			htmx.Target("#dashboard-section"),
+			g.Attr("hx-confirm", fmt.Sprintf("Remove %s from the tournament?", player.Name)),
+			I(Class("fa-solid fa-trash")),
+		))
+	}
+
+	var finished = "-"
+	if !player.IsPlaying() {
+		finished = ordinal(player.Position)
+	}
+
+	return Tr(
+		Td(g.Text(player.Name)),
+		Td(Class("text-center"), g.Text(format(player.Rebuys))),
+		Td(Class("text-center"), g.Text(format(player.AddOns))),
+		Td(Class("text-center"), g.Text(finished)),
+		Td(
+			Class("text-end"),
+			Div(append([]g.Node{Class("btn-group"), Role("group")}, buttons...)...),
+		),
+	)
+
+}
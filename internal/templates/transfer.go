@@ -0,0 +1,99 @@
+package templates
+
+import (
+	"context"
+	"poker"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+type DashboardImportTimerProps struct {
+	Errors []string
+}
+
+func (s *Service) DashboardImportTimerComponent(ctx context.Context, props *DashboardImportTimerProps) g.Node {
+	return Div(
+		ID("dashboard-section"), g.Attr("hx-swap-oob", "true"),
+		Div(
+			Class("row"),
+			Div(
+				Class("col"),
+				H5(Class("text-center"), g.Text("Import Timer")),
+				Hr(),
+			),
+		),
+		Div(
+			Class("row mb-3"),
+			Div(
+				Class("col-8 offset-2"),
+				Div(
+					Class("card"),
+					Div(
+						Class("card-body"),
+						s.renderErrorAlert(props.Errors),
+						FormEl(
+							htmx.Post(s.buildRoute("dashboard-timers-import")), htmx.Target("#dashboard-section"),
+							g.Attr("hx-encoding", "multipart/form-data"),
+							Div(
+								Class("mb-3"),
+								Label(Class("form-label"), g.Text("File")),
+								Input(Class("form-control"), Type("file"), Name("File"), g.Attr("accept", ".json,.yaml,.yml,.csv")),
+							),
+							Div(
+								Class("row mb-3"),
+								Div(
+									Class("col"),
+									Label(Class("form-label"), g.Text("Format")),
+									Select(
+										Class("form-select"), Name("Format"),
+										Option(Value(""), g.Text("From the file name")),
+										Option(Value(poker.TimerFormatJSON.String()), g.Text("JSON")),
+										Option(Value(poker.TimerFormatYAML.String()), g.Text("YAML")),
+										Option(Value(poker.TimerFormatCSV.String()), g.Text("CSV")),
+									),
+								),
+								Div(
+									Class("col"),
+									Label(Class("form-label"), g.Text("Timer Name")),
+									Input(Class("form-control"), Type("text"), AutoComplete("off"), Name("Name")),
+								),
+							),
+							P(
+								Class("form-text"),
+								g.Text("CSV files need a header row with the columns type, small_blind, big_blind, ante, ante_type, duration_min and events, one level per row. "),
+								g.Text("A level's events are separated by semicolons, such as late-registration-ends;rebuys-end. "),
+								g.Text("Leave the name empty to use the one in the file, or the file name for CSV."),
+							),
+							Div(
+								Class("d-flex justify-content-center"),
+								Button(Type("submit"), Class("btn btn-primary"), g.Text("Import Timer")),
+							),
+						),
+					),
+				),
+			),
+		),
+	)
+}
+
+// timerExportButtons link to a download of the timer in each format it can be exported to
+func (s *Service) timerExportButtons(_ context.Context, timer *poker.Timer) g.Node {
+
+	link := func(format poker.TimerFormat, label string) g.Node {
+		return A(
+			Class("btn btn-sm btn-outline-secondary"),
+			Href(s.buildRoute("dashboard-timer-export", "timerID", timer.ID, "format", format.String())),
+			g.Text(label),
+		)
+	}
+
+	return Div(
+		Class("btn-group"), Role("group"),
+		link(poker.TimerFormatJSON, "Export JSON"),
+		link(poker.TimerFormatYAML, "Export YAML"),
+		link(poker.TimerFormatCSV, "Export CSV"),
+	)
+
+}
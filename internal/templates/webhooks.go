@@ -0,0 +1,203 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"poker"
+
+	g "github.com/maragudk/gomponents"
+	htmx "github.com/maragudk/gomponents-htmx"
+	. "github.com/maragudk/gomponents/html"
+)
+
+// webhookEventDescription explains when an event is sent where it is chosen
+func webhookEventDescription(event poker.WebhookEvent) string {
+
+	switch event {
+	case poker.WebhookEventLevelChanged:
+		return "Level changed, when the blinds go up or down"
+	case poker.WebhookEventBreakStarted:
+		return "Break started"
+	case poker.WebhookEventBreakEnded:
+		return "Break ended"
+	case poker.WebhookEventTimerCompleted:
+		return "Timer completed, when the final level ends"
+	case poker.WebhookEventTimerReset:
+		return "Timer reset, when the current level is restarted"
+	}
+
+	return event.String()
+
+}
+
+type DashboardTimerWebhooksProps struct {
+	Timer    *poker.Timer
+	Webhooks []*poker.Webhook
+	// Deliveries are the most recent deliveries of each webhook by its id
+	Deliveries map[string][]*poker.WebhookDelivery
+	// URL and Events are the webhook shown in the form, which is the one submitted when it has errors
+	URL    string
+	Events []poker.WebhookEvent
+	Errors []string
+}
+
+// DashboardTimerWebhooksPlaceholder loads the timer's webhooks once the page has, as they are kept apart from the
+// timer
+func (s *Service) DashboardTimerWebhooksPlaceholder(_ context.Context, timer *poker.Timer) g.Node {
+	return Div(
+		ID("timer-webhooks-container"),
+		htmx.Get(s.buildRoute("dashboard-timer-webhooks", "timerID", timer.ID)),
+		htmx.Trigger("load"), htmx.Swap("outerHTML"),
+	)
+}
+
+// DashboardTimerWebhooksComponent adds webhooks that are sent the timer's events, listing each with its most recent
+// deliveries and a button to send it a test event
+func (s *Service) DashboardTimerWebhooksComponent(_ context.Context, props *DashboardTimerWebhooksProps) g.Node {
+
+	timer := props.Timer
+
+	events := make([]g.Node, 0, len(poker.AllWebhookEvents))
+	for _, event := range poker.AllWebhookEvents {
+		id := "webhook-event-" + event.String()
+		events = append(events, Div(
+			Class("form-check"),
+			Input(
+				ID(id), Class("form-check-input"), Type("checkbox"), Name("Events"), Value(event.String()),
+				g.If(hasWebhookEvent(props.Events, event), g.Attr("checked")),
+			),
+			Label(Class("form-check-label"), For(id), g.Text(webhookEventDescription(event))),
+		))
+	}
+
+	items := make([]g.Node, 0, len(props.Webhooks))
+	for _, webhook := range props.Webhooks {
+		items = append(items, s.dashboardTimerWebhookItem(timer, webhook, props.Deliveries[webhook.ID]))
+	}
+
+	return Div(
+		ID("timer-webhooks-container"),
+		Class("row mt-4"),
+		Div(
+			Class("col-8 offset-2"),
+			Div(
+				Class("card"),
+				Div(
+					Class("card-header text-center"),
+					g.Text("Webhooks"),
+				),
+				Div(
+					Class("card-body"),
+					s.renderErrorAlert(props.Errors),
+					g.If(
+						len(props.Webhooks) == 0,
+						P(
+							Class("text-center"),
+							g.Text("Send the timer's events to a bot or your lights. Every request is signed with the webhook's secret"),
+						),
+					),
+					g.Group(items),
+					g.If(len(props.Webhooks) < poker.MaxTimerWebhooks, FormEl(
+						htmx.Post(s.buildRoute("dashboard-timer-webhooks", "timerID", timer.ID)),
+						htmx.Target("#timer-webhooks-container"), htmx.Swap("outerHTML"),
+						Div(
+							Class("mb-3"),
+							Label(Class("form-label"), For("webhook-url"), g.Text("URL")),
+							Input(
+								ID("webhook-url"), Class("form-control"), Type("url"), AutoComplete("off"), Name("URL"),
+								Placeholder("https://example.com/hooks/poker"), Value(props.URL),
+							),
+						),
+						Div(
+							Class("mb-3"),
+							Label(Class("form-label"), g.Text("Events")),
+							g.Group(events),
+						),
+						Button(Type("submit"), Class("btn btn-primary"), g.Text("Add Webhook")),
+					)),
+				),
+			),
+		),
+	)
+
+}
+
+func (s *Service) dashboardTimerWebhookItem(timer *poker.Timer, webhook *poker.Webhook, deliveries []*poker.WebhookDelivery) g.Node {
+
+	var route = s.buildRoute("dashboard-timer-webhook", "timerID", timer.ID, "webhookID", webhook.ID)
+
+	badges := make([]g.Node, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		badges = append(badges, Span(Class("badge text-bg-secondary me-1"), g.Text(event.String())))
+	}
+
+	var log g.Node = P(Class("text-body-secondary small mb-0"), g.Text("Nothing has been sent yet"))
+	if len(deliveries) > 0 {
+		rows := make([]g.Node, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			rows = append(rows, Tr(
+				Td(g.Text(delivery.CreatedAt.UTC().Format("2 Jan 15:04:05"))),
+				Td(g.Text(delivery.Event.String())),
+				Td(g.Text(fmt.Sprintf("%d", delivery.Attempts))),
+				Td(webhookDeliveryStatus(delivery)),
+			))
+		}
+
+		log = Table(
+			Class("table table-sm small mb-0"),
+			THead(Tr(Th(g.Text("Sent (UTC)")), Th(g.Text("Event")), Th(g.Text("Attempts")), Th(g.Text("Result")))),
+			TBody(g.Group(rows)),
+		)
+	}
+
+	return Div(
+		Class("border rounded p-3 mb-3"),
+		Div(
+			Class("d-flex justify-content-between align-items-center mb-2"),
+			Code(Class("text-break"), g.Text(webhook.URL)),
+			Div(
+				Class("btn-group"), Role("group"),
+				Button(
+					Type("button"), Class("btn btn-sm btn-outline-primary"),
+					htmx.Post(route+"/test"),
+					htmx.Target("#timer-webhooks-container"), htmx.Swap("outerHTML"),
+					g.Text("Send Test Event"),
+				),
+				Button(
+					Type("button"), Class("btn btn-sm btn-outline-danger"),
+					htmx.Delete(route),
+					htmx.Target("#timer-webhooks-container"), htmx.Swap("outerHTML"),
+					g.Attr("hx-confirm", "Delete this webhook? Its deliveries are deleted with it"),
+					g.Text("Delete"),
+				),
+			),
+		),
+		Div(Class("mb-2"), g.Group(badges)),
+		Div(
+			Class("input-group input-group-sm mb-2"),
+			Span(Class("input-group-text"), g.Text("Secret")),
+			Input(Class("form-control font-monospace"), Type("text"), ReadOnly(), Value(webhook.Secret), g.Attr("onclick", "this.select()")),
+		),
+		log,
+	)
+
+}
+
+func webhookDeliveryStatus(delivery *poker.WebhookDelivery) g.Node {
+
+	if delivery.Succeeded {
+		return Span(Class("text-success"), g.Textf("%d", delivery.StatusCode))
+	}
+
+	return Span(Class("text-danger"), g.Text(delivery.Error))
+
+}
+
+func hasWebhookEvent(events []poker.WebhookEvent, event poker.WebhookEvent) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
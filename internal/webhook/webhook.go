@@ -0,0 +1,273 @@
+// Package webhook sends signed webhook payloads, retrying those that fail and recording every attempt in the
+// webhook's delivery log
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"poker"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// retryBackoff is how long to wait before each retry of a delivery whose first attempt failed
+var retryBackoff = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+const (
+	// firstAttemptTimeout bounds the first attempts, made while the request that caused the events is still being
+	// handled, so a slow or unreachable receiver holds up the response by no more than this
+	firstAttemptTimeout = 2 * time.Second
+	attemptTimeout      = 10 * time.Second
+	// maxResponseBytes is how much of a response is read before the connection is closed, nothing is done with it
+	maxResponseBytes = 64 << 10
+	userAgent        = "poker-webhooks/1"
+)
+
+type Config struct {
+	// AllowPrivateNetworks lets webhooks be sent to loopback and private addresses. It should only be set where
+	// those addresses can't reach anything a user shouldn't, such as running locally
+	AllowPrivateNetworks bool
+}
+
+// Sender sends payloads as the events happen, retrying those that fail in the background. Retries only live in this
+// process, so behind lambda a delivery whose first attempt fails may not be retried before the function is frozen
+type Sender struct {
+	repo   poker.WebhookRepository
+	logger *logrus.Logger
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+func New(repo poker.WebhookRepository, logger *logrus.Logger, cfg *Config) *Sender {
+
+	dialer := &net.Dialer{Timeout: attemptTimeout}
+	if !cfg.AllowPrivateNetworks {
+		// Checked once the address is resolved, so a public name can't point at a private address
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+
+			if !isPublic(ip.Unmap()) {
+				return fmt.Errorf("webhooks can't be sent to %s, it is not a public address", ip)
+			}
+
+			return nil
+		}
+	}
+
+	return &Sender{
+		repo:   repo,
+		logger: logger,
+		client: &http.Client{
+			Timeout:   attemptTimeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: attemptTimeout},
+			// Redirects aren't followed, a webhook's URL is where its payloads go
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+
+}
+
+func isPublic(ip netip.Addr) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// pendingDelivery is a delivery waiting to be retried, with the payload it was created with
+type pendingDelivery struct {
+	delivery *poker.WebhookDelivery
+	body     []byte
+}
+
+// Send delivers the events to the webhook in order. The first attempts are made before returning, together given
+// firstAttemptTimeout, so the events are sent even where nothing runs once the request has been handled. Those that
+// fail are retried in the background until they succeed or retryBackoff runs out. previous is the index of the level
+// the timer was on before the events, or -1
+func (s *Sender) Send(ctx context.Context, webhook *poker.Webhook, events []poker.WebhookEvent, timer *poker.Timer, previous int) {
+
+	deadline := time.Now().Add(firstAttemptTimeout)
+
+	var failed []*pendingDelivery
+	for _, event := range events {
+		delivery, body, err := s.newDelivery(webhook, event, timer, previous)
+		if err != nil {
+			s.logger.WithError(err).WithField("webhookID", webhook.ID).Error("failed to create webhook delivery")
+			continue
+		}
+
+		// Once the time for first attempts is used up the rest of the events wait for the first retry
+		if timeout := time.Until(deadline); timeout > 0 {
+			s.attempt(ctx, timeout, webhook, delivery, body)
+			if delivery.Succeeded {
+				continue
+			}
+		}
+
+		failed = append(failed, &pendingDelivery{delivery: delivery, body: body})
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for _, wait := range retryBackoff {
+			time.Sleep(wait)
+
+			remaining := failed[:0]
+			for _, pending := range failed {
+				s.attempt(context.Background(), attemptTimeout, webhook, pending.delivery, pending.body)
+				if !pending.delivery.Succeeded {
+					remaining = append(remaining, pending)
+				}
+			}
+
+			failed = remaining
+			if len(failed) == 0 {
+				return
+			}
+		}
+	}()
+
+}
+
+// SendNow makes a single attempt to deliver the event, giving the receiver timeout to respond, and returns the
+// delivery once it has been made
+func (s *Sender) SendNow(ctx context.Context, timeout time.Duration, webhook *poker.Webhook, event poker.WebhookEvent, timer *poker.Timer, previous int) (*poker.WebhookDelivery, error) {
+
+	delivery, body, err := s.newDelivery(webhook, event, timer, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	s.attempt(ctx, timeout, webhook, delivery, body)
+
+	return delivery, nil
+
+}
+
+// Wait blocks until every delivery in the background has finished retrying or ctx is done
+func (s *Sender) Wait(ctx context.Context) error {
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+}
+
+// newDelivery encodes the payload straight away, so the timer can go on changing while it is sent
+func (s *Sender) newDelivery(webhook *poker.Webhook, event poker.WebhookEvent, timer *poker.Timer, previous int) (*poker.WebhookDelivery, []byte, error) {
+
+	now := time.Now()
+
+	delivery := &poker.WebhookDelivery{
+		ID:        uuid.New().String(),
+		WebhookID: webhook.ID,
+		Event:     event,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	body, err := json.Marshal(poker.NewWebhookPayload(delivery.ID, event, timer, previous, now))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	delivery.Payload = string(body)
+
+	return delivery, body, nil
+
+}
+
+// attempt sends the payload once, recording the outcome on the delivery and saving it to the log. The timeout only
+// applies to sending, so a slow receiver is still logged
+func (s *Sender) attempt(ctx context.Context, timeout time.Duration, webhook *poker.Webhook, delivery *poker.WebhookDelivery, body []byte) {
+
+	entry := s.logger.WithContext(ctx).WithField("webhookID", webhook.ID).WithField("deliveryID", delivery.ID)
+
+	delivery.Attempts++
+	delivery.StatusCode = 0
+	delivery.Error = ""
+
+	postCtx, cancel := context.WithTimeout(ctx, timeout)
+	res, err := s.post(postCtx, webhook, delivery, body)
+	cancel()
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		delivery.StatusCode = res.StatusCode
+		delivery.Succeeded = res.StatusCode >= 200 && res.StatusCode < 300
+		if !delivery.Succeeded {
+			delivery.Error = fmt.Sprintf("responded with %d %s", res.StatusCode, http.StatusText(res.StatusCode))
+		}
+	}
+
+	delivery.UpdatedAt = time.Now()
+
+	if !delivery.Succeeded {
+		entry.WithField("attempts", delivery.Attempts).WithField("error", delivery.Error).Warn("webhook delivery attempt failed")
+	}
+
+	err = s.repo.SaveWebhookDelivery(ctx, delivery)
+	if err != nil {
+		entry.WithError(err).Error("failed to save webhook delivery")
+	}
+
+}
+
+func (s *Sender) post(ctx context.Context, webhook *poker.Webhook, delivery *poker.WebhookDelivery, body []byte) (*http.Response, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(poker.WebhookEventHeader, delivery.Event.String())
+	req.Header.Set(poker.WebhookDeliveryHeader, delivery.ID)
+	// Every attempt is signed afresh, so a retry isn't refused for being too old
+	req.Header.Set(poker.WebhookSignatureHeader, poker.SignWebhookPayload(webhook.Secret, time.Now(), body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseBytes))
+
+	return res, nil
+
+}
@@ -0,0 +1,260 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+)
+
+// LevelBulkEdit changes every level between FromLevel and ToLevel at once. Fields left at 0 are not changed
+type LevelBulkEdit struct {
+	// FromLevel is the 1 based position of the first level changed, 0 to start at the first level
+	FromLevel uint
+	// ToLevel is the 1 based position of the last level changed, 0 to carry on to the last level
+	ToLevel uint
+	// BlindMultiplier scales the small blind, big blind and ante of blind levels, rounding them to the timer's smallest
+	// chip or to whole numbers when it has no chip set
+	BlindMultiplier float64
+	// DurationMin is the new duration of blind levels
+	DurationMin float64
+	// BreakDurationMin is the new duration of breaks
+	BreakDurationMin float64
+}
+
+// RenumberLevels sets each level's Level to its 1 based position in the timer
+func (t *Timer) RenumberLevels() {
+	for i, level := range t.Levels {
+		level.Level = float64(i + 1)
+	}
+}
+
+// LevelIndex returns the index of the level with the id, or -1 if the timer doesn't have it
+func (t *Timer) LevelIndex(id string) int {
+
+	for i, level := range t.Levels {
+		if level.ID == id {
+			return i
+		}
+	}
+
+	return -1
+
+}
+
+// InsertLevel adds level so that it ends up at the 1 based position, 0 appends it after the last level
+func (t *Timer) InsertLevel(level *TimerLevel, position uint) error {
+
+	if position > uint(len(t.Levels))+1 {
+		return fmt.Errorf("position must be between 1 and %d", len(t.Levels)+1)
+	}
+
+	if position == 0 {
+		position = uint(len(t.Levels)) + 1
+	}
+
+	t.rearrangeLevels(func() {
+		i := position - 1
+		t.Levels = append(t.Levels, nil)
+		copy(t.Levels[i+1:], t.Levels[i:])
+		t.Levels[i] = level
+	})
+
+	return nil
+
+}
+
+// RemoveLevel deletes the level with the id
+func (t *Timer) RemoveLevel(id string) error {
+
+	i := t.LevelIndex(id)
+	if i < 0 {
+		return fmt.Errorf("level not found")
+	}
+
+	t.rearrangeLevels(func() {
+		t.Levels = append(t.Levels[:i], t.Levels[i+1:]...)
+	})
+
+	return nil
+
+}
+
+// MoveLevel moves the level with the id to the 1 based position, shifting the levels in between
+func (t *Timer) MoveLevel(id string, position uint) error {
+
+	i := t.LevelIndex(id)
+	if i < 0 {
+		return fmt.Errorf("level not found")
+	}
+
+	if position < 1 || position > uint(len(t.Levels)) {
+		return fmt.Errorf("position must be between 1 and %d", len(t.Levels))
+	}
+
+	t.rearrangeLevels(func() {
+		level := t.Levels[i]
+		t.Levels = append(t.Levels[:i], t.Levels[i+1:]...)
+
+		j := position - 1
+		t.Levels = append(t.Levels, nil)
+		copy(t.Levels[j+1:], t.Levels[j:])
+		t.Levels[j] = level
+	})
+
+	return nil
+
+}
+
+// ReorderLevels puts the levels in the order of ids, which must list every level exactly once
+func (t *Timer) ReorderLevels(ids []string) error {
+
+	if len(ids) != len(t.Levels) {
+		return fmt.Errorf("the new order must include all %d levels", len(t.Levels))
+	}
+
+	levels := make([]*TimerLevel, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		i := t.LevelIndex(id)
+		if i < 0 || seen[id] {
+			return fmt.Errorf("the new order must include every level exactly once")
+		}
+		seen[id] = true
+		levels = append(levels, t.Levels[i])
+	}
+
+	t.rearrangeLevels(func() {
+		t.Levels = levels
+	})
+
+	return nil
+
+}
+
+// DuplicateLevel inserts a copy of the level with the id straight after it, the copy is given newID
+func (t *Timer) DuplicateLevel(id, newID string) (*TimerLevel, error) {
+
+	i := t.LevelIndex(id)
+	if i < 0 {
+		return nil, fmt.Errorf("level not found")
+	}
+
+	duplicate := *t.Levels[i]
+	duplicate.ID = newID
+	duplicate.DurationStr = ""
+	// Events mark a single point in the tournament, so they stay with the original level
+	duplicate.Events = nil
+
+	return &duplicate, t.InsertLevel(&duplicate, uint(i)+2)
+
+}
+
+// BulkEditLevels applies edit to the levels in its range. Nothing is changed unless every edited level is valid
+func (t *Timer) BulkEditLevels(edit LevelBulkEdit) error {
+
+	if len(t.Levels) == 0 {
+		return fmt.Errorf("the timer does not have any levels")
+	}
+
+	if edit.BlindMultiplier < 0 || edit.DurationMin < 0 || edit.BreakDurationMin < 0 {
+		return fmt.Errorf("multipliers and durations must be greater than or equal to 0")
+	}
+
+	if edit.BlindMultiplier == 0 && edit.DurationMin == 0 && edit.BreakDurationMin == 0 {
+		return fmt.Errorf("there is nothing to change, enter a multiplier or a duration")
+	}
+
+	from, to := edit.FromLevel, edit.ToLevel
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 {
+		to = uint(len(t.Levels))
+	}
+
+	if from > to || to > uint(len(t.Levels)) {
+		return fmt.Errorf("levels must be a range between 1 and %d", len(t.Levels))
+	}
+
+	var unit float64 = 1
+	if t.ChipSet != nil && len(t.ChipSet.Chips) > 0 {
+		unit = t.ChipSet.SortedChips()[0].Denomination
+	}
+
+	edited := make([]*TimerLevel, 0, to-from+1)
+	for i := from - 1; i < to; i++ {
+		level := *t.Levels[i]
+
+		switch level.Type {
+		case LevelTypeBlind:
+			if edit.BlindMultiplier > 0 {
+				level.SmallBlind = roundToUnit(level.SmallBlind*edit.BlindMultiplier, unit)
+				level.BigBlind = roundToUnit(level.BigBlind*edit.BlindMultiplier, unit)
+				level.Ante = roundToUnit(level.Ante*edit.BlindMultiplier, unit)
+			}
+			if edit.DurationMin > 0 {
+				level.DurationMin = edit.DurationMin
+			}
+		case LevelTypeBreak:
+			if edit.BreakDurationMin > 0 {
+				level.DurationMin = edit.BreakDurationMin
+			}
+		}
+
+		level.DurationSec = level.DurationMin * 60
+
+		err := level.Validate()
+		if err == nil && t.ChipSet != nil {
+			err = t.ChipSet.ValidateLevel(&level)
+		}
+		if err != nil {
+			return fmt.Errorf("level %d: %w", i+1, err)
+		}
+
+		edited = append(edited, &level)
+	}
+
+	for i, level := range edited {
+		*t.Levels[int(from)-1+i] = *level
+	}
+
+	return nil
+
+}
+
+// roundToUnit rounds amount to the closest multiple of unit, never rounding an amount above 0 down to 0
+func roundToUnit(amount, unit float64) float64 {
+
+	if amount <= 0 {
+		return amount
+	}
+
+	return math.Max(math.Round(amount/unit), 1) * unit
+
+}
+
+// rearrangeLevels runs fn, which changes the order or number of levels, keeping the timer on the level it was on
+// and renumbering the levels afterwards. If the current level is removed the timer stays at the same position, on a
+// level that hasn't been played, so its clock is stopped at the start of it
+func (t *Timer) rearrangeLevels(fn func()) {
+
+	current := t.Level()
+
+	fn()
+
+	for i, level := range t.Levels {
+		if level == current {
+			t.CurrentLevel = uint(i)
+		}
+	}
+
+	if len(t.Levels) > 0 && int(t.CurrentLevel) > len(t.Levels)-1 {
+		t.CurrentLevel = uint(len(t.Levels) - 1)
+	}
+
+	if next := t.Level(); current != nil && (next == nil || next.ID != current.ID) {
+		t.StopClock()
+	}
+
+	t.RenumberLevels()
+
+}
@@ -0,0 +1,267 @@
+package poker
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// levelsTimer has four levels and is part way through the second with its clock running
+func levelsTimer(startedAt time.Time) *Timer {
+
+	timer := &Timer{
+		ID: "levels",
+		Levels: []*TimerLevel{
+			{ID: "a", TimerID: "levels", Type: LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 20, DurationSec: 1200},
+			{ID: "b", TimerID: "levels", Type: LevelTypeBlind, SmallBlind: 50, BigBlind: 100, DurationMin: 20, DurationSec: 1200},
+			{ID: "c", TimerID: "levels", Type: LevelTypeBreak, DurationMin: 10, DurationSec: 600},
+			{ID: "d", TimerID: "levels", Type: LevelTypeBlind, SmallBlind: 100, BigBlind: 200, Ante: 25, DurationMin: 20, DurationSec: 1200},
+		},
+		CurrentLevel: 1,
+		ElapsedSec:   300,
+		StartedAt:    &startedAt,
+	}
+	timer.RenumberLevels()
+
+	return timer
+
+}
+
+func levelIDs(timer *Timer) string {
+
+	ids := make([]string, 0, len(timer.Levels))
+	for i, level := range timer.Levels {
+		if level.Level != float64(i+1) {
+			ids = append(ids, "!")
+		}
+		ids = append(ids, level.ID)
+	}
+
+	return strings.Join(ids, "")
+
+}
+
+func TestRearrangeLevels(t *testing.T) {
+
+	startedAt := time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name        string
+		change      func(timer *Timer) error
+		wantIDs     string
+		wantCurrent string
+		// wantPlayed is whether the clock carries on with the time already played on the current level
+		wantPlayed bool
+	}{
+		{
+			name:        "insert before the current level",
+			change:      func(timer *Timer) error { return timer.InsertLevel(&TimerLevel{ID: "x", Type: LevelTypeBreak}, 1) },
+			wantIDs:     "xabcd",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+		{
+			name:        "insert after the last level",
+			change:      func(timer *Timer) error { return timer.InsertLevel(&TimerLevel{ID: "x", Type: LevelTypeBreak}, 0) },
+			wantIDs:     "abcdx",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+		{
+			name:        "move the current level",
+			change:      func(timer *Timer) error { return timer.MoveLevel("b", 4) },
+			wantIDs:     "acdb",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+		{
+			name:        "move a level over the current level",
+			change:      func(timer *Timer) error { return timer.MoveLevel("d", 1) },
+			wantIDs:     "dabc",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+		{
+			name:        "reorder",
+			change:      func(timer *Timer) error { return timer.ReorderLevels([]string{"d", "c", "b", "a"}) },
+			wantIDs:     "dcba",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+		{
+			name:        "delete a level before the current level",
+			change:      func(timer *Timer) error { return timer.RemoveLevel("a") },
+			wantIDs:     "bcd",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+		{
+			name:        "delete the current level",
+			change:      func(timer *Timer) error { return timer.RemoveLevel("b") },
+			wantIDs:     "acd",
+			wantCurrent: "c",
+		},
+		{
+			name: "delete the current level when it is the last",
+			change: func(timer *Timer) error {
+				timer.CurrentLevel = 3
+				return timer.RemoveLevel("d")
+			},
+			wantIDs:     "abc",
+			wantCurrent: "c",
+		},
+		{
+			name:        "duplicate the current level",
+			change:      func(timer *Timer) error { _, err := timer.DuplicateLevel("b", "x"); return err },
+			wantIDs:     "abxcd",
+			wantCurrent: "b",
+			wantPlayed:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			timer := levelsTimer(startedAt)
+
+			err := tc.change(timer)
+			if err != nil {
+				t.Fatalf("failed to change levels: %s", err)
+			}
+
+			if got := levelIDs(timer); got != tc.wantIDs {
+				t.Errorf("expected the levels %s, got %s", tc.wantIDs, got)
+			}
+
+			if got := timer.Level().ID; got != tc.wantCurrent {
+				t.Errorf("expected to be on level %s, got %s", tc.wantCurrent, got)
+			}
+
+			played := timer.IsRunning() && timer.ElapsedSec == 300
+			stopped := !timer.IsRunning() && !timer.IsPaused() && timer.ElapsedSec == 0
+			if tc.wantPlayed && !played {
+				t.Errorf("expected the clock to carry on with the time played, got %v played started at %v", timer.ElapsedSec, timer.StartedAt)
+			}
+			if !tc.wantPlayed && !stopped {
+				t.Errorf("expected the clock to be stopped at the start of the level, got %v played started at %v", timer.ElapsedSec, timer.StartedAt)
+			}
+
+		})
+	}
+
+}
+
+func TestRearrangeLevelsRefused(t *testing.T) {
+
+	timer := levelsTimer(time.Now())
+
+	for name, err := range map[string]error{
+		"insert past the end":     timer.InsertLevel(&TimerLevel{ID: "x"}, 6),
+		"move past the end":       timer.MoveLevel("a", 5),
+		"move a missing level":    timer.MoveLevel("x", 1),
+		"delete a missing level":  timer.RemoveLevel("x"),
+		"reorder without a level": timer.ReorderLevels([]string{"a", "b", "c"}),
+		"reorder a level twice":   timer.ReorderLevels([]string{"a", "b", "c", "c"}),
+	} {
+		if err == nil {
+			t.Errorf("%s: expected the change to be refused", name)
+		}
+	}
+
+	if got := levelIDs(timer); got != "abcd" {
+		t.Errorf("expected the levels to be left alone, got %s", got)
+	}
+
+}
+
+func TestBulkEditLevels(t *testing.T) {
+
+	chipSet := &ChipSet{Chips: []*Chip{
+		{Denomination: 100, Color: "black", Count: 100},
+		{Denomination: 25, Color: "white", Count: 100},
+		{Denomination: 500, Color: "purple", Count: 100},
+	}}
+
+	tt := []struct {
+		name    string
+		chipSet *ChipSet
+		edit    LevelBulkEdit
+		// want is the small blind, big blind, ante and duration of each level afterwards
+		want [][4]float64
+		// wantErr is part of the error expected, nothing is changed when it is set
+		wantErr string
+	}{
+		{
+			name: "multiplier rounded to whole numbers",
+			edit: LevelBulkEdit{BlindMultiplier: 1.3},
+			want: [][4]float64{{33, 65, 0, 20}, {65, 130, 0, 20}, {0, 0, 0, 10}, {130, 260, 33, 20}},
+		},
+		{
+			name:    "multiplier rounded to the smallest chip",
+			chipSet: chipSet,
+			edit:    LevelBulkEdit{BlindMultiplier: 1.3},
+			want:    [][4]float64{{25, 75, 0, 20}, {75, 125, 0, 20}, {0, 0, 0, 10}, {125, 250, 25, 20}},
+		},
+		{
+			name:    "multiplier never rounds a blind away",
+			chipSet: chipSet,
+			edit:    LevelBulkEdit{BlindMultiplier: 0.1},
+			want:    [][4]float64{{25, 25, 0, 20}, {25, 25, 0, 20}, {0, 0, 0, 10}, {25, 25, 25, 20}},
+		},
+		{
+			name: "durations within a range",
+			edit: LevelBulkEdit{FromLevel: 2, ToLevel: 3, DurationMin: 15, BreakDurationMin: 5},
+			want: [][4]float64{{25, 50, 0, 20}, {50, 100, 0, 15}, {0, 0, 0, 5}, {100, 200, 25, 20}},
+		},
+		{
+			name:    "blinds the chip set can't make",
+			chipSet: &ChipSet{Chips: []*Chip{{Denomination: 100, Color: "black", Count: 100}}},
+			edit:    LevelBulkEdit{FromLevel: 2, DurationMin: 15},
+			wantErr: "level 2: the small blind of 50",
+		},
+		{
+			name:    "range past the last level",
+			edit:    LevelBulkEdit{FromLevel: 2, ToLevel: 5, DurationMin: 15},
+			wantErr: "between 1 and 4",
+		},
+		{
+			name:    "nothing to change",
+			edit:    LevelBulkEdit{FromLevel: 1},
+			wantErr: "nothing to change",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			timer := levelsTimer(time.Now())
+			timer.ChipSet = tc.chipSet
+			before := make([][4]float64, 0, len(timer.Levels))
+			for _, level := range timer.Levels {
+				before = append(before, [4]float64{level.SmallBlind, level.BigBlind, level.Ante, level.DurationMin})
+			}
+
+			err := timer.BulkEditLevels(tc.edit)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected an error containing %q, got %v", tc.wantErr, err)
+				}
+				tc.want = before
+			} else if err != nil {
+				t.Fatalf("failed to edit levels: %s", err)
+			}
+
+			for i, level := range timer.Levels {
+				got := [4]float64{level.SmallBlind, level.BigBlind, level.Ante, level.DurationMin}
+				if got != tc.want[i] {
+					t.Errorf("level %d: expected %v, got %v", i+1, tc.want[i], got)
+				}
+				if level.DurationSec != level.DurationMin*60 {
+					t.Errorf("level %d: expected the duration in seconds to follow the minutes, got %v", i+1, level.DurationSec)
+				}
+			}
+
+		})
+	}
+
+}
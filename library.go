@@ -0,0 +1,129 @@
+package poker
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BuiltinStructure is one of the curated structures every user can start a timer from
+type BuiltinStructure struct {
+	Slug        string
+	Description string
+	// Timer holds the structure's name and levels. It isn't owned by anyone, use Clone to get a timer to save
+	Timer *Timer
+
+	file string
+}
+
+// builtinStructures are listed in the order they are offered, shortest game first
+var builtinStructures = []*BuiltinStructure{
+	{
+		Slug:        "sit-and-go",
+		Description: "A single table with 12 minute levels and no breaks, finishes in around two and a half hours",
+		file:        "sit-and-go.yaml",
+	},
+	{
+		Slug:        "turbo",
+		Description: "10 minute levels that climb quickly, for when the game needs to be over in three hours",
+		file:        "turbo.yaml",
+	},
+	{
+		Slug:        "standard",
+		Description: "20 minute levels with antes after the first break, the usual home game night",
+		file:        "standard.yaml",
+	},
+	{
+		Slug:        "deep-stack",
+		Description: "30 minute levels with a gentle climb, for long games with plenty of play",
+		file:        "deep-stack.yaml",
+	},
+}
+
+var (
+	loadStructuresOnce sync.Once
+	loadStructuresErr  error
+)
+
+// BuiltinStructures returns the curated structures, read from the embedded YAML files the first time they're needed
+func BuiltinStructures() ([]*BuiltinStructure, error) {
+
+	loadStructuresOnce.Do(func() {
+		for _, structure := range builtinStructures {
+			file, err := structures.Open(path.Join("structures", structure.file))
+			if err != nil {
+				loadStructuresErr = fmt.Errorf("failed to open built-in structure %s: %w", structure.Slug, err)
+				return
+			}
+
+			structure.Timer, err = DecodeTimer(file, TimerFormatYAML)
+			_ = file.Close()
+			if err != nil {
+				loadStructuresErr = fmt.Errorf("failed to decode built-in structure %s: %w", structure.Slug, err)
+				return
+			}
+		}
+	})
+
+	if loadStructuresErr != nil {
+		return nil, loadStructuresErr
+	}
+
+	return builtinStructures, nil
+
+}
+
+// BuiltinStructureBySlug returns the curated structure with the slug, or nil when there isn't one
+func BuiltinStructureBySlug(slug string) (*BuiltinStructure, error) {
+
+	all, err := BuiltinStructures()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, structure := range all {
+		if structure.Slug == slug {
+			return structure, nil
+		}
+	}
+
+	return nil, nil
+
+}
+
+// Clone copies the timer's name, levels and chip set into a new timer owned by userID. The copy has new ids, starts at
+// the first level with the clock stopped, has no planned start, and isn't shared or marked as a template
+func (t *Timer) Clone(userID string) *Timer {
+
+	clone := &Timer{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Name:   t.Name,
+		Levels: make([]*TimerLevel, 0, len(t.Levels)),
+	}
+
+	for _, level := range t.Levels {
+		copied := *level
+		copied.ID = uuid.New().String()
+		copied.TimerID = clone.ID
+		copied.DurationStr = ""
+		copied.Events = append([]LevelEvent(nil), level.Events...)
+		clone.Levels = append(clone.Levels, &copied)
+	}
+
+	if t.ChipSet != nil {
+		chipSet := &ChipSet{Name: t.ChipSet.Name, Chips: make([]*Chip, 0, len(t.ChipSet.Chips))}
+		for _, chip := range t.ChipSet.Chips {
+			copied := *chip
+			chipSet.Chips = append(chipSet.Chips, &copied)
+		}
+		clone.ChipSet = chipSet
+	}
+
+	clone.RenumberLevels()
+
+	return clone
+
+}
@@ -0,0 +1,15 @@
+package poker
+
+import "context"
+
+// Mailer sends email, such as the links used to sign in without a password
+type Mailer interface {
+	SendMail(ctx context.Context, mail *Mail) error
+}
+
+// Mail is a plain text email to a single recipient
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
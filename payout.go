@@ -0,0 +1,235 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+type PayoutScheme string
+
+const (
+	// PayoutSchemeTable pays by a percentage table chosen by the size of the field
+	PayoutSchemeTable PayoutScheme = "table"
+	// PayoutSchemeTop pays the top TopPercent of the field on a sliding curve
+	PayoutSchemeTop PayoutScheme = "top"
+	// PayoutSchemeManual pays by the percentages entered by the tournament director
+	PayoutSchemeManual PayoutScheme = "manual"
+)
+
+func (ps PayoutScheme) String() string {
+	return string(ps)
+}
+
+var allPayoutSchemes = []PayoutScheme{PayoutSchemeTable, PayoutSchemeTop, PayoutSchemeManual}
+
+func (ps PayoutScheme) Valid() bool {
+	for _, s := range allPayoutSchemes {
+		if s == ps {
+			return true
+		}
+	}
+	return false
+}
+
+var strAllPayoutSchemes = []string{PayoutSchemeTable.String(), PayoutSchemeTop.String(), PayoutSchemeManual.String()}
+
+// payoutTable is the percentage each place is paid in fields of up to maxEntrants players
+type payoutTable struct {
+	maxEntrants int
+	percents    []float64
+}
+
+// payoutTables are checked in order, fields larger than the last table fall back to paying the top 15%
+var payoutTables = []payoutTable{
+	{maxEntrants: 3, percents: []float64{100}},
+	{maxEntrants: 6, percents: []float64{65, 35}},
+	{maxEntrants: 10, percents: []float64{50, 30, 20}},
+	{maxEntrants: 20, percents: []float64{45, 25, 18, 12}},
+	{maxEntrants: 30, percents: []float64{38, 24, 16, 12, 10}},
+	{maxEntrants: 50, percents: []float64{32, 20, 14, 11, 9, 8, 6}},
+	{maxEntrants: 100, percents: []float64{27, 17, 12, 9, 7.5, 6.5, 6, 5.5, 5, 4.5}},
+}
+
+const defaultPayoutTopPercent = 15
+
+// PayoutRules decide how a prize pool is split between the places
+type PayoutRules struct {
+	// Scheme defaults to PayoutSchemeTable when empty
+	Scheme PayoutScheme
+	// TopPercent is the share of the field that is paid under PayoutSchemeTop
+	TopPercent float64
+	// Manual is the percentage paid to each place, starting with first, under PayoutSchemeManual
+	Manual []float64
+	// RoundTo is the smallest chip or note payouts are made in. Every place but first is rounded
+	// down to it and first place takes what is left over. 0 leaves payouts unrounded
+	RoundTo float64
+}
+
+type Payout struct {
+	// Place is the 1 based finishing position being paid
+	Place   int
+	Percent float64
+	Amount  float64
+}
+
+func (r PayoutRules) Validate() error {
+
+	if r.Scheme != "" && !r.Scheme.Valid() {
+		return fmt.Errorf("payout scheme is not valid, expected one of: %s", strings.Join(strAllPayoutSchemes, ","))
+	}
+
+	if r.RoundTo < 0 {
+		return fmt.Errorf("payout rounding must be greater than or equal to 0")
+	}
+
+	switch r.Scheme {
+	case PayoutSchemeTop:
+		if r.TopPercent <= 0 || r.TopPercent > 100 {
+			return fmt.Errorf("the share of the field paid must be greater than 0 and at most 100 percent")
+		}
+	case PayoutSchemeManual:
+		if len(r.Manual) == 0 {
+			return fmt.Errorf("at least one place must be paid")
+		}
+
+		var total float64
+		for i, percent := range r.Manual {
+			if percent <= 0 {
+				return fmt.Errorf("the payout for place %d must be greater than 0", i+1)
+			}
+			if i > 0 && percent > r.Manual[i-1] {
+				return fmt.Errorf("place %d cannot be paid more than place %d", i+1, i)
+			}
+			total += percent
+		}
+
+		if math.Abs(total-100) > 0.01 {
+			return fmt.Errorf("payout percentages must add up to 100, they add up to %v", total)
+		}
+	}
+
+	return nil
+
+}
+
+// Percents returns the share of the pool paid to each place for a field of entrants, starting with first.
+// When fewer players entered than there are places paid, the places that can't be filled are dropped and
+// the remaining places are scaled back up to 100
+func (r PayoutRules) Percents(entrants int) []float64 {
+
+	if entrants <= 0 {
+		return nil
+	}
+
+	var percents []float64
+	switch r.Scheme {
+	case PayoutSchemeTop:
+		percents = topPercents(entrants, r.TopPercent)
+	case PayoutSchemeManual:
+		percents = r.Manual
+	default:
+		percents = tablePercents(entrants)
+	}
+
+	if len(percents) > entrants {
+		percents = percents[:entrants]
+	}
+
+	var total float64
+	for _, percent := range percents {
+		total += percent
+	}
+
+	scaled := make([]float64, len(percents))
+	for i, percent := range percents {
+		scaled[i] = percent / total * 100
+	}
+
+	return scaled
+
+}
+
+// Payouts splits pool between the places paid in a field of entrants
+func (r PayoutRules) Payouts(entrants int, pool float64) ([]*Payout, error) {
+
+	err := r.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	percents := r.Percents(entrants)
+	if len(percents) == 0 || pool <= 0 {
+		return nil, nil
+	}
+
+	payouts := make([]*Payout, len(percents))
+	var paid float64
+	for i := len(percents) - 1; i >= 0; i-- {
+		// Amounts are paid in whole cents, so first place takes what is left after the others are rounded to them
+		amount := pool * percents[i] / 100
+		if i > 0 {
+			amount = math.Round(r.round(amount)*100) / 100
+			paid += amount
+		} else {
+			amount = math.Round((pool-paid)*100) / 100
+		}
+
+		payouts[i] = &Payout{
+			Place:   i + 1,
+			Percent: percents[i],
+			Amount:  amount,
+		}
+	}
+
+	return payouts, nil
+
+}
+
+func (r PayoutRules) round(amount float64) float64 {
+
+	if r.RoundTo == 0 {
+		return amount
+	}
+
+	// A little slack stops an amount that is a whole multiple, but not quite in floating point, losing a unit
+	return math.Floor(amount/r.RoundTo+1e-9) * r.RoundTo
+
+}
+
+func tablePercents(entrants int) []float64 {
+
+	for _, table := range payoutTables {
+		if entrants <= table.maxEntrants {
+			return table.percents
+		}
+	}
+
+	return topPercents(entrants, defaultPayoutTopPercent)
+
+}
+
+// topPercents pays the top percent of the field, each place getting a share in proportion
+// to 1/place so first takes about twice second and three times third
+func topPercents(entrants int, percent float64) []float64 {
+
+	places := int(math.Ceil(float64(entrants) * percent / 100))
+	if places < 1 {
+		places = 1
+	}
+
+	var total float64
+	weights := make([]float64, places)
+	for i := range weights {
+		weights[i] = 1 / float64(i+1)
+		total += weights[i]
+	}
+
+	percents := make([]float64, places)
+	for i, weight := range weights {
+		percents[i] = weight / total * 100
+	}
+
+	return percents
+
+}
@@ -0,0 +1,143 @@
+package poker
+
+import (
+	"math"
+	"testing"
+)
+
+func sumPayouts(payouts []*Payout) float64 {
+
+	var total float64
+	for _, payout := range payouts {
+		total += payout.Amount
+	}
+
+	return total
+
+}
+
+func TestPayoutTable(t *testing.T) {
+
+	tt := []struct {
+		entrants int
+		places   int
+	}{
+		{entrants: 2, places: 1},
+		{entrants: 6, places: 2},
+		{entrants: 9, places: 3},
+		{entrants: 45, places: 7},
+		{entrants: 100, places: 10},
+		// Larger fields pay the top 15%
+		{entrants: 200, places: 30},
+	}
+
+	for _, tc := range tt {
+		payouts, err := PayoutRules{}.Payouts(tc.entrants, 1000)
+		if err != nil {
+			t.Fatalf("failed to calculate payouts for %d entrants: %s", tc.entrants, err)
+		}
+
+		if len(payouts) != tc.places {
+			t.Errorf("expected %d entrants to pay %d places, got %d", tc.entrants, tc.places, len(payouts))
+		}
+
+		if total := sumPayouts(payouts); math.Abs(total-1000) > 0.01 {
+			t.Errorf("expected %d entrants to pay out the whole pool of 1000, got %v", tc.entrants, total)
+		}
+
+		for i := 1; i < len(payouts); i++ {
+			if payouts[i].Amount > payouts[i-1].Amount {
+				t.Errorf("expected place %d of %d entrants not to be paid more than place %d", i+1, tc.entrants, i)
+			}
+		}
+	}
+
+}
+
+func TestPayoutTopPercent(t *testing.T) {
+
+	rules := PayoutRules{Scheme: PayoutSchemeTop, TopPercent: 20}
+
+	percents := rules.Percents(50)
+	if len(percents) != 10 {
+		t.Fatalf("expected the top 20%% of 50 to be 10 places, got %d", len(percents))
+	}
+
+	// Places are weighted by 1/place, so first is paid twice second
+	if math.Abs(percents[0]-2*percents[1]) > 1e-9 {
+		t.Errorf("expected first to be paid twice second, got %v and %v", percents[0], percents[1])
+	}
+
+	// Anything below one place still pays the winner
+	if got := rules.Percents(2); len(got) != 1 || got[0] != 100 {
+		t.Errorf("expected the winner of 2 to take everything, got %v", got)
+	}
+
+}
+
+func TestPayoutManualScaledToField(t *testing.T) {
+
+	rules := PayoutRules{Scheme: PayoutSchemeManual, Manual: []float64{50, 30, 20}}
+
+	// With only two players the third place can't be paid, so the first two are scaled up to the whole pool
+	payouts, err := rules.Payouts(2, 800)
+	if err != nil {
+		t.Fatalf("failed to calculate payouts: %s", err)
+	}
+
+	if len(payouts) != 2 || payouts[0].Amount != 500 || payouts[1].Amount != 300 {
+		t.Fatalf("expected 500 and 300, got %+v", payouts)
+	}
+
+}
+
+func TestPayoutRounding(t *testing.T) {
+
+	rules := PayoutRules{RoundTo: 5}
+
+	payouts, err := rules.Payouts(10, 333)
+	if err != nil {
+		t.Fatalf("failed to calculate payouts: %s", err)
+	}
+
+	// 30% and 20% of 333 are rounded down to 95 and 65, first takes what is left
+	want := []float64{173, 95, 65}
+	for i, payout := range payouts {
+		if payout.Amount != want[i] {
+			t.Errorf("expected place %d to be paid %v, got %v", i+1, want[i], payout.Amount)
+		}
+	}
+
+	if total := sumPayouts(payouts); total != 333 {
+		t.Errorf("expected rounding to pay out the whole pool, got %v", total)
+	}
+
+}
+
+func TestPayoutRulesValidate(t *testing.T) {
+
+	tt := []struct {
+		name  string
+		rules PayoutRules
+		valid bool
+	}{
+		{name: "default", rules: PayoutRules{}, valid: true},
+		{name: "unknown scheme", rules: PayoutRules{Scheme: "lottery"}},
+		{name: "negative rounding", rules: PayoutRules{RoundTo: -1}},
+		{name: "top without a percent", rules: PayoutRules{Scheme: PayoutSchemeTop}},
+		{name: "manual adding to 100", rules: PayoutRules{Scheme: PayoutSchemeManual, Manual: []float64{60, 40}}, valid: true},
+		{name: "manual not adding to 100", rules: PayoutRules{Scheme: PayoutSchemeManual, Manual: []float64{60, 30}}},
+		{name: "manual paying second more", rules: PayoutRules{Scheme: PayoutSchemeManual, Manual: []float64{40, 60}}},
+	}
+
+	for _, tc := range tt {
+		err := tc.rules.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected rules to be valid, got %s", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected rules to be refused", tc.name)
+		}
+	}
+
+}
@@ -0,0 +1,144 @@
+package poker
+
+import (
+	"sort"
+	"time"
+)
+
+// Schedule is when each level of a timer is expected to be played
+type Schedule struct {
+	Levels []*ScheduledLevel
+	// StartsAt is when the first level starts, or started if it is already being played
+	StartsAt time.Time
+	EndsAt   time.Time
+	// IsLive is true when the times are projected from the clock, rather than the timer's planned start
+	IsLive bool
+	// IsPlanned is true when the times come from the timer's planned start, when it is false and the timer
+	// isn't live the times are what they would be if it were started now
+	IsPlanned bool
+}
+
+type ScheduledLevel struct {
+	Level *TimerLevel
+	// Number is the 1 based position of the level in the timer, breaks included
+	Number   uint
+	StartsAt time.Time
+	EndsAt   time.Time
+	// IsPlayed and IsCurrent are only ever set on a live schedule
+	IsPlayed  bool
+	IsCurrent bool
+}
+
+// ScheduledEvent is a level event along with the level it happens at the start of
+type ScheduledEvent struct {
+	Event LevelEvent
+	Level *ScheduledLevel
+}
+
+// UpcomingEvents are the events of the levels after the current one, the first time each event happens
+func (s *Schedule) UpcomingEvents() []*ScheduledEvent {
+
+	events := make([]*ScheduledEvent, 0)
+	for _, event := range AllLevelEvents {
+		for _, level := range s.Levels {
+			if level.IsPlayed || level.IsCurrent || !level.Level.HasEvent(event) {
+				continue
+			}
+
+			events = append(events, &ScheduledEvent{Event: event, Level: level})
+			break
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Level.Number < events[j].Level.Number
+	})
+
+	return events
+
+}
+
+// NextBreak is the first break after the current level, nil if there are none left
+func (s *Schedule) NextBreak() *ScheduledLevel {
+
+	for _, level := range s.Levels {
+		if level.Level.Type == LevelTypeBreak && !level.IsPlayed && !level.IsCurrent {
+			return level
+		}
+	}
+
+	return nil
+
+}
+
+// IsStarted reports whether the timer has been played at all, which is when its schedule is taken from the clock
+func (t *Timer) IsStarted() bool {
+	return t.IsRunning() || t.IsPaused() || t.CurrentLevel > 0 || t.ElapsedSec > 0
+}
+
+// Schedule projects when each level will be played as of now. Once the timer has been started the current level
+// ends when the clock runs out and the levels either side are laid out from there, so skipping, pausing and
+// extending levels moves the rest of the schedule with them. Before that the schedule starts at ScheduledStart,
+// or now when it hasn't been set or has already passed. Nil is returned for a complete timer or one without levels
+func (t *Timer) Schedule(now time.Time) *Schedule {
+
+	if t.IsComplete || len(t.Levels) == 0 {
+		return nil
+	}
+
+	schedule := &Schedule{
+		Levels: make([]*ScheduledLevel, len(t.Levels)),
+		IsLive: t.IsStarted(),
+	}
+
+	// anchor is the index of the level whose start time is known, the others are worked out from it
+	var anchor int
+	var start time.Time
+	switch {
+	case schedule.IsLive:
+		level := t.Level()
+		anchor = int(t.CurrentLevel)
+		start = now.Add(t.Remaining(now)).Add(-levelDuration(level))
+	case t.ScheduledStart != nil && t.ScheduledStart.After(now):
+		schedule.IsPlanned = true
+		start = *t.ScheduledStart
+	default:
+		start = now
+	}
+
+	at := start
+	for i := anchor; i < len(t.Levels); i++ {
+		level := t.Levels[i]
+		schedule.Levels[i] = &ScheduledLevel{
+			Level:     level,
+			Number:    uint(i + 1),
+			StartsAt:  at,
+			EndsAt:    at.Add(levelDuration(level)),
+			IsCurrent: schedule.IsLive && i == anchor,
+		}
+		at = schedule.Levels[i].EndsAt
+	}
+	schedule.EndsAt = at
+
+	at = start
+	for i := anchor - 1; i >= 0; i-- {
+		level := t.Levels[i]
+		schedule.Levels[i] = &ScheduledLevel{
+			Level:    level,
+			Number:   uint(i + 1),
+			StartsAt: at.Add(-levelDuration(level)),
+			EndsAt:   at,
+			IsPlayed: true,
+		}
+		at = schedule.Levels[i].StartsAt
+	}
+	schedule.StartsAt = at
+
+	return schedule
+
+}
+
+// levelDuration is how long the level is played for, measured the same way as the clock
+func levelDuration(level *TimerLevel) time.Duration {
+	return time.Duration(level.DurationSec * float64(time.Second))
+}
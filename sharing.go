@@ -0,0 +1,209 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimerRole is what a user may do with a timer. Each role can do everything the roles before it can
+type TimerRole string
+
+const (
+	// TimerRoleViewer may watch the timer and read its structure
+	TimerRoleViewer TimerRole = "viewer"
+	// TimerRoleOperator may also run the clock
+	TimerRoleOperator TimerRole = "operator"
+	// TimerRoleEditor may also change the levels, chips and schedule
+	TimerRoleEditor TimerRole = "editor"
+	// TimerRoleOwner is the user the timer belongs to, it can't be granted to anyone else
+	TimerRoleOwner TimerRole = "owner"
+)
+
+func (r TimerRole) String() string {
+	return string(r)
+}
+
+// AllTimerRoles are the roles an owner can grant, in the order they are offered
+var AllTimerRoles = []TimerRole{TimerRoleViewer, TimerRoleOperator, TimerRoleEditor}
+
+// Valid reports whether the role can be granted to another user
+func (r TimerRole) Valid() bool {
+	for _, role := range AllTimerRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+var strAllTimerRoles = []string{TimerRoleViewer.String(), TimerRoleOperator.String(), TimerRoleEditor.String()}
+
+func (r TimerRole) rank() int {
+
+	switch r {
+	case TimerRoleViewer:
+		return 1
+	case TimerRoleOperator:
+		return 2
+	case TimerRoleEditor:
+		return 3
+	case TimerRoleOwner:
+		return 4
+	}
+
+	return 0
+
+}
+
+// Includes reports whether a user with the role may do what required allows. The empty role includes nothing
+func (r TimerRole) Includes(required TimerRole) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// TimerShare grants a user other than the owner a role on the timer. It starts as an invitation, which gives no
+// access until the user accepts it
+type TimerShare struct {
+	UserID string
+	// Email is the address the user was invited by, kept so the owner can tell who they shared with
+	Email     string
+	Role      TimerRole
+	InvitedAt time.Time
+	// AcceptedAt is nil while the invitation is waiting to be accepted
+	AcceptedAt *time.Time
+}
+
+func (s *TimerShare) IsAccepted() bool {
+	return s.AcceptedAt != nil
+}
+
+func (s TimerShare) Validate() error {
+
+	if s.UserID == "" {
+		return fmt.Errorf("user id cannot be empty")
+	}
+
+	if !s.Role.Valid() {
+		return fmt.Errorf("role is not a valid role, expected one of: %s", strings.Join(strAllTimerRoles, ","))
+	}
+
+	return nil
+
+}
+
+// ValidateShares checks every share is valid and that nobody, the owner included, is shared with more than once
+func (t Timer) ValidateShares() error {
+
+	for i, share := range t.Shares {
+		err := share.Validate()
+		if err != nil {
+			return err
+		}
+
+		if share.UserID == t.UserID {
+			return fmt.Errorf("a timer cannot be shared with its owner")
+		}
+
+		for _, other := range t.Shares[:i] {
+			if other.UserID == share.UserID {
+				return fmt.Errorf("the timer is already shared with %s", share.Email)
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// Share returns the share for the user, accepted or not, nil when the timer hasn't been shared with them
+func (t *Timer) Share(userID string) *TimerShare {
+
+	for _, share := range t.Shares {
+		if share.UserID == userID {
+			return share
+		}
+	}
+
+	return nil
+
+}
+
+// RoleOf is the role the user has on the timer, empty when they have none. An invitation that hasn't been accepted
+// yet grants nothing
+func (t *Timer) RoleOf(userID string) TimerRole {
+
+	if userID == "" {
+		return ""
+	}
+
+	if t.UserID == userID {
+		return TimerRoleOwner
+	}
+
+	share := t.Share(userID)
+	if share == nil || !share.IsAccepted() {
+		return ""
+	}
+
+	return share.Role
+
+}
+
+// Invite shares the timer with the user at the role. Inviting someone the timer is already shared with changes
+// their role, keeping their invitation accepted if it was
+func (t *Timer) Invite(user *User, role TimerRole, now time.Time) error {
+
+	if user.ID == t.UserID {
+		return fmt.Errorf("you already own this timer")
+	}
+
+	if !role.Valid() {
+		return fmt.Errorf("role is not a valid role, expected one of: %s", strings.Join(strAllTimerRoles, ","))
+	}
+
+	if share := t.Share(user.ID); share != nil {
+		share.Role = role
+		share.Email = user.Email
+		return nil
+	}
+
+	t.Shares = append(t.Shares, &TimerShare{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      role,
+		InvitedAt: now,
+	})
+
+	return nil
+
+}
+
+// AcceptInvitation gives the user the access they were invited with
+func (t *Timer) AcceptInvitation(userID string, now time.Time) error {
+
+	share := t.Share(userID)
+	if share == nil {
+		return fmt.Errorf("you have not been invited to this timer")
+	}
+
+	if !share.IsAccepted() {
+		share.AcceptedAt = &now
+	}
+
+	return nil
+
+}
+
+// Unshare removes the user's share or invitation, reporting whether there was one
+func (t *Timer) Unshare(userID string) bool {
+
+	for i, share := range t.Shares {
+		if share.UserID == userID {
+			t.Shares = append(t.Shares[:i], t.Shares[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+
+}
@@ -0,0 +1,236 @@
+package poker
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// TimerSheet is a timer's structure laid out for a paper sheet handed to the table
+type TimerSheet struct {
+	Timer *Timer
+	// Tournament is the tournament played on the timer, nil when there isn't one
+	Tournament *Tournament
+	Rows       []*TimerSheetRow
+	// TotalMin is how long the whole structure takes to play
+	TotalMin float64
+}
+
+type TimerSheetRow struct {
+	Level *TimerLevel
+	// Number is the 1 based position of the level in the timer, breaks included, which is how rebuy and add-on
+	// cut offs count levels
+	Number uint
+	// StartMin and EndMin are the minutes of play before the level starts and once it has finished
+	StartMin float64
+	EndMin   float64
+}
+
+// SheetDetail is a line of tournament information printed above the levels, such as the buy in
+type SheetDetail struct {
+	Label string
+	Value string
+}
+
+// NewTimerSheet lays out the levels of timer, tournament may be nil
+func NewTimerSheet(timer *Timer, tournament *Tournament) *TimerSheet {
+
+	sheet := &TimerSheet{
+		Timer:      timer,
+		Tournament: tournament,
+		Rows:       make([]*TimerSheetRow, 0, len(timer.Levels)),
+	}
+
+	for i, level := range timer.Levels {
+		row := &TimerSheetRow{
+			Level:    level,
+			Number:   uint(i + 1),
+			StartMin: sheet.TotalMin,
+			EndMin:   sheet.TotalMin + level.DurationMin,
+		}
+
+		sheet.TotalMin = row.EndMin
+		sheet.Rows = append(sheet.Rows, row)
+	}
+
+	return sheet
+
+}
+
+// Details describes the tournament played on the timer, it is empty when there isn't one
+func (s *TimerSheet) Details() []*SheetDetail {
+
+	t := s.Tournament
+	if t == nil {
+		return nil
+	}
+
+	details := []*SheetDetail{{Label: "Buy In", Value: formatSheetNumber(t.BuyIn)}}
+
+	if t.RebuyAmount > 0 {
+		details = append(details, &SheetDetail{Label: "Rebuy", Value: sheetAllowance(t.RebuyAmount, t.RebuyLimit, t.RebuyUntilLevel)})
+	}
+
+	if t.AddOnAmount > 0 {
+		details = append(details, &SheetDetail{Label: "Add-On", Value: sheetAllowance(t.AddOnAmount, t.AddOnLimit, t.AddOnUntilLevel)})
+	}
+
+	if t.RakePercent > 0 || t.RakeFixed > 0 {
+		var rake string
+		switch {
+		case t.RakePercent > 0 && t.RakeFixed > 0:
+			rake = fmt.Sprintf("%s%% + %s", formatSheetNumber(t.RakePercent), formatSheetNumber(t.RakeFixed))
+		case t.RakePercent > 0:
+			rake = fmt.Sprintf("%s%%", formatSheetNumber(t.RakePercent))
+		default:
+			rake = formatSheetNumber(t.RakeFixed)
+		}
+		details = append(details, &SheetDetail{Label: "Rake", Value: rake})
+	}
+
+	if t.Entrants() > 0 {
+		details = append(details,
+			&SheetDetail{Label: "Entrants", Value: strconv.Itoa(t.Entrants())},
+			&SheetDetail{Label: "Prize Pool", Value: formatSheetNumber(t.PrizePool())},
+		)
+	}
+
+	return details
+
+}
+
+// Blinds renders a row's blinds such as "100 / 200", empty for breaks
+func (r *TimerSheetRow) Blinds() string {
+
+	if r.Level.Type != LevelTypeBlind {
+		return ""
+	}
+
+	return fmt.Sprintf("%s / %s", formatSheetNumber(r.Level.SmallBlind), formatSheetNumber(r.Level.BigBlind))
+
+}
+
+// Ante renders a row's ante such as "25" or "200 BB", empty when the level doesn't have one
+func (r *TimerSheetRow) Ante() string {
+
+	if r.Level.Type != LevelTypeBlind {
+		return ""
+	}
+
+	switch r.Level.EffectiveAnteType() {
+	case AnteTypeTraditional:
+		return formatSheetNumber(r.Level.Ante)
+	case AnteTypeBigBlind:
+		return fmt.Sprintf("%s BB", formatSheetNumber(r.Level.Ante))
+	}
+
+	return ""
+
+}
+
+// FormatSheetMinutes renders minutes of play as a clock offset such as 1:40
+func FormatSheetMinutes(minutes float64) string {
+
+	total := int(minutes*60 + 0.5)
+
+	return fmt.Sprintf("%d:%02d", total/3600, total/60%60)
+
+}
+
+// WritePDF writes the sheet to w as an A4 PDF
+func (s *TimerSheet) WritePDF(w io.Writer) error {
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(s.Timer.Name, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+
+	// The core fonts only cover latin-1, so names are translated rather than printed as mojibake
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	widths := []float64{25, 50, 30, 30, 45}
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(220, 220, 220)
+		for i, heading := range []string{"Level", "Blinds", "Ante", "Minutes", "Elapsed"} {
+			pdf.CellFormat(widths[i], 8, heading, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.SetHeaderFuncMode(func() {
+		if pdf.PageNo() > 1 {
+			header()
+		}
+	}, true)
+
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, tr(s.Timer.Name), "", 1, "C", false, 0, "")
+
+	if s.Tournament != nil {
+		pdf.SetFont("Helvetica", "", 12)
+		pdf.CellFormat(0, 7, tr(s.Tournament.Name), "", 1, "C", false, 0, "")
+
+		pdf.SetFont("Helvetica", "", 10)
+		for _, detail := range s.Details() {
+			pdf.CellFormat(0, 5, tr(fmt.Sprintf("%s: %s", detail.Label, detail.Value)), "", 1, "C", false, 0, "")
+		}
+	}
+
+	pdf.Ln(4)
+	header()
+
+	for _, row := range s.Rows {
+		elapsed := fmt.Sprintf("%s - %s", FormatSheetMinutes(row.StartMin), FormatSheetMinutes(row.EndMin))
+		minutes := formatSheetNumber(row.Level.DurationMin)
+		number := strconv.FormatUint(uint64(row.Number), 10)
+
+		if row.Level.Type == LevelTypeBreak {
+			pdf.SetFont("Helvetica", "BI", 11)
+			pdf.SetFillColor(245, 245, 245)
+			pdf.CellFormat(widths[0], 7, number, "1", 0, "C", true, 0, "")
+			pdf.CellFormat(widths[1]+widths[2], 7, "Break", "1", 0, "C", true, 0, "")
+			pdf.CellFormat(widths[3], 7, minutes, "1", 0, "C", true, 0, "")
+			pdf.CellFormat(widths[4], 7, elapsed, "1", 1, "C", true, 0, "")
+			continue
+		}
+
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(widths[0], 7, number, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[1], 7, row.Blinds(), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[2], 7, row.Ante(), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[3], 7, minutes, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(widths[4], 7, elapsed, "1", 1, "C", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Total playing time %s", FormatSheetMinutes(s.TotalMin)), "", 1, "R", false, 0, "")
+
+	return pdf.Output(w)
+
+}
+
+// sheetAllowance describes what a rebuy or add-on costs and how long it is allowed for
+func sheetAllowance(amount float64, limit, untilLevel uint) string {
+
+	value := formatSheetNumber(amount)
+	if limit > 0 {
+		value = fmt.Sprintf("%s, up to %d per player", value, limit)
+	}
+
+	if untilLevel > 0 {
+		value = fmt.Sprintf("%s, until the end of level %d", value, untilLevel)
+	}
+
+	return value
+
+}
+
+func formatSheetNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,18 @@
+package poker
+
+import "context"
+
+// SpeechSynthesizer turns the text of an announcement into audio that can be played by the browser
+type SpeechSynthesizer interface {
+	// Key identifies the backend and the voice it speaks with. Audio is cached under it, so
+	// changing either never serves announcements recorded with the old voice. It is empty for
+	// the voice announcements were made with before it could be chosen, whose audio is cached
+	// without a prefix
+	Key() string
+	SynthesizeSpeech(ctx context.Context, text string) (*Speech, error)
+}
+
+type Speech struct {
+	Audio       []byte
+	ContentType string
+}
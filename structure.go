@@ -0,0 +1,221 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+)
+
+// StructureParams describe the tournament a blind structure is generated for
+type StructureParams struct {
+	StartingStack float64
+	Players       uint
+	// LengthHours is roughly how long the tournament should take to reach heads up
+	LengthHours float64
+	LevelMin    float64
+	// SmallestChip is the lowest denomination in play, every blind and ante is a multiple of it
+	SmallestChip float64
+	// BreakEvery is the number of blind levels played between breaks, 0 for no breaks
+	BreakEvery uint
+	BreakMin   float64
+	// AnteFromLevel is the first blind level with an ante, 0 for no antes
+	AnteFromLevel uint
+}
+
+// DefaultStructureParams suit a typical home game, they are what the generator starts from
+var DefaultStructureParams = StructureParams{
+	StartingStack: 10000,
+	Players:       10,
+	LengthHours:   4,
+	LevelMin:      20,
+	SmallestChip:  25,
+	BreakEvery:    4,
+	BreakMin:      10,
+}
+
+func (p StructureParams) Validate() error {
+
+	if p.StartingStack <= 0 {
+		return fmt.Errorf("starting stack must be greater than 0")
+	}
+
+	if p.Players < 2 {
+		return fmt.Errorf("there must be at least 2 players")
+	}
+
+	if p.LengthHours <= 0 {
+		return fmt.Errorf("tournament length must be greater than 0")
+	}
+
+	if p.LevelMin <= 0 {
+		return fmt.Errorf("level duration must be greater than 0")
+	}
+
+	if p.LengthHours*60 < p.LevelMin*2 {
+		return fmt.Errorf("the tournament must be long enough for at least 2 levels")
+	}
+
+	if p.SmallestChip <= 0 {
+		return fmt.Errorf("smallest chip must be greater than 0")
+	}
+
+	if p.StartingStack < p.SmallestChip*20 {
+		return fmt.Errorf("starting stack must be at least 20 of the smallest chip")
+	}
+
+	if p.BreakEvery > 0 && p.BreakMin <= 0 {
+		return fmt.Errorf("break duration must be greater than 0")
+	}
+
+	return nil
+
+}
+
+// niceMultipliers are the leading figures blinds are rounded to within each power of ten, the
+// amounts players are used to seeing such as 150, 400 or 2,500
+var niceMultipliers = []float64{1, 1.5, 2, 2.5, 3, 4, 5, 6, 8}
+
+// maxBlindDrift is how far, as a fraction of the amount, a blind can be moved to land on a nice figure. An awkward
+// smallest chip can leave nice figures so sparse that rounding to them would throw the structure off course
+const maxBlindDrift = 0.25
+
+// GenerateStructure builds a ladder of blind levels, with breaks and antes, that starts players about
+// 100 big blinds deep and grows the blinds steadily so that by the end of the target length the chips
+// in play are worth around 20 big blinds. The levels returned have no ID or TimerID
+func GenerateStructure(p StructureParams) ([]*TimerLevel, error) {
+
+	err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Work out how many blind levels fit in the target length once breaks are taken out
+	var blindLevels int
+	for n := 1; ; n++ {
+		minutes := float64(n) * p.LevelMin
+		if p.BreakEvery > 0 {
+			minutes += float64((n-1)/int(p.BreakEvery)) * p.BreakMin
+		}
+		if minutes > p.LengthHours*60 {
+			break
+		}
+		blindLevels = n
+	}
+
+	if blindLevels < 2 {
+		blindLevels = 2
+	}
+
+	// The small blind has to be payable in whole chips, so the big blind is a multiple of two of them
+	var unit = p.SmallestChip * 2
+
+	startBB := math.Max(p.StartingStack/100, unit)
+	endBB := math.Max(p.StartingStack*float64(p.Players)/20, startBB*2)
+	growth := math.Pow(endBB/startBB, 1/float64(blindLevels-1))
+
+	levels := make([]*TimerLevel, 0, blindLevels+blindLevels/int(math.Max(float64(p.BreakEvery), 1)))
+
+	var previous float64
+	for i := 0; i < blindLevels; i++ {
+
+		bigBlind := roundBlind(startBB*math.Pow(growth, float64(i)), unit)
+		if bigBlind <= previous {
+			bigBlind = nextBlind(previous, unit)
+		}
+		previous = bigBlind
+
+		level := &TimerLevel{
+			Type:        LevelTypeBlind,
+			SmallBlind:  bigBlind / 2,
+			BigBlind:    bigBlind,
+			AnteType:    AnteTypeNone,
+			DurationMin: p.LevelMin,
+			DurationSec: p.LevelMin * 60,
+		}
+
+		if p.AnteFromLevel > 0 && uint(i+1) >= p.AnteFromLevel {
+			// A traditional ante of around an eighth of the big blind
+			level.AnteType = AnteTypeTraditional
+			level.Ante = roundBlind(bigBlind/8, p.SmallestChip)
+		}
+
+		if p.BreakEvery > 0 && i > 0 && i%int(p.BreakEvery) == 0 {
+			levels = append(levels, &TimerLevel{
+				Type:        LevelTypeBreak,
+				DurationMin: p.BreakMin,
+				DurationSec: p.BreakMin * 60,
+			})
+		}
+
+		levels = append(levels, level)
+	}
+
+	for i, level := range levels {
+		level.Level = float64(i + 1)
+	}
+
+	return levels, nil
+
+}
+
+// roundBlind rounds amount to the closest nice figure that is a multiple of unit. When unit leaves no nice
+// figure within maxBlindDrift of amount, amount is rounded to a plain multiple of unit instead
+func roundBlind(amount, unit float64) float64 {
+
+	var best = unit
+	for _, candidate := range blindCandidates(amount, unit) {
+		if math.Abs(candidate-amount) < math.Abs(best-amount) {
+			best = candidate
+		}
+	}
+
+	if math.Abs(best-amount) > amount*maxBlindDrift {
+		return math.Max(math.Round(amount/unit), 1) * unit
+	}
+
+	return best
+
+}
+
+// nextBlind is the smallest nice figure that is a multiple of unit and more than amount, or the next multiple of
+// unit when that would jump too far
+func nextBlind(amount, unit float64) float64 {
+
+	var next = math.Inf(1)
+	for _, candidate := range blindCandidates(amount*1.01, unit) {
+		if candidate > amount && candidate < next {
+			next = candidate
+		}
+	}
+
+	if math.IsInf(next, 1) || next-amount > amount*maxBlindDrift*2 {
+		return (math.Floor(amount/unit) + 1) * unit
+	}
+
+	return next
+
+}
+
+// blindCandidates lists the nice figures either side of amount that are multiples of unit. When unit is
+// too awkward for any of them, amount rounded to a multiple of unit is the only candidate
+func blindCandidates(amount, unit float64) []float64 {
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(amount)))
+
+	candidates := make([]float64, 0, len(niceMultipliers)*3)
+	for _, scale := range []float64{magnitude / 10, magnitude, magnitude * 10} {
+		for _, multiplier := range niceMultipliers {
+			candidate := multiplier * scale
+			units := candidate / unit
+			if candidate >= unit && math.Abs(units-math.Round(units)) < 1e-9 {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = append(candidates, math.Max(math.Round(amount/unit), 1)*unit)
+	}
+
+	return candidates
+
+}
@@ -0,0 +1,138 @@
+package poker
+
+import (
+	"math"
+	"testing"
+)
+
+func isMultiple(amount, unit float64) bool {
+	units := amount / unit
+	return math.Abs(units-math.Round(units)) < 1e-9
+}
+
+func TestGenerateStructure(t *testing.T) {
+
+	tt := []struct {
+		name   string
+		params StructureParams
+	}{
+		{name: "defaults", params: DefaultStructureParams},
+		{name: "deep stacks with antes", params: StructureParams{
+			StartingStack: 30000, Players: 40, LengthHours: 6, LevelMin: 30, SmallestChip: 100,
+			BreakEvery: 3, BreakMin: 15, AnteFromLevel: 5,
+		}},
+		{name: "awkward smallest chip", params: StructureParams{
+			StartingStack: 1500, Players: 6, LengthHours: 2, LevelMin: 15, SmallestChip: 15,
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			p := tc.params
+
+			levels, err := GenerateStructure(p)
+			if err != nil {
+				t.Fatalf("failed to generate structure: %s", err)
+			}
+
+			var minutes, previous float64
+			var blinds, sinceBreak uint
+			for i, level := range levels {
+				minutes += level.DurationMin
+
+				if level.Level != float64(i+1) {
+					t.Errorf("expected level %d to be numbered %d, got %v", i+1, i+1, level.Level)
+				}
+
+				if level.Type == LevelTypeBreak {
+					if sinceBreak != p.BreakEvery {
+						t.Errorf("expected a break after every %d levels, got one after %d", p.BreakEvery, sinceBreak)
+					}
+					sinceBreak = 0
+					continue
+				}
+
+				blinds++
+				sinceBreak++
+
+				// Generated levels are given IDs when they're saved to a timer
+				level.ID, level.TimerID = "generated", "timer"
+				err = level.Validate()
+				if err != nil {
+					t.Errorf("level %d is not valid: %s", i+1, err)
+				}
+
+				if level.BigBlind <= previous {
+					t.Errorf("expected the big blind to grow every level, level %d is %v after %v", i+1, level.BigBlind, previous)
+				}
+				previous = level.BigBlind
+
+				if !isMultiple(level.SmallBlind, p.SmallestChip) || !isMultiple(level.Ante, p.SmallestChip) {
+					t.Errorf("level %d can't be paid in chips of %v: %v/%v ante %v", i+1, p.SmallestChip, level.SmallBlind, level.BigBlind, level.Ante)
+				}
+
+				wantAnte := p.AnteFromLevel > 0 && blinds >= p.AnteFromLevel
+				if wantAnte != (level.Ante > 0) {
+					t.Errorf("expected blind level %d to have an ante %t, got %v", blinds, wantAnte, level.Ante)
+				}
+			}
+
+			if minutes > p.LengthHours*60 {
+				t.Errorf("expected the structure to fit in %v hours, it takes %v minutes", p.LengthHours, minutes)
+			}
+
+			// Players start about 100 big blinds deep
+			if depth := p.StartingStack / levels[0].BigBlind; depth < 50 || depth > 150 {
+				t.Errorf("expected to start around 100 big blinds deep, got %v", depth)
+			}
+
+			// and the chips in play are worth around 20 big blinds by the end
+			if depth := p.StartingStack * float64(p.Players) / previous; depth < 10 || depth > 40 {
+				t.Errorf("expected to end around 20 big blinds in play, got %v", depth)
+			}
+
+		})
+	}
+
+}
+
+func TestRoundBlind(t *testing.T) {
+
+	tt := []struct {
+		amount, unit, want float64
+	}{
+		{amount: 130, unit: 50, want: 150},
+		{amount: 370, unit: 50, want: 400},
+		{amount: 2300, unit: 50, want: 2500},
+		{amount: 10, unit: 50, want: 50},
+		{amount: 95, unit: 30, want: 90},
+	}
+
+	for _, tc := range tt {
+		if got := roundBlind(tc.amount, tc.unit); got != tc.want {
+			t.Errorf("expected %v in multiples of %v to round to %v, got %v", tc.amount, tc.unit, tc.want, got)
+		}
+	}
+
+}
+
+func TestStructureParamsValidate(t *testing.T) {
+
+	p := DefaultStructureParams
+	p.StartingStack = p.SmallestChip * 10
+
+	_, err := GenerateStructure(p)
+	if err == nil {
+		t.Errorf("expected a starting stack of 10 of the smallest chip to be refused")
+	}
+
+	p = DefaultStructureParams
+	p.LengthHours = 0.5
+
+	_, err = GenerateStructure(p)
+	if err == nil {
+		t.Errorf("expected a tournament too short for 2 levels to be refused")
+	}
+
+}
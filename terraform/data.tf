@@ -12,8 +12,18 @@ data "aws_iam_policy_document" "allow_dynamodb_basic" {
       "${aws_dynamodb_table.sessions.arn}/*",
       aws_dynamodb_table.timers.arn,
       "${aws_dynamodb_table.timers.arn}/*",
+      aws_dynamodb_table.timer_shares.arn,
+      "${aws_dynamodb_table.timer_shares.arn}/*",
       aws_dynamodb_table.users.arn,
       "${aws_dynamodb_table.users.arn}/*",
+      aws_dynamodb_table.tournaments.arn,
+      "${aws_dynamodb_table.tournaments.arn}/*",
+      aws_dynamodb_table.api_tokens.arn,
+      "${aws_dynamodb_table.api_tokens.arn}/*",
+      aws_dynamodb_table.webhooks.arn,
+      "${aws_dynamodb_table.webhooks.arn}/*",
+      aws_dynamodb_table.webhook_deliveries.arn,
+      "${aws_dynamodb_table.webhook_deliveries.arn}/*",
     ]
   }
 }
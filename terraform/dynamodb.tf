@@ -71,3 +71,160 @@ resource "aws_dynamodb_table" "timers" {
 output "timers_table_name" {
   value = aws_dynamodb_table.timers.name
 }
+
+resource "aws_dynamodb_table" "timer_shares" {
+  name         = "poker-timer-shares-${var.region}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "UserID"
+  range_key    = "TimerID"
+
+  attribute {
+    name = "UserID"
+    type = "S"
+  }
+
+  attribute {
+    name = "TimerID"
+    type = "S"
+  }
+
+}
+
+output "timer_shares_table_name" {
+  value = aws_dynamodb_table.timer_shares.name
+}
+
+resource "aws_dynamodb_table" "tournaments" {
+  name         = "poker-tournaments-${var.region}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ID"
+
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+
+  attribute {
+    name = "UserID"
+    type = "S"
+  }
+
+  attribute {
+    name = "TimerID"
+    type = "S"
+  }
+
+  global_secondary_index {
+    hash_key        = "UserID"
+    name            = "user-id-index"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    hash_key        = "TimerID"
+    name            = "timer-id-index"
+    projection_type = "ALL"
+  }
+
+}
+
+output "tournaments_table_name" {
+  value = aws_dynamodb_table.tournaments.name
+}
+
+resource "aws_dynamodb_table" "api_tokens" {
+  name         = "poker-api-tokens-${var.region}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ID"
+
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+
+  attribute {
+    name = "UserID"
+    type = "S"
+  }
+
+  attribute {
+    name = "Hash"
+    type = "S"
+  }
+
+  global_secondary_index {
+    hash_key        = "UserID"
+    name            = "user-id-index"
+    projection_type = "ALL"
+  }
+
+  global_secondary_index {
+    hash_key        = "Hash"
+    name            = "hash-index"
+    projection_type = "ALL"
+  }
+
+}
+
+output "api_tokens_table_name" {
+  value = aws_dynamodb_table.api_tokens.name
+}
+
+resource "aws_dynamodb_table" "webhooks" {
+  name         = "poker-webhooks-${var.region}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ID"
+
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+
+  attribute {
+    name = "TimerID"
+    type = "S"
+  }
+
+  global_secondary_index {
+    hash_key        = "TimerID"
+    name            = "timer-id-index"
+    projection_type = "ALL"
+  }
+
+}
+
+output "webhooks_table_name" {
+  value = aws_dynamodb_table.webhooks.name
+}
+
+resource "aws_dynamodb_table" "webhook_deliveries" {
+  name         = "poker-webhook-deliveries-${var.region}"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ID"
+
+  attribute {
+    name = "ID"
+    type = "S"
+  }
+
+  attribute {
+    name = "WebhookID"
+    type = "S"
+  }
+
+  global_secondary_index {
+    hash_key        = "WebhookID"
+    name            = "webhook-id-index"
+    projection_type = "ALL"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+
+}
+
+output "webhook_deliveries_table_name" {
+  value = aws_dynamodb_table.webhook_deliveries.name
+}
@@ -1,11 +1,22 @@
 package poker
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 )
 
+// TimerRepository stores timers. Timer returns nil when no timer exists with the id
+type TimerRepository interface {
+	Timer(ctx context.Context, id string) (*Timer, error)
+	TimersByUserID(ctx context.Context, userID string) ([]*Timer, error)
+	// TimersSharedWithUserID returns the timers the user has been invited to, whether or not they have accepted
+	TimersSharedWithUserID(ctx context.Context, userID string) ([]*Timer, error)
+	SaveTimer(ctx context.Context, timer *Timer) error
+	DeleteTimer(ctx context.Context, id string) error
+}
+
 type Timer struct {
 	ID           string `schema:"-"`
 	UserID       string `schema:"-"`
@@ -15,6 +26,58 @@ type Timer struct {
 	IsComplete   bool      `schema:"-"`
 	CreatedAt    time.Time `schema:"-"`
 	UpdatedAt    time.Time `schema:"-"`
+
+	// Version is incremented on every save. A save only succeeds when the stored timer is still at the
+	// version it was loaded at, otherwise a ConflictError is returned
+	Version uint `schema:"-"`
+
+	// StartedAt is when the clock was last started or resumed, nil while the clock is stopped
+	StartedAt *time.Time `schema:"-"`
+	// PausedAt is when the clock was last paused, nil unless the clock is paused part way through a level
+	PausedAt *time.Time `schema:"-"`
+	// ElapsedSec is the number of seconds of the current level played before StartedAt
+	ElapsedSec float64 `schema:"-"`
+
+	// DisplayToken grants read only access to the timer's display, empty when no link has been shared
+	DisplayToken string `schema:"-"`
+
+	// IsTemplate marks the timer as one of its owner's personal templates, offered alongside the built-in
+	// structures when starting a new timer
+	IsTemplate bool `schema:"-"`
+
+	// ChipSet is the set of chips the timer is played with, nil when none has been attached. When set every
+	// blind and ante must be able to be made from it
+	ChipSet *ChipSet `schema:"-"`
+
+	// ScheduledStart is when play is planned to start, used to project the schedule until the clock is started
+	ScheduledStart *time.Time `schema:"-"`
+
+	// Shares are the other users the owner has given access to, see RoleOf
+	Shares []*TimerShare `schema:"-"`
+
+	// LateRegistrationLevel is only set on timers saved before level events existed, see UpgradeLateRegistration
+	LateRegistrationLevel uint `schema:"-" json:",omitempty" dynamodbav:",omitempty"`
+}
+
+// UpgradeLateRegistration moves the late registration of a timer saved before level events existed onto its
+// levels. It was the 1 based level late registration closed after, which is as the next level starts
+func (t *Timer) UpgradeLateRegistration() {
+
+	if t.LateRegistrationLevel == 0 {
+		return
+	}
+
+	// Closing after the last level never happened during play, so there is nothing to carry over
+	if int(t.LateRegistrationLevel) < len(t.Levels) {
+		level := t.Levels[t.LateRegistrationLevel]
+		if !level.HasEvent(LevelEventLateRegistrationEnds) {
+			// Late registration ending is the first of AllLevelEvents, so it goes before any others
+			level.Events = append([]LevelEvent{LevelEventLateRegistrationEnds}, level.Events...)
+		}
+	}
+
+	t.LateRegistrationLevel = 0
+
 }
 
 func (t Timer) Validate() error {
@@ -31,6 +94,24 @@ func (t Timer) Validate() error {
 		return fmt.Errorf("name must be 3 or more characters in length")
 	}
 
+	err := t.ValidateShares()
+	if err != nil {
+		return err
+	}
+
+	return t.ValidateChips()
+
+}
+
+// CheckVersion returns a ConflictError when the timer has been saved since it was at version, the version an edit
+// was made from. Saves only catch changes made between loading and saving the timer, this also catches a form or an
+// API client working from a copy read earlier
+func (t *Timer) CheckVersion(version uint) error {
+
+	if t.Version != version {
+		return &ConflictError{Resource: "timer", ID: t.ID}
+	}
+
 	return nil
 
 }
@@ -46,10 +127,11 @@ func (tt LevelType) String() string {
 	return string(tt)
 }
 
-var allLevelTypes = []LevelType{LevelTypeBlind, LevelTypeBreak}
+// AllLevelTypes is every level type
+var AllLevelTypes = []LevelType{LevelTypeBlind, LevelTypeBreak}
 
 func (tt LevelType) Valid() bool {
-	for _, t := range allLevelTypes {
+	for _, t := range AllLevelTypes {
 		if t == tt {
 			return true
 		}
@@ -59,16 +141,76 @@ func (tt LevelType) Valid() bool {
 
 var strAllLevelTypes = []string{LevelTypeBlind.String(), LevelTypeBreak.String()}
 
+// AnteType is how the ante of a blind level is paid
+type AnteType string
+
+const (
+	AnteTypeNone AnteType = "none"
+	// AnteTypeTraditional is paid by every player at the table
+	AnteTypeTraditional AnteType = "traditional"
+	// AnteTypeBigBlind is paid once per hand by the player in the big blind
+	AnteTypeBigBlind AnteType = "big-blind"
+)
+
+func (at AnteType) String() string {
+	return string(at)
+}
+
+// AllAnteTypes is every ante type, in the order they are offered
+var AllAnteTypes = []AnteType{AnteTypeNone, AnteTypeTraditional, AnteTypeBigBlind}
+
+func (at AnteType) Valid() bool {
+	for _, t := range AllAnteTypes {
+		if t == at {
+			return true
+		}
+	}
+	return false
+}
+
+var strAllAnteTypes = []string{AnteTypeNone.String(), AnteTypeTraditional.String(), AnteTypeBigBlind.String()}
+
+// LevelEvent is something that happens to the tournament as a level starts, such as late registration closing
+type LevelEvent string
+
+const (
+	LevelEventLateRegistrationEnds LevelEvent = "late-registration-ends"
+	LevelEventRebuysEnd            LevelEvent = "rebuys-end"
+	LevelEventAddOnAvailable       LevelEvent = "add-on-available"
+)
+
+func (le LevelEvent) String() string {
+	return string(le)
+}
+
+// AllLevelEvents is every level event in the order they are shown and announced
+var AllLevelEvents = []LevelEvent{LevelEventLateRegistrationEnds, LevelEventRebuysEnd, LevelEventAddOnAvailable}
+
+func (le LevelEvent) Valid() bool {
+	for _, e := range AllLevelEvents {
+		if e == le {
+			return true
+		}
+	}
+	return false
+}
+
+var strAllLevelEvents = []string{LevelEventLateRegistrationEnds.String(), LevelEventRebuysEnd.String(), LevelEventAddOnAvailable.String()}
+
 type TimerLevel struct {
-	ID          string
-	Type        LevelType
-	TimerID     string
-	Level       float64
-	SmallBlind  float64
-	BigBlind    float64
-	Ante        float64
+	ID         string
+	Type       LevelType
+	TimerID    string
+	Level      float64
+	SmallBlind float64
+	BigBlind   float64
+	Ante       float64
+	// AnteType is empty for levels saved before ante types existed, see EffectiveAnteType
+	AnteType    AnteType
 	DurationMin float64
 	DurationSec float64
+	// Events happen as the level starts, a level can have any number of them but each only once
+	Events []LevelEvent
 
 	// DurationStr is a the string representation of the remaining time in the MM:SS format
 	DurationStr string
@@ -104,23 +246,114 @@ func (t TimerLevel) Validate() error {
 		if t.SmallBlind > t.BigBlind {
 			return fmt.Errorf("small blind cannot be greater than big blind")
 		}
+
+		if t.AnteType != "" && !t.AnteType.Valid() {
+			return fmt.Errorf("ante type is not a valid type, expected one of: %s", strings.Join(strAllAnteTypes, ","))
+		}
+
+		if t.Ante < 0 {
+			return fmt.Errorf("ante must be greater than or equal to 0")
+		}
+
+		switch t.EffectiveAnteType() {
+		case AnteTypeNone:
+			if t.Ante != 0 {
+				return fmt.Errorf("ante must be 0 when the level has no ante")
+			}
+		case AnteTypeTraditional:
+			if t.Ante == 0 {
+				return fmt.Errorf("ante must be greater than 0 for a traditional ante")
+			}
+
+			if t.Ante > t.SmallBlind {
+				return fmt.Errorf("a traditional ante cannot be greater than the small blind")
+			}
+		case AnteTypeBigBlind:
+			if t.Ante == 0 {
+				return fmt.Errorf("ante must be greater than 0 for a big blind ante")
+			}
+
+			if t.Ante > t.BigBlind {
+				return fmt.Errorf("a big blind ante cannot be greater than the big blind")
+			}
+		}
 	}
 
 	if t.DurationMin <= 0 {
 		return fmt.Errorf("duration must be greater than 0")
 	}
 
+	for i, event := range t.Events {
+		if !event.Valid() {
+			return fmt.Errorf("event is not a valid event, expected one of: %s", strings.Join(strAllLevelEvents, ","))
+		}
+
+		for _, other := range t.Events[:i] {
+			if other == event {
+				return fmt.Errorf("the %s event can only be added to a level once", event)
+			}
+		}
+	}
+
 	return nil
 
 }
 
+// EffectiveAnteType is the level's ante type. Levels saved before ante types existed only have an amount, which
+// was always a traditional ante
+func (t TimerLevel) EffectiveAnteType() AnteType {
+
+	if t.AnteType != "" {
+		return t.AnteType
+	}
+
+	if t.Type == LevelTypeBlind && t.Ante > 0 {
+		return AnteTypeTraditional
+	}
+
+	return AnteTypeNone
+
+}
+
+// HasEvent reports whether the event happens as the level starts
+func (t TimerLevel) HasEvent(event LevelEvent) bool {
+	for _, e := range t.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// AudioS3Key identifies the level's announcement. Levels without an ante or events keep the key they had before
+// those were announced, which the default voice still caches under without a prefix, so its audio that is already
+// cached is still used
 func (t TimerLevel) AudioS3Key() string {
+
+	var key string
 	switch t.Type {
 	case LevelTypeBlind:
-		return fmt.Sprintf("%.0f-%.0f-%.0f", t.SmallBlind, t.BigBlind, t.DurationMin)
+		switch t.EffectiveAnteType() {
+		case AnteTypeTraditional:
+			key = fmt.Sprintf("%.0f-%.0f-%.0f-ante-%.0f", t.SmallBlind, t.BigBlind, t.DurationMin, t.Ante)
+		case AnteTypeBigBlind:
+			key = fmt.Sprintf("%.0f-%.0f-%.0f-bba-%.0f", t.SmallBlind, t.BigBlind, t.DurationMin, t.Ante)
+		default:
+			key = fmt.Sprintf("%.0f-%.0f-%.0f", t.SmallBlind, t.BigBlind, t.DurationMin)
+		}
 	case LevelTypeBreak:
-		return fmt.Sprintf("%.0f", t.DurationMin)
+		key = fmt.Sprintf("%.0f", t.DurationMin)
+	default:
+		return "unrecognized-level-type"
 	}
 
-	return "unrecognized-level-type"
+	// Events are added in a fixed order so the same events always share a key
+	for _, event := range AllLevelEvents {
+		if t.HasEvent(event) {
+			key += "-" + event.String()
+		}
+	}
+
+	return key
+
 }
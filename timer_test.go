@@ -0,0 +1,100 @@
+package poker
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestUpgradeLateRegistration decodes a timer saved before level events existed, when late registration was the level
+// it closed after
+func TestUpgradeLateRegistration(t *testing.T) {
+
+	tt := []struct {
+		name  string
+		after uint
+		want  int
+	}{
+		{name: "after the second level", after: 2, want: 2},
+		{name: "after the first level", after: 1, want: 1},
+		{name: "after the last level", after: 4, want: -1},
+		{name: "none", after: 0, want: -1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			data, err := json.Marshal(map[string]interface{}{
+				"ID":                    "timer",
+				"Levels":                []map[string]interface{}{{"ID": "1"}, {"ID": "2"}, {"ID": "3", "Events": []string{"rebuys-end"}}, {"ID": "4"}},
+				"LateRegistrationLevel": tc.after,
+			})
+			if err != nil {
+				t.Fatalf("failed to encode legacy timer: %s", err)
+			}
+
+			var timer = new(Timer)
+			err = json.Unmarshal(data, timer)
+			if err != nil {
+				t.Fatalf("failed to decode legacy timer: %s", err)
+			}
+
+			timer.UpgradeLateRegistration()
+
+			for i, level := range timer.Levels {
+				if got := level.HasEvent(LevelEventLateRegistrationEnds); got != (i == tc.want) {
+					t.Errorf("expected late registration to end as level %d starts %t, got %t", i+1, i == tc.want, got)
+				}
+			}
+
+			if tc.want == 2 && (len(timer.Levels[2].Events) != 2 || timer.Levels[2].Events[1] != LevelEventRebuysEnd) {
+				t.Errorf("expected the level's other events to be kept after late registration, got %v", timer.Levels[2].Events)
+			}
+
+			if timer.LateRegistrationLevel != 0 {
+				t.Errorf("expected the legacy late registration level to be cleared, got %d", timer.LateRegistrationLevel)
+			}
+
+			// Upgraded timers are saved without the legacy field
+			data, err = json.Marshal(timer)
+			if err != nil {
+				t.Fatalf("failed to encode timer: %s", err)
+			}
+
+			var saved map[string]interface{}
+			_ = json.Unmarshal(data, &saved)
+			if _, ok := saved["LateRegistrationLevel"]; ok {
+				t.Errorf("expected the legacy late registration level to be left out once upgraded")
+			}
+
+		})
+	}
+
+}
+
+// TestAudioS3Key expects levels without an ante or events to keep the key their audio was cached under before either
+// was announced
+func TestAudioS3Key(t *testing.T) {
+
+	tt := []struct {
+		name  string
+		level TimerLevel
+		want  string
+	}{
+		{name: "blind", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 20}, want: "25-50-20"},
+		{name: "break", level: TimerLevel{Type: LevelTypeBreak, DurationMin: 10}, want: "10"},
+		{name: "ante", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 100, BigBlind: 200, Ante: 25, DurationMin: 20}, want: "100-200-20-ante-25"},
+		{name: "big blind ante", level: TimerLevel{Type: LevelTypeBlind, SmallBlind: 100, BigBlind: 200, Ante: 200, AnteType: AnteTypeBigBlind, DurationMin: 20}, want: "100-200-20-bba-200"},
+		{
+			name:  "events in announced order",
+			level: TimerLevel{Type: LevelTypeBreak, DurationMin: 15, Events: []LevelEvent{LevelEventAddOnAvailable, LevelEventLateRegistrationEnds}},
+			want:  "15-late-registration-ends-add-on-available",
+		},
+	}
+
+	for _, tc := range tt {
+		if got := tc.level.AudioS3Key(); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+}
@@ -0,0 +1,161 @@
+package poker
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APITokenRepository stores personal access tokens. APIToken and APITokenByHash return nil when no token matches
+type APITokenRepository interface {
+	APIToken(ctx context.Context, id string) (*APIToken, error)
+	APITokenByHash(ctx context.Context, hash string) (*APIToken, error)
+	APITokensByUserID(ctx context.Context, userID string) ([]*APIToken, error)
+	SaveAPIToken(ctx context.Context, token *APIToken) error
+	DeleteAPIToken(ctx context.Context, id string) error
+}
+
+// TokenScope is what an API token may be used for. Scopes don't include each other, a token is given each one it
+// needs
+type TokenScope string
+
+const (
+	// TokenScopeRead may read timers and their levels
+	TokenScopeRead TokenScope = "read"
+	// TokenScopeControl may run the clock of a timer
+	TokenScopeControl TokenScope = "control"
+	// TokenScopeWrite may create, change and delete timers and their levels
+	TokenScopeWrite TokenScope = "write"
+)
+
+func (ts TokenScope) String() string {
+	return string(ts)
+}
+
+// AllTokenScopes is every scope in the order they are offered
+var AllTokenScopes = []TokenScope{TokenScopeRead, TokenScopeControl, TokenScopeWrite}
+
+func (ts TokenScope) Valid() bool {
+	for _, s := range AllTokenScopes {
+		if s == ts {
+			return true
+		}
+	}
+	return false
+}
+
+var strAllTokenScopes = []string{TokenScopeRead.String(), TokenScopeControl.String(), TokenScopeWrite.String()}
+
+// apiTokenPrefix starts every token so they are easy to recognise, and to find if one is leaked
+const apiTokenPrefix = "pkr_"
+
+// APIToken lets scripts use the API as the user who created it. Only a hash of the token is kept, the token
+// itself is shown once when it is created
+type APIToken struct {
+	ID     string
+	UserID string
+	Name   string
+	// Hash is the hex encoded SHA-256 of the token, see HashAPIToken
+	Hash string
+	// Hint is the start of the token, shown so the user can tell their tokens apart
+	Hint      string
+	Scopes    []TokenScope
+	CreatedAt time.Time
+	// LastUsedAt is nil until the token is first used
+	LastUsedAt *time.Time
+}
+
+// NewAPIToken creates a token for the user, returning it alongside the token itself which is not kept
+func NewAPIToken(userID, name string, scopes []TokenScope, now time.Time) (*APIToken, string, error) {
+
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	secret := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	token := &APIToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Hash:      HashAPIToken(secret),
+		Hint:      secret[:len(apiTokenPrefix)+4],
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+
+	err = token.Validate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return token, secret, nil
+
+}
+
+// HashAPIToken is how tokens are looked up. Tokens are random rather than chosen by people, so a fast hash is
+// enough to make a leaked hash useless
+func HashAPIToken(secret string) string {
+
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+
+}
+
+func (t APIToken) Validate() error {
+
+	if t.ID == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if t.UserID == "" {
+		return fmt.Errorf("user id cannot be empty")
+	}
+
+	if len(t.Name) < 3 {
+		return fmt.Errorf("name must be 3 or more characters in length")
+	}
+
+	if t.Hash == "" {
+		return fmt.Errorf("hash cannot be empty")
+	}
+
+	if len(t.Scopes) == 0 {
+		return fmt.Errorf("choose at least one scope")
+	}
+
+	for i, scope := range t.Scopes {
+		if !scope.Valid() {
+			return fmt.Errorf("scope is not a valid scope, expected one of: %s", strings.Join(strAllTokenScopes, ","))
+		}
+
+		for _, other := range t.Scopes[:i] {
+			if other == scope {
+				return fmt.Errorf("the %s scope can only be given once", scope)
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// HasScope reports whether the token was given the scope
+func (t *APIToken) HasScope(scope TokenScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
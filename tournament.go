@@ -0,0 +1,403 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// TournamentRepository stores tournaments. Tournament and TournamentByTimerID return nil when nothing matches
+type TournamentRepository interface {
+	Tournament(ctx context.Context, id string) (*Tournament, error)
+	// TournamentByTimerID returns the most recently created tournament played on the timer
+	TournamentByTimerID(ctx context.Context, timerID string) (*Tournament, error)
+	TournamentsByUserID(ctx context.Context, userID string) ([]*Tournament, error)
+	SaveTournament(ctx context.Context, tournament *Tournament) error
+	DeleteTournament(ctx context.Context, id string) error
+}
+
+// Tournament tracks the players and money of a game played on a Timer
+type Tournament struct {
+	ID      string `schema:"-"`
+	UserID  string `schema:"-"`
+	TimerID string
+	Name    string
+
+	BuyIn float64
+	// RebuyAmount is what a rebuy costs, rebuys are not allowed when it is 0
+	RebuyAmount float64
+	// RebuyLimit is how many times each player may rebuy, 0 for no limit
+	RebuyLimit uint
+	// RebuyUntilLevel is the last level rebuys are allowed in, 0 to allow them throughout
+	RebuyUntilLevel uint
+	// AddOnAmount is what an add-on costs, add-ons are not allowed when it is 0
+	AddOnAmount float64
+	// AddOnLimit is how many add-ons each player may take, 0 for no limit
+	AddOnLimit uint
+	// AddOnUntilLevel is the last level add-ons are allowed in, 0 to allow them throughout
+	AddOnUntilLevel uint
+	// RakePercent is the share of the money collected that is kept by the house
+	RakePercent float64
+	// RakeFixed is a flat amount kept by the house on top of RakePercent
+	RakeFixed float64
+
+	// Payout decides how the prize pool is split, it is edited separately to the rest of the tournament
+	Payout PayoutRules `schema:"-"`
+
+	Players []*TournamentPlayer `schema:"-"`
+
+	CreatedAt time.Time `schema:"-"`
+	UpdatedAt time.Time `schema:"-"`
+
+	// Version is incremented on every save, see Timer.Version
+	Version uint `schema:"-"`
+}
+
+type TournamentPlayer struct {
+	ID     string
+	Name   string
+	Rebuys uint
+	AddOns uint
+	// Position is where the player finished, 0 while they are still playing
+	Position     uint
+	RegisteredAt time.Time
+	EliminatedAt *time.Time
+}
+
+// IsPlaying reports whether the player has not been eliminated yet
+func (p *TournamentPlayer) IsPlaying() bool {
+	return p.Position == 0
+}
+
+func (t Tournament) Validate() error {
+
+	if t.ID == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if t.UserID == "" {
+		return fmt.Errorf("user id cannot be empty")
+	}
+
+	if t.TimerID == "" {
+		return fmt.Errorf("a timer must be selected")
+	}
+
+	if len(t.Name) < 3 {
+		return fmt.Errorf("name must be 3 or more characters in length")
+	}
+
+	if t.BuyIn < 0 {
+		return fmt.Errorf("buy in must be greater than or equal to 0")
+	}
+
+	if t.RebuyAmount < 0 {
+		return fmt.Errorf("rebuy amount must be greater than or equal to 0")
+	}
+
+	if t.AddOnAmount < 0 {
+		return fmt.Errorf("add-on amount must be greater than or equal to 0")
+	}
+
+	if t.RakePercent < 0 || t.RakePercent > 100 {
+		return fmt.Errorf("rake percent must be between 0 and 100")
+	}
+
+	if t.RakeFixed < 0 {
+		return fmt.Errorf("fixed rake must be greater than or equal to 0")
+	}
+
+	return t.Payout.Validate()
+
+}
+
+// Entrants is the number of players registered, including those already eliminated
+func (t *Tournament) Entrants() int {
+	return len(t.Players)
+}
+
+// Remaining is the number of players still playing
+func (t *Tournament) Remaining() int {
+
+	var remaining int
+	for _, player := range t.Players {
+		if player.IsPlaying() {
+			remaining++
+		}
+	}
+
+	return remaining
+
+}
+
+func (t *Tournament) TotalRebuys() uint {
+
+	var rebuys uint
+	for _, player := range t.Players {
+		rebuys += player.Rebuys
+	}
+
+	return rebuys
+
+}
+
+func (t *Tournament) TotalAddOns() uint {
+
+	var addOns uint
+	for _, player := range t.Players {
+		addOns += player.AddOns
+	}
+
+	return addOns
+
+}
+
+// Collected is all of the money taken in buy ins, rebuys and add-ons
+func (t *Tournament) Collected() float64 {
+	return float64(t.Entrants())*t.BuyIn +
+		float64(t.TotalRebuys())*t.RebuyAmount +
+		float64(t.TotalAddOns())*t.AddOnAmount
+}
+
+// Rake is the part of Collected kept by the house. It never exceeds Collected
+func (t *Tournament) Rake() float64 {
+
+	collected := t.Collected()
+	if collected == 0 {
+		return 0
+	}
+
+	rake := collected*t.RakePercent/100 + t.RakeFixed
+
+	return math.Min(rake, collected)
+
+}
+
+// PrizePool is the money paid out to the players
+func (t *Tournament) PrizePool() float64 {
+	return t.Collected() - t.Rake()
+}
+
+// Payouts is what each paid place wins from the current prize pool
+func (t *Tournament) Payouts() ([]*Payout, error) {
+	return t.Payout.Payouts(t.Entrants(), t.PrizePool())
+}
+
+// PlayerInPosition returns the player that finished in position, or nil if nobody has yet
+func (t *Tournament) PlayerInPosition(position uint) *TournamentPlayer {
+
+	for _, player := range t.Players {
+		if player.Position == position {
+			return player
+		}
+	}
+
+	return nil
+
+}
+
+// Player returns the player with the id, or nil if they aren't registered
+func (t *Tournament) Player(id string) *TournamentPlayer {
+
+	for _, player := range t.Players {
+		if player.ID == id {
+			return player
+		}
+	}
+
+	return nil
+
+}
+
+// IsComplete reports whether every player but the winner has been eliminated
+func (t *Tournament) IsComplete() bool {
+	return len(t.Players) > 0 && t.Remaining() == 0
+}
+
+// Register adds a player to the tournament. A late registration makes the field one bigger, so everyone
+// already knocked out drops a place
+func (t *Tournament) Register(player *TournamentPlayer) error {
+
+	player.Name = strings.TrimSpace(player.Name)
+	if player.Name == "" {
+		return fmt.Errorf("player name cannot be empty")
+	}
+
+	if player.ID == "" {
+		return fmt.Errorf("player id cannot be empty")
+	}
+
+	if t.IsComplete() {
+		return fmt.Errorf("players cannot be registered once the tournament is complete")
+	}
+
+	for _, p := range t.Players {
+		if strings.EqualFold(p.Name, player.Name) {
+			return fmt.Errorf("%s is already registered", player.Name)
+		}
+	}
+
+	player.Position = 0
+	for _, p := range t.Players {
+		if !p.IsPlaying() {
+			p.Position++
+		}
+	}
+
+	t.Players = append(t.Players, player)
+
+	return nil
+
+}
+
+// Unregister removes a player that was registered by mistake. Players that have been eliminated
+// or have paid for rebuys or add-ons are part of the results and can't be removed
+func (t *Tournament) Unregister(playerID string) error {
+
+	for i, player := range t.Players {
+		if player.ID != playerID {
+			continue
+		}
+
+		if !player.IsPlaying() || player.Rebuys > 0 || player.AddOns > 0 {
+			return fmt.Errorf("%s has already played and cannot be removed", player.Name)
+		}
+
+		t.Players = append(t.Players[:i], t.Players[i+1:]...)
+
+		// The field is one smaller, so everyone already knocked out moves up a place
+		for _, p := range t.Players {
+			if !p.IsPlaying() {
+				p.Position--
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("player not found")
+
+}
+
+// Rebuy records a rebuy for the player during level, the 1 based number of the level being played
+func (t *Tournament) Rebuy(playerID string, level uint) error {
+
+	player := t.Player(playerID)
+	if player == nil {
+		return fmt.Errorf("player not found")
+	}
+
+	if t.RebuyAmount == 0 {
+		return fmt.Errorf("this tournament does not allow rebuys")
+	}
+
+	if t.RebuyUntilLevel > 0 && level > t.RebuyUntilLevel {
+		return fmt.Errorf("rebuys closed after level %d", t.RebuyUntilLevel)
+	}
+
+	if t.RebuyLimit > 0 && player.Rebuys >= t.RebuyLimit {
+		return fmt.Errorf("%s has already used all %d rebuys", player.Name, t.RebuyLimit)
+	}
+
+	if !player.IsPlaying() {
+		// A player busting out and buying straight back in keeps their seat
+		t.reinstate(player)
+	}
+
+	player.Rebuys++
+
+	return nil
+
+}
+
+// AddOn records an add-on for the player during level, the 1 based number of the level being played
+func (t *Tournament) AddOn(playerID string, level uint) error {
+
+	player := t.Player(playerID)
+	if player == nil {
+		return fmt.Errorf("player not found")
+	}
+
+	if t.AddOnAmount == 0 {
+		return fmt.Errorf("this tournament does not allow add-ons")
+	}
+
+	if t.AddOnUntilLevel > 0 && level > t.AddOnUntilLevel {
+		return fmt.Errorf("add-ons closed after level %d", t.AddOnUntilLevel)
+	}
+
+	if t.AddOnLimit > 0 && player.AddOns >= t.AddOnLimit {
+		return fmt.Errorf("%s has already taken all %d add-ons", player.Name, t.AddOnLimit)
+	}
+
+	if !player.IsPlaying() {
+		return fmt.Errorf("%s has been eliminated", player.Name)
+	}
+
+	player.AddOns++
+
+	return nil
+
+}
+
+// Eliminate knocks the player out, finishing in the best position not already taken. When only one
+// player is left they are recorded as the winner
+func (t *Tournament) Eliminate(playerID string, now time.Time) error {
+
+	player := t.Player(playerID)
+	if player == nil {
+		return fmt.Errorf("player not found")
+	}
+
+	if !player.IsPlaying() {
+		return fmt.Errorf("%s has already been eliminated", player.Name)
+	}
+
+	remaining := t.Remaining()
+	if remaining == 1 {
+		return fmt.Errorf("%s is the last player left", player.Name)
+	}
+
+	player.Position = uint(remaining)
+	player.EliminatedAt = &now
+
+	if remaining == 2 {
+		for _, winner := range t.Players {
+			if winner.IsPlaying() {
+				winner.Position = 1
+				winner.EliminatedAt = &now
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// reinstate puts an eliminated player back in. Everyone knocked out after them went out with one
+// more player still in, so they each drop a place
+func (t *Tournament) reinstate(player *TournamentPlayer) {
+
+	// The winner is only decided by the last elimination, so undo that first
+	if t.IsComplete() {
+		for _, p := range t.Players {
+			if p.Position == 1 {
+				p.Position = 0
+				p.EliminatedAt = nil
+			}
+		}
+	}
+
+	position := player.Position
+	for _, p := range t.Players {
+		if p.Position > 0 && p.Position < position {
+			p.Position++
+		}
+	}
+
+	player.Position = 0
+	player.EliminatedAt = nil
+
+}
@@ -0,0 +1,224 @@
+package poker
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestTournament returns a tournament with players p0 to p(n-1) registered
+func newTestTournament(t *testing.T, n int) *Tournament {
+	t.Helper()
+
+	tournament := &Tournament{BuyIn: 20, RebuyAmount: 20}
+	for i := 0; i < n; i++ {
+		err := tournament.Register(&TournamentPlayer{ID: fmt.Sprintf("p%d", i), Name: fmt.Sprintf("Player %d", i)})
+		if err != nil {
+			t.Fatalf("failed to register player %d: %s", i, err)
+		}
+	}
+
+	return tournament
+}
+
+func eliminate(t *testing.T, tournament *Tournament, playerID string) {
+	t.Helper()
+
+	err := tournament.Eliminate(playerID, time.Now())
+	if err != nil {
+		t.Fatalf("failed to eliminate %s: %s", playerID, err)
+	}
+}
+
+// expectPositions checks each player is in the position wanted, 0 for those missing from want, and that no two
+// players share a position
+func expectPositions(t *testing.T, tournament *Tournament, want map[string]uint) {
+	t.Helper()
+
+	seen := make(map[uint]string)
+	for _, player := range tournament.Players {
+		if player.Position != want[player.ID] {
+			t.Errorf("expected %s to be in position %d, got %d", player.ID, want[player.ID], player.Position)
+		}
+
+		if player.Position == 0 {
+			continue
+		}
+
+		if other, ok := seen[player.Position]; ok {
+			t.Errorf("%s and %s both finished in position %d", other, player.ID, player.Position)
+		}
+		seen[player.Position] = player.ID
+	}
+}
+
+func TestTournamentEliminate(t *testing.T) {
+
+	tournament := newTestTournament(t, 4)
+
+	eliminate(t, tournament, "p2")
+	eliminate(t, tournament, "p0")
+
+	if tournament.IsComplete() {
+		t.Fatalf("expected the tournament to carry on with 2 players left")
+	}
+
+	eliminate(t, tournament, "p3")
+
+	if !tournament.IsComplete() {
+		t.Fatalf("expected the tournament to be complete with 1 player left")
+	}
+
+	expectPositions(t, tournament, map[string]uint{"p0": 3, "p1": 1, "p2": 4, "p3": 2})
+
+	err := tournament.Eliminate("p1", time.Now())
+	if err == nil {
+		t.Errorf("expected the winner not to be eliminated")
+	}
+
+}
+
+// TestTournamentLateRegistration registers a player after others are out, each of whom then finished a place lower
+func TestTournamentLateRegistration(t *testing.T) {
+
+	tournament := newTestTournament(t, 5)
+
+	eliminate(t, tournament, "p0")
+
+	err := tournament.Register(&TournamentPlayer{ID: "p5", Name: "Player 5"})
+	if err != nil {
+		t.Fatalf("failed to register late player: %s", err)
+	}
+
+	expectPositions(t, tournament, map[string]uint{"p0": 6})
+
+	eliminate(t, tournament, "p1")
+	expectPositions(t, tournament, map[string]uint{"p0": 6, "p1": 5})
+
+	for _, id := range []string{"p2", "p5", "p3"} {
+		eliminate(t, tournament, id)
+	}
+
+	expectPositions(t, tournament, map[string]uint{"p0": 6, "p1": 5, "p2": 4, "p3": 2, "p4": 1, "p5": 3})
+
+	err = tournament.Register(&TournamentPlayer{ID: "p6", Name: "Player 6"})
+	if err == nil {
+		t.Errorf("expected registration to be refused once the tournament is complete")
+	}
+
+}
+
+func TestTournamentUnregister(t *testing.T) {
+
+	tournament := newTestTournament(t, 4)
+
+	eliminate(t, tournament, "p0")
+
+	err := tournament.Unregister("p0")
+	if err == nil {
+		t.Fatalf("expected an eliminated player not to be removed")
+	}
+
+	err = tournament.Unregister("p3")
+	if err != nil {
+		t.Fatalf("failed to unregister player: %s", err)
+	}
+
+	expectPositions(t, tournament, map[string]uint{"p0": 3})
+
+	eliminate(t, tournament, "p1")
+	expectPositions(t, tournament, map[string]uint{"p0": 3, "p1": 2, "p2": 1})
+
+}
+
+func TestTournamentRebuyReinstates(t *testing.T) {
+
+	tournament := newTestTournament(t, 4)
+
+	eliminate(t, tournament, "p0")
+	eliminate(t, tournament, "p1")
+
+	// p1 went out third to last, with p0 buying back in there are three players left ahead of them
+	err := tournament.Rebuy("p0", 1)
+	if err != nil {
+		t.Fatalf("failed to rebuy: %s", err)
+	}
+
+	expectPositions(t, tournament, map[string]uint{"p1": 4})
+
+	eliminate(t, tournament, "p0")
+	eliminate(t, tournament, "p2")
+
+	expectPositions(t, tournament, map[string]uint{"p0": 3, "p1": 4, "p2": 2, "p3": 1})
+
+	if got := tournament.Collected(); got != 100 {
+		t.Errorf("expected 4 buy ins and a rebuy to collect 100, got %v", got)
+	}
+
+}
+
+func TestTournamentRake(t *testing.T) {
+
+	tournament := newTestTournament(t, 10)
+	tournament.RakePercent = 10
+	tournament.RakeFixed = 5
+
+	if got := tournament.Rake(); got != 25 {
+		t.Errorf("expected 10%% of 200 plus 5 in rake, got %v", got)
+	}
+
+	if got := tournament.PrizePool(); got != 175 {
+		t.Errorf("expected a prize pool of 175, got %v", got)
+	}
+
+	tournament.RakeFixed = 500
+	if got := tournament.PrizePool(); got != 0 {
+		t.Errorf("expected rake never to take more than was collected, got a prize pool of %v", got)
+	}
+
+}
+
+// TestTournamentPositionsAreAPermutation plays out tournaments with a late registration after each number of
+// eliminations, checking the finishing positions are always 1 to the number of entrants
+func TestTournamentPositionsAreAPermutation(t *testing.T) {
+
+	for lateAfter := 0; lateAfter < 5; lateAfter++ {
+		t.Run(fmt.Sprintf("late after %d", lateAfter), func(t *testing.T) {
+
+			tournament := newTestTournament(t, 6)
+
+			out := 0
+			for !tournament.IsComplete() {
+				if out == lateAfter {
+					err := tournament.Register(&TournamentPlayer{ID: "late", Name: "Late"})
+					if err != nil {
+						t.Fatalf("failed to register late player: %s", err)
+					}
+				}
+
+				for _, player := range tournament.Players {
+					if player.IsPlaying() {
+						eliminate(t, tournament, player.ID)
+						break
+					}
+				}
+				out++
+			}
+
+			positions := make([]int, 0, len(tournament.Players))
+			for _, player := range tournament.Players {
+				positions = append(positions, int(player.Position))
+			}
+			sort.Ints(positions)
+
+			for i, position := range positions {
+				if position != i+1 {
+					t.Fatalf("expected positions 1 to %d, got %v", len(positions), positions)
+				}
+			}
+
+		})
+	}
+
+}
@@ -0,0 +1,413 @@
+package poker
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// TimerFormat is a file format timers can be exported to and imported from
+type TimerFormat string
+
+const (
+	TimerFormatJSON TimerFormat = "json"
+	TimerFormatYAML TimerFormat = "yaml"
+	// TimerFormatCSV has one level per row and no timer name, the name is given when importing
+	TimerFormatCSV TimerFormat = "csv"
+)
+
+func (tf TimerFormat) String() string {
+	return string(tf)
+}
+
+var allTimerFormats = []TimerFormat{TimerFormatJSON, TimerFormatYAML, TimerFormatCSV}
+
+func (tf TimerFormat) Valid() bool {
+	for _, f := range allTimerFormats {
+		if f == tf {
+			return true
+		}
+	}
+	return false
+}
+
+func (tf TimerFormat) ContentType() string {
+	switch tf {
+	case TimerFormatJSON:
+		return "application/json"
+	case TimerFormatYAML:
+		return "application/yaml"
+	case TimerFormatCSV:
+		return "text/csv"
+	}
+
+	return "application/octet-stream"
+}
+
+// TimerFormatFromFilename picks the format from a file's extension
+func TimerFormatFromFilename(name string) (TimerFormat, bool) {
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return TimerFormatJSON, true
+	case ".yaml", ".yml":
+		return TimerFormatYAML, true
+	case ".csv":
+		return TimerFormatCSV, true
+	}
+
+	return "", false
+
+}
+
+// csvHeader is the header row written to CSV exports. Imports match columns by these names in any order
+var csvHeader = []string{"type", "small_blind", "big_blind", "ante", "ante_type", "duration_min", "events"}
+
+// csvEventSeparator separates the events of a level within the events column of a CSV file
+const csvEventSeparator = ";"
+
+// timerDocument is the portable form of a timer. It leaves out ids, ownership and clock state so
+// a structure can be shared and imported as a brand new timer
+type timerDocument struct {
+	Name   string          `json:"name" yaml:"name"`
+	Levels []levelDocument `json:"levels" yaml:"levels"`
+}
+
+type levelDocument struct {
+	Type       LevelType `json:"type" yaml:"type"`
+	SmallBlind float64   `json:"small_blind,omitempty" yaml:"small_blind,omitempty"`
+	BigBlind   float64   `json:"big_blind,omitempty" yaml:"big_blind,omitempty"`
+	Ante       float64   `json:"ante,omitempty" yaml:"ante,omitempty"`
+	// AnteType is left out for levels without an ante, and files written before ante types existed
+	AnteType    AnteType     `json:"ante_type,omitempty" yaml:"ante_type,omitempty"`
+	DurationMin float64      `json:"duration_min" yaml:"duration_min"`
+	Events      []LevelEvent `json:"events,omitempty" yaml:"events,omitempty"`
+
+	// row is where the level was read from, see ImportRowError.Row
+	row int
+}
+
+// ImportError lists every level of an import that failed validation, so they can all be fixed at once
+type ImportError struct {
+	Rows []*ImportRowError
+}
+
+type ImportRowError struct {
+	// Row is the 1 based line of a CSV file, header included, or the 1 based level of a JSON or YAML file
+	Row int
+	Err error
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *ImportError) Error() string {
+
+	lines := make([]string, 0, len(e.Rows))
+	for _, row := range e.Rows {
+		lines = append(lines, row.Error())
+	}
+
+	return strings.Join(lines, "; ")
+
+}
+
+// EncodeTimer writes the timer's name and levels in format
+func EncodeTimer(w io.Writer, timer *Timer, format TimerFormat) error {
+
+	doc := timerDocument{Name: timer.Name, Levels: make([]levelDocument, 0, len(timer.Levels))}
+	for _, level := range timer.Levels {
+		var anteType AnteType
+		if level.Type == LevelTypeBlind && level.EffectiveAnteType() != AnteTypeNone {
+			anteType = level.EffectiveAnteType()
+		}
+
+		doc.Levels = append(doc.Levels, levelDocument{
+			Type:        level.Type,
+			SmallBlind:  level.SmallBlind,
+			BigBlind:    level.BigBlind,
+			Ante:        level.Ante,
+			AnteType:    anteType,
+			DurationMin: level.DurationMin,
+			Events:      level.Events,
+		})
+	}
+
+	switch format {
+	case TimerFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	case TimerFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		err := encoder.Encode(doc)
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+	case TimerFormatCSV:
+		cw := csv.NewWriter(w)
+		err := cw.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+
+		for _, level := range doc.Levels {
+			events := make([]string, 0, len(level.Events))
+			for _, event := range level.Events {
+				events = append(events, event.String())
+			}
+
+			err = cw.Write([]string{
+				level.Type.String(),
+				formatCSVNumber(level.SmallBlind),
+				formatCSVNumber(level.BigBlind),
+				formatCSVNumber(level.Ante),
+				level.AnteType.String(),
+				formatCSVNumber(level.DurationMin),
+				strings.Join(events, csvEventSeparator),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unsupported timer format %q", format)
+
+}
+
+// DecodeTimer reads a timer written in format. The timer and its levels are given new ids, and every level
+// is validated. When any level is invalid an *ImportError listing all of them is returned. The caller is
+// left to set the timer's owner, and its name when importing CSV
+func DecodeTimer(r io.Reader, format TimerFormat) (*Timer, error) {
+
+	var doc timerDocument
+	var importErr = new(ImportError)
+	switch format {
+	case TimerFormatJSON:
+		err := json.NewDecoder(r).Decode(&doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read json: %w", err)
+		}
+	case TimerFormatYAML:
+		err := yaml.NewDecoder(r).Decode(&doc)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read yaml: %w", err)
+		}
+	case TimerFormatCSV:
+		var err error
+		doc.Levels, importErr.Rows, err = decodeCSVLevels(r)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported timer format %q", format)
+	}
+
+	if len(doc.Levels) == 0 && len(importErr.Rows) == 0 {
+		return nil, fmt.Errorf("the file does not have any levels")
+	}
+
+	timer := &Timer{
+		ID:     uuid.New().String(),
+		Name:   strings.TrimSpace(doc.Name),
+		Levels: make([]*TimerLevel, 0, len(doc.Levels)),
+	}
+
+	for i, l := range doc.Levels {
+		level := &TimerLevel{
+			ID:          uuid.New().String(),
+			Type:        LevelType(strings.ToLower(strings.TrimSpace(l.Type.String()))),
+			TimerID:     timer.ID,
+			SmallBlind:  l.SmallBlind,
+			BigBlind:    l.BigBlind,
+			Ante:        l.Ante,
+			AnteType:    AnteType(strings.ToLower(strings.TrimSpace(l.AnteType.String()))),
+			DurationMin: l.DurationMin,
+			DurationSec: l.DurationMin * 60,
+		}
+
+		for _, event := range l.Events {
+			level.Events = append(level.Events, LevelEvent(strings.ToLower(strings.TrimSpace(event.String()))))
+		}
+
+		// JSON can't hold NaN or infinity, but YAML can
+		err := l.finite()
+		if err == nil {
+			err = level.Validate()
+		}
+		if err != nil {
+			row := l.row
+			if row == 0 {
+				row = i + 1
+			}
+			importErr.Rows = append(importErr.Rows, &ImportRowError{Row: row, Err: err})
+			continue
+		}
+
+		timer.Levels = append(timer.Levels, level)
+	}
+
+	if len(importErr.Rows) > 0 {
+		sort.Slice(importErr.Rows, func(i, j int) bool {
+			return importErr.Rows[i].Row < importErr.Rows[j].Row
+		})
+		return nil, importErr
+	}
+
+	timer.RenumberLevels()
+
+	return timer, nil
+
+}
+
+// decodeCSVLevels reads the levels from a CSV file. Rows with cells that can't be read are returned
+// as row errors, an error is only returned when the file itself can't be read
+func decodeCSVLevels(r io.Reader) ([]levelDocument, []*ImportRowError, error) {
+
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	// Spreadsheets often leave trailing empty cells off some rows
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")] = i
+	}
+
+	for _, required := range []string{"type", "duration_min"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("the csv header is missing the %s column, expected: %s", required, strings.Join(csvHeader, ","))
+		}
+	}
+
+	var rowErrs []*ImportRowError
+	var levels []levelDocument
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+
+		// Blank lines are skipped and quoted cells can span lines, so the row is the line the record started on
+		row, _ := cr.FieldPos(0)
+
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		number := func(column string) float64 {
+			value := cell(column)
+			if value == "" || err != nil {
+				return 0
+			}
+
+			n, parseErr := strconv.ParseFloat(value, 64)
+			switch {
+			case errors.Is(parseErr, strconv.ErrRange):
+				err = fmt.Errorf("%s is out of range, got %q", column, value)
+			case parseErr != nil:
+				err = fmt.Errorf("%s must be a number, got %q", column, value)
+			default:
+				err = finiteNumber(column, n)
+			}
+			if err != nil {
+				return 0
+			}
+			return n
+		}
+
+		level := levelDocument{
+			Type:        LevelType(cell("type")),
+			SmallBlind:  number("small_blind"),
+			BigBlind:    number("big_blind"),
+			Ante:        number("ante"),
+			AnteType:    AnteType(cell("ante_type")),
+			DurationMin: number("duration_min"),
+			row:         row,
+		}
+
+		for _, event := range strings.Split(cell("events"), csvEventSeparator) {
+			if event = strings.TrimSpace(event); event != "" {
+				level.Events = append(level.Events, LevelEvent(event))
+			}
+		}
+
+		if err != nil {
+			rowErrs = append(rowErrs, &ImportRowError{Row: row, Err: err})
+			continue
+		}
+
+		levels = append(levels, level)
+	}
+
+	return levels, rowErrs, nil
+
+}
+
+// finite returns an error for the first of the level's numbers that is NaN or infinite
+func (l levelDocument) finite() error {
+
+	for _, column := range []struct {
+		name  string
+		value float64
+	}{
+		{"small_blind", l.SmallBlind},
+		{"big_blind", l.BigBlind},
+		{"ante", l.Ante},
+		{"duration_min", l.DurationMin},
+	} {
+		err := finiteNumber(column.name, column.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+
+}
+
+func finiteNumber(column string, n float64) error {
+
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return fmt.Errorf("%s must be a finite number", column)
+	}
+
+	return nil
+
+}
+
+func formatCSVNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,170 @@
+package poker
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTimerRoundTrip(t *testing.T) {
+
+	timer := &Timer{
+		ID:   "original",
+		Name: "Friday Night",
+		Levels: []*TimerLevel{
+			{Type: LevelTypeBlind, SmallBlind: 25, BigBlind: 50, DurationMin: 20},
+			{Type: LevelTypeBlind, SmallBlind: 50, BigBlind: 100, Ante: 10, DurationMin: 20, Events: []LevelEvent{LevelEventRebuysEnd}},
+			{Type: LevelTypeBreak, DurationMin: 10, Events: []LevelEvent{LevelEventLateRegistrationEnds, LevelEventAddOnAvailable}},
+			{Type: LevelTypeBlind, SmallBlind: 100, BigBlind: 200, Ante: 200, AnteType: AnteTypeBigBlind, DurationMin: 15.5},
+		},
+	}
+
+	for _, format := range allTimerFormats {
+		t.Run(format.String(), func(t *testing.T) {
+
+			var buf bytes.Buffer
+			err := EncodeTimer(&buf, timer, format)
+			if err != nil {
+				t.Fatalf("failed to encode timer: %s", err)
+			}
+
+			decoded, err := DecodeTimer(&buf, format)
+			if err != nil {
+				t.Fatalf("failed to decode timer: %s", err)
+			}
+
+			if decoded.ID == timer.ID {
+				t.Errorf("expected the imported timer to be given a new id")
+			}
+
+			// CSV files don't carry the name, it is given when importing
+			if format != TimerFormatCSV && decoded.Name != timer.Name {
+				t.Errorf("expected the name %q, got %q", timer.Name, decoded.Name)
+			}
+
+			if len(decoded.Levels) != len(timer.Levels) {
+				t.Fatalf("expected %d levels, got %d", len(timer.Levels), len(decoded.Levels))
+			}
+
+			for i, want := range timer.Levels {
+				got := decoded.Levels[i]
+				if got.Type != want.Type || got.SmallBlind != want.SmallBlind || got.BigBlind != want.BigBlind ||
+					got.Ante != want.Ante || got.EffectiveAnteType() != want.EffectiveAnteType() ||
+					got.DurationMin != want.DurationMin || got.DurationSec != want.DurationMin*60 {
+					t.Errorf("level %d: expected %+v, got %+v", i+1, want, got)
+				}
+
+				if len(want.Events) > 0 && !reflect.DeepEqual(got.Events, want.Events) {
+					t.Errorf("level %d: expected the events %v, got %v", i+1, want.Events, got.Events)
+				}
+
+				if got.TimerID != decoded.ID || got.ID == "" {
+					t.Errorf("level %d: expected a new id on the imported timer, got %q on %q", i+1, got.ID, got.TimerID)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestDecodeTimerMalformedRows(t *testing.T) {
+
+	tt := []struct {
+		name   string
+		format TimerFormat
+		input  string
+		// want maps each row expected to be refused to part of its error
+		want map[int]string
+	}{
+		{
+			name:   "cells that aren't numbers",
+			format: TimerFormatCSV,
+			input:  "type,small_blind,big_blind,duration_min\nblind,25,50,20\nblind,fifty,100,20\nblind,100,200,ten\n",
+			want:   map[int]string{3: "small_blind must be a number", 4: "duration_min must be a number"},
+		},
+		{
+			name:   "numbers that aren't finite",
+			format: TimerFormatCSV,
+			input:  "type,small_blind,big_blind,duration_min\nblind,NaN,50,20\nblind,25,Inf,20\nblind,25,50,-infinity\n",
+			want:   map[int]string{2: "small_blind must be a finite number", 3: "big_blind must be a finite number", 4: "duration_min must be a finite number"},
+		},
+		{
+			name:   "numbers that overflow",
+			format: TimerFormatCSV,
+			input:  "type,small_blind,big_blind,duration_min\nblind,25,1e400,20\n",
+			want:   map[int]string{2: "big_blind is out of range"},
+		},
+		{
+			name:   "rows that fail validation",
+			format: TimerFormatCSV,
+			input:  "type,small_blind,big_blind,duration_min\nblind,100,50,20\nlunch,,,30\nbreak,,,0\n",
+			want:   map[int]string{2: "small blind cannot be greater than big blind", 3: "type is not a valid type", 4: "duration must be greater than 0"},
+		},
+		{
+			name:   "lines after blank lines and cells over several lines",
+			format: TimerFormatCSV,
+			input:  "type,small_blind,big_blind,duration_min,events\n\nblind,25,50,20,\"rebuys-end;\nadd-on-available\"\n\nblind,x,100,20,\n",
+			want:   map[int]string{6: "small_blind must be a number"},
+		},
+		{
+			name:   "yaml numbers that aren't finite",
+			format: TimerFormatYAML,
+			input:  "name: Infinite\nlevels:\n  - type: blind\n    small_blind: 25\n    big_blind: 50\n    duration_min: .inf\n  - type: blind\n    small_blind: .nan\n    big_blind: 100\n    duration_min: 20\n",
+			want:   map[int]string{1: "duration_min must be a finite number", 2: "small_blind must be a finite number"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+
+			_, err := DecodeTimer(strings.NewReader(tc.input), tc.format)
+
+			var importErr *ImportError
+			if !errors.As(err, &importErr) {
+				t.Fatalf("expected an import error, got %v", err)
+			}
+
+			if len(importErr.Rows) != len(tc.want) {
+				t.Errorf("expected %d rows to be refused, got %s", len(tc.want), importErr)
+			}
+
+			for _, row := range importErr.Rows {
+				want, ok := tc.want[row.Row]
+				if !ok || !strings.Contains(row.Err.Error(), want) {
+					t.Errorf("row %d: expected an error containing %q, got %q", row.Row, want, row.Err)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestDecodeTimerUnreadable(t *testing.T) {
+
+	tt := []struct {
+		name   string
+		format TimerFormat
+		input  string
+	}{
+		{name: "empty csv", format: TimerFormatCSV, input: ""},
+		{name: "csv header without a duration", format: TimerFormatCSV, input: "type,small_blind,big_blind\nblind,25,50\n"},
+		{name: "csv without levels", format: TimerFormatCSV, input: "type,duration_min\n"},
+		{name: "unterminated quote", format: TimerFormatCSV, input: "type,duration_min\n\"blind,20\n"},
+		{name: "json that isn't json", format: TimerFormatJSON, input: "{"},
+		{name: "unsupported format", format: TimerFormat("xml"), input: "<timer/>"},
+	}
+
+	for _, tc := range tt {
+		_, err := DecodeTimer(strings.NewReader(tc.input), tc.format)
+
+		var importErr *ImportError
+		if err == nil || errors.As(err, &importErr) {
+			t.Errorf("%s: expected the file to be refused as a whole, got %v", tc.name, err)
+		}
+	}
+
+}
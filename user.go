@@ -1,14 +1,28 @@
 package poker
 
 import (
+	"context"
 	"time"
 )
 
+// UserRepository stores users. User and UserByEmail return nil when no user matches
+type UserRepository interface {
+	User(ctx context.Context, id string) (*User, error)
+	UserByEmail(ctx context.Context, email string) (*User, error)
+	SaveUser(ctx context.Context, user *User) error
+	DeleteUser(ctx context.Context, id string) error
+}
+
 type User struct {
 	ID         string
 	EmployeeID *uint
 	Email      string
 	Name       string
-	CreatedAt  time.Time
-	UpdateAt   time.Time
+	// PasswordHash is the bcrypt or argon2id hash of the user's password, empty for users who sign in some other way
+	PasswordHash string
+	// MagicLinkSentAt is when the last magic link the user signed in with was sent, nil until they first use one.
+	// Links sent at or before it have been used, so they are refused
+	MagicLinkSentAt *time.Time
+	CreatedAt       time.Time
+	UpdateAt        time.Time
 }
@@ -0,0 +1,331 @@
+package poker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookRepository stores the webhooks subscribed to timers and a log of what was sent to each. Webhook returns
+// nil when no webhook matches
+type WebhookRepository interface {
+	Webhook(ctx context.Context, id string) (*Webhook, error)
+	WebhooksByTimerID(ctx context.Context, timerID string) ([]*Webhook, error)
+	SaveWebhook(ctx context.Context, webhook *Webhook) error
+	// DeleteWebhook deletes the webhook along with its deliveries
+	DeleteWebhook(ctx context.Context, id string) error
+	// WebhookDeliveries returns up to limit of the webhook's deliveries, the most recent first
+	WebhookDeliveries(ctx context.Context, webhookID string, limit int) ([]*WebhookDelivery, error)
+	// SaveWebhookDelivery saves the delivery, stores may drop a webhook's oldest deliveries to make room
+	SaveWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
+}
+
+// WebhookEvent is something that happened to a timer which a webhook can be sent
+type WebhookEvent string
+
+const (
+	// WebhookEventLevelChanged is sent whenever the timer moves to another level, whether the clock ran out or an
+	// operator moved it
+	WebhookEventLevelChanged WebhookEvent = "level.changed"
+	// WebhookEventBreakStarted is sent when the level the timer moves to is a break
+	WebhookEventBreakStarted WebhookEvent = "break.started"
+	// WebhookEventBreakEnded is sent when the timer moves on from a break
+	WebhookEventBreakEnded WebhookEvent = "break.ended"
+	// WebhookEventTimerCompleted is sent when the final level runs out or is skipped
+	WebhookEventTimerCompleted WebhookEvent = "timer.completed"
+	// WebhookEventTimerReset is sent when an operator restarts the current level
+	WebhookEventTimerReset WebhookEvent = "timer.reset"
+	// WebhookEventPing is only sent by the dashboard's test button, every webhook receives it
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+func (we WebhookEvent) String() string {
+	return string(we)
+}
+
+// AllWebhookEvents is every event a webhook can subscribe to, in the order they are offered
+var AllWebhookEvents = []WebhookEvent{
+	WebhookEventLevelChanged, WebhookEventBreakStarted, WebhookEventBreakEnded, WebhookEventTimerCompleted, WebhookEventTimerReset,
+}
+
+func (we WebhookEvent) Valid() bool {
+	for _, e := range AllWebhookEvents {
+		if e == we {
+			return true
+		}
+	}
+	return false
+}
+
+var strAllWebhookEvents = []string{
+	WebhookEventLevelChanged.String(), WebhookEventBreakStarted.String(), WebhookEventBreakEnded.String(),
+	WebhookEventTimerCompleted.String(), WebhookEventTimerReset.String(),
+}
+
+// MaxTimerWebhooks is how many webhooks a timer can have
+const MaxTimerWebhooks = 5
+
+// webhookSecretPrefix starts every webhook secret so it is easy to tell apart from an API token
+const webhookSecretPrefix = "whsec_"
+
+// Webhook sends a timer's events to a URL as they happen
+type Webhook struct {
+	ID      string
+	TimerID string
+	// UserID is the user who added the webhook
+	UserID string
+	URL    string
+	// Secret signs every payload sent, see SignWebhookPayload
+	Secret    string
+	Events    []WebhookEvent
+	CreatedAt time.Time
+}
+
+// NewWebhook creates a webhook for the timer with a new secret
+func NewWebhook(timerID, userID, rawURL string, events []WebhookEvent, now time.Time) (*Webhook, error) {
+
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	webhook := &Webhook{
+		ID:        uuid.New().String(),
+		TimerID:   timerID,
+		UserID:    userID,
+		URL:       strings.TrimSpace(rawURL),
+		Secret:    webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b),
+		Events:    events,
+		CreatedAt: now,
+	}
+
+	err = webhook.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+
+}
+
+func (w Webhook) Validate() error {
+
+	if w.ID == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	if w.TimerID == "" {
+		return fmt.Errorf("timer id cannot be empty")
+	}
+
+	if w.Secret == "" {
+		return fmt.Errorf("secret cannot be empty")
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("url must be a full http or https address, such as https://example.com/hooks/poker")
+	}
+
+	if u.User != nil {
+		return fmt.Errorf("url cannot contain a username or password, verify the signature instead")
+	}
+
+	if len(w.Events) == 0 {
+		return fmt.Errorf("choose at least one event")
+	}
+
+	for i, event := range w.Events {
+		if !event.Valid() {
+			return fmt.Errorf("event is not a valid event, expected one of: %s", strings.Join(strAllWebhookEvents, ","))
+		}
+
+		for _, other := range w.Events[:i] {
+			if other == event {
+				return fmt.Errorf("the %s event can only be chosen once", event)
+			}
+		}
+	}
+
+	return nil
+
+}
+
+// Subscribes reports whether the webhook is sent the event. Every webhook is sent pings
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+
+	if event == WebhookEventPing {
+		return true
+	}
+
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// WebhookPayload is the JSON body of every webhook request
+type WebhookPayload struct {
+	// ID is the delivery's id, which stays the same across retries so receivers can ignore repeats
+	ID        string       `json:"id"`
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"created_at"`
+	Timer     *APITimer    `json:"timer"`
+	// Level is the level the timer is on, PreviousLevel the one it was on before the event. Either is left out
+	// when there isn't one
+	Level         *APILevel `json:"level,omitempty"`
+	PreviousLevel *APILevel `json:"previous_level,omitempty"`
+}
+
+// NewWebhookPayload describes the event as of now, previous is the index of the level the timer was on before it,
+// or -1 when the event didn't move the timer
+func NewWebhookPayload(id string, event WebhookEvent, timer *Timer, previous int, now time.Time) *WebhookPayload {
+
+	payload := &WebhookPayload{
+		ID:        id,
+		Event:     event,
+		CreatedAt: now,
+		Timer:     NewAPITimer(timer, TimerRoleOwner, now),
+	}
+
+	if level := timer.Level(); level != nil {
+		payload.Level = NewAPILevel(level, int(timer.CurrentLevel))
+	}
+
+	if previous >= 0 && previous < len(timer.Levels) {
+		payload.PreviousLevel = NewAPILevel(timer.Levels[previous], previous)
+	}
+
+	return payload
+
+}
+
+const (
+	// WebhookSignatureHeader carries the payload's signature, see SignWebhookPayload
+	WebhookSignatureHeader = "X-Poker-Signature"
+	WebhookEventHeader     = "X-Poker-Event"
+	WebhookDeliveryHeader  = "X-Poker-Delivery"
+)
+
+// SignWebhookPayload is the value of the signature header sent with body at timestamp. It is t=<unix seconds>,
+// v1=<hex HMAC-SHA256 of "<unix seconds>.<body>" keyed with the secret>. The timestamp is signed so a receiver can
+// refuse requests replayed long after they were sent
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	return fmt.Sprintf("t=%s,v1=%s", unix, webhookSignature(secret, unix, body))
+
+}
+
+// VerifyWebhookSignature checks header is a signature of body made with the secret no more than tolerance before
+// now, for receivers written in Go
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || signature == "" {
+		return fmt.Errorf("signature header is malformed")
+	}
+
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp is outside of the tolerance")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(webhookSignature(secret, unix, body))) {
+		return fmt.Errorf("signature does not match")
+	}
+
+	return nil
+
+}
+
+func webhookSignature(secret, unix string, body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+
+}
+
+// WebhookDelivery is one event sent to a webhook, updated after every attempt to send it
+type WebhookDelivery struct {
+	ID        string
+	WebhookID string
+	Event     WebhookEvent
+	// Payload is the body that was sent
+	Payload string
+	// Attempts is how many times sending has been tried so far
+	Attempts int
+	// StatusCode is the status the URL responded to the last attempt with, 0 when it couldn't be reached
+	StatusCode int
+	// Error explains why the last attempt failed, empty once the delivery has succeeded
+	Error     string
+	Succeeded bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PlayPosition is where a timer is in its levels, kept from before a change to find the events the change fires
+type PlayPosition struct {
+	Level      uint
+	IsComplete bool
+}
+
+func (t *Timer) PlayPosition() PlayPosition {
+	return PlayPosition{Level: t.CurrentLevel, IsComplete: t.IsComplete}
+}
+
+// WebhookEventsSince lists the events the timer moving from position to where it is now fires
+func (t *Timer) WebhookEventsSince(from PlayPosition) []WebhookEvent {
+
+	var events []WebhookEvent
+
+	if t.CurrentLevel != from.Level && len(t.Levels) > 0 {
+		events = append(events, WebhookEventLevelChanged)
+
+		if int(from.Level) < len(t.Levels) && t.Levels[from.Level].Type == LevelTypeBreak {
+			events = append(events, WebhookEventBreakEnded)
+		}
+
+		if t.Level().Type == LevelTypeBreak {
+			events = append(events, WebhookEventBreakStarted)
+		}
+	}
+
+	if t.IsComplete && !from.IsComplete {
+		events = append(events, WebhookEventTimerCompleted)
+	}
+
+	return events
+
+}